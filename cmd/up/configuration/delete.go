@@ -27,16 +27,16 @@ import (
 	"github.com/upbound/up/internal/upbound"
 )
 
-// BeforeApply sets default values for the delete command, before assignment and validation.
-func (c *deleteCmd) BeforeApply() error {
-	c.prompter = input.NewPrompter()
-	return nil
-}
-
 // AfterApply accepts user input by default to confirm the delete operation.
-func (c *deleteCmd) AfterApply(cc *configurations.Client, cpc *controlplanes.Client, p pterm.TextPrinter, upCtx *upbound.Context) error {
+func (c *deleteCmd) AfterApply(cc *configurations.Client, cpc *controlplanes.Client, p pterm.TextPrinter, upCtx *upbound.Context, confirmer *input.Confirmer) error {
+	confirmation := input.Confirmation{
+		Target:   fmt.Sprintf("configuration %s/%s", upCtx.Account, c.Name),
+		Profile:  upCtx.ProfileName,
+		Prompt:   "Are you sure you want to delete this configuration?",
+		Approved: c.Force,
+	}
 	if c.Force {
-		return nil
+		return confirmer.Confirm(confirmation)
 	}
 	// Deleting a configuration can orphan any control planes that have it deployed.
 	// While the API will eventually return a 400 status, we can show the user
@@ -57,23 +57,15 @@ func (c *deleteCmd) AfterApply(cc *configurations.Client, cpc *controlplanes.Cli
 		return fmt.Errorf("this configuration is still in use by control plane(s): %v", deployedOn)
 	}
 
-	confirm, err := c.prompter.Prompt("Are you sure you want to delete this configuration? [y/n]", false)
-	if err != nil {
+	if err := confirmer.Confirm(confirmation); err != nil {
 		return err
 	}
-
-	if input.InputYes(confirm) {
-		p.Printfln("Deleting configuration %s. This cannot be undone,", c.Name)
-		return nil
-	}
-
-	return fmt.Errorf("operation canceled")
+	p.Printfln("Deleting configuration %s. This cannot be undone,", c.Name)
+	return nil
 }
 
 // deleteCmd deletes a single root configuration by name on Upbound.
 type deleteCmd struct {
-	prompter input.Prompter
-
 	Name string `arg:"" required:"" name:"The name of the configuration." predictor:"configs"`
 
 	Force bool `help:"Force deletion of the configuration." default:"false"`
@@ -0,0 +1,197 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/pterm/pterm"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/upbound/up-sdk-go/service/configurations"
+	cp "github.com/upbound/up-sdk-go/service/controlplanes"
+
+	"github.com/upbound/up/internal/controlplane"
+	"github.com/upbound/up/internal/controlplane/backup"
+	"github.com/upbound/up/internal/controlplane/cloud"
+	"github.com/upbound/up/internal/controlplane/space"
+	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/upterm"
+)
+
+const (
+	errFmtControlPlaneNotFound = "control plane %s not found"
+	errBuildControlPlaneClient = "failed to build control plane client"
+	errWriteBackup             = "failed to write backup"
+)
+
+// backupCmd exports the Crossplane state of a control plane to an archive.
+type backupCmd struct {
+	Name           string `arg:"" required:"" help:"Name of control plane." predictor:"ctps"`
+	Output         string `short:"o" type:"path" help:"Path of the backup archive. Defaults to <name>-backup.tar.gz."`
+	IncludeSecrets bool   `help:"Include the secrets referenced by the exported resources, e.g. the credentials of ProviderConfigs."`
+	Token          string `help:"API token used to authenticate. Required for Upbound Cloud; ignored otherwise."`
+
+	client ctpConnector
+}
+
+// AfterApply sets default values in command after assignment and validation.
+func (c *backupCmd) AfterApply(upCtx *upbound.Context) error {
+	if c.Output == "" {
+		c.Output = fmt.Sprintf("%s-backup.tar.gz", c.Name)
+	}
+	client, err := newConnector(upCtx, c.Token)
+	if err != nil {
+		return err
+	}
+	c.client = client
+	return nil
+}
+
+func (c *backupCmd) Help() string {
+	return `
+The backup command exports the Crossplane state of a control plane to a local
+archive: packages and their runtime configs, ProviderConfigs, composite resource
+definitions, compositions, managed resources, composite resources and claims.
+Managed resources keep their external-name annotations, so that a restore
+imports the existing external resources instead of creating new ones.
+Resources that are installed by Configurations are not exported; they are
+installed again when the Configurations are restored.
+
+Secrets referenced by the exported resources, e.g. the credentials of
+ProviderConfigs, are only included with --include-secrets. The archive then
+contains secret values and must be stored securely.
+
+Examples:
+  # Back up the control plane ctp1 to ctp1-backup.tar.gz.
+  up ctp backup ctp1
+
+  # Back up the control plane ctp1, including provider credentials.
+  up ctp backup ctp1 --include-secrets -o ctp1.tar.gz`
+}
+
+// Run executes the backup command.
+func (c *backupCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error {
+	client, err := controlPlaneClient(ctx, c.client, c.Name, upCtx)
+	if err != nil {
+		return err
+	}
+
+	var resources []backup.Resource
+	if err := upterm.WrapWithSuccessSpinner(
+		fmt.Sprintf("Exporting control plane %s", c.Name),
+		upterm.CheckmarkSuccessSpinner,
+		func() error {
+			resources, err = backup.NewExporter(client, backup.WithSecrets(c.IncludeSecrets)).Export(ctx)
+			return err
+		},
+	); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(c.Output, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.Wrap(err, errWriteBackup)
+	}
+	defer f.Close() //nolint:errcheck
+	m := backup.Metadata{ControlPlane: c.Name, Created: time.Now().UTC(), Secrets: c.IncludeSecrets}
+	if err := backup.Write(f, m, resources); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, errWriteBackup)
+	}
+
+	missing := 0
+	for _, r := range resources {
+		if r.Category == backup.CategoryManaged && !r.HasExternalName() {
+			missing++
+		}
+	}
+	if missing > 0 {
+		pterm.Warning.Printfln("%d managed resources have no external name and would be created again when restored.", missing)
+	}
+	if c.IncludeSecrets {
+		pterm.Warning.Printfln("%s contains secret values, store it securely.", c.Output)
+	}
+	p.Printfln("%d resources of %s backed up to %s", len(resources), c.Name, c.Output)
+	return nil
+}
+
+// newConnector returns a client that gets the kubeconfig of the control planes
+// of the current profile.
+func newConnector(upCtx *upbound.Context, token string) (ctpConnector, error) {
+	if upCtx.Profile.IsSpace() {
+		kubeconfig, err := upCtx.Profile.GetKubeClientConfig()
+		if err != nil {
+			return nil, err
+		}
+		client, err := dynamic.NewForConfig(kubeconfig)
+		if err != nil {
+			return nil, err
+		}
+		return space.New(client), nil
+	}
+
+	if token == "" {
+		return nil, fmt.Errorf("--token must be specified")
+	}
+	cfg, err := upCtx.BuildSDKConfig()
+	if err != nil {
+		return nil, err
+	}
+	return cloud.New(
+		cp.NewClient(cfg),
+		configurations.NewClient(cfg),
+		upCtx.Account,
+		cloud.WithToken(token),
+		cloud.WithProxyEndpoint(upCtx.ProxyEndpoint),
+	), nil
+}
+
+// controlPlaneClient builds a client for the API server of the named control
+// plane.
+func controlPlaneClient(ctx context.Context, c ctpConnector, name string, upCtx *upbound.Context) (dynamic.Interface, error) {
+	cfg, err := controlPlaneConfig(ctx, c, name, upCtx)
+	if err != nil {
+		return nil, err
+	}
+	client, err := dynamic.NewForConfig(cfg)
+	return client, errors.Wrap(err, errBuildControlPlaneClient)
+}
+
+// controlPlaneConfig builds the REST config for the API server of the named
+// control plane.
+func controlPlaneConfig(ctx context.Context, c ctpConnector, name string, upCtx *upbound.Context) (*rest.Config, error) {
+	kubeconfig, err := c.GetKubeConfig(ctx, name)
+	if controlplane.IsNotFound(err) {
+		return nil, errors.Errorf(errFmtControlPlaneNotFound, name)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errBuildControlPlaneClient)
+	}
+	cfg, err := clientcmd.NewDefaultClientConfig(*kubeconfig, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, errBuildControlPlaneClient)
+	}
+	cfg.WrapTransport = upCtx.WrapTransport
+	return cfg, nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/upbound/up/internal/controlplane"
+)
+
+const (
+	// maxSummary is the maximum number of control planes that are listed in
+	// the summary of a bulk operation.
+	maxSummary = 20
+
+	errNameOrSelector  = "either a control plane name or a selector must be specified, but not both"
+	errConcurrency     = "concurrency must be at least 1"
+	errFmtSelectorNone = "no control planes match %s"
+)
+
+// selectorFlags select the control planes of a bulk operation.
+type selectorFlags struct {
+	Selector      string `short:"l" help:"Select control planes by label selector, e.g. env=prod,team!=a."`
+	FieldSelector string `help:"Select control planes by field selector on status and configuration, e.g. status=Available."`
+	Concurrency   int    `default:"10" help:"Maximum number of selected control planes that are processed concurrently."`
+
+	filter controlplane.Filter
+}
+
+// parse parses the selectors and checks that either a name or selectors are
+// supplied.
+func (s *selectorFlags) parse(name string) error {
+	if (name == "") == (s.Selector == "" && s.FieldSelector == "") {
+		return errors.New(errNameOrSelector)
+	}
+	if s.Concurrency < 1 {
+		return errors.New(errConcurrency)
+	}
+	f, err := controlplane.ParseFilter(s.Selector, s.FieldSelector)
+	if err != nil {
+		return err
+	}
+	s.filter = f
+	return nil
+}
+
+// bulk returns true if control planes are selected by selectors.
+func (s *selectorFlags) bulk() bool {
+	return !s.filter.Empty()
+}
+
+// names returns the names of the selected control planes, or only the
+// supplied name if there are no selectors.
+func (s *selectorFlags) names(ctx context.Context, l ctpLister, name string) ([]string, error) {
+	if !s.bulk() {
+		return []string{name}, nil
+	}
+	ctps, err := l.List(ctx, s.filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(ctps) == 0 {
+		return nil, errors.Errorf(errFmtSelectorNone, s.filter)
+	}
+	names := make([]string, len(ctps))
+	for i, ctp := range ctps {
+		names[i] = ctp.Name
+	}
+	return names, nil
+}
+
+// forEach calls fn for each of the names, at most concurrency at a time. A
+// failure does not stop the other calls, the errors of all of them are
+// returned.
+func (s *selectorFlags) forEach(ctx context.Context, names []string, fn func(ctx context.Context, name string) error) error {
+	g := &errgroup.Group{}
+	g.SetLimit(s.Concurrency)
+	errs := make([]error, len(names))
+	for i, name := range names {
+		i, name := i, name
+		g.Go(func() error {
+			errs[i] = errors.Wrap(fn(ctx, name), name)
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return errors.Join(errs...)
+}
+
+// summary lists the names of the control planes of a bulk operation.
+func summary(names []string) string {
+	b := &strings.Builder{}
+	for i, name := range names {
+		if i == maxSummary {
+			fmt.Fprintf(b, "  ... and %d more\n", len(names)-maxSummary)
+			break
+		}
+		fmt.Fprintf(b, "  %s\n", name)
+	}
+	return b.String()
+}
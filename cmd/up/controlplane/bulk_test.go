@@ -0,0 +1,185 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/controlplane"
+)
+
+type mockLister struct {
+	ctps []*controlplane.Response
+}
+
+func (m *mockLister) List(_ context.Context, f controlplane.Filter) ([]*controlplane.Response, error) {
+	return f.Apply(m.ctps), nil
+}
+
+func TestSelectorNames(t *testing.T) {
+	l := &mockLister{ctps: []*controlplane.Response{
+		{Name: "ctp1", Labels: map[string]string{"env": "prod"}},
+		{Name: "ctp2", Labels: map[string]string{"env": "dev"}},
+		{Name: "ctp3", Labels: map[string]string{"env": "prod"}},
+	}}
+
+	type args struct {
+		name string
+		s    selectorFlags
+	}
+	type want struct {
+		names []string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Name": {
+			reason: "Without selectors only the named control plane should be returned.",
+			args: args{
+				name: "ctp2",
+				s:    selectorFlags{Concurrency: 1},
+			},
+			want: want{
+				names: []string{"ctp2"},
+			},
+		},
+		"Selector": {
+			reason: "The control planes matching the selector should be returned.",
+			args: args{
+				s: selectorFlags{Selector: "env=prod", Concurrency: 1},
+			},
+			want: want{
+				names: []string{"ctp1", "ctp3"},
+			},
+		},
+		"ErrorNoMatch": {
+			reason: "A selector that matches no control planes should be an error.",
+			args: args{
+				s: selectorFlags{Selector: "env=staging", Concurrency: 1},
+			},
+			want: want{
+				err: errors.Errorf(errFmtSelectorNone, "env=staging"),
+			},
+		},
+		"ErrorNameAndSelector": {
+			reason: "A name and a selector must not be supplied together.",
+			args: args{
+				name: "ctp1",
+				s:    selectorFlags{Selector: "env=prod", Concurrency: 1},
+			},
+			want: want{
+				err: errors.New(errNameOrSelector),
+			},
+		},
+		"ErrorNeither": {
+			reason: "Either a name or a selector must be supplied.",
+			args: args{
+				s: selectorFlags{Concurrency: 1},
+			},
+			want: want{
+				err: errors.New(errNameOrSelector),
+			},
+		},
+		"ErrorConcurrency": {
+			reason: "The concurrency must be positive.",
+			args: args{
+				s: selectorFlags{Selector: "env=prod"},
+			},
+			want: want{
+				err: errors.New(errConcurrency),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var names []string
+			err := tc.args.s.parse(tc.args.name)
+			if err == nil {
+				names, err = tc.args.s.names(context.Background(), l, tc.args.name)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nnames(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.names, names); diff != "" {
+				t.Errorf("\n%s\nnames(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSelectorForEach(t *testing.T) {
+	s := selectorFlags{Concurrency: 2}
+	names := []string{"ctp1", "ctp2", "ctp3", "ctp4"}
+
+	mu := sync.Mutex{}
+	running, maxRunning := 0, 0
+	called := map[string]bool{}
+	err := s.forEach(context.Background(), names, func(_ context.Context, name string) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		called[name] = true
+		mu.Unlock()
+
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+		if name == "ctp2" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+
+	if diff := cmp.Diff("ctp2: boom", fmt.Sprint(err)); diff != "" {
+		t.Errorf("forEach(...): -want error, +got error:\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]bool{"ctp1": true, "ctp2": true, "ctp3": true, "ctp4": true}, called); diff != "" {
+		t.Errorf("forEach(...): a failure should not stop the others: -want, +got:\n%s", diff)
+	}
+	if maxRunning > s.Concurrency {
+		t.Errorf("forEach(...): %d calls ran concurrently, want at most %d", maxRunning, s.Concurrency)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	names := make([]string, maxSummary+2)
+	for i := range names {
+		names[i] = fmt.Sprintf("ctp%d", i)
+	}
+	want := ""
+	for _, name := range names[:maxSummary] {
+		want += "  " + name + "\n"
+	}
+	want += "  ... and 2 more\n"
+
+	if diff := cmp.Diff(want, summary(names)); diff != "" {
+		t.Errorf("summary(...): -want, +got:\n%s", diff)
+	}
+}
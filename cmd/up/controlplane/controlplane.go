@@ -94,6 +94,11 @@ type Cmd struct {
 	Delete     deleteCmd     `cmd:"" help:"Delete a control plane."`
 	List       listCmd       `cmd:"" help:"List control planes for the account."`
 	Get        getCmd        `cmd:"" help:"Get a single control plane."`
+	Describe   describeCmd   `cmd:"" help:"Show the details of a control plane."`
+	Wait       waitCmd       `cmd:"" help:"Wait for a control plane to meet a condition."`
+	Backup     backupCmd     `cmd:"" help:"Back up the Crossplane state of a control plane to a local archive."`
+	Restore    restoreCmd    `cmd:"" help:"Restore a control plane backup to a control plane."`
+	Proxy      proxyCmd      `cmd:"" help:"Run local proxies to the API servers of control planes."`
 
 	Connector connector.Cmd `cmd:"" help:"Connect an App Cluster to a managed control plane."`
 
@@ -16,8 +16,10 @@ package controlplane
 
 import (
 	"context"
+	"time"
 
 	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/pterm/pterm"
 	"k8s.io/client-go/dynamic"
 
@@ -30,8 +32,13 @@ import (
 	"github.com/upbound/up/internal/upbound"
 )
 
+const (
+	errLabelsNotSupported = "labels and annotations are only supported for Space control planes"
+)
+
 type ctpCreator interface {
 	Create(ctx context.Context, name string, opts controlplane.Options) (*controlplane.Response, error)
+	controlplane.Watcher
 }
 
 // createCmd creates a control plane on Upbound.
@@ -44,13 +51,46 @@ type createCmd struct {
 	SecretName      string `help:"The name of the control plane's secret. Defaults to 'kubeconfig-{control plane name}'. Only applicable for Space control planes."`
 	SecretNamespace string `default:"default" help:"The name of namespace for the control plane's secret. Only applicable for Space control planes."`
 
+	Labels      map[string]string `name:"label" help:"Label of the control plane, e.g. --label env=prod. Can be repeated. Only applicable for Space control planes."`
+	Annotations map[string]string `name:"annotation" help:"Annotation of the control plane, e.g. --annotation owner=team-a. Can be repeated. Only applicable for Space control planes."`
+
+	Spec specFlags `embed:""`
+
+	Wait    bool          `help:"Wait for the control plane to become ready."`
+	Timeout time.Duration `default:"10m" help:"How long to wait for the control plane to become ready."`
+
+	spec   map[string]any
 	client ctpCreator
 }
 
+func (c *createCmd) Help() string {
+	return `
+Create a control plane. Space control planes can be configured at creation with
+a spec file and flags, e.g. their Crossplane version, resources, features or
+initial packages. The spec is validated against the ControlPlane API installed
+in the Space before the control plane is created.
+
+Examples:
+
+  # Create a control plane with a specific Crossplane version.
+  up ctp create my-ctp --crossplane-version=1.14.1-up.1
+
+  # Create a control plane from a spec file, overriding a field.
+  up ctp create my-ctp -f spec.yaml --set crossplane.autoUpgrade.channel=None`
+}
+
 // AfterApply sets default values in command after assignment and validation.
 func (c *createCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) error {
 
 	if upCtx.Profile.IsSpace() {
+		if !c.Spec.empty() {
+			spec, err := c.Spec.spec()
+			if err != nil {
+				return err
+			}
+			c.spec = spec
+		}
+
 		kubeconfig, err := upCtx.Profile.GetKubeClientConfig()
 		if err != nil {
 			return err
@@ -61,6 +101,12 @@ func (c *createCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) er
 		}
 		c.client = space.New(client)
 	} else {
+		if len(c.Labels) > 0 || len(c.Annotations) > 0 {
+			return errors.New(errLabelsNotSupported)
+		}
+		if !c.Spec.empty() {
+			return errors.New(errSpecNotSupported)
+		}
 		cfg, err := upCtx.BuildSDKConfig()
 		if err != nil {
 			return err
@@ -83,6 +129,9 @@ func (c *createCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound
 		controlplane.Options{
 			SecretName:      c.SecretName,
 			SecretNamespace: c.SecretNamespace,
+			Labels:          c.Labels,
+			Annotations:     c.Annotations,
+			Spec:            c.spec,
 		},
 	)
 	if err != nil {
@@ -90,5 +139,13 @@ func (c *createCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound
 	}
 
 	p.Printfln("%s created", c.Name)
-	return nil
+	if !c.Wait {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+	return controlplane.Wait(ctx, c.client, c.Name, controlplane.WaitFor{Type: "Ready", Status: "True"}, func(state string) {
+		p.Printfln("%s: %s", c.Name, state)
+	})
 }
@@ -16,6 +16,8 @@ package controlplane
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/pterm/pterm"
@@ -27,22 +29,53 @@ import (
 	"github.com/upbound/up/internal/controlplane"
 	"github.com/upbound/up/internal/controlplane/cloud"
 	"github.com/upbound/up/internal/controlplane/space"
+	"github.com/upbound/up/internal/input"
 	"github.com/upbound/up/internal/upbound"
 )
 
 type ctpDeleter interface {
 	Delete(ctx context.Context, name string) error
+	ctpLister
+	controlplane.Watcher
 }
 
 // deleteCmd deletes a control plane on Upbound.
 type deleteCmd struct {
-	Name string `arg:"" help:"Name of control plane." predictor:"ctps"`
+	Name string `arg:"" optional:"" help:"Name of control plane. Omit to delete the control planes matching --selector and --field-selector." predictor:"ctps"`
 
-	client ctpDeleter
+	Selection selectorFlags `embed:""`
+
+	Wait    bool          `help:"Wait for the control plane to be deleted."`
+	Timeout time.Duration `default:"10m" help:"How long to wait for the control plane to be deleted."`
+
+	client    ctpDeleter
+	confirmer *input.Confirmer
+	profile   string
+}
+
+func (c *deleteCmd) Help() string {
+	return `
+Delete a control plane by name, or all control planes matching a label selector
+and/or a field selector on status and configuration. A summary of the selected
+control planes is shown, and must be confirmed, before they are deleted.
+
+Examples:
+
+  # Delete the control plane my-ctp.
+  up ctp delete my-ctp
+
+  # Delete all control planes of the dev environment, 5 at a time, and wait
+  # until they are gone.
+  up ctp delete -l env=dev --concurrency=5 --wait`
 }
 
 // AfterApply sets default values in command after assignment and validation.
-func (c *deleteCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) error {
+func (c *deleteCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context, confirmer *input.Confirmer) error {
+	if err := c.Selection.parse(c.Name); err != nil {
+		return err
+	}
+	c.confirmer = confirmer
+	c.profile = upCtx.ProfileName
 
 	if upCtx.Profile.IsSpace() {
 		kubeconfig, err := upCtx.Profile.GetKubeClientConfig()
@@ -68,14 +101,63 @@ func (c *deleteCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) er
 }
 
 // Run executes the delete command.
-func (c *deleteCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error {
-	if err := c.client.Delete(ctx, c.Name); err != nil {
+func (c *deleteCmd) Run(ctx context.Context, p pterm.TextPrinter) error {
+	names, err := c.Selection.names(ctx, c.client, c.Name)
+	if err != nil {
+		return err
+	}
+	if c.Selection.bulk() {
+		p.Printf("The following %d control planes match %s:\n%s", len(names), c.Selection.filter, summary(names))
+	}
+	if err := c.confirmer.Confirm(c.confirmation(names)); err != nil {
+		return err
+	}
+
+	return c.Selection.forEach(ctx, names, func(ctx context.Context, name string) error {
+		return c.delete(ctx, p, name)
+	})
+}
+
+// confirmation returns the confirmation for deleting the named control
+// planes. Only bulk deletes are confirmed, deleting a single control plane by
+// name is only recorded in the audit log, as it never prompted.
+func (c *deleteCmd) confirmation(names []string) input.Confirmation {
+	if !c.Selection.bulk() {
+		return input.Confirmation{
+			Target:      "control plane " + c.Name,
+			Profile:     c.profile,
+			NotRequired: true,
+		}
+	}
+	return input.Confirmation{
+		Target:  fmt.Sprintf("%d control planes matching %s", len(names), c.Selection.filter),
+		Profile: c.profile,
+		Prompt:  fmt.Sprintf("Are you sure you want to delete these %d control planes? All of their resources will be deleted.", len(names)),
+	}
+}
+
+// delete deletes the named control plane and waits for it to be gone if
+// requested.
+func (c *deleteCmd) delete(ctx context.Context, p pterm.TextPrinter, name string) error {
+	if err := c.client.Delete(ctx, name); err != nil {
 		if controlplane.IsNotFound(err) {
-			p.Printfln("Control plane %s not found", c.Name)
+			p.Printfln("Control plane %s not found", name)
 			return nil
 		}
 		return err
 	}
-	p.Printfln("%s deleted", c.Name)
+	if !c.Wait {
+		p.Printfln("%s deleted", name)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+	if err := controlplane.Wait(ctx, c.client, name, controlplane.WaitFor{Delete: true}, func(state string) {
+		p.Printfln("%s: %s", name, state)
+	}); err != nil {
+		return err
+	}
+	p.Printfln("%s deleted", name)
 	return nil
 }
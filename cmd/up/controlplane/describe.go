@@ -0,0 +1,233 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/pterm/pterm"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/upbound/up-sdk-go/service/configurations"
+	cp "github.com/upbound/up-sdk-go/service/controlplanes"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/controlplane"
+	"github.com/upbound/up/internal/controlplane/cloud"
+	"github.com/upbound/up/internal/controlplane/space"
+	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/upterm"
+)
+
+const (
+	notAvailable = "n/a"
+
+	noTokenNote = "use --token to show the installed packages"
+)
+
+type ctpDescriber interface {
+	Describe(ctx context.Context, name string) (*controlplane.Description, error)
+	ctpConnector
+}
+
+// describeCmd shows the details of a control plane.
+type describeCmd struct {
+	Name  string `arg:"" required:"" help:"Name of control plane." predictor:"ctps"`
+	Token string `help:"API token used to list the installed packages of Upbound Cloud control planes; ignored otherwise."`
+
+	stdout io.Writer
+	client ctpDescriber
+}
+
+func (c *describeCmd) Help() string {
+	return `
+Show the details of a control plane: its conditions, creation time, Crossplane
+version, connection endpoint and the health of its installed packages. For
+Space control planes, the most recent events of the ControlPlane object are
+shown as well.
+
+Use --format=json or --format=yaml for machine readable output.`
+}
+
+// AfterApply sets default values in command after assignment and validation.
+func (c *describeCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) error {
+	c.stdout = kongCtx.Stdout
+
+	if upCtx.Profile.IsSpace() {
+		kubeconfig, err := upCtx.Profile.GetKubeClientConfig()
+		if err != nil {
+			return err
+		}
+		client, err := dynamic.NewForConfig(kubeconfig)
+		if err != nil {
+			return err
+		}
+		c.client = space.New(client)
+		return nil
+	}
+
+	cfg, err := upCtx.BuildSDKConfig()
+	if err != nil {
+		return err
+	}
+	c.client = cloud.New(
+		cp.NewClient(cfg),
+		configurations.NewClient(cfg),
+		upCtx.Account,
+		cloud.WithToken(c.Token),
+		cloud.WithProxyEndpoint(upCtx.ProxyEndpoint),
+	)
+	return nil
+}
+
+// Run executes the describe command.
+func (c *describeCmd) Run(ctx context.Context, printer upterm.ObjectPrinter, p pterm.TextPrinter, upCtx *upbound.Context) error {
+	d, err := c.client.Describe(ctx, c.Name)
+	if controlplane.IsNotFound(err) {
+		p.Printfln("Control plane %s not found", c.Name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// The endpoint and packages are not available before the control plane
+	// is ready, which is not an error.
+	if kubeconfig, err := c.client.GetKubeConfig(ctx, c.Name); err == nil {
+		d.Endpoint = endpoint(kubeconfig)
+	}
+	note := noTokenNote
+	if upCtx.Profile.IsSpace() || c.Token != "" {
+		note = ""
+		client, err := controlPlaneClient(ctx, c.client, c.Name, upCtx)
+		if err == nil {
+			d.Packages, err = controlplane.GetPackages(ctx, client)
+		}
+		if err != nil {
+			note = fmt.Sprintf("unavailable: %v", err)
+		}
+	}
+
+	if printer.Format != config.Default {
+		return printer.Print(d, nil, nil)
+	}
+	if printer.Quiet {
+		return nil
+	}
+	return printDescription(c.stdout, d, upCtx.Profile.IsSpace(), note)
+}
+
+// endpoint returns the API server of the current context of the kubeconfig.
+func endpoint(kubeconfig *api.Config) string {
+	kctx, ok := kubeconfig.Contexts[kubeconfig.CurrentContext]
+	if !ok {
+		return ""
+	}
+	if cluster, ok := kubeconfig.Clusters[kctx.Cluster]; ok {
+		return cluster.Server
+	}
+	return ""
+}
+
+// printDescription prints the description in a format similar to kubectl
+// describe. The note is printed instead of the packages if it is not empty.
+func printDescription(w io.Writer, d *controlplane.Description, isSpace bool, note string) error {
+	fields := [][]string{
+		{"Name", d.Name},
+		{"ID", d.ID},
+		{"Created", notAvailable},
+		{"Status", d.Status},
+		{"Crossplane Version", orNotAvailable(d.CrossplaneVersion)},
+		{"Endpoint", orNotAvailable(d.Endpoint)},
+	}
+	if d.Created != nil {
+		fields[2][1] = d.Created.UTC().Format(time.RFC3339)
+	}
+	if isSpace {
+		fields = append(fields,
+			[]string{"Message", d.Message},
+			[]string{"Connection Secret", fmt.Sprintf("%s/%s", d.ConnNamespace, d.ConnName)},
+		)
+	} else {
+		fields = append(fields, []string{"Configuration", fmt.Sprintf("%s (%s)", d.Cfg, d.CfgStatus)})
+	}
+	for _, f := range fields {
+		if _, err := fmt.Fprintf(w, "%-20s%s\n", f[0]+":", f[1]); err != nil {
+			return err
+		}
+	}
+
+	conditions := [][]string{{"TYPE", "STATUS", "REASON", "MESSAGE"}}
+	for _, cnd := range d.Conditions {
+		conditions = append(conditions, []string{cnd.Type, cnd.Status, cnd.Reason, cnd.Message})
+	}
+	if err := printSection(w, "Conditions", conditions, ""); err != nil {
+		return err
+	}
+
+	packages := [][]string{{"KIND", "NAME", "PACKAGE", "INSTALLED", "HEALTHY"}}
+	for _, pkg := range d.Packages {
+		packages = append(packages, []string{pkg.Kind, pkg.Name, pkg.Package, strconv.FormatBool(pkg.Installed), strconv.FormatBool(pkg.Healthy)})
+	}
+	if err := printSection(w, "Packages", packages, note); err != nil {
+		return err
+	}
+
+	if !isSpace {
+		return nil
+	}
+	events := [][]string{{"TYPE", "REASON", "AGE", "COUNT", "MESSAGE"}}
+	for _, e := range d.Events {
+		events = append(events, []string{e.Type, e.Reason, duration.HumanDuration(time.Since(e.LastSeen)), strconv.Itoa(int(e.Count)), e.Message})
+	}
+	return printSection(w, "Events", events, "")
+}
+
+// printSection prints a titled table, or the note or <none> if it is empty.
+func printSection(w io.Writer, title string, data [][]string, note string) error {
+	if _, err := fmt.Fprintf(w, "\n%s:\n", title); err != nil {
+		return err
+	}
+	if note == "" && len(data) == 1 {
+		note = "none"
+	}
+	if note != "" {
+		_, err := fmt.Fprintf(w, "  <%s>\n", note)
+		return err
+	}
+	for i := range data {
+		data[i][0] = "  " + data[i][0]
+	}
+	table, err := pterm.DefaultTable.WithSeparator("   ").WithHasHeader().WithData(data).Srender()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(w, table)
+	return err
+}
+
+func orNotAvailable(s string) string {
+	if s == "" {
+		return notAvailable
+	}
+	return s
+}
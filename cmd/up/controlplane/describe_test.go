@@ -0,0 +1,125 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controlplane
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/controlplane"
+)
+
+func TestPrintDescription(t *testing.T) {
+	created := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	type args struct {
+		d       *controlplane.Description
+		isSpace bool
+		note    string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   string
+	}{
+		"Space": {
+			reason: "Space control planes should be described with their connection secret and events.",
+			args: args{
+				d: &controlplane.Description{
+					Response: controlplane.Response{
+						Name:          "ctp1",
+						ID:            "mxp1",
+						Status:        "Available",
+						ConnName:      "kubeconfig-ctp1",
+						ConnNamespace: "default",
+						Conditions:    []controlplane.Condition{{Type: "Ready", Status: "True", Reason: "Available"}},
+					},
+					Created:           &created,
+					CrossplaneVersion: "1.14.1-up.1",
+					Endpoint:          "https://1.2.3.4",
+					Packages: []controlplane.Package{
+						{Kind: "Provider", Name: "provider-aws", Package: "xpkg.upbound.io/upbound/provider-aws:v0.40.0", Installed: true, Healthy: true},
+					},
+				},
+				isSpace: true,
+			},
+			want: `Name:               ctp1
+ID:                 mxp1
+Created:            2023-11-01T12:00:00Z
+Status:             Available
+Crossplane Version: 1.14.1-up.1
+Endpoint:           https://1.2.3.4
+Message:            
+Connection Secret:  default/kubeconfig-ctp1
+
+Conditions:
+  TYPE    STATUS   REASON      MESSAGE
+  Ready   True     Available   
+
+Packages:
+  KIND       NAME           PACKAGE                                        INSTALLED   HEALTHY
+  Provider   provider-aws   xpkg.upbound.io/upbound/provider-aws:v0.40.0   true        true
+
+Events:
+  <none>
+`,
+		},
+		"Cloud": {
+			reason: "Cloud control planes should be described with their configuration, and the note instead of packages.",
+			args: args{
+				d: &controlplane.Description{
+					Response: controlplane.Response{
+						Name:      "ctp1",
+						ID:        "00000000-0000-0000-0000-000000000000",
+						Status:    "ready",
+						Cfg:       "cfg1",
+						CfgStatus: "ready",
+					},
+				},
+				note: noTokenNote,
+			},
+			want: `Name:               ctp1
+ID:                 00000000-0000-0000-0000-000000000000
+Created:            n/a
+Status:             ready
+Crossplane Version: n/a
+Endpoint:           n/a
+Configuration:      cfg1 (ready)
+
+Conditions:
+  <none>
+
+Packages:
+  <use --token to show the installed packages>
+`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if err := printDescription(b, tc.args.d, tc.args.isSpace, tc.args.note); err != nil {
+				t.Fatalf("printDescription(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want, b.String()); diff != "" {
+				t.Errorf("\n%s\nprintDescription(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
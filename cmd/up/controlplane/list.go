@@ -32,16 +32,25 @@ import (
 )
 
 type ctpLister interface {
-	List(ctx context.Context) ([]*controlplane.Response, error)
+	List(ctx context.Context, f controlplane.Filter) ([]*controlplane.Response, error)
 }
 
 // listCmd list control planes in an account on Upbound.
 type listCmd struct {
+	Selector      string `short:"l" help:"Only list control planes matching the label selector, e.g. env=prod,team!=a. Only applicable for Space control planes."`
+	FieldSelector string `help:"Only list control planes matching the field selector on status and configuration, e.g. status=Available."`
+
+	filter controlplane.Filter
 	client ctpLister
 }
 
 // AfterApply sets default values in command after assignment and validation.
 func (c *listCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) error {
+	f, err := controlplane.ParseFilter(c.Selector, c.FieldSelector)
+	if err != nil {
+		return err
+	}
+	c.filter = f
 
 	if upCtx.Profile.IsSpace() {
 		kubeconfig, err := upCtx.Profile.GetKubeClientConfig()
@@ -70,7 +79,7 @@ func (c *listCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) erro
 
 // Run executes the list command.
 func (c *listCmd) Run(ctx context.Context, printer upterm.ObjectPrinter, p pterm.TextPrinter, upCtx *upbound.Context) error {
-	l, err := c.client.List(ctx)
+	l, err := c.client.List(ctx, c.filter)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,173 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controlplane
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/pterm/pterm"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/upbound/up/internal/controlplane/proxy"
+	"github.com/upbound/up/internal/upbound"
+)
+
+const (
+	// proxyShutdownTimeout is how long active requests may take to complete
+	// when the proxies are stopped.
+	proxyShutdownTimeout = 3 * time.Second
+
+	// proxyKubeconfigPattern is the name pattern of temporary kubeconfigs.
+	proxyKubeconfigPattern = "up-ctp-proxy-*.yaml"
+
+	errPortMultiple    = "--port can only be used with a single control plane"
+	errWriteKubeconfig = "failed to write kubeconfig"
+	errFmtProxyStopped = "proxy for control plane %s stopped"
+)
+
+// proxyCmd serves local proxies to the API servers of control planes.
+type proxyCmd struct {
+	Names      []string `arg:"" help:"Names of the control planes." predictor:"ctps"`
+	Port       int      `help:"Local port of the proxy. Defaults to a random free port. Only applicable for a single control plane."`
+	Kubeconfig string   `type:"path" help:"Path the kubeconfig of the proxies is written to. The file must not exist. Defaults to a temporary file."`
+	Token      string   `help:"API token used to authenticate. Required for Upbound Cloud; ignored otherwise."`
+
+	client ctpConnector
+}
+
+func (c *proxyCmd) Help() string {
+	return `
+Run local proxies to the API servers of control planes, until interrupted. Each
+proxy listens on localhost and authenticates to its control plane with the
+credentials of the current profile. The proxies accept only requests with a
+random token that is written to a kubeconfig for them, with a context per
+control plane. Your kubeconfig is not modified, and the kubeconfig of the
+proxies is removed when they are stopped.
+
+Examples:
+
+  # Proxy the control plane ctp1 and use it with kubectl.
+  up ctp proxy ctp1
+  KUBECONFIG=<printed path> kubectl get providers
+
+  # Proxy two control planes, each with its own context.
+  up ctp proxy ctp1 ctp2 --kubeconfig=./proxies.yaml`
+}
+
+// AfterApply sets default values in command after assignment and validation.
+func (c *proxyCmd) AfterApply(upCtx *upbound.Context) error {
+	if c.Port != 0 && len(c.Names) > 1 {
+		return errors.New(errPortMultiple)
+	}
+	client, err := newConnector(upCtx, c.Token)
+	if err != nil {
+		return err
+	}
+	c.client = client
+	return nil
+}
+
+// Run executes the proxy command.
+func (c *proxyCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error {
+	// The proxies run until interrupted. Replace the handler of the CLI, which
+	// exits immediately, so that the kubeconfig of the proxies is removed.
+	signal.Reset(os.Interrupt, syscall.SIGTERM)
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	servers := make([]*proxy.Server, 0, len(c.Names))
+	defer func() {
+		shutdownProxies(servers)
+	}()
+
+	stopped := make(chan *proxy.Server, len(c.Names))
+	for _, name := range c.Names {
+		cfg, err := controlPlaneConfig(ctx, c.client, name, upCtx)
+		if err != nil {
+			return err
+		}
+		s, err := proxy.New(name, cfg, c.Port)
+		if err != nil {
+			return err
+		}
+		servers = append(servers, s)
+		go func() {
+			_ = s.Serve()
+			stopped <- s
+		}()
+	}
+
+	path, err := c.writeKubeconfig(servers)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path) //nolint:errcheck
+
+	for _, s := range servers {
+		p.Printfln("Proxying control plane %s at %s", s.Name(), s.URL())
+	}
+	p.Printfln("\nUse the proxies with:\n\n  export KUBECONFIG=%s\n\nPress Ctrl+C to stop.", path)
+
+	select {
+	case <-ctx.Done():
+		p.Println("Stopping proxies")
+		return nil
+	case s := <-stopped:
+		return errors.Errorf(errFmtProxyStopped, s.Name())
+	}
+}
+
+// writeKubeconfig writes the kubeconfig of the proxies to the configured path
+// or to a temporary file, and returns the path.
+func (c *proxyCmd) writeKubeconfig(servers []*proxy.Server) (string, error) {
+	b, err := clientcmd.Write(*proxy.Kubeconfig(servers...))
+	if err != nil {
+		return "", errors.Wrap(err, errWriteKubeconfig)
+	}
+
+	var f *os.File
+	if c.Kubeconfig != "" {
+		f, err = os.OpenFile(c.Kubeconfig, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	} else {
+		f, err = os.CreateTemp("", proxyKubeconfigPattern)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, errWriteKubeconfig)
+	}
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return "", errors.Wrap(err, errWriteKubeconfig)
+	}
+	return f.Name(), nil
+}
+
+// shutdownProxies stops the proxies, giving active requests a moment to
+// complete.
+func shutdownProxies(servers []*proxy.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), proxyShutdownTimeout)
+	defer cancel()
+	for _, s := range servers {
+		_ = s.Shutdown(ctx)
+	}
+}
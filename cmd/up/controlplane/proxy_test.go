@@ -0,0 +1,78 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controlplane
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestWriteKubeconfig(t *testing.T) {
+	existing := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(existing, []byte("keep"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		reason  string
+		path    string
+		wantErr bool
+	}{
+		"Temporary": {
+			reason: "Without a path the kubeconfig should be written to a temporary file.",
+		},
+		"Path": {
+			reason: "The kubeconfig should be written to the supplied path.",
+			path:   filepath.Join(t.TempDir(), "proxies.yaml"),
+		},
+		"Existing": {
+			reason:  "An existing file must not be overwritten.",
+			path:    existing,
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := &proxyCmd{Kubeconfig: tc.path}
+			path, err := c.writeKubeconfig(nil)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("\n%s\nwriteKubeconfig(...): unexpected error: %v", tc.reason, err)
+			}
+			if tc.wantErr {
+				if b, _ := os.ReadFile(existing); string(b) != "keep" {
+					t.Errorf("\n%s\nwriteKubeconfig(...): existing file was modified", tc.reason)
+				}
+				return
+			}
+			defer os.Remove(path) //nolint:errcheck
+			if tc.path != "" && path != tc.path {
+				t.Errorf("\n%s\nwriteKubeconfig(...): wrote %s, want %s", tc.reason, path, tc.path)
+			}
+			fi, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("\n%s\nStat(...): %v", tc.reason, err)
+			}
+			if fi.Mode().Perm() != 0o600 {
+				t.Errorf("\n%s\nwriteKubeconfig(...): mode %v, want 0600", tc.reason, fi.Mode().Perm())
+			}
+			if _, err := clientcmd.LoadFromFile(path); err != nil {
+				t.Errorf("\n%s\nwriteKubeconfig(...): invalid kubeconfig: %v", tc.reason, err)
+			}
+		})
+	}
+}
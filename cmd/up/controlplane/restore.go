@@ -0,0 +1,128 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/controlplane/backup"
+	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/upterm"
+)
+
+const (
+	errReadBackup = "failed to read backup"
+)
+
+// restoreCmd restores a backup to a control plane.
+type restoreCmd struct {
+	Name    string        `arg:"" required:"" help:"Name of the control plane to restore to." predictor:"ctps"`
+	Archive string        `arg:"" type:"existingfile" help:"Path of the backup archive."`
+	Timeout time.Duration `default:"10m" help:"How long to wait for the types of restored resources to be served, e.g. while their providers are installed."`
+	Token   string        `help:"API token used to authenticate. Required for Upbound Cloud; ignored otherwise."`
+
+	client ctpConnector
+}
+
+// AfterApply sets default values in command after assignment and validation.
+func (c *restoreCmd) AfterApply(upCtx *upbound.Context) error {
+	client, err := newConnector(upCtx, c.Token)
+	if err != nil {
+		return err
+	}
+	c.client = client
+	return nil
+}
+
+func (c *restoreCmd) Help() string {
+	return `
+The restore command re-applies a backup created with 'up ctp backup' to a
+control plane, which is usually a new one created with 'up ctp create'.
+Resources are created in dependency order: namespaces, secrets, packages,
+ProviderConfigs, composite resource definitions, compositions, managed
+resources, composite resources and finally claims. Resources whose types are
+not served yet, e.g. while their provider is being installed, are retried
+until --timeout.
+
+Managed resources are restored with their external-name annotations, and before
+the composite resources that compose them, so that the existing external
+resources are imported instead of created again. Stop the source control plane
+from reconciling them, e.g. by deleting it with its resources orphaned, before
+restoring. Resources that already exist in the control plane are left
+unchanged, so a restore can be repeated.
+
+Examples:
+  # Restore the backup of ctp1 to the control plane ctp2.
+  up ctp restore ctp2 ctp1-backup.tar.gz`
+}
+
+// Run executes the restore command.
+func (c *restoreCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error {
+	f, err := os.Open(c.Archive)
+	if err != nil {
+		return errors.Wrap(err, errReadBackup)
+	}
+	defer f.Close() //nolint:errcheck
+	a, err := backup.Read(f)
+	if err != nil {
+		return err
+	}
+
+	client, err := controlPlaneClient(ctx, c.client, c.Name, upCtx)
+	if err != nil {
+		return err
+	}
+	r := backup.NewRestorer(client, backup.WithTimeout(c.Timeout))
+
+	byCategory := map[backup.Category][]backup.Resource{}
+	steps := 0
+	for _, rs := range a.Resources {
+		if len(byCategory[rs.Category]) == 0 {
+			steps++
+		}
+		byCategory[rs.Category] = append(byCategory[rs.Category], rs)
+	}
+
+	total := backup.Result{}
+	step := 0
+	for _, cat := range backup.Categories {
+		resources := byCategory[cat]
+		if len(resources) == 0 {
+			continue
+		}
+		step++
+		if err := upterm.WrapWithSuccessSpinner(
+			upterm.StepCounter(fmt.Sprintf("Restoring %d %s", len(resources), cat), step, steps),
+			upterm.CheckmarkSuccessSpinner,
+			func() error {
+				res, err := r.Restore(ctx, resources)
+				total.Created += res.Created
+				total.Existing += res.Existing
+				return err
+			},
+		); err != nil {
+			return err
+		}
+	}
+
+	p.Printfln("Backup of %s restored to %s: %d resources created, %d already existed", a.Metadata.ControlPlane, c.Name, total.Created, total.Existing)
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controlplane
+
+import (
+	"os"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"sigs.k8s.io/yaml"
+
+	"github.com/upbound/up/internal/install/helm"
+	"github.com/upbound/up/internal/resources"
+)
+
+const (
+	errReadSpecFile     = "failed to read control plane spec file"
+	errSpecNotSupported = "a control plane spec is only supported for Space control planes"
+)
+
+// specFlags configure the spec of a Space control plane.
+type specFlags struct {
+	SpecFile          string            `short:"f" type:"existingfile" help:"YAML file with the spec of the control plane, or a complete ControlPlane manifest. Only applicable for Space control planes."`
+	Set               map[string]string `help:"Set a field of the control plane spec, e.g. --set crossplane.autoUpgrade.channel=Rapid. Overrides the spec file. Only applicable for Space control planes."`
+	CrossplaneVersion string            `help:"Crossplane version of the control plane. Only applicable for Space control planes."`
+	CrossplaneChannel string            `help:"Crossplane auto-upgrade channel of the control plane, e.g. None, Patch, Stable or Rapid. Only applicable for Space control planes."`
+}
+
+// empty returns true if no spec is configured.
+func (f *specFlags) empty() bool {
+	return f.SpecFile == "" && len(f.Set) == 0 && f.CrossplaneVersion == "" && f.CrossplaneChannel == ""
+}
+
+// spec returns the configured spec. The spec file is overridden by --set,
+// which is overridden by the dedicated flags.
+func (f *specFlags) spec() (map[string]any, error) {
+	spec := map[string]any{}
+	if f.SpecFile != "" {
+		b, err := os.ReadFile(f.SpecFile)
+		if err != nil {
+			return nil, errors.Wrap(err, errReadSpecFile)
+		}
+		if err := yaml.Unmarshal(b, &spec); err != nil {
+			return nil, errors.Wrap(err, errReadSpecFile)
+		}
+		if spec["kind"] == resources.ControlPlaneGVK.Kind {
+			spec, _ = spec["spec"].(map[string]any)
+		}
+		if spec == nil {
+			spec = map[string]any{}
+		}
+	}
+
+	spec, err := helm.NewParser(spec, f.Set).Parse()
+	if err != nil {
+		return nil, err
+	}
+	p := fieldpath.Pave(spec)
+	if f.CrossplaneVersion != "" {
+		if err := p.SetString("crossplane.version", f.CrossplaneVersion); err != nil {
+			return nil, err
+		}
+	}
+	if f.CrossplaneChannel != "" {
+		if err := p.SetString("crossplane.autoUpgrade.channel", f.CrossplaneChannel); err != nil {
+			return nil, err
+		}
+	}
+	return p.UnstructuredContent(), nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controlplane
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSpecFlags(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	bare := write("spec.yaml", `
+crossplane:
+  version: 1.14.1-up.1
+  autoUpgrade:
+    channel: Stable
+`)
+	manifest := write("ctp.yaml", `
+apiVersion: spaces.upbound.io/v1beta1
+kind: ControlPlane
+metadata:
+  name: ctp1
+spec:
+  crossplane:
+    version: 1.14.1-up.1
+`)
+
+	missing := filepath.Join(dir, "missing.yaml")
+	_, errMissing := os.ReadFile(missing)
+
+	type want struct {
+		spec map[string]any
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		flags  specFlags
+		want   want
+	}{
+		"SpecFile": {
+			reason: "A spec file should be used as the spec.",
+			flags:  specFlags{SpecFile: bare},
+			want: want{
+				spec: map[string]any{
+					"crossplane": map[string]any{"version": "1.14.1-up.1", "autoUpgrade": map[string]any{"channel": "Stable"}},
+				},
+			},
+		},
+		"Manifest": {
+			reason: "The spec of a ControlPlane manifest should be used as the spec.",
+			flags:  specFlags{SpecFile: manifest},
+			want: want{
+				spec: map[string]any{
+					"crossplane": map[string]any{"version": "1.14.1-up.1"},
+				},
+			},
+		},
+		"Overrides": {
+			reason: "Set values should override the spec file, and dedicated flags should override set values.",
+			flags: specFlags{
+				SpecFile:          bare,
+				Set:               map[string]string{"crossplane.autoUpgrade.channel": "Rapid", "crossplane.version": "1.13.0"},
+				CrossplaneVersion: "1.14.2-up.1",
+			},
+			want: want{
+				spec: map[string]any{
+					"crossplane": map[string]any{"version": "1.14.2-up.1", "autoUpgrade": map[string]any{"channel": "Rapid"}},
+				},
+			},
+		},
+		"Flags": {
+			reason: "The dedicated flags should be supported without a spec file.",
+			flags:  specFlags{CrossplaneChannel: "None"},
+			want: want{
+				spec: map[string]any{
+					"crossplane": map[string]any{"autoUpgrade": map[string]any{"channel": "None"}},
+				},
+			},
+		},
+		"ErrorReadSpecFile": {
+			reason: "Errors reading the spec file should be returned.",
+			flags:  specFlags{SpecFile: missing},
+			want: want{
+				err: errors.Wrap(errMissing, errReadSpecFile),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.flags.spec()
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nspec(): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.spec, got); diff != "" {
+				t.Errorf("\n%s\nspec(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
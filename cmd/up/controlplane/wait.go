@@ -0,0 +1,120 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controlplane
+
+import (
+	"context"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/pterm/pterm"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/upbound/up-sdk-go/service/configurations"
+	cp "github.com/upbound/up-sdk-go/service/controlplanes"
+
+	"github.com/upbound/up/internal/controlplane"
+	"github.com/upbound/up/internal/controlplane/cloud"
+	"github.com/upbound/up/internal/controlplane/space"
+	"github.com/upbound/up/internal/upbound"
+)
+
+type ctpWaiter interface {
+	ctpLister
+	controlplane.Watcher
+}
+
+// waitCmd waits for a control plane to meet a condition.
+type waitCmd struct {
+	Name    string        `arg:"" optional:"" help:"Name of control plane. Omit to wait for the control planes matching --selector and --field-selector." predictor:"ctps"`
+	For     string        `default:"condition=Ready" help:"The condition to wait for, either delete or condition=<type>[=<status>]."`
+	Timeout time.Duration `default:"10m" help:"How long to wait for the condition."`
+
+	Selection selectorFlags `embed:""`
+
+	waitFor controlplane.WaitFor
+	client  ctpWaiter
+}
+
+func (c *waitCmd) Help() string {
+	return `
+Wait for a control plane to meet a condition, similar to kubectl wait. Each
+transition of the condition is printed as it happens.
+
+Examples:
+
+  # Wait for a control plane to become ready.
+  up ctp wait my-ctp --for=condition=Ready
+
+  # Wait up to 2 minutes for a control plane to be deleted.
+  up ctp wait my-ctp --for=delete --timeout=2m
+
+  # Wait for all control planes of the prod environment to become ready.
+  up ctp wait -l env=prod`
+}
+
+// AfterApply sets default values in command after assignment and validation.
+func (c *waitCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) error {
+	if err := c.Selection.parse(c.Name); err != nil {
+		return err
+	}
+	f, err := controlplane.ParseWaitFor(c.For)
+	if err != nil {
+		return err
+	}
+	c.waitFor = f
+
+	if upCtx.Profile.IsSpace() {
+		kubeconfig, err := upCtx.Profile.GetKubeClientConfig()
+		if err != nil {
+			return err
+		}
+		client, err := dynamic.NewForConfig(kubeconfig)
+		if err != nil {
+			return err
+		}
+		c.client = space.New(client)
+	} else {
+		cfg, err := upCtx.BuildSDKConfig()
+		if err != nil {
+			return err
+		}
+		ctpclient := cp.NewClient(cfg)
+		cfgclient := configurations.NewClient(cfg)
+
+		c.client = cloud.New(ctpclient, cfgclient, upCtx.Account)
+	}
+	return nil
+}
+
+// Run executes the wait command.
+func (c *waitCmd) Run(ctx context.Context, p pterm.TextPrinter) error {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	names, err := c.Selection.names(ctx, c.client, c.Name)
+	if err != nil {
+		return err
+	}
+	return c.Selection.forEach(ctx, names, func(ctx context.Context, name string) error {
+		if err := controlplane.Wait(ctx, c.client, name, c.waitFor, func(state string) {
+			p.Printfln("%s: %s", name, state)
+		}); err != nil {
+			return err
+		}
+		p.Printfln("%s is %s", name, c.waitFor)
+		return nil
+	})
+}
@@ -16,6 +16,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -39,6 +40,7 @@ import (
 	"github.com/upbound/up/cmd/up/xpls"
 	"github.com/upbound/up/internal/config"
 	"github.com/upbound/up/internal/feature"
+	"github.com/upbound/up/internal/input"
 	"github.com/upbound/up/internal/upterm"
 	"github.com/upbound/up/internal/version"
 
@@ -80,6 +82,15 @@ func (c *cli) AfterApply(ctx *kong.Context) error { //nolint:unparam
 
 	ctx.Bind(printer)
 	ctx.Bind(c.Quiet)
+
+	opts := []input.ConfirmerOption{
+		input.WithYes(bool(c.Yes)),
+		input.WithCommand(ctx.Command()),
+	}
+	if path, err := config.GetAuditLogPath(); err == nil {
+		opts = append(opts, input.WithAuditLog(input.NewFileAuditLog(path)))
+	}
+	ctx.Bind(input.NewConfirmer(opts...))
 	return nil
 }
 
@@ -94,10 +105,11 @@ func (c *cli) BeforeReset(ctx *kong.Context, p *kong.Path) error {
 }
 
 type cli struct {
-	Format  config.Format    `name:"format" enum:"default,json,yaml" default:"default" help:"Format for get/list commands. Can be: json, yaml, default"`
+	Format  config.Format    `name:"format" enum:"default,json,yaml,csv" default:"default" help:"Format for get/list commands. Can be: json, yaml, csv, default"`
 	Version versionFlag      `short:"v" name:"version" help:"Print version and exit."`
 	Quiet   config.QuietFlag `short:"q" name:"quiet" help:"Suppress all output."`
 	Pretty  bool             `name:"pretty" help:"Pretty print output."`
+	Yes     config.YesFlag   `short:"y" name:"yes" help:"Answer yes to all confirmation prompts. Required to run destructive commands non-interactively."`
 
 	License licenseCmd `cmd:"" help:"Print Up license information."`
 
@@ -176,7 +188,7 @@ func main() {
 	}
 
 	kongCtx, err := parser.Parse(os.Args[1:])
-	parser.FatalIfErrorf(err)
+	fatalIfErrorf(parser, err)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	sigCh := make(chan os.Signal, 1)
@@ -189,5 +201,17 @@ func main() {
 	}()
 
 	kongCtx.BindTo(ctx, (*context.Context)(nil))
-	kongCtx.FatalIfErrorf(kongCtx.Run())
+	fatalIfErrorf(parser, kongCtx.Run())
+}
+
+// fatalIfErrorf terminates with an error message if err is not nil. Errors that
+// carry an exit code, such as declined confirmations, exit with that code so
+// that automation can tell them apart.
+func fatalIfErrorf(k *kong.Kong, err error) {
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) {
+		fmt.Fprintf(k.Stderr, "%s: error: %s\n", k.Model.Name, err)
+		k.Exit(exitErr.ExitCode())
+	}
+	k.FatalIfErrorf(err)
 }
@@ -23,37 +23,25 @@ import (
 	"github.com/upbound/up-sdk-go/service/organizations"
 
 	"github.com/upbound/up/internal/input"
+	"github.com/upbound/up/internal/upbound"
 )
 
-// BeforeApply sets default values for the delete command, before assignment and validation.
-func (c *deleteCmd) BeforeApply() error {
-	c.prompter = input.NewPrompter()
-	return nil
-}
-
 // AfterApply accepts user input by default to confirm the delete operation.
-func (c *deleteCmd) AfterApply(p pterm.TextPrinter) error {
-	if c.Force {
-		return nil
-	}
-
-	confirm, err := c.prompter.Prompt("Are you sure you want to delete this organization? [y/n]", false)
-	if err != nil {
+func (c *deleteCmd) AfterApply(p pterm.TextPrinter, upCtx *upbound.Context, confirmer *input.Confirmer) error {
+	if err := confirmer.Confirm(input.Confirmation{
+		Target:   fmt.Sprintf("organization %s", c.Name),
+		Profile:  upCtx.ProfileName,
+		Prompt:   "Are you sure you want to delete this organization?",
+		Approved: c.Force,
+	}); err != nil {
 		return err
 	}
-
-	if input.InputYes(confirm) {
-		p.Printfln("Deleting organization %s. This cannot be undone.", c.Name)
-		return nil
-	}
-
-	return fmt.Errorf("operation canceled")
+	p.Printfln("Deleting organization %s. This cannot be undone.", c.Name)
+	return nil
 }
 
 // deleteCmd deletes an organization on Upbound.
 type deleteCmd struct {
-	prompter input.Prompter
-
 	Name string `arg:"" required:"" help:"Name of organization." predictor:"orgs"`
 
 	Force bool `help:"Force deletion of the organization." default:"false"`
@@ -32,8 +32,6 @@ import (
 // If the user has been invited (but not yet joined) the invite is removed.
 // If the user is a member of the organization, the user is removed.
 type removeCmd struct {
-	prompter input.Prompter
-
 	OrgName string `arg:"" required:"" help:"Name of the organization."`
 	User    string `arg:"" required:"" help:"Username or email of the user to remove."`
 
@@ -44,28 +42,14 @@ const (
 	errUserNotFound = "user not found"
 )
 
-// BeforeApply sets default values for the delete command, before assignment and validation.
-func (c *removeCmd) BeforeApply() error {
-	c.prompter = input.NewPrompter()
-	return nil
-}
-
 // AfterApply accepts user input by default to confirm the delete operation.
-func (c *removeCmd) AfterApply(p pterm.TextPrinter) error {
-	if c.Force {
-		return nil
-	}
-
-	confirm, err := c.prompter.Prompt("Are you sure you want to remove this member? [y/n]", false)
-	if err != nil {
-		return err
-	}
-
-	if input.InputYes(confirm) {
-		return nil
-	}
-
-	return fmt.Errorf("operation canceled")
+func (c *removeCmd) AfterApply(upCtx *upbound.Context, confirmer *input.Confirmer) error {
+	return confirmer.Confirm(input.Confirmation{
+		Target:   fmt.Sprintf("member %s of organization %s", c.User, c.OrgName),
+		Profile:  upCtx.ProfileName,
+		Prompt:   "Are you sure you want to remove this member?",
+		Approved: c.Force,
+	})
 }
 
 // Run executes the remove command.
@@ -26,35 +26,22 @@ import (
 	"github.com/upbound/up/internal/upbound"
 )
 
-// BeforeApply sets default values for the delete command, before assignment and validation.
-func (c *deleteCmd) BeforeApply() error {
-	c.prompter = input.NewPrompter()
-	return nil
-}
-
 // AfterApply accepts user input by default to confirm the delete operation.
-func (c *deleteCmd) AfterApply(p pterm.TextPrinter, upCtx *upbound.Context) error {
-	if c.Force {
-		return nil
-	}
-	confirm, err := c.prompter.Prompt("Are you sure you want to delete this repository? [y/n]", false)
-	if err != nil {
+func (c *deleteCmd) AfterApply(p pterm.TextPrinter, upCtx *upbound.Context, confirmer *input.Confirmer) error {
+	if err := confirmer.Confirm(input.Confirmation{
+		Target:   fmt.Sprintf("repository %s/%s", upCtx.Account, c.Name),
+		Profile:  upCtx.ProfileName,
+		Prompt:   "Are you sure you want to delete this repository?",
+		Approved: c.Force,
+	}); err != nil {
 		return err
 	}
-
-	if input.InputYes(confirm) {
-		p.Printfln("Deleting repository %s/%s. This cannot be undone.", upCtx.Account, c.Name)
-		return nil
-	}
-
-	return fmt.Errorf("operation canceled")
-
+	p.Printfln("Deleting repository %s/%s. This cannot be undone.", upCtx.Account, c.Name)
+	return nil
 }
 
 // deleteCmd deletes a repository on Upbound.
 type deleteCmd struct {
-	prompter input.Prompter
-
 	Name string `arg:"" required:"" help:"Name of repository." predictor:"repos"`
 
 	Force bool `help:"Force deletion of repository." default:"false"`
@@ -35,35 +35,22 @@ const (
 	errFindRobotFmt     = "could not find robot %s in %s"
 )
 
-// BeforeApply sets default values for the delete command, before assignment and validation.
-func (c *deleteCmd) BeforeApply() error {
-	c.prompter = input.NewPrompter()
-	return nil
-}
-
 // AfterApply accepts user input by default to confirm the delete operation.
-func (c *deleteCmd) AfterApply(p pterm.TextPrinter, upCtx *upbound.Context) error {
-	if c.Force {
-		return nil
-	}
-
-	confirm, err := c.prompter.Prompt("Are you sure you want to delete this robot? [y/n]", false)
-	if err != nil {
+func (c *deleteCmd) AfterApply(p pterm.TextPrinter, upCtx *upbound.Context, confirmer *input.Confirmer) error {
+	if err := confirmer.Confirm(input.Confirmation{
+		Target:   fmt.Sprintf("robot %s/%s", upCtx.Account, c.Name),
+		Profile:  upCtx.ProfileName,
+		Prompt:   "Are you sure you want to delete this robot?",
+		Approved: c.Force,
+	}); err != nil {
 		return err
 	}
-
-	if input.InputYes(confirm) {
-		p.Printfln("Deleting robot %s/%s. This cannot be undone.", upCtx.Account, c.Name)
-		return nil
-	}
-
-	return fmt.Errorf("operation canceled")
+	p.Printfln("Deleting robot %s/%s. This cannot be undone.", upCtx.Account, c.Name)
+	return nil
 }
 
 // deleteCmd deletes a robot on Upbound.
 type deleteCmd struct {
-	prompter input.Prompter
-
 	Name string `arg:"" required:"" help:"Name of robot." predictor:"robots"`
 
 	Force bool `help:"Force delete robot even if conflicts exist." default:"false"`
@@ -31,35 +31,22 @@ import (
 	"github.com/upbound/up/internal/upbound"
 )
 
-// BeforeApply sets default values for the delete command, before assignment and validation.
-func (c *deleteCmd) BeforeApply() error {
-	c.prompter = input.NewPrompter()
-	return nil
-}
-
 // AfterApply accepts user input by default to confirm the delete operation.
-func (c *deleteCmd) AfterApply(p pterm.TextPrinter, upCtx *upbound.Context) error {
-	if c.Force {
-		return nil
-	}
-
-	confirm, err := c.prompter.Prompt("Are you sure you want to delete this robot token? [y/n]", false)
-	if err != nil {
+func (c *deleteCmd) AfterApply(p pterm.TextPrinter, upCtx *upbound.Context, confirmer *input.Confirmer) error {
+	if err := confirmer.Confirm(input.Confirmation{
+		Target:   fmt.Sprintf("robot token %s/%s/%s", upCtx.Account, c.RobotName, c.TokenName),
+		Profile:  upCtx.ProfileName,
+		Prompt:   "Are you sure you want to delete this robot token?",
+		Approved: c.Force,
+	}); err != nil {
 		return err
 	}
-
-	if input.InputYes(confirm) {
-		p.Printfln("Deleting robot token %s/%s/%s. This cannot be undone.", upCtx.Account, c.RobotName, c.TokenName)
-		return nil
-	}
-
-	return fmt.Errorf("operation canceled")
+	p.Printfln("Deleting robot token %s/%s/%s. This cannot be undone.", upCtx.Account, c.RobotName, c.TokenName)
+	return nil
 }
 
 // deleteCmd deletes a robot token on Upbound.
 type deleteCmd struct {
-	prompter input.Prompter
-
 	RobotName string `arg:"" required:"" help:"Name of robot."`
 	TokenName string `arg:"" required:"" help:"Name of token."`
 
@@ -15,5 +15,7 @@
 package billing
 
 type Cmd struct {
-	Export exportCmd `cmd:"" help:"Export a billing report for submission to Upbound."`
+	Export    exportCmd    `cmd:"" help:"Export a billing report for submission to Upbound."`
+	Summarize summarizeCmd `cmd:"" help:"Print totals of the usage in a billing report."`
+	Verify    verifyCmd    `cmd:"" help:"Verify the checksums and signature of a billing report."`
 }
@@ -18,6 +18,7 @@ import (
 	"archive/tar"
 	"compress/gzip"
 	"context"
+	"crypto"
 	_ "embed"
 	"fmt"
 	"io/fs"
@@ -27,33 +28,18 @@ import (
 	"strings"
 	"time"
 
-	"cloud.google.com/go/storage"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/alecthomas/kong"
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
-	gcpopt "google.golang.org/api/option"
+	"github.com/spf13/afero"
 
-	usageaws "github.com/upbound/up/internal/usage/aws"
-	"github.com/upbound/up/internal/usage/azure"
-	"github.com/upbound/up/internal/usage/event"
-	"github.com/upbound/up/internal/usage/gcp"
+	"github.com/upbound/up/internal/usage/aggregate"
 	"github.com/upbound/up/internal/usage/report"
 	reporttar "github.com/upbound/up/internal/usage/report/file/tar"
+	"github.com/upbound/up/internal/usage/report/sign"
+	"github.com/upbound/up/internal/usage/storage"
 	usagetime "github.com/upbound/up/internal/usage/time"
 )
 
-const (
-	providerAWS   = "aws"
-	providerGCP   = "gcp"
-	providerAzure = "azure"
-
-	errFmtProviderNotSupported = "%q is not supported"
-)
-
 type dateRange usagetime.Range
 
 func (d *dateRange) Decode(ctx *kong.DecodeContext) error {
@@ -83,37 +69,31 @@ func (d *dateRange) Decode(ctx *kong.DecodeContext) error {
 	return nil
 }
 
-type provider string
-
-func (p provider) Validate() error {
-	switch p {
-	case providerGCP:
-		return nil
-	case providerAWS:
-		return nil
-	case providerAzure:
-		return nil
-	default:
-		return fmt.Errorf(errFmtProviderNotSupported, p)
-	}
-}
-
 type exportCmd struct {
 	Out string `optional:"" short:"o" env:"UP_BILLING_OUT" default:"upbound_billing_report.tgz" help:"Name of the output file."`
 
-	// TODO(branden): Make storage params optional and fetch missing values from spaces cluster.
-	Provider            provider `required:"" enum:"aws,gcp,azure," env:"UP_BILLING_PROVIDER" group:"Storage" help:"Storage provider. Must be one of: aws, gcp, azure."`
-	Bucket              string   `required:"" env:"UP_BILLING_BUCKET" group:"Storage" help:"Storage bucket."`
-	Endpoint            string   `env:"UP_BILLING_ENDPOINT" group:"Storage" help:"Custom storage endpoint."`
-	Account             string   `required:"" env:"UP_BILLING_ACCOUNT" group:"Storage" help:"Name of the Upbound account whose billing report is being collected."`
-	AzureStorageAccount string   `optional:"" env:"UP_AZURE_STORAGE_ACCOUNT" group:"Storage" help:"Name of the Azure storage account. Required for --provider=azure."`
+	Storage storage.Flags `embed:""`
 
 	BillingMonth    time.Time  `format:"2006-01" required:"" xor:"billingperiod" env:"UP_BILLING_MONTH" group:"Billing period" help:"Export a report for a billing period of one calendar month. Format: 2006-01."`
 	BillingCustom   *dateRange `required:"" xor:"billingperiod" env:"UP_BILLING_CUSTOM" group:"Billing period" help:"Export a report for a custom billing period. Date range is inclusive. Format: 2006-01-02/2006-01-02."`
 	ForceIncomplete bool       `env:"UP_BILLING_FORCE_INCOMPLETE" group:"Billing period" help:"Export a report for an incomplete billing period."`
+	TimeZone        string     `default:"UTC" env:"UP_BILLING_TIME_ZONE" group:"Billing period" help:"IANA name of the time zone the billing period is defined in, e.g. America/New_York. Its offset from UTC must be a whole number of hours."`
+
+	Window       time.Duration `default:"1h" env:"UP_BILLING_WINDOW" group:"Report" help:"Window of time usage is aggregated over. Must be a multiple of 1h."`
+	Aggregations []string      `default:"max_resource_count_per_gvk_per_mxp" env:"UP_BILLING_AGGREGATIONS" group:"Report" help:"Comma-separated list of aggregations to include in the report. See the command help for the available aggregations."`
+	SigningKey   string        `optional:"" type:"existingfile" env:"UP_BILLING_SIGNING_KEY" group:"Report" help:"Path to a PEM encoded PKCS #8 private key to sign the report with."`
+
+	Resume bool `default:"true" negatable:"" env:"UP_BILLING_RESUME" group:"Report" help:"Resume an interrupted export of the same report from its checkpoint file. Use --no-resume to read all usage data again."`
+
+	Concurrency       int `default:"4" env:"UP_BILLING_CONCURRENCY" group:"Performance" help:"Number of windows of usage data read at once."`
+	ObjectConcurrency int `default:"8" env:"UP_BILLING_OBJECT_CONCURRENCY" group:"Performance" help:"Number of storage objects read at once within each window."`
 
 	outAbs        string
+	checkpoint    string
 	billingPeriod usagetime.Range
+	location      *time.Location
+	aggregations  []aggregate.NewFn
+	signer        crypto.Signer
 }
 
 //go:embed export_help.txt
@@ -123,22 +103,57 @@ func (c *exportCmd) Help() string {
 	return exportCmdHelp
 }
 
-func (c *exportCmd) Validate() error {
-	if c.Provider == providerAzure {
-		if c.AzureStorageAccount == "" {
-			return fmt.Errorf("--azure-storage-account must be set for --provider=azure")
+func (c *exportCmd) Validate() error { //nolint:gocyclo
+	// Get aggregations.
+	if len(c.Aggregations) == 0 {
+		return fmt.Errorf("--aggregations must not be empty")
+	}
+	c.aggregations = make([]aggregate.NewFn, 0, len(c.Aggregations))
+	seen := map[string]bool{}
+	for _, name := range c.Aggregations {
+		fn, ok := aggregate.Lookup(name)
+		if !ok {
+			return fmt.Errorf("aggregation %q is not supported, must be one of: %s", name, strings.Join(aggregate.Names(), ", "))
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		c.aggregations = append(c.aggregations, fn)
+	}
+
+	if c.Concurrency < 1 || c.ObjectConcurrency < 1 {
+		return fmt.Errorf("--concurrency and --object-concurrency must be at least 1")
+	}
+
+	// Get signing key.
+	if c.SigningKey != "" {
+		b, err := os.ReadFile(c.SigningKey)
+		if err != nil {
+			return errors.Wrap(err, "error reading signing key")
 		}
-		if c.Endpoint != "" {
-			return fmt.Errorf("--endpoint is not supported for --provider=azure")
+		if c.signer, err = sign.ParsePrivateKey(b); err != nil {
+			return errors.Wrap(err, "error reading signing key")
 		}
 	}
 
+	if c.Window < time.Hour || c.Window%time.Hour != 0 {
+		return fmt.Errorf("--window must be a multiple of 1h")
+	}
+
 	// Get billing period.
 	var err error
+	c.location, err = time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return errors.Wrap(err, "error loading time zone")
+	}
 	c.billingPeriod, err = c.getBillingPeriod()
 	if err != nil {
 		return errors.Wrap(err, "error getting billing period")
 	}
+	if !c.billingPeriod.Start.Equal(c.billingPeriod.Start.Truncate(time.Hour)) || !c.billingPeriod.End.Equal(c.billingPeriod.End.Truncate(time.Hour)) {
+		return fmt.Errorf("billing period in time zone %s does not start and end on a whole hour in UTC", c.TimeZone)
+	}
 
 	// Validate billing period.
 	now := time.Now()
@@ -151,26 +166,63 @@ func (c *exportCmd) Validate() error {
 	if err != nil {
 		return err
 	}
-	_, err = os.Stat(c.outAbs)
-	if !errors.Is(err, fs.ErrNotExist) {
-		return fmt.Errorf("file \"%s\" already exists", c.Out)
-	}
+	c.checkpoint = c.outAbs + ".checkpoint"
 	return nil
 }
 
+// AfterApply reads missing storage settings from the Spaces cluster and
+// validates them, and then checks that the report may be written.
+func (c *exportCmd) AfterApply(kongCtx *kong.Context) error {
+	if err := c.Storage.Resolve(storage.EndpointSet(kongCtx)); err != nil {
+		return err
+	}
+	return c.validateOut(afero.NewOsFs())
+}
+
+// validateOut returns an error if the output file exists, unless the export
+// is resumed from a checkpoint of the same report. The output of an export
+// that was killed before it could clean up is then overwritten.
+func (c *exportCmd) validateOut(fsys afero.Fs) error {
+	_, err := fsys.Stat(c.outAbs)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if c.Resume {
+		ok, err := report.HasCheckpoint(fsys, c.checkpoint, c.checkpointParams())
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("file \"%s\" already exists, delete it to export the report", c.Out)
+}
+
 func (c *exportCmd) Run() error {
 	fmt.Printf(
 		"Exporting billing report for Upbound account %s from %s to %s.\n",
-		c.Account,
+		c.Storage.Account,
 		formatTimestamp(c.billingPeriod.Start),
 		formatTimestamp(c.billingPeriod.End),
 	)
+	if c.TimeZone != "UTC" {
+		fmt.Printf("Billing period is in time zone %s.\n", c.TimeZone)
+	}
+	fmt.Printf("Usage is aggregated per %s.\n", c.Window)
 	fmt.Printf("\n")
 	fmt.Printf("Reading usage data from storage...\n")
-	fmt.Printf("Provider: %s\n", c.Provider)
-	fmt.Printf("Bucket: %s\n", c.Bucket)
-	if c.Endpoint != "" {
-		fmt.Printf("Endpoint: %s\n", c.Endpoint)
+	fmt.Printf("Provider: %s\n", c.Storage.Provider)
+	if c.Storage.Provider == storage.ProviderFile {
+		fmt.Printf("Directory: %s\n", c.Storage.Directory)
+	} else {
+		fmt.Printf("Bucket: %s\n", c.Storage.Bucket)
+	}
+	if c.Storage.Endpoint != "" {
+		fmt.Printf("Endpoint: %s\n", c.Storage.Endpoint)
 	}
 
 	if err := c.collectReport(); err != nil {
@@ -184,9 +236,12 @@ func (c *exportCmd) Run() error {
 }
 
 func (c *exportCmd) cleanupOnError() {
-	if err := os.Remove(c.outAbs); err != nil {
+	if err := os.Remove(c.outAbs); err != nil && !errors.Is(err, fs.ErrNotExist) {
 		fmt.Fprintf(os.Stderr, "error cleaning up: %s", err)
 	}
+	if _, err := os.Stat(c.checkpoint); err == nil {
+		fmt.Fprintf(os.Stderr, "Progress saved to %s. Run the same command again to resume.\n", c.checkpoint)
+	}
 }
 
 func (c *exportCmd) collectReport() error {
@@ -194,19 +249,7 @@ func (c *exportCmd) collectReport() error {
 	defer stop()
 
 	// Make event window iterator.
-	window := time.Hour
-	var iter event.WindowIterator
-	var err error
-	switch c.Provider {
-	case providerGCP:
-		iter, err = c.getGCPIter(ctx, window)
-	case providerAWS:
-		iter, err = c.getAWSIter(window)
-	case providerAzure:
-		iter, err = c.getAzureIter(window)
-	default:
-		return fmt.Errorf(errFmtProviderNotSupported, c.Provider)
-	}
+	iter, err := c.Storage.WindowIterator(ctx, c.billingPeriod, c.Window, c.ObjectConcurrency)
 	if err != nil {
 		return err
 	}
@@ -219,17 +262,39 @@ func (c *exportCmd) collectReport() error {
 	defer f.Close() // nolint:errcheck
 	gw := gzip.NewWriter(f)
 	tw := tar.NewWriter(gw)
+	var opts []reporttar.WriterOption
+	if c.signer != nil {
+		opts = append(opts, reporttar.WithSigner(c.signer))
+	}
 	rw, err := reporttar.NewWriter(tw, report.Meta{
-		UpboundAccount: c.Account,
+		UpboundAccount: c.Storage.Account,
 		TimeRange:      c.billingPeriod,
 		CollectedAt:    time.Now(),
-	})
+		Aggregations:   c.Aggregations,
+		Window:         c.Window.String(),
+		TimeZone:       c.TimeZone,
+	}, opts...)
 	if err != nil {
 		return errors.Wrap(err, "error creating report")
 	}
 
+	// Open checkpoint.
+	if !c.Resume {
+		if err := os.Remove(c.checkpoint); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return errors.Wrap(err, "error removing checkpoint")
+		}
+	}
+	cp, err := report.OpenCheckpoint(afero.NewOsFs(), c.checkpoint, c.checkpointParams())
+	if err != nil {
+		return err
+	}
+	defer cp.Close() // nolint:errcheck
+	if n := cp.Len(); n > 0 {
+		fmt.Printf("Resuming from checkpoint %s with %d windows already read.\n", c.checkpoint, n)
+	}
+
 	// Write report.
-	if err := report.MaxResourceCountPerGVKPerMXP(ctx, iter, rw); err != nil {
+	if err := report.Aggregate(ctx, iter, rw, c.aggregations, report.WithConcurrency(c.Concurrency), report.WithCheckpoint(cp)); err != nil {
 		return err
 	}
 	if err := rw.Close(); err != nil {
@@ -238,56 +303,51 @@ func (c *exportCmd) collectReport() error {
 	if err := tw.Close(); err != nil {
 		return err
 	}
-	return gw.Close()
-}
-
-func (c *exportCmd) getGCPIter(ctx context.Context, window time.Duration) (event.WindowIterator, error) {
-	opts := []gcpopt.ClientOption{}
-	if c.Endpoint != "" {
-		opts = append(opts, gcpopt.WithEndpoint(c.Endpoint))
-	}
-	gcsCli, err := storage.NewClient(ctx, opts...)
-	if err != nil {
-		return nil, errors.Wrap(err, "error creating storage client")
+	if err := gw.Close(); err != nil {
+		return err
 	}
-	bkt := gcsCli.Bucket(c.Bucket)
-	return gcp.NewWindowIterator(bkt, c.Account, c.billingPeriod, window)
+	return cp.Remove()
 }
 
-func (c *exportCmd) getAWSIter(window time.Duration) (event.WindowIterator, error) {
-	sess, err := session.NewSession(&aws.Config{})
-	if err != nil {
-		return nil, errors.Wrap(err, "error creating aws session")
+// checkpointParams returns the parameters that must match for an export to
+// be resumed from a checkpoint.
+func (c *exportCmd) checkpointParams() any {
+	return struct {
+		Provider            storage.Provider `json:"provider"`
+		Bucket              string           `json:"bucket,omitempty"`
+		Directory           string           `json:"directory,omitempty"`
+		Endpoint            string           `json:"endpoint,omitempty"`
+		Account             string           `json:"account"`
+		AzureStorageAccount string           `json:"azure_storage_account,omitempty"`
+		BillingPeriod       usagetime.Range  `json:"billing_period"`
+		Window              time.Duration    `json:"window"`
+		Aggregations        []string         `json:"aggregations"`
+	}{
+		Provider:            c.Storage.Provider,
+		Bucket:              c.Storage.Bucket,
+		Directory:           c.Storage.Directory,
+		Endpoint:            c.Storage.Endpoint,
+		Account:             c.Storage.Account,
+		AzureStorageAccount: c.Storage.AzureStorageAccount,
+		BillingPeriod:       c.billingPeriod,
+		Window:              c.Window,
+		Aggregations:        c.Aggregations,
 	}
-	config := &aws.Config{}
-	if c.Endpoint != "" {
-		config = &aws.Config{
-			Endpoint: aws.String(c.Endpoint),
-		}
-	}
-	s3client := s3.New(sess, config)
-	return usageaws.NewWindowIterator(s3client, c.Bucket, c.Account, c.billingPeriod, window)
 }
 
-func (c *exportCmd) getAzureIter(window time.Duration) (event.WindowIterator, error) {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		return nil, err
-	}
-	cli, err := azblob.NewClient(fmt.Sprintf("https://%s.blob.core.windows.net/", c.AzureStorageAccount), cred, nil)
-	if err != nil {
-		return nil, err
+// getBillingPeriod returns the billing period in UTC. Dates are interpreted
+// in the billing time zone.
+func (c *exportCmd) getBillingPeriod() (usagetime.Range, error) {
+	loc := c.location
+	if loc == nil {
+		loc = time.UTC
 	}
-	containerCli := cli.ServiceClient().NewContainerClient(c.Bucket)
-	return azure.NewWindowIterator(containerCli, c.Account, c.billingPeriod, window)
-}
 
-func (c *exportCmd) getBillingPeriod() (usagetime.Range, error) {
 	if !c.BillingMonth.IsZero() {
-		start := time.Date(c.BillingMonth.Year(), c.BillingMonth.Month(), 1, 0, 0, 0, 0, time.UTC)
+		start := time.Date(c.BillingMonth.Year(), c.BillingMonth.Month(), 1, 0, 0, 0, 0, loc)
 		return usagetime.Range{
-			Start: start,
-			End:   start.AddDate(0, 1, 0),
+			Start: start.UTC(),
+			End:   start.AddDate(0, 1, 0).UTC(),
 		}, nil
 	}
 
@@ -301,8 +361,8 @@ func (c *exportCmd) getBillingPeriod() (usagetime.Range, error) {
 				0,
 				0,
 				0,
-				time.UTC,
-			),
+				loc,
+			).UTC(),
 			End: time.Date(
 				c.BillingCustom.End.Year(),
 				c.BillingCustom.End.Month(),
@@ -311,8 +371,8 @@ func (c *exportCmd) getBillingPeriod() (usagetime.Range, error) {
 				0,
 				0,
 				0,
-				time.UTC,
-			).AddDate(0, 0, 1),
+				loc,
+			).AddDate(0, 0, 1).UTC(),
 		}, nil
 	}
 
@@ -21,14 +21,22 @@ import (
 
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
 
+	"github.com/upbound/up/internal/usage/report"
 	usagetime "github.com/upbound/up/internal/usage/time"
 )
 
 func TestGetBillingPeriod(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	type args struct {
 		billingMonth  time.Time
 		billingCustom *dateRange
+		location      *time.Location
 	}
 	type want struct {
 		billingPeriod usagetime.Range
@@ -73,6 +81,35 @@ func TestGetBillingPeriod(t *testing.T) {
 				},
 			},
 		},
+		"BillingMonthTimeZone": {
+			reason: "A billing month in a time zone should cover the entire month in local time, including daylight saving time changes.",
+			args: args{
+				billingMonth: time.Date(2010, 3, 1, 0, 0, 0, 0, time.UTC),
+				location:     newYork,
+			},
+			want: want{
+				billingPeriod: usagetime.Range{
+					Start: time.Date(2010, 3, 1, 5, 0, 0, 0, time.UTC),
+					End:   time.Date(2010, 4, 1, 4, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		"BillingCustomTimeZone": {
+			reason: "A custom billing period in a time zone should cover the dates in local time.",
+			args: args{
+				billingCustom: &dateRange{
+					Start: time.Date(2006, 5, 4, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 5, 7, 0, 0, 0, 0, time.UTC),
+				},
+				location: newYork,
+			},
+			want: want{
+				billingPeriod: usagetime.Range{
+					Start: time.Date(2006, 5, 4, 4, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 5, 8, 4, 0, 0, 0, time.UTC),
+				},
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -80,6 +117,7 @@ func TestGetBillingPeriod(t *testing.T) {
 			c := &exportCmd{
 				BillingMonth:  tc.args.billingMonth,
 				BillingCustom: tc.args.billingCustom,
+				location:      tc.args.location,
 			}
 
 			got, err := c.getBillingPeriod()
@@ -92,3 +130,79 @@ func TestGetBillingPeriod(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateOut(t *testing.T) {
+	withOut := func(t *testing.T, fs afero.Fs) {
+		if err := afero.WriteFile(fs, "/report.tgz", []byte("partial"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	withCheckpoint := func(t *testing.T, fs afero.Fs, params any) {
+		cp, err := report.OpenCheckpoint(fs, "/report.tgz.checkpoint", params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cp.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	errExists := fmt.Errorf("file \"report.tgz\" already exists, delete it to export the report")
+
+	cases := map[string]struct {
+		reason string
+		resume bool
+		setup  func(t *testing.T, fs afero.Fs, c *exportCmd)
+		want   error
+	}{
+		"NoOutput": {
+			reason: "A report may be written if the output file does not exist.",
+			resume: true,
+			setup:  func(*testing.T, afero.Fs, *exportCmd) {},
+		},
+		"OutputWithCheckpoint": {
+			reason: "The output of an interrupted export may be overwritten when resuming it from its checkpoint.",
+			resume: true,
+			setup: func(t *testing.T, fs afero.Fs, c *exportCmd) {
+				withOut(t, fs)
+				withCheckpoint(t, fs, c.checkpointParams())
+			},
+		},
+		"OutputWithCheckpointNoResume": {
+			reason: "The output file must not be overwritten if the export is not resumed.",
+			setup: func(t *testing.T, fs afero.Fs, c *exportCmd) {
+				withOut(t, fs)
+				withCheckpoint(t, fs, c.checkpointParams())
+			},
+			want: errExists,
+		},
+		"OutputWithOtherCheckpoint": {
+			reason: "The output file must not be overwritten if the checkpoint is for another report.",
+			resume: true,
+			setup: func(t *testing.T, fs afero.Fs, _ *exportCmd) {
+				withOut(t, fs)
+				withCheckpoint(t, fs, map[string]string{"account": "other-account"})
+			},
+			want: errExists,
+		},
+		"OutputWithoutCheckpoint": {
+			reason: "The output file must not be overwritten if there is no checkpoint.",
+			resume: true,
+			setup: func(t *testing.T, fs afero.Fs, _ *exportCmd) {
+				withOut(t, fs)
+			},
+			want: errExists,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			c := &exportCmd{Out: "report.tgz", Resume: tc.resume, outAbs: "/report.tgz", checkpoint: "/report.tgz.checkpoint"}
+			tc.setup(t, fs, c)
+
+			err := c.validateOut(fs)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nvalidateOut(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
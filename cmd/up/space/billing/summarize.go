@@ -0,0 +1,159 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package billing
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/csv"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/upterm"
+	"github.com/upbound/up/internal/usage/report"
+	reporttar "github.com/upbound/up/internal/usage/report/file/tar"
+)
+
+const (
+	errOpenReport = "error opening report"
+	errReadReport = "error reading report"
+)
+
+var summaryFieldNames = []string{"AGGREGATION", "KEY", "TOTAL"}
+
+// AfterApply sets default values in command after assignment and validation.
+func (c *summarizeCmd) AfterApply(kongCtx *kong.Context) error {
+	c.stdout = kongCtx.Stdout
+	return nil
+}
+
+// summarizeCmd prints totals of the usage in a billing report.
+type summarizeCmd struct {
+	stdout io.Writer
+
+	Report string `arg:"" type:"existingfile" help:"Path to a billing report created by the export command."`
+}
+
+func (c *summarizeCmd) Help() string {
+	return `
+The summarize command reads a billing report created by the export command and
+prints the totals of each aggregation in the report per managed control plane
+(MXP), per GVK and per day. Use it to review a report before submitting it.
+
+Totals are printed as tables by default. Use --format=csv to print them as CSV
+for import into a spreadsheet, or --format=json or --format=yaml to print them
+as structured data.
+
+Examples:
+  # Print the totals of a report.
+  up space billing summarize upbound_billing_report.tgz
+
+  # Save the totals of a report as CSV.
+  up space billing summarize upbound_billing_report.tgz --format=csv > totals.csv`
+}
+
+// Run executes the summarize command.
+func (c *summarizeCmd) Run(printer upterm.ObjectPrinter, p pterm.TextPrinter) error {
+	s, err := readSummary(c.Report)
+	if err != nil {
+		return err
+	}
+
+	switch printer.Format { //nolint:exhaustive
+	case config.CSV:
+		return writeSummaryCSV(c.stdout, s)
+	case config.JSON, config.YAML:
+		return printer.Print(s, nil, nil)
+	}
+
+	p.Printfln("Account: %s", s.Meta.UpboundAccount)
+	p.Printfln("Period:  %s to %s\n", formatTimestamp(s.Meta.TimeRange.Start), formatTimestamp(s.Meta.TimeRange.End))
+	for _, sec := range []struct {
+		title  string
+		totals []report.Total
+	}{
+		{title: "Totals per MXP", totals: s.MXPs},
+		{title: "Totals per GVK", totals: s.GVKs},
+		{title: "Totals per day", totals: s.Days},
+	} {
+		p.Printfln("%s:", sec.title)
+		if len(sec.totals) == 0 {
+			p.Printfln("No usage found\n")
+			continue
+		}
+		if err := printer.Print(sec.totals, summaryFieldNames, extractTotalFields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readSummary reads and summarizes the gzipped billing report at path.
+func readSummary(path string) (report.Summary, error) {
+	f, err := os.Open(path) // nolint:gosec
+	if err != nil {
+		return report.Summary{}, errors.Wrap(err, errOpenReport)
+	}
+	defer f.Close() // nolint:errcheck
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return report.Summary{}, errors.Wrap(err, errReadReport)
+	}
+	meta, events, err := reporttar.Read(tar.NewReader(gr))
+	if err != nil {
+		return report.Summary{}, errors.Wrap(err, errReadReport)
+	}
+	return report.Summarize(meta, events)
+}
+
+// writeSummaryCSV writes all totals of a summary to w as CSV. The dimension
+// column is one of mxp, gvk or day.
+func writeSummaryCSV(w io.Writer, s report.Summary) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"dimension", "aggregation", "key", "total"}); err != nil {
+		return err
+	}
+	for _, d := range []struct {
+		name   string
+		totals []report.Total
+	}{
+		{name: "mxp", totals: s.MXPs},
+		{name: "gvk", totals: s.GVKs},
+		{name: "day", totals: s.Days},
+	} {
+		for _, t := range d.totals {
+			if err := cw.Write([]string{d.name, t.Aggregation, t.Key, formatValue(t.Value)}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func extractTotalFields(obj any) []string {
+	t := obj.(report.Total)
+	return []string{t.Aggregation, t.Key, formatValue(t.Value)}
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package billing
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/usage/report"
+)
+
+func TestWriteSummaryCSV(t *testing.T) {
+	type args struct {
+		summary report.Summary
+	}
+	type want struct {
+		csv string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Empty": {
+			reason: "A summary without totals should be written as a CSV header only.",
+			args:   args{},
+			want: want{
+				csv: "dimension,aggregation,key,total\n",
+			},
+		},
+		"Totals": {
+			reason: "All totals of a summary should be written with their dimension.",
+			args: args{
+				summary: report.Summary{
+					MXPs: []report.Total{{Aggregation: "max", Key: "mxp-1", Value: 1.5}},
+					GVKs: []report.Total{{Aggregation: "max", Key: "Thing.v1.example.com", Value: 2}},
+					Days: []report.Total{{Aggregation: "max", Key: "2023-01-01", Value: 3}},
+				},
+			},
+			want: want{
+				csv: "dimension,aggregation,key,total\n" +
+					"mxp,max,mxp-1,1.5\n" +
+					"gvk,max,Thing.v1.example.com,2\n" +
+					"day,max,2023-01-01,3\n",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := writeSummaryCSV(buf, tc.args.summary)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nwriteSummaryCSV(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.csv, buf.String()); diff != "" {
+				t.Errorf("\n%s\nwriteSummaryCSV(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
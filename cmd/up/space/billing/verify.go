@@ -0,0 +1,95 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package billing
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto"
+	"os"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/pterm/pterm"
+
+	reporttar "github.com/upbound/up/internal/usage/report/file/tar"
+	"github.com/upbound/up/internal/usage/report/sign"
+)
+
+const (
+	errReadPublicKey = "error reading public key"
+	errVerifyReport  = "report verification failed"
+)
+
+// verifyCmd verifies the integrity of a billing report.
+type verifyCmd struct {
+	Report    string `arg:"" type:"existingfile" help:"Path to a billing report created by the export command."`
+	PublicKey string `optional:"" type:"existingfile" env:"UP_BILLING_PUBLIC_KEY" help:"Path to a PEM encoded PKIX public key to verify the signature of the report with."`
+}
+
+func (c *verifyCmd) Help() string {
+	return `
+The verify command checks that the files of a billing report match the
+checksums in its manifest. If --public-key is set, the report must be signed
+and its signature is verified with the public key matching the private key
+passed to export --signing-key.
+
+Examples:
+  # Verify the checksums of a report.
+  up space billing verify upbound_billing_report.tgz
+
+  # Verify the checksums and signature of a report.
+  up space billing verify upbound_billing_report.tgz --public-key=billing.pub`
+}
+
+// Run executes the verify command.
+func (c *verifyCmd) Run(p pterm.TextPrinter) error {
+	var pub crypto.PublicKey
+	if c.PublicKey != "" {
+		b, err := os.ReadFile(c.PublicKey)
+		if err != nil {
+			return errors.Wrap(err, errReadPublicKey)
+		}
+		if pub, err = sign.ParsePublicKey(b); err != nil {
+			return errors.Wrap(err, errReadPublicKey)
+		}
+	}
+
+	f, err := os.Open(c.Report)
+	if err != nil {
+		return errors.Wrap(err, errOpenReport)
+	}
+	defer f.Close() // nolint:errcheck
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return errors.Wrap(err, errReadReport)
+	}
+	v, err := reporttar.Verify(tar.NewReader(gr), pub)
+	if err != nil {
+		return errors.Wrap(err, errVerifyReport)
+	}
+
+	for _, name := range v.Files {
+		p.Printfln("Checksum OK: %s", name)
+	}
+	switch {
+	case v.SignatureVerified:
+		p.Printfln("Signature OK")
+	case v.Signed:
+		p.Printfln("Signature not verified, use --public-key to verify it")
+	default:
+		p.Printfln("Report is not signed")
+	}
+	return nil
+}
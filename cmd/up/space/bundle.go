@@ -0,0 +1,271 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package space
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pterm/pterm"
+	"helm.sh/helm/v3/pkg/chart/loader"
+
+	"github.com/upbound/up/cmd/up/space/prerequisites"
+	"github.com/upbound/up/internal/install"
+	"github.com/upbound/up/internal/install/bundle"
+	"github.com/upbound/up/internal/install/helm"
+	"github.com/upbound/up/internal/upterm"
+)
+
+const (
+	// annotationSpacesVersion holds the Spaces version of a bundle.
+	annotationSpacesVersion = "io.upbound.spaces.version"
+	// annotationSpacesRegistry holds the registry the Spaces chart and
+	// images of a bundle were pulled from.
+	annotationSpacesRegistry = "io.upbound.spaces.registry"
+
+	errParsePlatform     = "unable to parse platform"
+	errFmtBundleChart    = "unable to bundle chart %s"
+	errFmtParseImage     = "unable to parse image %q"
+	errCreateBundle      = "unable to create bundle"
+	errFmtBundleMismatch = "bundle contains Spaces version %s, not %s"
+	errBundleRegistry    = "--registry-repository must be set to a private registry to install from a bundle"
+	errOpenBundle        = "unable to open bundle"
+	errPushBundle        = "unable to push bundled images"
+)
+
+// bundleCmd contains commands for air-gapped installation bundles.
+type bundleCmd struct {
+	Create bundleCreateCmd `cmd:"" help:"Create an installation bundle for clusters without internet access."`
+}
+
+// bundleCreateCmd downloads the charts, images and packages of a Spaces
+// installation into a bundle.
+type bundleCreateCmd struct {
+	Registry      authorizedRegistryFlags `embed:""`
+	Prerequisites prerequisitesFlags      `embed:""`
+
+	Version  string   `arg:"" help:"Upbound Spaces version to bundle."`
+	Output   string   `short:"o" type:"path" help:"Path of the bundle archive. Defaults to spaces-<version>.tar."`
+	Platform string   `default:"linux/amd64" help:"Platform of the bundled images."`
+	Images   []string `name:"image" help:"Additional image to bundle, e.g. an image that is not part of a rendered chart. May be repeated."`
+}
+
+// AfterApply sets default values in command after assignment and validation.
+func (c *bundleCreateCmd) AfterApply() error {
+	if err := c.Registry.AfterApply(); err != nil {
+		return err
+	}
+	if err := c.Prerequisites.AfterApply(); err != nil {
+		return err
+	}
+	if c.Output == "" {
+		c.Output = fmt.Sprintf("spaces-%s.tar", c.Version)
+	}
+
+	// NOTE(tnthornton) we currently only have support for stylized output.
+	pterm.EnableStyling()
+	upterm.DefaultObjPrinter.Pretty = true
+	return nil
+}
+
+func (c *bundleCreateCmd) Help() string {
+	return `
+The create command downloads the Spaces chart, the charts of the prerequisites
+(cert-manager, universal-crossplane and ingress-nginx), every image these
+charts deploy when rendered with their default values, and the provider
+packages installed as prerequisites. It writes them to a single archive of an
+OCI image layout.
+
+Copy the archive into an environment without internet access and install from
+it with 'up space init --bundle'. The images are pushed to the private registry
+set with --registry-repository during the installation.
+
+Images that are only referenced at run time, and not by a rendered chart, can
+be added with --image. The versions and values of the prerequisites can be
+overridden with --prerequisites-config, which should then also be passed to
+'up space init'.
+
+Examples:
+  # Bundle Spaces v1.2.0 and its prerequisites.
+  up space bundle create v1.2.0 --token-file=key.json
+
+  # Install Spaces from the bundle, pushing its images to a private registry.
+  up space init v1.2.0 --bundle=spaces-v1.2.0.tar \
+    --registry-repository=registry.example.com/upbound \
+    --registry-endpoint=https://registry.example.com`
+}
+
+// Run executes the create command.
+func (c *bundleCreateCmd) Run(ctx context.Context) error {
+	platform, err := v1.ParsePlatform(c.Platform)
+	if err != nil {
+		return errors.Wrap(err, errParsePlatform)
+	}
+	dir, err := os.MkdirTemp("", "up-bundle-")
+	if err != nil {
+		return errors.Wrap(err, errCreateBundle)
+	}
+	defer os.RemoveAll(dir) //nolint:errcheck
+
+	w, err := bundle.NewWriter(filepath.Join(dir, "layout"), map[string]string{
+		annotationSpacesVersion:  strings.TrimPrefix(c.Version, "v"),
+		annotationSpacesRegistry: c.Registry.Repository.String(),
+	}, bundle.WithRemoteOptions(
+		remote.WithContext(ctx),
+		remote.WithPlatform(*platform),
+		remote.WithAuthFromKeychain(c.keychain()),
+	))
+	if err != nil {
+		return errors.Wrap(err, errCreateBundle)
+	}
+
+	spaces := install.ChartRef{
+		Name:    spacesChart,
+		Version: strings.TrimPrefix(c.Version, "v"),
+		RepoURL: c.Registry.Repository,
+		OCI:     true,
+		Values:  map[string]any{},
+	}
+	overrideRegistry(c.Registry.Repository.String(), spaces.Values)
+	ensureAccount(spaces.Values)
+	charts := append([]install.ChartRef{spaces}, prerequisites.Charts(c.Prerequisites.config)...)
+
+	images := []string{}
+	for i, ch := range charts {
+		var chImages []string
+		if err := upterm.WrapWithSuccessSpinner(
+			upterm.StepCounter(fmt.Sprintf("Bundling chart %s %s", ch.Name, ch.Version), i+1, len(charts)+1),
+			upterm.CheckmarkSuccessSpinner,
+			func() error {
+				chImages, err = c.addChart(w, ch, filepath.Join(dir, "charts"))
+				return errors.Wrapf(err, errFmtBundleChart, ch.Name)
+			},
+		); err != nil {
+			return err
+		}
+		images = append(images, chImages...)
+	}
+	images = append(images, c.Images...)
+
+	refs := map[bundle.Kind][]name.Reference{}
+	for _, i := range images {
+		ref, err := name.ParseReference(i)
+		if err != nil {
+			return errors.Wrapf(err, errFmtParseImage, i)
+		}
+		refs[bundle.KindImage] = append(refs[bundle.KindImage], ref)
+	}
+	refs[bundle.KindPackage] = prerequisites.Packages(c.Prerequisites.config)
+
+	if err := upterm.WrapWithSuccessSpinner(
+		upterm.StepCounter(fmt.Sprintf("Bundling %d images and packages", len(refs[bundle.KindImage])+len(refs[bundle.KindPackage])), len(charts)+1, len(charts)+1),
+		upterm.CheckmarkSuccessSpinner,
+		func() error {
+			for _, k := range []bundle.Kind{bundle.KindImage, bundle.KindPackage} {
+				for _, ref := range refs[k] {
+					if err := w.AddImage(ref, k); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	); err != nil {
+		return err
+	}
+
+	f, err := os.Create(c.Output)
+	if err != nil {
+		return errors.Wrap(err, errCreateBundle)
+	}
+	if err := w.Archive(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, errCreateBundle)
+	}
+	pterm.Info.Printfln("Bundle written to %s", c.Output)
+	return nil
+}
+
+// addChart pulls a chart into dir, adds it to the bundle and returns the
+// images of its workloads.
+func (c *bundleCreateCmd) addChart(w *bundle.Writer, ch install.ChartRef, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	mods := []helm.InstallerModifierFn{}
+	if ch.OCI {
+		mods = append(mods, helm.IsOCI(), helm.WithBasicAuth(c.Registry.Username, c.Registry.Password))
+	}
+	path, err := helm.PullChart(ch.Name, ch.Version, ch.RepoURL, dir, mods...)
+	if err != nil {
+		return nil, err
+	}
+	loaded, err := loader.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := helm.Render(loaded, ns, ch.Values)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.AddChart(ch.Name, ch.Version, path); err != nil {
+		return nil, err
+	}
+	return bundle.Images(manifest)
+}
+
+// keychain authenticates to the Spaces registry with the registry flags and
+// to other registries with the default keychain.
+func (c *bundleCreateCmd) keychain() authn.Keychain {
+	return authn.NewMultiKeychain(&registryKeychain{
+		registry: registryHost(c.Registry.Repository.String()),
+		auth:     &authn.Basic{Username: c.Registry.Username, Password: c.Registry.Password},
+	}, authn.DefaultKeychain)
+}
+
+// registryKeychain supplies credentials for a single registry.
+type registryKeychain struct {
+	registry string
+	auth     authn.Authenticator
+}
+
+// Resolve returns the credentials of the registry, or anonymous credentials
+// for other registries.
+func (k *registryKeychain) Resolve(r authn.Resource) (authn.Authenticator, error) {
+	if r.RegistryStr() == k.registry {
+		return k.auth, nil
+	}
+	return authn.Anonymous, nil
+}
+
+// registryHost returns the registry of an OCI repository reference.
+func registryHost(repository string) string {
+	repo, err := name.NewRepository(repository)
+	if err != nil {
+		return ""
+	}
+	return repo.RegistryStr()
+}
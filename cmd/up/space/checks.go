@@ -0,0 +1,512 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package space
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/upbound/up/cmd/up/space/prerequisites"
+	"github.com/upbound/up/internal/resources"
+)
+
+const (
+	ingressNamespace = "ingress-nginx"
+	ingressService   = "ingress-nginx-controller"
+
+	dialTimeout = 5 * time.Second
+)
+
+var controlPlaneGVR = resources.ControlPlaneGVK.GroupVersion().WithResource("controlplanes")
+
+// checkStatus is the outcome of a health check.
+type checkStatus string
+
+const (
+	checkOK      checkStatus = "OK"
+	checkWarning checkStatus = "Warning"
+	checkFailed  checkStatus = "Failed"
+)
+
+// check is the result of a health check of a Space component. Fix suggests
+// how to resolve a failed check.
+type check struct {
+	Component string      `json:"component"`
+	Status    checkStatus `json:"status"`
+	Message   string      `json:"message"`
+	Fix       string      `json:"fix,omitempty"`
+}
+
+// versionGetter gets the version of an installed Helm release.
+type versionGetter interface {
+	GetCurrentVersion() (string, error)
+}
+
+// healthChecker checks the health of the components of a Space.
+type healthChecker struct {
+	version versionGetter
+	prereqs []prerequisites.Prerequisite
+	kClient kubernetes.Interface
+	dClient dynamic.Interface
+	dial    func(ctx context.Context, network, address string) (net.Conn, error)
+
+	// ctpClient builds a client for the API server of a control plane.
+	ctpClient func(ctx context.Context, name string) (dynamic.Interface, error)
+
+	// timeout bounds each call of a check that supports it, such as the
+	// checks of each control plane. Zero means no bound.
+	timeout time.Duration
+}
+
+// withTimeout returns a context bounded by the timeout of the checker.
+func (h *healthChecker) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if h.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, h.timeout)
+}
+
+// status runs the checks reported by the status command. No further checks
+// are run if the cluster is unreachable.
+func (h *healthChecker) status(ctx context.Context) []check {
+	cluster := h.checkCluster()
+	if cluster.Status == checkFailed {
+		return []check{cluster}
+	}
+	checks := []check{cluster, h.checkVersion()}
+	checks = append(checks, h.checkPrerequisites()...)
+	checks = append(checks, h.checkHostCluster(ctx))
+	checks = append(checks, h.checkDeployments(ctx)...)
+	checks = append(checks, h.checkIngress(ctx))
+	checks = append(checks, h.checkControlPlanes(ctx))
+	return checks
+}
+
+// doctor runs the checks of the status command followed by checks that
+// inspect the cluster in more detail.
+func (h *healthChecker) doctor(ctx context.Context) []check {
+	checks := h.status(ctx)
+	if len(checks) == 1 {
+		return checks
+	}
+	checks = append(checks, h.checkNodes(ctx)...)
+	checks = append(checks, h.checkPullSecret(ctx))
+	for _, namespace := range []string{ns, ingressNamespace} {
+		checks = append(checks, h.checkPods(ctx, namespace)...)
+	}
+	checks = append(checks, h.checkIngressReachable(ctx))
+	checks = append(checks, h.checkNotReadyControlPlanes(ctx)...)
+	return checks
+}
+
+func (h *healthChecker) checkCluster() check {
+	c := check{Component: "Cluster"}
+	v, err := h.kClient.Discovery().ServerVersion()
+	if err != nil {
+		c.Status = checkFailed
+		c.Message = err.Error()
+		c.Fix = "Ensure the kubeconfig or the current profile points to a reachable cluster."
+		return c
+	}
+	c.Status = checkOK
+	c.Message = fmt.Sprintf("Kubernetes %s reachable", v.GitVersion)
+	return c
+}
+
+func (h *healthChecker) checkVersion() check {
+	c := check{Component: "Spaces"}
+	v, err := h.version.GetCurrentVersion()
+	if err != nil {
+		c.Status = checkFailed
+		c.Message = err.Error()
+		c.Fix = "Install Spaces with 'up space init'."
+		return c
+	}
+	c.Status = checkOK
+	c.Message = fmt.Sprintf("version %s installed", v)
+	return c
+}
+
+func (h *healthChecker) checkPrerequisites() []check {
+	checks := make([]check, 0, len(h.prereqs))
+	for _, p := range h.prereqs {
+		c := check{Component: "Prerequisite " + p.GetName(), Status: checkOK, Message: "installed"}
+		if !p.IsInstalled() {
+			c.Status = checkFailed
+			c.Message = "not installed"
+			c.Fix = "Install missing prerequisites with 'up space init'."
+			checks = append(checks, c)
+			continue
+		}
+		v := prerequisites.VersionStatus{Prerequisite: p, Desired: p.GetVersion()}
+		v.Installed, v.Err = p.GetInstalledVersion()
+		switch {
+		case v.Err != nil:
+			// The version of a prerequisite that was not installed by up
+			// cannot be determined.
+		case v.Outdated():
+			c.Status = checkWarning
+			c.Message = fmt.Sprintf("version %s installed, %s desired", v.Installed, v.Desired)
+			c.Fix = "Upgrade prerequisites with 'up space upgrade --upgrade-prerequisites'."
+		default:
+			c.Message = fmt.Sprintf("version %s installed", v.Installed)
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+func (h *healthChecker) checkHostCluster(ctx context.Context) check {
+	c := check{Component: "Host cluster"}
+	l, err := h.dClient.Resource(hostclusterGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		c.Status = checkFailed
+		c.Message = err.Error()
+		c.Fix = "Install Spaces with 'up space init'."
+		return c
+	}
+	if len(l.Items) == 0 {
+		c.Status = checkFailed
+		c.Message = "no host cluster found"
+		c.Fix = "Check the logs of the Spaces deployments in the upbound-system namespace."
+		return c
+	}
+	for _, u := range l.Items {
+		hc := resources.HostCluster{Unstructured: u}
+		cnd := hc.GetCondition(xpv1.TypeReady)
+		if !resource.IsConditionTrue(cnd) {
+			c.Status = checkFailed
+			c.Message = fmt.Sprintf("%s is not ready", hc.GetName())
+			if cnd.Message != "" {
+				c.Message += ": " + cnd.Message
+			}
+			c.Fix = fmt.Sprintf("Inspect the host cluster with 'kubectl describe %s %s'.", hcResourcePlural, hc.GetName())
+			return c
+		}
+	}
+	c.Status = checkOK
+	c.Message = "ready"
+	return c
+}
+
+func (h *healthChecker) checkDeployments(ctx context.Context) []check {
+	l, err := h.kClient.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return []check{{Component: "Deployments", Status: checkFailed, Message: err.Error()}}
+	}
+	if len(l.Items) == 0 {
+		return []check{{
+			Component: "Deployments",
+			Status:    checkFailed,
+			Message:   fmt.Sprintf("no deployments found in namespace %s", ns),
+			Fix:       "Install Spaces with 'up space init'.",
+		}}
+	}
+	checks := make([]check, 0, len(l.Items))
+	for _, d := range l.Items {
+		c := check{
+			Component: "Deployment " + d.GetName(),
+			Status:    checkOK,
+			Message:   fmt.Sprintf("%d/%d replicas available", d.Status.AvailableReplicas, replicas(d)),
+		}
+		dep := resources.Deployment{Deployment: d}
+		if !resource.IsConditionTrue(dep.GetCondition(appsv1.DeploymentAvailable)) {
+			c.Status = checkFailed
+			c.Fix = fmt.Sprintf("Inspect the deployment with 'kubectl -n %s describe deployment %s'.", ns, d.GetName())
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+// replicas returns the desired number of replicas of a deployment.
+func replicas(d appsv1.Deployment) int32 {
+	if d.Spec.Replicas == nil {
+		return 1
+	}
+	return *d.Spec.Replicas
+}
+
+func (h *healthChecker) checkIngress(ctx context.Context) check {
+	c := check{Component: "Ingress"}
+	ep, err := h.kClient.CoreV1().Endpoints(ingressNamespace).Get(ctx, ingressService, metav1.GetOptions{})
+	if err != nil {
+		c.Status = checkFailed
+		c.Message = err.Error()
+		if kerrors.IsNotFound(err) {
+			c.Fix = "Install missing prerequisites with 'up space init'."
+		}
+		return c
+	}
+	ready := 0
+	for _, s := range ep.Subsets {
+		ready += len(s.Addresses)
+	}
+	if ready == 0 {
+		c.Status = checkFailed
+		c.Message = "no ready ingress controller endpoints"
+		c.Fix = fmt.Sprintf("Inspect the ingress controller pods with 'kubectl -n %s get pods'.", ingressNamespace)
+		return c
+	}
+
+	svc, err := h.kClient.CoreV1().Services(ingressNamespace).Get(ctx, ingressService, metav1.GetOptions{})
+	if err != nil {
+		c.Status = checkFailed
+		c.Message = err.Error()
+		return c
+	}
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer && len(svc.Status.LoadBalancer.Ingress) == 0 {
+		c.Status = checkWarning
+		c.Message = "load balancer address is pending"
+		c.Fix = "Ensure the cluster can provision load balancers, or install Spaces without --public-ingress."
+		return c
+	}
+	c.Status = checkOK
+	c.Message = fmt.Sprintf("%d ready endpoints", ready)
+	if addr := ingressAddress(svc); addr != "" {
+		c.Message += fmt.Sprintf(", address %s", addr)
+	}
+	return c
+}
+
+// ingressAddress returns the external address of the ingress controller
+// service, if any.
+func ingressAddress(svc *corev1.Service) string {
+	for _, i := range svc.Status.LoadBalancer.Ingress {
+		if i.Hostname != "" {
+			return i.Hostname
+		}
+		if i.IP != "" {
+			return i.IP
+		}
+	}
+	return ""
+}
+
+func (h *healthChecker) checkControlPlanes(ctx context.Context) check {
+	c := check{Component: "Control planes"}
+	ctps, err := h.listControlPlanes(ctx)
+	if err != nil {
+		c.Status = checkFailed
+		c.Message = err.Error()
+		return c
+	}
+	ready := 0
+	for _, ctp := range ctps {
+		if resource.IsConditionTrue(ctp.GetCondition(xpv1.TypeReady)) {
+			ready++
+		}
+	}
+	c.Status = checkOK
+	c.Message = fmt.Sprintf("%d/%d ready", ready, len(ctps))
+	if ready < len(ctps) {
+		c.Status = checkWarning
+		c.Fix = "List the control planes that are not ready with 'up space doctor'."
+	}
+	return c
+}
+
+func (h *healthChecker) listControlPlanes(ctx context.Context) ([]resources.ControlPlane, error) {
+	l, err := h.dClient.Resource(controlPlaneGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	ctps := make([]resources.ControlPlane, 0, len(l.Items))
+	for _, u := range l.Items {
+		ctps = append(ctps, resources.ControlPlane{Unstructured: u})
+	}
+	sort.Slice(ctps, func(i, j int) bool { return ctps[i].GetName() < ctps[j].GetName() })
+	return ctps, nil
+}
+
+func (h *healthChecker) checkNodes(ctx context.Context) []check {
+	l, err := h.kClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return []check{{Component: "Nodes", Status: checkFailed, Message: err.Error()}}
+	}
+	notReady := []string{}
+	for _, n := range l.Items {
+		if !nodeReady(n) {
+			notReady = append(notReady, n.GetName())
+		}
+	}
+	if len(notReady) > 0 {
+		return []check{{
+			Component: "Nodes",
+			Status:    checkWarning,
+			Message:   fmt.Sprintf("%d/%d nodes not ready: %s", len(notReady), len(l.Items), strings.Join(notReady, ", ")),
+			Fix:       "Inspect the nodes with 'kubectl describe nodes'.",
+		}}
+	}
+	return []check{{Component: "Nodes", Status: checkOK, Message: fmt.Sprintf("%d nodes ready", len(l.Items))}}
+}
+
+func nodeReady(n corev1.Node) bool {
+	for _, c := range n.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func (h *healthChecker) checkPullSecret(ctx context.Context) check {
+	c := check{Component: "Image pull secret"}
+	_, err := h.kClient.CoreV1().Secrets(ns).Get(ctx, defaultImagePullSecret, metav1.GetOptions{})
+	switch {
+	case kerrors.IsNotFound(err):
+		c.Status = checkFailed
+		c.Message = fmt.Sprintf("secret %s/%s not found", ns, defaultImagePullSecret)
+		c.Fix = "Recreate the secret by running 'up space upgrade' with --token-file."
+	case err != nil:
+		c.Status = checkFailed
+		c.Message = err.Error()
+	default:
+		c.Status = checkOK
+		c.Message = "present"
+	}
+	return c
+}
+
+// checkPods reports pods of a namespace that are stuck, together with a fix
+// for the most common reasons.
+func (h *healthChecker) checkPods(ctx context.Context, namespace string) []check {
+	l, err := h.kClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return []check{{Component: "Pods " + namespace, Status: checkFailed, Message: err.Error()}}
+	}
+	checks := []check{}
+	for _, p := range l.Items {
+		if c, ok := podProblem(p); ok {
+			checks = append(checks, c)
+		}
+	}
+	if len(checks) == 0 {
+		return []check{{Component: "Pods " + namespace, Status: checkOK, Message: fmt.Sprintf("%d pods healthy", len(l.Items))}}
+	}
+	return checks
+}
+
+// podProblem returns a failed check if the pod is unschedulable or one of its
+// containers is waiting for a reason that needs intervention.
+func podProblem(p corev1.Pod) (check, bool) {
+	c := check{Component: fmt.Sprintf("Pod %s/%s", p.GetNamespace(), p.GetName()), Status: checkFailed}
+	for _, cnd := range p.Status.Conditions {
+		if cnd.Type == corev1.PodScheduled && cnd.Status == corev1.ConditionFalse && cnd.Reason == corev1.PodReasonUnschedulable {
+			c.Message = "unschedulable: " + cnd.Message
+			c.Fix = "Add nodes or free up resources in the cluster."
+			return c, true
+		}
+	}
+	for _, cs := range append(p.Status.InitContainerStatuses, p.Status.ContainerStatuses...) {
+		if cs.State.Waiting == nil {
+			continue
+		}
+		c.Message = fmt.Sprintf("container %s: %s", cs.Name, cs.State.Waiting.Reason)
+		switch cs.State.Waiting.Reason {
+		case "ErrImagePull", "ImagePullBackOff":
+			c.Fix = "Check the registry credentials by running 'up space upgrade' with --token-file."
+		case "CrashLoopBackOff":
+			c.Fix = fmt.Sprintf("Inspect the logs with 'kubectl -n %s logs %s -c %s --previous'.", p.GetNamespace(), p.GetName(), cs.Name)
+		case "CreateContainerConfigError":
+			c.Fix = fmt.Sprintf("Ensure the secrets and config maps referenced by pod %s exist.", p.GetName())
+		default:
+			continue
+		}
+		return c, true
+	}
+	return check{}, false
+}
+
+// checkIngressReachable connects to the external address of the ingress
+// controller, if it has one.
+func (h *healthChecker) checkIngressReachable(ctx context.Context) check {
+	c := check{Component: "Ingress reachability"}
+	svc, err := h.kClient.CoreV1().Services(ingressNamespace).Get(ctx, ingressService, metav1.GetOptions{})
+	if err != nil {
+		c.Status = checkFailed
+		c.Message = err.Error()
+		return c
+	}
+	addr := ingressAddress(svc)
+	if addr == "" {
+		c.Status = checkOK
+		c.Message = "no external address, skipped"
+		return c
+	}
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+	conn, err := h.dial(ctx, "tcp", net.JoinHostPort(addr, "443"))
+	if err != nil {
+		c.Status = checkFailed
+		c.Message = err.Error()
+		c.Fix = "Ensure firewall rules allow traffic to the load balancer on port 443."
+		return c
+	}
+	_ = conn.Close()
+	c.Status = checkOK
+	c.Message = fmt.Sprintf("%s:443 reachable", addr)
+	return c
+}
+
+func (h *healthChecker) checkNotReadyControlPlanes(ctx context.Context) []check {
+	ctps, err := h.listControlPlanes(ctx)
+	if err != nil {
+		// Already reported by checkControlPlanes.
+		return nil
+	}
+	checks := []check{}
+	for _, ctp := range ctps {
+		cnd := ctp.GetCondition(xpv1.TypeReady)
+		if resource.IsConditionTrue(cnd) {
+			continue
+		}
+		msg := "not ready"
+		if cnd.Message != "" {
+			msg += ": " + cnd.Message
+		}
+		checks = append(checks, check{
+			Component: "Control plane " + ctp.GetName(),
+			Status:    checkWarning,
+			Message:   msg,
+			Fix:       fmt.Sprintf("Inspect the control plane with 'kubectl describe controlplane %s'.", ctp.GetName()),
+		})
+	}
+	return checks
+}
+
+// failed returns the number of failed checks.
+func failed(checks []check) int {
+	n := 0
+	for _, c := range checks {
+		if c.Status == checkFailed {
+			n++
+		}
+	}
+	return n
+}
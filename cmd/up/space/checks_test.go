@@ -0,0 +1,319 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package space
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/upbound/up/cmd/up/space/prerequisites"
+)
+
+type fakeVersionGetter struct {
+	version string
+	err     error
+}
+
+func (f *fakeVersionGetter) GetCurrentVersion() (string, error) {
+	return f.version, f.err
+}
+
+type fakeInstalledPrerequisite struct {
+	fakePrerequisite
+	installed bool
+}
+
+func (p *fakeInstalledPrerequisite) IsInstalled() bool { return p.installed }
+
+func withReady(u *unstructured.Unstructured, status string, msg string) *unstructured.Unstructured {
+	_ = unstructured.SetNestedSlice(u.Object, []any{map[string]any{
+		"type":    "Ready",
+		"status":  status,
+		"message": msg,
+	}}, "status", "conditions")
+	return u
+}
+
+func hostCluster(name, ready string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(hcGroup + "/" + hcVersion)
+	u.SetKind("XHostCluster")
+	u.SetName(name)
+	return withReady(u, ready, "")
+}
+
+func controlPlane(name, ready, msg string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(controlPlaneGVR.GroupVersion().String())
+	u.SetKind("ControlPlane")
+	u.SetName(name)
+	return withReady(u, ready, msg)
+}
+
+func deployment(name string, available corev1.ConditionStatus, replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			AvailableReplicas: replicas,
+			Conditions:        []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: available}},
+		},
+	}
+}
+
+func ingressObjects(lb bool, ready int) []runtime.Object {
+	ep := &corev1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: ingressService, Namespace: ingressNamespace}}
+	if ready > 0 {
+		addrs := make([]corev1.EndpointAddress, ready)
+		ep.Subsets = []corev1.EndpointSubset{{Addresses: addrs}}
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: ingressService, Namespace: ingressNamespace},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeNodePort},
+	}
+	if lb {
+		svc.Spec.Type = corev1.ServiceTypeLoadBalancer
+	}
+	return []runtime.Object{ep, svc}
+}
+
+func newFakeDynamicClient(objs ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		hostclusterGVR:  "XHostClusterList",
+		controlPlaneGVR: "ControlPlaneList",
+	}, objs...)
+}
+
+func TestHealthCheckerStatus(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason  string
+		version versionGetter
+		prereqs []prerequisites.Prerequisite
+		kube    []runtime.Object
+		dynamic []runtime.Object
+		want    []check
+	}{
+		"Healthy": {
+			reason:  "A Space with all components ready should only report successful checks.",
+			version: &fakeVersionGetter{version: "1.2.0"},
+			prereqs: []prerequisites.Prerequisite{&fakeInstalledPrerequisite{fakePrerequisite: fakePrerequisite{name: "uxp", version: "1.13.2-up.2", installedVersion: "1.13.2-up.2"}, installed: true}},
+			kube:    append(ingressObjects(false, 1), deployment("mxe-controller", corev1.ConditionTrue, 2)),
+			dynamic: []runtime.Object{hostCluster("hc", "True"), controlPlane("ctp1", "True", "")},
+			want: []check{
+				{Component: "Cluster", Status: checkOK, Message: "Kubernetes v1.28.0 reachable"},
+				{Component: "Spaces", Status: checkOK, Message: "version 1.2.0 installed"},
+				{Component: "Prerequisite uxp", Status: checkOK, Message: "version 1.13.2-up.2 installed"},
+				{Component: "Host cluster", Status: checkOK, Message: "ready"},
+				{Component: "Deployment mxe-controller", Status: checkOK, Message: "2/2 replicas available"},
+				{Component: "Ingress", Status: checkOK, Message: "1 ready endpoints"},
+				{Component: "Control planes", Status: checkOK, Message: "1/1 ready"},
+			},
+		},
+		"Unhealthy": {
+			reason:  "Components that are missing or not ready should be reported with a fix.",
+			version: &fakeVersionGetter{err: errBoom},
+			prereqs: []prerequisites.Prerequisite{
+				&fakeInstalledPrerequisite{fakePrerequisite: fakePrerequisite{name: "uxp"}},
+				&fakeInstalledPrerequisite{fakePrerequisite: fakePrerequisite{name: "cert-manager", version: "v1.12.0", installedVersion: "v1.11.0"}, installed: true},
+				&fakeInstalledPrerequisite{fakePrerequisite: fakePrerequisite{name: "ingress-nginx", err: errBoom}, installed: true},
+			},
+			kube:    append(ingressObjects(true, 1), deployment("mxe-controller", corev1.ConditionFalse, 1)),
+			dynamic: []runtime.Object{hostCluster("hc", "False"), controlPlane("ctp1", "True", ""), controlPlane("ctp2", "False", "")},
+			want: []check{
+				{Component: "Cluster", Status: checkOK, Message: "Kubernetes v1.28.0 reachable"},
+				{Component: "Spaces", Status: checkFailed, Message: "boom", Fix: "Install Spaces with 'up space init'."},
+				{Component: "Prerequisite uxp", Status: checkFailed, Message: "not installed", Fix: "Install missing prerequisites with 'up space init'."},
+				{Component: "Prerequisite cert-manager", Status: checkWarning, Message: "version v1.11.0 installed, v1.12.0 desired", Fix: "Upgrade prerequisites with 'up space upgrade --upgrade-prerequisites'."},
+				{Component: "Prerequisite ingress-nginx", Status: checkOK, Message: "installed"},
+				{Component: "Host cluster", Status: checkFailed, Message: "hc is not ready", Fix: "Inspect the host cluster with 'kubectl describe xhostclusters hc'."},
+				{Component: "Deployment mxe-controller", Status: checkFailed, Message: "1/1 replicas available", Fix: "Inspect the deployment with 'kubectl -n upbound-system describe deployment mxe-controller'."},
+				{Component: "Ingress", Status: checkWarning, Message: "load balancer address is pending", Fix: "Ensure the cluster can provision load balancers, or install Spaces without --public-ingress."},
+				{Component: "Control planes", Status: checkWarning, Message: "1/2 ready", Fix: "List the control planes that are not ready with 'up space doctor'."},
+			},
+		},
+		"NotInstalled": {
+			reason:  "A cluster without Spaces should report missing components.",
+			version: &fakeVersionGetter{err: errBoom},
+			want: []check{
+				{Component: "Cluster", Status: checkOK, Message: "Kubernetes v1.28.0 reachable"},
+				{Component: "Spaces", Status: checkFailed, Message: "boom", Fix: "Install Spaces with 'up space init'."},
+				{Component: "Host cluster", Status: checkFailed, Message: "no host cluster found", Fix: "Check the logs of the Spaces deployments in the upbound-system namespace."},
+				{Component: "Deployments", Status: checkFailed, Message: "no deployments found in namespace upbound-system", Fix: "Install Spaces with 'up space init'."},
+				{Component: "Ingress", Status: checkFailed, Message: `endpoints "ingress-nginx-controller" not found`, Fix: "Install missing prerequisites with 'up space init'."},
+				{Component: "Control planes", Status: checkOK, Message: "0/0 ready"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kClient := kubefake.NewSimpleClientset(tc.kube...)
+			kClient.Discovery().(*discoveryfake.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.28.0"}
+			h := &healthChecker{
+				version: tc.version,
+				prereqs: tc.prereqs,
+				kClient: kClient,
+				dClient: newFakeDynamicClient(tc.dynamic...),
+			}
+			got := h.status(context.Background())
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nstatus(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestHealthCheckerIngressReachable(t *testing.T) {
+	errBoom := errors.New("boom")
+	lb := func(addr string) []runtime.Object {
+		objs := ingressObjects(true, 1)
+		objs[1].(*corev1.Service).Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: addr}}
+		return objs
+	}
+
+	cases := map[string]struct {
+		reason string
+		kube   []runtime.Object
+		dial   func(ctx context.Context, network, address string) (net.Conn, error)
+		want   check
+	}{
+		"NoAddress": {
+			reason: "The connection check should be skipped if the ingress controller has no external address.",
+			kube:   ingressObjects(false, 1),
+			want:   check{Component: "Ingress reachability", Status: checkOK, Message: "no external address, skipped"},
+		},
+		"Reachable": {
+			reason: "An external address that accepts connections should be reported as reachable.",
+			kube:   lb("10.0.0.1"),
+			dial: func(_ context.Context, _, _ string) (net.Conn, error) {
+				c, _ := net.Pipe()
+				return c, nil
+			},
+			want: check{Component: "Ingress reachability", Status: checkOK, Message: "10.0.0.1:443 reachable"},
+		},
+		"Unreachable": {
+			reason: "An external address that refuses connections should fail with a fix.",
+			kube:   lb("10.0.0.1"),
+			dial: func(_ context.Context, _, _ string) (net.Conn, error) {
+				return nil, errBoom
+			},
+			want: check{Component: "Ingress reachability", Status: checkFailed, Message: "boom", Fix: "Ensure firewall rules allow traffic to the load balancer on port 443."},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := &healthChecker{kClient: kubefake.NewSimpleClientset(tc.kube...), dial: tc.dial}
+			got := h.checkIngressReachable(context.Background())
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ncheckIngressReachable(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPodProblem(t *testing.T) {
+	waiting := func(reason string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "p", Namespace: ns},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "c",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}},
+			}}},
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		pod    corev1.Pod
+		want   check
+		wantOK bool
+	}{
+		"Running": {
+			reason: "A running pod has no problem.",
+			pod:    corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p", Namespace: ns}},
+		},
+		"ContainerCreating": {
+			reason: "A container that is being created is not a problem.",
+			pod:    waiting("ContainerCreating"),
+		},
+		"ImagePullBackOff": {
+			reason: "A container that cannot pull its image should suggest checking registry credentials.",
+			pod:    waiting("ImagePullBackOff"),
+			want: check{
+				Component: "Pod upbound-system/p",
+				Status:    checkFailed,
+				Message:   "container c: ImagePullBackOff",
+				Fix:       "Check the registry credentials by running 'up space upgrade' with --token-file.",
+			},
+			wantOK: true,
+		},
+		"CrashLoopBackOff": {
+			reason: "A crash looping container should suggest inspecting its logs.",
+			pod:    waiting("CrashLoopBackOff"),
+			want: check{
+				Component: "Pod upbound-system/p",
+				Status:    checkFailed,
+				Message:   "container c: CrashLoopBackOff",
+				Fix:       "Inspect the logs with 'kubectl -n upbound-system logs p -c c --previous'.",
+			},
+			wantOK: true,
+		},
+		"Unschedulable": {
+			reason: "An unschedulable pod should suggest adding capacity.",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "p", Namespace: ns},
+				Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+					Type:    corev1.PodScheduled,
+					Status:  corev1.ConditionFalse,
+					Reason:  corev1.PodReasonUnschedulable,
+					Message: "0/1 nodes are available",
+				}}},
+			},
+			want: check{
+				Component: "Pod upbound-system/p",
+				Status:    checkFailed,
+				Message:   "unschedulable: 0/1 nodes are available",
+				Fix:       "Add nodes or free up resources in the cluster.",
+			},
+			wantOK: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, ok := podProblem(tc.pod)
+			if diff := cmp.Diff(tc.wantOK, ok); diff != "" {
+				t.Errorf("\n%s\npodProblem(...): -want ok, +got ok:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\npodProblem(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -29,6 +29,9 @@ type CloudType string
 type CloudConfig struct {
 	SpacesValues  map[string]string
 	PublicIngress bool
+	// PrerequisiteValues are the Helm values of prerequisite charts, keyed by
+	// chart name, e.g. ingress-nginx.
+	PrerequisiteValues map[string]map[string]any
 }
 
 const (
@@ -37,46 +40,113 @@ const (
 	Generic   CloudType = "generic"
 	GoogleGKE CloudType = "gke"
 	Kind      CloudType = "kind"
+	OpenShift CloudType = "openshift"
+	K3s       CloudType = "k3s"
+	RKE2      CloudType = "rke2"
+	VCluster  CloudType = "vcluster"
 
 	ClusterTypeStr = "clusterType"
 )
 
-var vendorDefaults = map[CloudType]map[string]string{
-	AmazonEKS: {
-		ClusterTypeStr: string(AmazonEKS),
+// Charts of prerequisites whose values are tuned by profiles.
+const (
+	chartUXP          = "universal-crossplane"
+	chartIngressNginx = "ingress-nginx"
+)
+
+// vendorProfiles are the built-in profiles in the order they are detected.
+// Distributions that run on the infrastructure of a cloud provider, like
+// vcluster and OpenShift, are detected before the cloud providers. Profiles
+// that do not set a storage class use the default storage class of the
+// cluster.
+var vendorProfiles = []Profile{
+	{
+		// Nodes of a vcluster are synced from, or faked after, the nodes of
+		// the host cluster, which does not provision load balancers for it.
+		Name:   VCluster,
+		Detect: Detection{NodeLabels: map[string]string{"vcluster.loft.sh/fake-node": "true"}},
+		PrerequisiteValues: map[string]map[string]any{
+			chartIngressNginx: {"controller": map[string]any{"admissionWebhooks": map[string]any{"enabled": false}}},
+		},
 	},
-	AzureAKS: {
-		ClusterTypeStr: string(AzureAKS),
+	{
+		// OpenShift assigns the users of pods from the UID range of their
+		// namespace, so charts must not pin them.
+		Name:   OpenShift,
+		Detect: Detection{APIGroups: []string{"config.openshift.io"}},
+		PrerequisiteValues: map[string]map[string]any{
+			chartUXP: {
+				"securityContextCrossplane":  map[string]any{"runAsUser": nil, "runAsGroup": nil},
+				"securityContextRBACManager": map[string]any{"runAsUser": nil, "runAsGroup": nil},
+			},
+			chartIngressNginx: {"controller": map[string]any{"image": map[string]any{"runAsUser": nil}}},
+		},
 	},
-	GoogleGKE: {
-		ClusterTypeStr: string(GoogleGKE),
+	{
+		// RKE2 ships its own ingress-nginx controller, which already owns the
+		// nginx ingress class and binds the host ports, so ours uses its own
+		// ingress class and no host ports. RKE2 ships no storage provisioner.
+		Name:         RKE2,
+		Detect:       Detection{ProviderIDPrefix: "rke2"},
+		SpacesValues: map[string]string{"ingress.className": "upbound-nginx"},
+		PrerequisiteValues: map[string]map[string]any{
+			chartIngressNginx: {"controller": map[string]any{
+				"ingressClassResource": map[string]any{"name": "upbound-nginx", "controllerValue": "k8s.io/upbound-nginx"},
+				"ingressClass":         "upbound-nginx",
+				"hostPort":             map[string]any{"enabled": false},
+				"nodeSelector":         nil,
+			}},
+		},
 	},
-	Kind: {
-		ClusterTypeStr: string(Kind),
+	{
+		// k3s exposes Traefik on the ports of its service load balancer, so
+		// ingress-nginx is exposed on node ports instead of host ports. k3s
+		// provisions volumes with its bundled local-path provisioner.
+		Name:         K3s,
+		Detect:       Detection{ProviderIDPrefix: "k3s"},
+		SpacesValues: map[string]string{"storageClass": "local-path"},
+		PrerequisiteValues: map[string]map[string]any{
+			chartIngressNginx: {"controller": map[string]any{
+				"service":      map[string]any{"type": "NodePort"},
+				"hostPort":     map[string]any{"enabled": false},
+				"nodeSelector": nil,
+			}},
+		},
 	},
-
-	Generic: {},
+	{Name: AzureAKS, Detect: Detection{ProviderIDPrefix: "azure"}, SpacesValues: map[string]string{ClusterTypeStr: string(AzureAKS)}, PublicIngress: true},
+	{Name: AmazonEKS, Detect: Detection{ProviderIDPrefix: "aws"}, SpacesValues: map[string]string{ClusterTypeStr: string(AmazonEKS)}, PublicIngress: true},
+	{Name: GoogleGKE, Detect: Detection{ProviderIDPrefix: "gce"}, SpacesValues: map[string]string{ClusterTypeStr: string(GoogleGKE)}, PublicIngress: true},
+	{Name: Kind, Detect: Detection{ProviderIDPrefix: "kind"}, SpacesValues: map[string]string{ClusterTypeStr: string(Kind)}},
+	{Name: Generic},
 }
 
-func (ct *CloudType) getSpaceValues() map[string]string {
-	if v, ok := vendorDefaults[*ct]; ok {
-		return v
+// getProfile returns the profile of the cloud type, preferring custom
+// profiles over the built-in ones.
+func (ct *CloudType) getProfile(custom []Profile) (Profile, bool) {
+	for _, ps := range [][]Profile{custom, vendorProfiles} {
+		for _, p := range ps {
+			if p.Name == *ct {
+				return p, true
+			}
+		}
 	}
-	return nil
+	return Profile{}, false
 }
 
 func (ct *CloudType) Defaults() CloudConfig {
-	publicIngress := true
-	if *ct == Generic || *ct == Kind {
-		publicIngress = false
-	}
-	return CloudConfig{
-		SpacesValues:  ct.getSpaceValues(),
-		PublicIngress: publicIngress,
+	p, ok := ct.getProfile(nil)
+	if !ok {
+		// NOTE: unknown cloud types are still passed to Spaces, and exposed
+		// publicly like a managed Kubernetes service.
+		return CloudConfig{PublicIngress: true}
 	}
+	return p.config()
 }
 
-func GetConfig(kClient kubernetes.Interface, override string) (*CloudConfig, error) {
+// GetConfig returns the defaults of the cloud type override, or of the
+// detected cloud type if override is empty. Custom profiles take precedence
+// over built-in profiles of the same name, and are detected first.
+func GetConfig(kClient kubernetes.Interface, override string, custom ...Profile) (*CloudConfig, error) {
 	if kClient == nil {
 		return nil, errors.New("no kubernetes client")
 	}
@@ -84,17 +154,21 @@ func GetConfig(kClient kubernetes.Interface, override string) (*CloudConfig, err
 	if override != "" {
 		cloud = CloudType(strings.ToLower(override))
 	} else {
-		cloud = detectKubernetes(kClient)
+		cloud = detectKubernetes(kClient, custom)
+	}
+	cfg := cloud.Defaults()
+	if p, ok := cloud.getProfile(custom); ok {
+		cfg = p.config()
 	}
-	if cloud == Generic || cloud == Kind {
+	switch {
+	case cloud == Generic || cloud == Kind:
 		pterm.Info.Printfln("Setting defaults for vanilla Kubernetes (type %s)", string(cloud))
-	} else {
+	case cfg.PublicIngress:
 		pterm.Info.Printfln("Applying settings for Managed Kubernetes on %s", strings.ToUpper(string(cloud)))
+	default:
+		pterm.Info.Printfln("Applying settings for Kubernetes distribution %s", string(cloud))
 	}
-	return &CloudConfig{
-		SpacesValues:  cloud.Defaults().SpacesValues,
-		PublicIngress: cloud.Defaults().PublicIngress,
-	}, nil
+	return &cfg, nil
 }
 
 // detectKubernetes looks at a nodes provider to determine what type of cluster
@@ -102,21 +176,22 @@ func GetConfig(kClient kubernetes.Interface, override string) (*CloudConfig, err
 // to use the installer would be incorrect. This is a "best effort" attempt to
 // add some CLI sugar, so reacting to an error seems suboptimal, especially if the
 // installer doesn't have RBAC permissions to list nodes.
-func detectKubernetes(kClient kubernetes.Interface) CloudType {
+func detectKubernetes(kClient kubernetes.Interface, custom []Profile) CloudType {
 	// EKS and Kind are _harder_ to detect based on version, so look at node labels.
 	ctx := context.Background()
+	c := cluster{groups: map[string]bool{}}
 	if nodes, err := kClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{}); err == nil {
-		for _, n := range nodes.Items {
-			providerPrefix := strings.Split(n.Spec.ProviderID, "://")[0]
-			switch providerPrefix {
-			case "azure":
-				return AzureAKS
-			case "aws":
-				return AmazonEKS
-			case "gce":
-				return GoogleGKE
-			case "kind":
-				return Kind
+		c.nodes = nodes.Items
+	}
+	if groups, err := kClient.Discovery().ServerGroups(); err == nil {
+		for _, g := range groups.Groups {
+			c.groups[g.Name] = true
+		}
+	}
+	for _, ps := range [][]Profile{custom, vendorProfiles} {
+		for _, p := range ps {
+			if p.Detect.matches(c) {
+				return p.Name
 			}
 		}
 	}
@@ -0,0 +1,226 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func node(providerID string, labels map[string]string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: labels},
+		Spec:       corev1.NodeSpec{ProviderID: providerID},
+	}
+}
+
+func TestDetectKubernetes(t *testing.T) {
+	custom := []Profile{{Name: "acme", Detect: Detection{NodeLabels: map[string]string{"acme.io/cluster": "true"}}}}
+
+	cases := map[string]struct {
+		reason string
+		nodes  []runtime.Object
+		groups []string
+		custom []Profile
+		want   CloudType
+	}{
+		"EKS": {
+			reason: "Clusters should be detected by the provider IDs of their nodes.",
+			nodes:  []runtime.Object{node("aws:///us-east-1a/i-0123", nil)},
+			want:   AmazonEKS,
+		},
+		"K3s": {
+			reason: "k3s should be detected by the provider IDs of its nodes.",
+			nodes:  []runtime.Object{node("k3s://node", nil)},
+			want:   K3s,
+		},
+		"RKE2": {
+			reason: "RKE2 should be detected by the provider IDs of its nodes.",
+			nodes:  []runtime.Object{node("rke2://node", nil)},
+			want:   RKE2,
+		},
+		"OpenShift": {
+			reason: "OpenShift should be detected by its API groups, even if it runs on a cloud provider.",
+			nodes:  []runtime.Object{node("aws:///us-east-1a/i-0123", nil)},
+			groups: []string{"config.openshift.io"},
+			want:   OpenShift,
+		},
+		"VCluster": {
+			reason: "vcluster should be detected by the labels of its fake nodes.",
+			nodes:  []runtime.Object{node("gce://project/zone/node", map[string]string{"vcluster.loft.sh/fake-node": "true"})},
+			want:   VCluster,
+		},
+		"Custom": {
+			reason: "Custom profiles should be detected before the built-in ones.",
+			nodes:  []runtime.Object{node("aws:///us-east-1a/i-0123", map[string]string{"acme.io/cluster": "true"})},
+			custom: custom,
+			want:   "acme",
+		},
+		"Generic": {
+			reason: "Clusters that match no profile should be generic.",
+			nodes:  []runtime.Object{node("", nil)},
+			want:   Generic,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kClient := kubefake.NewSimpleClientset(tc.nodes...)
+			for _, g := range tc.groups {
+				kClient.Discovery().(*discoveryfake.FakeDiscovery).Resources = append(kClient.Discovery().(*discoveryfake.FakeDiscovery).Resources, &metav1.APIResourceList{GroupVersion: g + "/v1"})
+			}
+			got := detectKubernetes(kClient, tc.custom)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ndetectKubernetes(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetConfig(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "profiles.yaml")
+	if err := os.WriteFile(file, []byte(`
+profiles:
+- name: Kind
+  spacesValues:
+    clusterType: kind
+    storageClass: standard
+- name: acme
+  detect:
+    nodeLabels:
+      acme.io/cluster: "true"
+  publicIngress: true
+  prerequisiteValues:
+    ingress-nginx:
+      controller:
+        replicaCount: 2
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	custom, err := ReadProfiles(file)
+	if err != nil {
+		t.Fatalf("ReadProfiles(...): %s", err)
+	}
+
+	cases := map[string]struct {
+		reason   string
+		override string
+		want     *CloudConfig
+	}{
+		"BuiltIn": {
+			reason:   "The defaults of built-in profiles should be returned.",
+			override: "eks",
+			want: &CloudConfig{
+				SpacesValues:       map[string]string{ClusterTypeStr: "eks"},
+				PublicIngress:      true,
+				PrerequisiteValues: map[string]map[string]any{},
+			},
+		},
+		"VCluster": {
+			reason:   "vcluster should disable the admission webhooks of ingress-nginx and not be exposed publicly.",
+			override: "vcluster",
+			want: &CloudConfig{
+				SpacesValues: map[string]string{},
+				PrerequisiteValues: map[string]map[string]any{
+					"ingress-nginx": {"controller": map[string]any{"admissionWebhooks": map[string]any{"enabled": false}}},
+				},
+			},
+		},
+		"OpenShift": {
+			reason:   "OpenShift should not pin the users of Crossplane and ingress-nginx pods.",
+			override: "openshift",
+			want: &CloudConfig{
+				SpacesValues: map[string]string{},
+				PrerequisiteValues: map[string]map[string]any{
+					"universal-crossplane": {
+						"securityContextCrossplane":  map[string]any{"runAsUser": nil, "runAsGroup": nil},
+						"securityContextRBACManager": map[string]any{"runAsUser": nil, "runAsGroup": nil},
+					},
+					"ingress-nginx": {"controller": map[string]any{"image": map[string]any{"runAsUser": nil}}},
+				},
+			},
+		},
+		"RKE2": {
+			reason:   "RKE2 should use an ingress class and ports that do not conflict with its own ingress-nginx.",
+			override: "rke2",
+			want: &CloudConfig{
+				SpacesValues: map[string]string{"ingress.className": "upbound-nginx"},
+				PrerequisiteValues: map[string]map[string]any{
+					"ingress-nginx": {"controller": map[string]any{
+						"ingressClassResource": map[string]any{"name": "upbound-nginx", "controllerValue": "k8s.io/upbound-nginx"},
+						"ingressClass":         "upbound-nginx",
+						"hostPort":             map[string]any{"enabled": false},
+						"nodeSelector":         nil,
+					}},
+				},
+			},
+		},
+		"K3s": {
+			reason:   "k3s should expose ingress-nginx on node ports and use the local-path storage class.",
+			override: "k3s",
+			want: &CloudConfig{
+				SpacesValues: map[string]string{"storageClass": "local-path"},
+				PrerequisiteValues: map[string]map[string]any{
+					"ingress-nginx": {"controller": map[string]any{
+						"service":      map[string]any{"type": "NodePort"},
+						"hostPort":     map[string]any{"enabled": false},
+						"nodeSelector": nil,
+					}},
+				},
+			},
+		},
+		"OverrideBuiltIn": {
+			reason:   "Custom profiles should replace built-in profiles of the same name.",
+			override: "kind",
+			want: &CloudConfig{
+				SpacesValues:       map[string]string{ClusterTypeStr: "kind", "storageClass": "standard"},
+				PrerequisiteValues: map[string]map[string]any{},
+			},
+		},
+		"Custom": {
+			reason:   "Custom profiles should be selectable by name.",
+			override: "acme",
+			want: &CloudConfig{
+				SpacesValues:       map[string]string{},
+				PublicIngress:      true,
+				PrerequisiteValues: map[string]map[string]any{"ingress-nginx": {"controller": map[string]any{"replicaCount": float64(2)}}},
+			},
+		},
+		"Unknown": {
+			reason:   "Unknown cloud types should be exposed publicly.",
+			override: "other",
+			want:     &CloudConfig{PublicIngress: true},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := GetConfig(kubefake.NewSimpleClientset(), tc.override, custom...)
+			if err != nil {
+				t.Fatalf("GetConfig(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nGetConfig(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	errReadProfiles        = "unable to read vendor profiles"
+	errFmtProfileNameEmpty = "profile %d has no name"
+	errFmtProfileDuplicate = "duplicate profile %q"
+)
+
+// Profile is the set of defaults of a Kubernetes distribution or cloud
+// provider.
+type Profile struct {
+	// Name of the profile, which is also the cloud type.
+	Name CloudType `json:"name"`
+	// Detect describes how clusters of the profile are detected. Profiles
+	// without detection rules are only used if selected explicitly.
+	Detect Detection `json:"detect,omitempty"`
+	// SpacesValues are Helm values of Spaces.
+	SpacesValues map[string]string `json:"spacesValues,omitempty"`
+	// PublicIngress exposes the ingress controller through a load balancer.
+	PublicIngress bool `json:"publicIngress,omitempty"`
+	// PrerequisiteValues are the Helm values of prerequisite charts, keyed
+	// by chart name, e.g. ingress-nginx.
+	PrerequisiteValues map[string]map[string]any `json:"prerequisiteValues,omitempty"`
+}
+
+// Detection describes how clusters are detected. All of its rules that are
+// set must match.
+type Detection struct {
+	// ProviderIDPrefix matches clusters with a node whose provider ID has
+	// the prefix, e.g. aws for aws:///us-east-1a/i-0123.
+	ProviderIDPrefix string `json:"providerIDPrefix,omitempty"`
+	// NodeLabels matches clusters with a node that has all labels.
+	NodeLabels map[string]string `json:"nodeLabels,omitempty"`
+	// APIGroups matches clusters that serve all API groups.
+	APIGroups []string `json:"apiGroups,omitempty"`
+}
+
+// profilesFile is the file custom profiles are read from.
+type profilesFile struct {
+	Profiles []Profile `json:"profiles"`
+}
+
+// cluster is what detection rules are matched against.
+type cluster struct {
+	nodes  []corev1.Node
+	groups map[string]bool
+}
+
+// ReadProfiles reads custom vendor profiles from a file.
+func ReadProfiles(file string) ([]Profile, error) {
+	b, err := os.ReadFile(file) //nolint:gosec
+	if err != nil {
+		return nil, errors.Wrap(err, errReadProfiles)
+	}
+	f := &profilesFile{}
+	if err := yaml.UnmarshalStrict(b, f); err != nil {
+		return nil, errors.Wrap(err, errReadProfiles)
+	}
+	seen := map[CloudType]bool{}
+	for i := range f.Profiles {
+		p := &f.Profiles[i]
+		p.Name = CloudType(strings.ToLower(string(p.Name)))
+		if p.Name == "" {
+			return nil, errors.Wrap(fmt.Errorf(errFmtProfileNameEmpty, i), errReadProfiles)
+		}
+		if seen[p.Name] {
+			return nil, errors.Wrap(fmt.Errorf(errFmtProfileDuplicate, p.Name), errReadProfiles)
+		}
+		seen[p.Name] = true
+	}
+	return f.Profiles, nil
+}
+
+// config returns the defaults of the profile. The values are copied, so
+// that callers can modify them.
+func (p Profile) config() CloudConfig {
+	spaces := make(map[string]string, len(p.SpacesValues))
+	for k, v := range p.SpacesValues {
+		spaces[k] = v
+	}
+	prereqs := make(map[string]map[string]any, len(p.PrerequisiteValues))
+	for k, v := range p.PrerequisiteValues {
+		prereqs[k] = v
+	}
+	return CloudConfig{
+		SpacesValues:       spaces,
+		PublicIngress:      p.PublicIngress,
+		PrerequisiteValues: prereqs,
+	}
+}
+
+func (d Detection) empty() bool {
+	return d.ProviderIDPrefix == "" && len(d.NodeLabels) == 0 && len(d.APIGroups) == 0
+}
+
+// matches returns true if the cluster matches all rules of the detection.
+func (d Detection) matches(c cluster) bool {
+	if d.empty() {
+		return false
+	}
+	for _, g := range d.APIGroups {
+		if !c.groups[g] {
+			return false
+		}
+	}
+	if d.ProviderIDPrefix == "" && len(d.NodeLabels) == 0 {
+		return true
+	}
+	for _, n := range c.nodes {
+		if d.matchesNode(n) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d Detection) matchesNode(n corev1.Node) bool {
+	if d.ProviderIDPrefix != "" && strings.Split(n.Spec.ProviderID, "://")[0] != d.ProviderIDPrefix {
+		return false
+	}
+	for k, v := range d.NodeLabels {
+		if n.GetLabels()[k] != v {
+			return false
+		}
+	}
+	return true
+}
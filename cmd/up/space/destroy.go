@@ -17,7 +17,6 @@ package space
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"github.com/alecthomas/kong"
 	"github.com/pterm/pterm"
@@ -47,7 +46,7 @@ type destroyCmd struct {
 }
 
 // AfterApply sets default values in command after assignment and validation.
-func (c *destroyCmd) AfterApply(kongCtx *kong.Context) error {
+func (c *destroyCmd) AfterApply(kongCtx *kong.Context, confirmer *input.Confirmer) error {
 	if err := c.Kube.AfterApply(); err != nil {
 		return err
 	}
@@ -90,15 +89,17 @@ func (c *destroyCmd) AfterApply(kongCtx *kong.Context) error {
 	pterm.EnableStyling()
 	upterm.DefaultObjPrinter.Pretty = true
 
-	c.confirm()
-
-	return nil
+	return c.confirm(confirmer, upCtx.ProfileName)
 }
 
-// confirm prompts for confirmation and exits if the user declines.
-func (c *destroyCmd) confirm() {
+// confirm prompts for confirmation and fails if the user declines.
+func (c *destroyCmd) confirm(confirmer *input.Confirmer, profile string) error {
+	target := "Space and all control planes"
+	if c.Orphan {
+		target = "Space components, retaining control planes"
+	}
 	if c.Confirmed {
-		return
+		return confirmer.Confirm(input.Confirmation{Target: target, Profile: profile, Approved: true})
 	}
 	if c.Orphan {
 		pterm.Info.Println()
@@ -118,16 +119,12 @@ func (c *destroyCmd) confirm() {
 		pterm.Println()
 	}
 
-	prompter := input.NewPrompter()
-	in, err := prompter.Prompt(fmt.Sprintf("To proceed, type: %q", confirmStr), false)
-	if err != nil {
-		pterm.Error.Printfln("error getting user confirmation: %v", err)
-		os.Exit(1)
-	}
-	if in != confirmStr {
-		pterm.Error.Println("Destruction was not confirmed")
-		os.Exit(10)
-	}
+	return confirmer.Confirm(input.Confirmation{
+		Target:  target,
+		Profile: profile,
+		Prompt:  "Destroy the Space?",
+		Phrase:  confirmStr,
+	})
 }
 
 // getKubeconfig returns the kubeconfig from flags if provided, otherwise the
@@ -0,0 +1,84 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package space
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/upterm"
+)
+
+const (
+	errFmtChecksFailed = "%d checks failed"
+)
+
+// doctorCmd diagnoses common problems of a Space.
+type doctorCmd struct {
+	Upbound       upbound.Flags      `embed:""`
+	Kube          upbound.KubeFlags  `embed:""`
+	Prerequisites prerequisitesFlags `embed:""`
+
+	checker *healthChecker
+}
+
+// AfterApply sets default values in command after assignment and validation.
+func (c *doctorCmd) AfterApply() error {
+	if err := c.Prerequisites.AfterApply(); err != nil {
+		return err
+	}
+	checker, err := newHealthChecker(&c.Kube, c.Upbound, c.Prerequisites.config)
+	if err != nil {
+		return err
+	}
+	c.checker = checker
+	return nil
+}
+
+func (c *doctorCmd) Help() string {
+	return `
+The doctor command runs the checks of 'up space status' and then inspects the
+cluster in more detail: node readiness, the image pull secret of Spaces, pods
+that are unschedulable or stuck pulling images or crash looping, whether the
+external address of the ingress controller accepts connections, and the
+control planes that are not ready. Each problem found comes with a suggested
+fix.
+
+The command exits with an error if any check failed. Warnings do not cause an
+error.`
+}
+
+// Run executes the doctor command.
+func (c *doctorCmd) Run(ctx context.Context, printer upterm.ObjectPrinter, p pterm.TextPrinter) error {
+	checks := c.checker.doctor(ctx)
+	if printer.Format != config.Default {
+		if err := printer.Print(checks, nil, nil); err != nil {
+			return err
+		}
+	} else if err := printer.Print(checks, doctorFieldNames, extractDoctorFields); err != nil {
+		return err
+	}
+	if n := failed(checks); n > 0 {
+		return errors.Errorf(errFmtChecksFailed, n)
+	}
+	if printer.Format == config.Default {
+		p.Println("No problems found.")
+	}
+	return nil
+}
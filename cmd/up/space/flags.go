@@ -10,6 +10,7 @@ import (
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 
+	"github.com/upbound/up/cmd/up/space/prerequisites"
 	"github.com/upbound/up/internal/input"
 )
 
@@ -61,3 +62,21 @@ func (p *authorizedRegistryFlags) AfterApply() error {
 
 	return nil
 }
+
+type prerequisitesFlags struct {
+	PrerequisitesConfig string `name:"prerequisites-config" type:"existingfile" help:"File that pins or overrides the versions and Helm values of prerequisites."`
+
+	config *prerequisites.Config
+}
+
+func (p *prerequisitesFlags) AfterApply() error {
+	if p.PrerequisitesConfig == "" {
+		return nil
+	}
+	cfg, err := prerequisites.ReadConfig(p.PrerequisitesConfig)
+	if err != nil {
+		return err
+	}
+	p.config = cfg
+	return nil
+}
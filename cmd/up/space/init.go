@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 	"time"
 
@@ -25,6 +26,9 @@ import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/pterm/pterm"
 	"golang.org/x/exp/maps"
 	"helm.sh/helm/v3/pkg/chart"
@@ -36,12 +40,13 @@ import (
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
-	"sigs.k8s.io/yaml"
 
 	"github.com/upbound/up/cmd/up/space/defaults"
 	"github.com/upbound/up/cmd/up/space/prerequisites"
 	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/input"
 	"github.com/upbound/up/internal/install"
+	"github.com/upbound/up/internal/install/bundle"
 	"github.com/upbound/up/internal/install/helm"
 	"github.com/upbound/up/internal/kube"
 	"github.com/upbound/up/internal/profile"
@@ -88,15 +93,21 @@ const (
 
 // initCmd installs Upbound Spaces.
 type initCmd struct {
-	Kube     upbound.KubeFlags       `embed:""`
-	Registry authorizedRegistryFlags `embed:""`
+	Kube          upbound.KubeFlags       `embed:""`
+	Registry      authorizedRegistryFlags `embed:""`
+	Prerequisites prerequisitesFlags      `embed:""`
 	install.CommonParams
 	Upbound upbound.Flags `embed:""`
 
-	Version       string `arg:"" help:"Upbound Spaces version to install."`
-	Yes           bool   `name:"yes" type:"bool" help:"Answer yes to all questions"`
-	PublicIngress bool   `name:"public-ingress" type:"bool" help:"For AKS,EKS,GKE expose ingress publically"`
+	Version        string `arg:"" optional:"" help:"Upbound Spaces version to install. Required unless set in the SpaceConfig passed with --file."`
+	PublicIngress  bool   `name:"public-ingress" type:"bool" help:"For AKS,EKS,GKE expose ingress publically"`
+	DryRun         bool   `help:"Print the prerequisites, Helm values and resources of the installation without changing the cluster."`
+	VendorProfiles string `name:"vendor-profiles" type:"existingfile" help:"File with custom profiles of Kubernetes distributions, which are detected before the built-in ones and can be selected with --set clusterType=<name>."`
 
+	stdout     io.Writer
+	defs       *defaults.CloudConfig
+	bundle     *bundle.Bundle
+	mirror     *bundle.Mirror
 	helmMgr    install.Manager
 	prereqs    *prerequisites.Manager
 	parser     install.ParameterParser
@@ -104,6 +115,8 @@ type initCmd struct {
 	dClient    dynamic.Interface
 	pullSecret *kube.ImagePullApplicator
 	quiet      config.QuietFlag
+	confirmer  *input.Confirmer
+	spaceCfg   *spaceConfig
 }
 
 func init() {
@@ -118,14 +131,71 @@ func (c *initCmd) BeforeApply() error {
 	return nil
 }
 
+// Help returns the help text of the init command.
+func (c *initCmd) Help() string {
+	return `
+The init command installs the prerequisites of Spaces that are missing and then
+Spaces itself, and creates a profile for the Space.
+
+Instead of flags, the installation can be described by a SpaceConfig passed
+with --file. The same file can be passed to 'up space upgrade', so that the
+configuration of a Space can be kept in Git and applied repeatedly. Flags and
+--set take precedence over the file. Files that are not a SpaceConfig are read
+as Helm values of Spaces.
+
+  apiVersion: up.upbound.io/v1alpha1
+  kind: SpaceConfig
+  version: 1.2.0
+  registry:
+    # Relative to the SpaceConfig.
+    tokenFile: key.json
+  cloud: eks
+  publicIngress: true
+  prerequisites:
+    cert-manager:
+      version: v1.13.1
+  values:
+    account: my-org
+  profile:
+    name: my-space
+
+Examples:
+  # Install Spaces v1.2.0.
+  up space init v1.2.0 --token-file=key.json
+
+  # Install Spaces as described by a SpaceConfig.
+  up space init -f space.yaml`
+}
+
 // AfterApply sets default values in command after assignment and validation.
-func (c *initCmd) AfterApply(kongCtx *kong.Context, quiet config.QuietFlag) error { //nolint:gocyclo
+func (c *initCmd) AfterApply(kongCtx *kong.Context, quiet config.QuietFlag, confirmer *input.Confirmer) error { //nolint:gocyclo
+	c.confirmer = confirmer
+	base, spaceCfg, err := readParametersFile(c.File)
+	if err != nil {
+		return err
+	}
+	if spaceCfg != nil {
+		if c.Set == nil {
+			c.Set = map[string]string{}
+		}
+		if err := spaceCfg.apply(&c.Version, c.Set, &c.Registry, &c.Prerequisites); err != nil {
+			return err
+		}
+		c.PublicIngress = c.PublicIngress || spaceCfg.PublicIngress
+		c.spaceCfg = spaceCfg
+	}
+	if c.Version == "" {
+		return errors.New(errVersionRequired)
+	}
 	if err := c.Kube.AfterApply(); err != nil {
 		return err
 	}
 	if err := c.Registry.AfterApply(); err != nil {
 		return err
 	}
+	if err := c.Prerequisites.AfterApply(); err != nil {
+		return err
+	}
 
 	// NOTE(tnthornton) we currently only have support for stylized output.
 	pterm.EnableStyling()
@@ -136,6 +206,7 @@ func (c *initCmd) AfterApply(kongCtx *kong.Context, quiet config.QuietFlag) erro
 		return err
 	}
 	kongCtx.Bind(upCtx)
+	c.stdout = kongCtx.Stdout
 
 	kubeconfig := c.Kube.GetConfig()
 
@@ -146,8 +217,14 @@ func (c *initCmd) AfterApply(kongCtx *kong.Context, quiet config.QuietFlag) erro
 	c.kClient = kClient
 
 	// set the defaults
+	var profiles []defaults.Profile
+	if c.VendorProfiles != "" {
+		if profiles, err = defaults.ReadProfiles(c.VendorProfiles); err != nil {
+			return err
+		}
+	}
 	cloud := c.Set[defaults.ClusterTypeStr]
-	defs, err := defaults.GetConfig(c.kClient, cloud)
+	defs, err := defaults.GetConfig(c.kClient, cloud, profiles...)
 	if err != nil {
 		return err
 	}
@@ -160,7 +237,18 @@ func (c *initCmd) AfterApply(kongCtx *kong.Context, quiet config.QuietFlag) erro
 		pterm.Info.Println("Public ingress will be exposed")
 	}
 
-	prereqs, err := prerequisites.New(kubeconfig, defs)
+	c.defs = defs
+
+	chartFile := c.Bundle
+	prereqOpts := []prerequisites.Option{prerequisites.WithConfig(c.Prerequisites.config)}
+	if c.Bundle != nil && bundle.IsBundle(c.Bundle) {
+		if chartFile, err = c.openBundle(); err != nil {
+			return err
+		}
+		prereqOpts = append(prereqOpts, prerequisites.FromBundle(c.bundle, c.mirror))
+	}
+
+	prereqs, err := prerequisites.New(kubeconfig, defs, prereqOpts...)
 	if err != nil {
 		return err
 	}
@@ -173,34 +261,26 @@ func (c *initCmd) AfterApply(kongCtx *kong.Context, quiet config.QuietFlag) erro
 		return err
 	}
 	c.dClient = dClient
-	mgr, err := helm.NewManager(kubeconfig,
-		spacesChart,
-		c.Registry.Repository,
+	mods := []helm.InstallerModifierFn{
 		helm.WithNamespace(ns),
 		helm.WithBasicAuth(c.Registry.Username, c.Registry.Password),
 		helm.IsOCI(),
-		helm.WithChart(c.Bundle),
+		helm.WithChart(chartFile),
 		helm.Wait(),
+	}
+	if c.mirror != nil {
+		mods = append(mods, helm.WithPostRenderer(c.mirror))
+	}
+	mgr, err := helm.NewManager(kubeconfig,
+		spacesChart,
+		c.Registry.Repository,
+		mods...,
 	)
 	if err != nil {
 		return err
 	}
 	c.helmMgr = mgr
 
-	base := map[string]any{}
-	if c.File != nil {
-		defer c.File.Close() //nolint:errcheck,gosec
-		b, err := io.ReadAll(c.File)
-		if err != nil {
-			return errors.Wrap(err, errReadParametersFile)
-		}
-		if err := yaml.Unmarshal(b, &base); err != nil {
-			return errors.Wrap(err, errReadParametersFile)
-		}
-		if err := c.File.Close(); err != nil {
-			return errors.Wrap(err, errReadParametersFile)
-		}
-	}
 	c.parser = helm.NewParser(base, c.Set)
 	c.quiet = quiet
 
@@ -209,6 +289,10 @@ func (c *initCmd) AfterApply(kongCtx *kong.Context, quiet config.QuietFlag) erro
 
 // Run executes the install command.
 func (c *initCmd) Run(ctx context.Context, upCtx *upbound.Context) error {
+	if c.bundle != nil {
+		defer c.bundle.Close() //nolint:errcheck
+	}
+
 	params, err := c.parser.Parse()
 	if err != nil {
 		return errors.Wrap(err, errParseInstallParameters)
@@ -219,6 +303,16 @@ func (c *initCmd) Run(ctx context.Context, upCtx *upbound.Context) error {
 	// check if required prerequisites are installed
 	status := c.prereqs.Check()
 
+	if c.DryRun {
+		return c.plan(params, status)
+	}
+
+	if c.bundle != nil {
+		if err := c.pushBundle(ctx); err != nil {
+			return err
+		}
+	}
+
 	// At least 1 prerequisite is not installed, check if we should install the
 	// missing ones for the client.
 	if len(status.NotInstalled) > 0 {
@@ -228,17 +322,16 @@ func (c *initCmd) Run(ctx context.Context, upCtx *upbound.Context) error {
 			pterm.Println(fmt.Sprintf("❌ %s", p.GetName()))
 		}
 
-		if !c.Yes {
-			pterm.Println() // Blank line
-			confirm := pterm.DefaultInteractiveConfirm
-			confirm.DefaultText = "Would you like to install them now?"
-			result, _ := confirm.Show()
-			pterm.Println() // Blank line
-			if !result {
-				pterm.Error.Println("prerequisites must be met in order to proceed with installation")
-				return nil
-			}
+		pterm.Println() // Blank line
+		if err := c.confirmer.Confirm(input.Confirmation{
+			Target:  fmt.Sprintf("install of %d prerequisites", len(status.NotInstalled)),
+			Profile: upCtx.ProfileName,
+			Prompt:  "Would you like to install them now?",
+		}); err != nil {
+			pterm.Error.Println("prerequisites must be met in order to proceed with installation")
+			return err
 		}
+		pterm.Println() // Blank line
 		if err := c.installPrereqs(); err != nil {
 			return err
 		}
@@ -262,10 +355,90 @@ func (c *initCmd) Run(ctx context.Context, upCtx *upbound.Context) error {
 	return c.createOrUpdateProfile(getAcct(params), upCtx)
 }
 
+// openBundle opens the bundle of an air-gapped installation, maps its images
+// into the private registry and returns the Spaces chart of the bundle.
+func (c *initCmd) openBundle() (f *os.File, err error) {
+	if c.Registry.Repository.String() == defaultRegistry {
+		return nil, errors.New(errBundleRegistry)
+	}
+	repo, err := name.NewRepository(c.Registry.Repository.String())
+	if err != nil {
+		return nil, errors.Wrap(err, errOpenBundle)
+	}
+	b, err := bundle.Open(c.Bundle.Name())
+	if err != nil {
+		return nil, errors.Wrap(err, errOpenBundle)
+	}
+	defer func() {
+		if err != nil {
+			_ = b.Close()
+		}
+	}()
+	c.bundle = b
+	e, f, err := b.Chart(spacesChart)
+	if err != nil {
+		return nil, errors.Wrap(err, errOpenBundle)
+	}
+	if v := strings.TrimPrefix(c.Version, "v"); e.ChartVersion != v {
+		return nil, errors.Errorf(errFmtBundleMismatch, e.ChartVersion, v)
+	}
+	if c.mirror, err = b.NewMirror(repo, b.Annotations()[annotationSpacesRegistry]); err != nil {
+		return nil, errors.Wrap(err, errOpenBundle)
+	}
+	return f, nil
+}
+
+// pushBundle pushes the images and packages of the bundle to the private
+// registry.
+func (c *initCmd) pushBundle(ctx context.Context) error {
+	push := func() error {
+		return errors.Wrap(c.bundle.Push(ctx, c.mirror, remote.WithAuth(&authn.Basic{
+			Username: c.Registry.Username,
+			Password: c.Registry.Password,
+		})), errPushBundle)
+	}
+	if c.quiet {
+		return push()
+	}
+	return upterm.WrapWithSuccessSpinner(
+		fmt.Sprintf("Pushing bundled images to %s", c.Registry.Repository),
+		upterm.CheckmarkSuccessSpinner,
+		push,
+	)
+}
+
+// plan prints the changes the installation would make.
+func (c *initCmd) plan(params map[string]any, status *prerequisites.Status) error {
+	plan, err := c.helmMgr.PlanInstall(strings.TrimPrefix(c.Version, "v"), params, initVersionBounds, upVersionBounds)
+	if err != nil {
+		return err
+	}
+	missing := make([]prerequisites.VersionStatus, 0, len(status.NotInstalled))
+	for _, p := range status.NotInstalled {
+		missing = append(missing, prerequisites.VersionStatus{Prerequisite: p, Desired: p.GetVersion()})
+	}
+	if err := writePlan(c.stdout, missing, c.defs, plan); err != nil {
+		return err
+	}
+	pterm.Println()
+	pterm.Info.Println("Dry run complete, no changes have been made to the cluster.")
+	return nil
+}
+
 // createOrUpdateProfile updates the active profile to access the new space,
 // or if there is no active profile, creates a new profile. The profile is set
 // as the default.
 func (c *initCmd) createOrUpdateProfile(acct string, upCtx *upbound.Context) error {
+	if c.spaceCfg != nil {
+		if c.spaceCfg.Profile.Disabled {
+			return nil
+		}
+		if n := c.spaceCfg.Profile.Name; n != "" && n != upCtx.ProfileName {
+			// Carry over the existing config of the named profile, if any.
+			p, _ := upCtx.Cfg.GetUpboundProfile(n)
+			upCtx.ProfileName, upCtx.Profile = n, p
+		}
+	}
 	// If profile name was not provided and no default exists, set name to
 	// the default.
 	if upCtx.ProfileName == "" {
@@ -0,0 +1,140 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package space
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"github.com/upbound/up/cmd/up/space/defaults"
+	"github.com/upbound/up/cmd/up/space/prerequisites"
+	"github.com/upbound/up/internal/install"
+)
+
+const (
+	errDiffManifests = "unable to compare rendered manifests"
+	errMarshalValues = "unable to marshal values"
+)
+
+// writePlan writes a plan of an install or upgrade of Spaces to w. Plans of
+// installs list the resources that would be created, plans of upgrades
+// include the diff of every changed resource. Prerequisites that would be
+// installed or upgraded are listed unless prereqs is nil.
+func writePlan(w io.Writer, prereqs []prerequisites.VersionStatus, defs *defaults.CloudConfig, plan *install.Plan) error { //nolint:gocyclo
+	upgrade := plan.CurrentVersion != ""
+
+	if prereqs != nil {
+		fmt.Fprintln(w, "Prerequisites:")
+		switch {
+		case len(prereqs) > 0:
+		case upgrade:
+			fmt.Fprintln(w, "  All prerequisites are up to date.")
+		default:
+			fmt.Fprintln(w, "  All prerequisites are installed.")
+		}
+		for _, v := range prereqs {
+			if v.Installed == "" {
+				fmt.Fprintf(w, "  + %s %s\n", v.Prerequisite.GetName(), v.Desired)
+				continue
+			}
+			fmt.Fprintf(w, "  ~ %s %s -> %s\n", v.Prerequisite.GetName(), v.Installed, v.Desired)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, "Chart:")
+	if upgrade {
+		fmt.Fprintf(w, "  %s %s -> %s\n", spacesChart, plan.CurrentVersion, plan.Version)
+	} else {
+		fmt.Fprintf(w, "  %s %s\n", spacesChart, plan.Version)
+	}
+	fmt.Fprintln(w)
+
+	if defs != nil {
+		fmt.Fprintln(w, "Cluster defaults:")
+		keys := make([]string, 0, len(defs.SpacesValues))
+		for k := range defs.SpacesValues {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(w, "  %s=%s\n", k, defs.SpacesValues[k])
+		}
+		fmt.Fprintf(w, "  public ingress: %t\n", defs.PublicIngress)
+		if len(defs.PrerequisiteValues) > 0 {
+			charts := make([]string, 0, len(defs.PrerequisiteValues))
+			for ch := range defs.PrerequisiteValues {
+				charts = append(charts, ch)
+			}
+			sort.Strings(charts)
+			fmt.Fprintf(w, "  prerequisite values: %s\n", strings.Join(charts, ", "))
+		}
+		fmt.Fprintln(w)
+	}
+
+	b, err := yaml.Marshal(plan.Values)
+	if err != nil {
+		return errors.Wrap(err, errMarshalValues)
+	}
+	fmt.Fprintln(w, "Values:")
+	fmt.Fprint(w, indent(string(b), "  "))
+	fmt.Fprintln(w)
+
+	diffs, err := install.DiffManifests(plan.CurrentManifest, plan.Manifest)
+	if err != nil {
+		return errors.Wrap(err, errDiffManifests)
+	}
+	fmt.Fprintln(w, "Resources:")
+	counts := map[install.Change]int{}
+	for _, d := range diffs {
+		counts[d.Change]++
+		fmt.Fprintf(w, "  %s %s\n", changeSymbol(d.Change), d.Resource)
+		if upgrade {
+			fmt.Fprint(w, indent(d.Diff, "      "))
+		}
+	}
+	if len(diffs) == 0 {
+		fmt.Fprintln(w, "  No changes.")
+	}
+	fmt.Fprintf(w, "\n%d to add, %d to change, %d to remove.\n", counts[install.ChangeAdd], counts[install.ChangeUpdate], counts[install.ChangeRemove])
+	return nil
+}
+
+func changeSymbol(c install.Change) string {
+	switch c {
+	case install.ChangeAdd:
+		return "+"
+	case install.ChangeRemove:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// indent prefixes every non-empty line of s.
+func indent(s, prefix string) string {
+	lines := strings.SplitAfter(s, "\n")
+	for i, l := range lines {
+		if strings.TrimSpace(l) != "" {
+			lines[i] = prefix + l
+		}
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package space
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/cmd/up/space/defaults"
+	"github.com/upbound/up/cmd/up/space/prerequisites"
+	"github.com/upbound/up/internal/install"
+)
+
+type fakePrerequisite struct {
+	name             string
+	version          string
+	installedVersion string
+	err              error
+}
+
+func (p *fakePrerequisite) GetName() string    { return p.name }
+func (p *fakePrerequisite) GetVersion() string { return p.version }
+func (p *fakePrerequisite) GetInstalledVersion() (string, error) {
+	return p.installedVersion, p.err
+}
+func (p *fakePrerequisite) Install() error    { return nil }
+func (p *fakePrerequisite) IsInstalled() bool { return false }
+func (p *fakePrerequisite) Upgrade() error    { return nil }
+
+func TestWritePlan(t *testing.T) {
+	type args struct {
+		prereqs []prerequisites.VersionStatus
+		defs    *defaults.CloudConfig
+		plan    *install.Plan
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   string
+	}{
+		"Install": {
+			reason: "A plan of an install should list missing prerequisites, cluster defaults and created resources.",
+			args: args{
+				prereqs: []prerequisites.VersionStatus{{Prerequisite: &fakePrerequisite{name: "cert-manager"}, Desired: "v1.11.0"}},
+				defs: &defaults.CloudConfig{
+					SpacesValues:  map[string]string{"clusterType": "kind"},
+					PublicIngress: false,
+				},
+				plan: &install.Plan{
+					Version:  "1.2.0",
+					Values:   map[string]any{"account": "acct"},
+					Manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cfg\n  namespace: upbound-system\n",
+				},
+			},
+			want: `Prerequisites:
+  + cert-manager v1.11.0
+
+Chart:
+  spaces 1.2.0
+
+Cluster defaults:
+  clusterType=kind
+  public ingress: false
+
+Values:
+  account: acct
+
+Resources:
+  + ConfigMap/upbound-system/cfg
+
+1 to add, 0 to change, 0 to remove.
+`,
+		},
+		"UpgradeNoChanges": {
+			reason: "A plan of an upgrade with an unchanged manifest should report no changes.",
+			args: args{
+				plan: &install.Plan{
+					CurrentVersion:  "1.1.0",
+					Version:         "1.2.0",
+					Values:          map[string]any{},
+					CurrentManifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cfg\n",
+					Manifest:        "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cfg\n",
+				},
+			},
+			want: `Chart:
+  spaces 1.1.0 -> 1.2.0
+
+Values:
+  {}
+
+Resources:
+  No changes.
+
+0 to add, 0 to change, 0 to remove.
+`,
+		},
+		"UpgradePrerequisites": {
+			reason: "A plan of an upgrade should list the prerequisites that would be upgraded.",
+			args: args{
+				prereqs: []prerequisites.VersionStatus{{Prerequisite: &fakePrerequisite{name: "cert-manager"}, Installed: "v1.11.0", Desired: "v1.12.0"}},
+				plan: &install.Plan{
+					CurrentVersion:  "1.2.0",
+					Version:         "1.2.0",
+					Values:          map[string]any{},
+					CurrentManifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cfg\n",
+					Manifest:        "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cfg\n",
+				},
+			},
+			want: `Prerequisites:
+  ~ cert-manager v1.11.0 -> v1.12.0
+
+Chart:
+  spaces 1.2.0 -> 1.2.0
+
+Values:
+  {}
+
+Resources:
+  No changes.
+
+0 to add, 0 to change, 0 to remove.
+`,
+		},
+		"UpgradePrerequisitesUpToDate": {
+			reason: "A plan of an upgrade of prerequisites that are up to date should say so.",
+			args: args{
+				prereqs: []prerequisites.VersionStatus{},
+				plan: &install.Plan{
+					CurrentVersion: "1.1.0",
+					Version:        "1.2.0",
+					Values:         map[string]any{},
+				},
+			},
+			want: `Prerequisites:
+  All prerequisites are up to date.
+
+Chart:
+  spaces 1.1.0 -> 1.2.0
+
+Values:
+  {}
+
+Resources:
+  No changes.
+
+0 to add, 0 to change, 0 to remove.
+`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writePlan(&buf, tc.args.prereqs, tc.args.defs, tc.args.plan); err != nil {
+				t.Fatalf("\n%s\nwritePlan(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, buf.String()); diff != "" {
+				t.Errorf("\n%s\nwritePlan(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package space
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/upbound/up/cmd/up/space/prerequisites"
+)
+
+var crdGVR = extv1.SchemeGroupVersion.WithResource("customresourcedefinitions")
+
+// preflight runs the checks that must pass before Spaces is upgraded. The
+// versions of outdated prerequisites are only reported as warnings if they
+// are not going to be upgraded. No further checks are run if the cluster is
+// unreachable.
+func (h *healthChecker) preflight(ctx context.Context, upgradePrereqs bool) []check {
+	cluster := h.checkCluster()
+	if cluster.Status == checkFailed {
+		return []check{cluster}
+	}
+	checks := []check{h.checkKubernetesVersion()}
+	checks = append(checks, h.checkPrerequisiteVersions(upgradePrereqs)...)
+	cctx, cancel := h.withTimeout(ctx)
+	checks = append(checks, h.checkCapacity(cctx))
+	cancel()
+	checks = append(checks, h.checkDeprecatedAPIs(ctx)...)
+	return checks
+}
+
+// verify runs the checks that pass once an upgrade has completed: every
+// Spaces deployment has rolled out and every control plane is ready.
+func (h *healthChecker) verify(ctx context.Context) []check {
+	checks := []check{}
+	l, err := h.kClient.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return []check{{Component: "Deployments", Status: checkFailed, Message: err.Error()}}
+	}
+	for _, d := range l.Items {
+		c := check{Component: "Deployment " + d.GetName(), Status: checkOK, Message: "rolled out"}
+		if !rolledOut(d) {
+			c.Status = checkFailed
+			ready := d.Status.UpdatedReplicas
+			if d.Status.AvailableReplicas < ready {
+				ready = d.Status.AvailableReplicas
+			}
+			c.Message = fmt.Sprintf("%d/%d replicas updated and available", ready, replicas(d))
+			c.Fix = fmt.Sprintf("Inspect the deployment with 'kubectl -n %s describe deployment %s'.", ns, d.GetName())
+		}
+		checks = append(checks, c)
+	}
+
+	ctps, err := h.listControlPlanes(ctx)
+	if err != nil {
+		return append(checks, check{Component: "Control planes", Status: checkFailed, Message: err.Error()})
+	}
+	for _, ctp := range ctps {
+		cnd := ctp.GetCondition(xpv1.TypeReady)
+		if resource.IsConditionTrue(cnd) {
+			continue
+		}
+		checks = append(checks, check{
+			Component: "Control plane " + ctp.GetName(),
+			Status:    checkFailed,
+			Message:   fmt.Sprintf("not ready: %s", cnd.Message),
+			Fix:       fmt.Sprintf("Inspect the control plane with 'kubectl describe controlplanes %s'.", ctp.GetName()),
+		})
+	}
+	return checks
+}
+
+// rolledOut returns true if all replicas of a deployment run its current
+// template and are available.
+func rolledOut(d appsv1.Deployment) bool {
+	r := replicas(d)
+	return d.Status.ObservedGeneration >= d.GetGeneration() &&
+		d.Status.Replicas == r &&
+		d.Status.UpdatedReplicas == r &&
+		d.Status.AvailableReplicas == r
+}
+
+func (h *healthChecker) checkKubernetesVersion() check {
+	c := check{Component: "Kubernetes version"}
+	v, err := h.kClient.Discovery().ServerVersion()
+	if err != nil {
+		c.Status = checkFailed
+		c.Message = err.Error()
+		return c
+	}
+	if err := checkVersion(fmt.Sprintf("unsupported Kubernetes version %s", v.GitVersion), kubernetesVersionConstraints, v.GitVersion); err != nil {
+		c.Status = checkFailed
+		c.Message = err.Error()
+		c.Fix = "Upgrade the Kubernetes cluster before upgrading Spaces."
+		return c
+	}
+	c.Status = checkOK
+	c.Message = fmt.Sprintf("%s is supported", v.GitVersion)
+	return c
+}
+
+func (h *healthChecker) checkPrerequisiteVersions(upgradePrereqs bool) []check {
+	checks := make([]check, 0, len(h.prereqs))
+	for _, p := range h.prereqs {
+		c := check{Component: "Prerequisite " + p.GetName(), Status: checkOK}
+		if !p.IsInstalled() {
+			c.Status = checkFailed
+			c.Message = "not installed"
+			c.Fix = "Install missing prerequisites with 'up space init'."
+			checks = append(checks, c)
+			continue
+		}
+		v := prerequisites.VersionStatus{Prerequisite: p, Desired: p.GetVersion()}
+		v.Installed, v.Err = p.GetInstalledVersion()
+		switch {
+		case v.Err != nil:
+			c.Message = "installed, version unknown"
+		case v.Outdated() && upgradePrereqs:
+			c.Message = fmt.Sprintf("will be upgraded from %s to %s", v.Installed, v.Desired)
+		case v.Outdated():
+			c.Status = checkWarning
+			c.Message = fmt.Sprintf("version %s installed, %s desired", v.Installed, v.Desired)
+			c.Fix = "Upgrade prerequisites with --upgrade-prerequisites."
+		default:
+			c.Message = fmt.Sprintf("version %s installed", v.Installed)
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+// checkCapacity checks that the schedulable nodes have enough unrequested
+// CPU and memory to start one additional pod of every Spaces deployment, which
+// a rolling update requires.
+func (h *healthChecker) checkCapacity(ctx context.Context) check { //nolint:gocyclo
+	c := check{Component: "Cluster capacity"}
+	nodes, err := h.kClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		c.Status = checkFailed
+		c.Message = err.Error()
+		return c
+	}
+	pods, err := h.kClient.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		c.Status = checkFailed
+		c.Message = err.Error()
+		return c
+	}
+	deps, err := h.kClient.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		c.Status = checkFailed
+		c.Message = err.Error()
+		return c
+	}
+
+	free := corev1.ResourceList{corev1.ResourceCPU: apiresource.Quantity{}, corev1.ResourceMemory: apiresource.Quantity{}}
+	schedulable := map[string]bool{}
+	for _, n := range nodes.Items {
+		if n.Spec.Unschedulable || !nodeReady(n) {
+			continue
+		}
+		schedulable[n.GetName()] = true
+		addResources(free, n.Status.Allocatable, 1)
+	}
+	for _, p := range pods.Items {
+		if !schedulable[p.Spec.NodeName] || p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		addResources(free, podRequests(p.Spec), -1)
+	}
+	needed := corev1.ResourceList{corev1.ResourceCPU: apiresource.Quantity{}, corev1.ResourceMemory: apiresource.Quantity{}}
+	for _, d := range deps.Items {
+		addResources(needed, podRequests(d.Spec.Template.Spec), 1)
+	}
+
+	freeCPU, freeMem := free[corev1.ResourceCPU], free[corev1.ResourceMemory]
+	neededCPU, neededMem := needed[corev1.ResourceCPU], needed[corev1.ResourceMemory]
+	c.Message = fmt.Sprintf("%s CPU and %s memory free, %s CPU and %s memory needed for the rollout", freeCPU.String(), freeMem.String(), neededCPU.String(), neededMem.String())
+	c.Status = checkOK
+	if freeCPU.Cmp(neededCPU) < 0 || freeMem.Cmp(neededMem) < 0 {
+		c.Status = checkWarning
+		c.Fix = "Add nodes or free capacity, otherwise updated pods may not be scheduled."
+	}
+	return c
+}
+
+// addResources adds the CPU and memory of src, multiplied by sign, to dst.
+func addResources(dst, src corev1.ResourceList, sign int) {
+	for _, r := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		q, ok := src[r]
+		if !ok {
+			continue
+		}
+		sum := dst[r]
+		if sign < 0 {
+			sum.Sub(q)
+		} else {
+			sum.Add(q)
+		}
+		dst[r] = sum
+	}
+}
+
+func podRequests(spec corev1.PodSpec) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, c := range spec.Containers {
+		addResources(requests, c.Resources.Requests, 1)
+	}
+	return requests
+}
+
+// checkDeprecatedAPIs checks whether the control planes have objects of API
+// versions that are deprecated, and may no longer be served after the
+// upgrade.
+func (h *healthChecker) checkDeprecatedAPIs(ctx context.Context) []check {
+	lctx, cancel := h.withTimeout(ctx)
+	ctps, err := h.listControlPlanes(lctx)
+	cancel()
+	if err != nil {
+		return []check{{Component: "Deprecated APIs", Status: checkFailed, Message: err.Error()}}
+	}
+	checks := []check{}
+	ready := 0
+	for _, ctp := range ctps {
+		if !resource.IsConditionTrue(ctp.GetCondition(xpv1.TypeReady)) {
+			continue
+		}
+		ready++
+		c := check{Component: "Control plane " + ctp.GetName()}
+		// Each control plane gets its own timeout, so that a Space with many
+		// control planes is not failed by the total time of the checks.
+		cctx, cancel := h.withTimeout(ctx)
+		used, err := h.deprecatedAPIs(cctx, ctp.GetName())
+		cancel()
+		switch {
+		case err != nil:
+			c.Status = checkWarning
+			c.Message = fmt.Sprintf("unable to check for deprecated APIs: %s", err)
+		case len(used) > 0:
+			c.Status = checkWarning
+			c.Message = fmt.Sprintf("objects of deprecated API versions exist: %s", strings.Join(used, ", "))
+			c.Fix = "Migrate the objects to a supported API version."
+		default:
+			continue
+		}
+		checks = append(checks, c)
+	}
+	if len(checks) == 0 {
+		checks = append(checks, check{Component: "Deprecated APIs", Status: checkOK, Message: fmt.Sprintf("not used by %d ready control planes", ready)})
+	}
+	return checks
+}
+
+// deprecatedAPIs returns the deprecated versions of the custom resources of a
+// control plane that have objects, e.g. controllerconfigs.v1alpha1.pkg.crossplane.io.
+// Only versions that objects may be stored at, according to the stored
+// versions of the CRD, are considered. The API server converts every object to
+// the requested version, so listing a served version alone does not tell
+// whether any object was written at it.
+func (h *healthChecker) deprecatedAPIs(ctx context.Context, ctp string) ([]string, error) {
+	client, err := h.ctpClient(ctx, ctp)
+	if err != nil {
+		return nil, err
+	}
+	l, err := client.Resource(crdGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	used := []string{}
+	for _, u := range l.Items {
+		crd := &extv1.CustomResourceDefinition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, crd); err != nil {
+			return nil, err
+		}
+		stored := map[string]bool{}
+		for _, v := range crd.Status.StoredVersions {
+			stored[v] = true
+		}
+		for _, v := range crd.Spec.Versions {
+			if !v.Served || !v.Deprecated || !stored[v.Name] {
+				continue
+			}
+			gvr := schema.GroupVersionResource{Group: crd.Spec.Group, Version: v.Name, Resource: crd.Spec.Names.Plural}
+			objs, err := client.Resource(gvr).List(ctx, metav1.ListOptions{Limit: 1})
+			if err != nil {
+				return nil, err
+			}
+			if len(objs.Items) > 0 {
+				used = append(used, gvr.String())
+			}
+		}
+	}
+	sort.Strings(used)
+	return used, nil
+}
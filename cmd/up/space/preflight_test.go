@@ -0,0 +1,339 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package space
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/upbound/up/cmd/up/space/prerequisites"
+)
+
+func TestCheckKubernetesVersion(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		version string
+		want    check
+	}{
+		"Supported": {
+			reason:  "A supported Kubernetes version should pass.",
+			version: "v1.27.3-eks-a5565ad",
+			want:    check{Component: "Kubernetes version", Status: checkOK, Message: "v1.27.3-eks-a5565ad is supported"},
+		},
+		"Unsupported": {
+			reason:  "An unsupported Kubernetes version should fail with a fix.",
+			version: "v1.24.0",
+			want: check{
+				Component: "Kubernetes version",
+				Status:    checkFailed,
+				Message:   "unsupported Kubernetes version v1.24.0: Kubernetes 1.25 or later is required.",
+				Fix:       "Upgrade the Kubernetes cluster before upgrading Spaces.",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kClient := kubefake.NewSimpleClientset()
+			kClient.Discovery().(*discoveryfake.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: tc.version}
+			h := &healthChecker{kClient: kClient}
+			got := h.checkKubernetesVersion()
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ncheckKubernetesVersion(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCheckPrerequisiteVersions(t *testing.T) {
+	prereqs := []prerequisites.Prerequisite{
+		&fakeInstalledPrerequisite{fakePrerequisite: fakePrerequisite{name: "uxp"}},
+		&fakeInstalledPrerequisite{fakePrerequisite: fakePrerequisite{name: "cert-manager", version: "v1.12.0", installedVersion: "v1.11.0"}, installed: true},
+		&fakeInstalledPrerequisite{fakePrerequisite: fakePrerequisite{name: "ingress-nginx", version: "4.7.1", installedVersion: "4.7.1"}, installed: true},
+	}
+
+	cases := map[string]struct {
+		reason  string
+		upgrade bool
+		want    []check
+	}{
+		"NoUpgrade": {
+			reason: "Missing prerequisites should fail and outdated ones should warn.",
+			want: []check{
+				{Component: "Prerequisite uxp", Status: checkFailed, Message: "not installed", Fix: "Install missing prerequisites with 'up space init'."},
+				{Component: "Prerequisite cert-manager", Status: checkWarning, Message: "version v1.11.0 installed, v1.12.0 desired", Fix: "Upgrade prerequisites with --upgrade-prerequisites."},
+				{Component: "Prerequisite ingress-nginx", Status: checkOK, Message: "version 4.7.1 installed"},
+			},
+		},
+		"Upgrade": {
+			reason:  "Outdated prerequisites should pass if they are going to be upgraded.",
+			upgrade: true,
+			want: []check{
+				{Component: "Prerequisite uxp", Status: checkFailed, Message: "not installed", Fix: "Install missing prerequisites with 'up space init'."},
+				{Component: "Prerequisite cert-manager", Status: checkOK, Message: "will be upgraded from v1.11.0 to v1.12.0"},
+				{Component: "Prerequisite ingress-nginx", Status: checkOK, Message: "version 4.7.1 installed"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := &healthChecker{prereqs: prereqs}
+			got := h.checkPrerequisiteVersions(tc.upgrade)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ncheckPrerequisiteVersions(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func node(name, cpu, memory string, ready bool) *corev1.Node {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    apiresource.MustParse(cpu),
+				corev1.ResourceMemory: apiresource.MustParse(memory),
+			},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+		},
+	}
+}
+
+func requests(cpu, memory string) corev1.PodSpec {
+	return corev1.PodSpec{Containers: []corev1.Container{{
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    apiresource.MustParse(cpu),
+			corev1.ResourceMemory: apiresource.MustParse(memory),
+		}},
+	}}}
+}
+
+func TestCheckCapacity(t *testing.T) {
+	pod := func(name, nodeName string, phase corev1.PodPhase) *corev1.Pod {
+		spec := requests("1", "1Gi")
+		spec.NodeName = nodeName
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       spec,
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "mxe-controller", Namespace: ns},
+		Spec:       appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: requests("500m", "512Mi")}},
+	}
+
+	cases := map[string]struct {
+		reason string
+		kube   []runtime.Object
+		want   check
+	}{
+		"Sufficient": {
+			reason: "Free capacity on ready nodes, ignoring completed pods, should be enough for the rollout.",
+			kube:   []runtime.Object{node("n1", "2", "2Gi", true), pod("p1", "n1", corev1.PodRunning), pod("p2", "n1", corev1.PodSucceeded), dep},
+			want:   check{Component: "Cluster capacity", Status: checkOK, Message: "1 CPU and 1Gi memory free, 500m CPU and 512Mi memory needed for the rollout"},
+		},
+		"Insufficient": {
+			reason: "Nodes that are not ready should not count towards free capacity.",
+			kube:   []runtime.Object{node("n1", "2", "2Gi", true), node("n2", "4", "8Gi", false), pod("p1", "n1", corev1.PodRunning), pod("p2", "n1", corev1.PodRunning), dep},
+			want: check{
+				Component: "Cluster capacity",
+				Status:    checkWarning,
+				Message:   "0 CPU and 0 memory free, 500m CPU and 512Mi memory needed for the rollout",
+				Fix:       "Add nodes or free capacity, otherwise updated pods may not be scheduled.",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := &healthChecker{kClient: kubefake.NewSimpleClientset(tc.kube...)}
+			got := h.checkCapacity(context.Background())
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ncheckCapacity(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCheckDeprecatedAPIs(t *testing.T) {
+	errBoom := errors.New("boom")
+	deprecatedGVR := schema.GroupVersionResource{Group: "pkg.crossplane.io", Version: "v1alpha1", Resource: "controllerconfigs"}
+	crd := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]any{"name": "controllerconfigs.pkg.crossplane.io"},
+		"spec": map[string]any{
+			"group": "pkg.crossplane.io",
+			"names": map[string]any{"plural": "controllerconfigs", "kind": "ControllerConfig"},
+			"versions": []any{
+				map[string]any{"name": "v1alpha1", "served": true, "storage": true, "deprecated": true},
+			},
+		},
+		"status": map[string]any{"storedVersions": []any{"v1alpha1"}},
+	}}
+	// A CRD that still serves a deprecated version, whose objects were all
+	// stored at the current version.
+	migrated := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]any{"name": "controllerconfigs.pkg.crossplane.io"},
+		"spec": map[string]any{
+			"group": "pkg.crossplane.io",
+			"names": map[string]any{"plural": "controllerconfigs", "kind": "ControllerConfig"},
+			"versions": []any{
+				map[string]any{"name": "v1alpha1", "served": true, "storage": false, "deprecated": true},
+				map[string]any{"name": "v1beta1", "served": true, "storage": true},
+			},
+		},
+		"status": map[string]any{"storedVersions": []any{"v1beta1"}},
+	}}
+	cc := &unstructured.Unstructured{}
+	cc.SetAPIVersion("pkg.crossplane.io/v1alpha1")
+	cc.SetKind("ControllerConfig")
+	cc.SetName("default")
+	ctpClient := func(objs ...runtime.Object) func(context.Context, string) (dynamic.Interface, error) {
+		return func(_ context.Context, _ string) (dynamic.Interface, error) {
+			return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+				crdGVR:        "CustomResourceDefinitionList",
+				deprecatedGVR: "ControllerConfigList",
+			}, objs...), nil
+		}
+	}
+
+	cases := map[string]struct {
+		reason    string
+		dynamic   []runtime.Object
+		ctpClient func(context.Context, string) (dynamic.Interface, error)
+		timeout   time.Duration
+		want      []check
+	}{
+		"NotUsed": {
+			reason:    "Deprecated versions without objects should not be reported, and control planes that are not ready should be skipped.",
+			dynamic:   []runtime.Object{controlPlane("ctp1", "True", ""), controlPlane("ctp2", "False", "")},
+			ctpClient: ctpClient(crd),
+			want:      []check{{Component: "Deprecated APIs", Status: checkOK, Message: "not used by 1 ready control planes"}},
+		},
+		"Used": {
+			reason:    "Objects of deprecated versions should be reported per control plane.",
+			dynamic:   []runtime.Object{controlPlane("ctp1", "True", "")},
+			ctpClient: ctpClient(crd, cc),
+			want: []check{{
+				Component: "Control plane ctp1",
+				Status:    checkWarning,
+				Message:   "objects of deprecated API versions exist: pkg.crossplane.io/v1alpha1, Resource=controllerconfigs",
+				Fix:       "Migrate the objects to a supported API version.",
+			}},
+		},
+		"StoredAtCurrentVersion": {
+			reason:    "A served deprecated version should not be reported if no objects are stored at it, even though objects can be listed through it.",
+			dynamic:   []runtime.Object{controlPlane("ctp1", "True", "")},
+			ctpClient: ctpClient(migrated, cc),
+			want:      []check{{Component: "Deprecated APIs", Status: checkOK, Message: "not used by 1 ready control planes"}},
+		},
+		"Unreachable": {
+			reason:  "A control plane that cannot be reached should be reported as a warning.",
+			dynamic: []runtime.Object{controlPlane("ctp1", "True", "")},
+			ctpClient: func(_ context.Context, _ string) (dynamic.Interface, error) {
+				return nil, errBoom
+			},
+			want: []check{{Component: "Control plane ctp1", Status: checkWarning, Message: "unable to check for deprecated APIs: boom"}},
+		},
+		"TimeoutPerControlPlane": {
+			reason:  "Each control plane should be checked within its own timeout rather than a shared one.",
+			dynamic: []runtime.Object{controlPlane("ctp1", "True", ""), controlPlane("ctp2", "True", ""), controlPlane("ctp3", "True", "")},
+			ctpClient: func(ctx context.Context, name string) (dynamic.Interface, error) {
+				time.Sleep(20 * time.Millisecond)
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				return ctpClient(crd)(ctx, name)
+			},
+			timeout: 50 * time.Millisecond,
+			want:    []check{{Component: "Deprecated APIs", Status: checkOK, Message: "not used by 3 ready control planes"}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := &healthChecker{dClient: newFakeDynamicClient(tc.dynamic...), ctpClient: tc.ctpClient, timeout: tc.timeout}
+			got := h.checkDeprecatedAPIs(context.Background())
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ncheckDeprecatedAPIs(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestHealthCheckerVerify(t *testing.T) {
+	rolling := deployment("mxe-controller", corev1.ConditionTrue, 2)
+	rolling.Generation = 2
+	rolling.Status.ObservedGeneration = 2
+	rolling.Status.Replicas = 3
+	rolling.Status.UpdatedReplicas = 1
+
+	done := deployment("mxe-controller", corev1.ConditionTrue, 2)
+	done.Status.Replicas = 2
+	done.Status.UpdatedReplicas = 2
+
+	cases := map[string]struct {
+		reason  string
+		kube    []runtime.Object
+		dynamic []runtime.Object
+		want    []check
+	}{
+		"Ready": {
+			reason:  "Rolled out deployments and ready control planes should pass.",
+			kube:    []runtime.Object{done},
+			dynamic: []runtime.Object{controlPlane("ctp1", "True", "")},
+			want:    []check{{Component: "Deployment mxe-controller", Status: checkOK, Message: "rolled out"}},
+		},
+		"NotReady": {
+			reason:  "Deployments that are still rolling out and control planes that are not ready should fail.",
+			kube:    []runtime.Object{rolling},
+			dynamic: []runtime.Object{controlPlane("ctp1", "False", "creating")},
+			want: []check{
+				{Component: "Deployment mxe-controller", Status: checkFailed, Message: "1/2 replicas updated and available", Fix: "Inspect the deployment with 'kubectl -n upbound-system describe deployment mxe-controller'."},
+				{Component: "Control plane ctp1", Status: checkFailed, Message: "not ready: creating", Fix: "Inspect the control plane with 'kubectl describe controlplanes ctp1'."},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := &healthChecker{kClient: kubefake.NewSimpleClientset(tc.kube...), dClient: newFakeDynamicClient(tc.dynamic...)}
+			got := h.verify(context.Background())
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nverify(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
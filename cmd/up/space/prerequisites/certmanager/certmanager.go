@@ -54,15 +54,63 @@ type CertManager struct {
 	mgr       install.Manager
 	crdclient *apixv1client.ApiextensionsV1Client
 	kclient   kubernetes.Interface
+	version   string
+	values    map[string]any
+}
+
+type settings struct {
+	version   string
+	values    map[string]any
+	modifiers []helm.InstallerModifierFn
+}
+
+// Option modifies how the cert-manager chart is installed.
+type Option func(*settings)
+
+// WithVersion installs the supplied version of the chart instead of the
+// pinned one. An empty version keeps the pinned one.
+func WithVersion(v string) Option {
+	return func(s *settings) {
+		if v != "" {
+			s.version = v
+		}
+	}
+}
+
+// WithValues merges the supplied values over the default values of the chart.
+func WithValues(v map[string]any) Option {
+	return func(s *settings) {
+		s.values = helm.MergeValues(s.values, v)
+	}
+}
+
+// WithHelmModifiers applies the supplied modifiers to the Helm manager of the
+// chart.
+func WithHelmModifiers(m ...helm.InstallerModifierFn) Option {
+	return func(s *settings) {
+		s.modifiers = append(s.modifiers, m...)
+	}
+}
+
+func newSettings(opts []Option) *settings {
+	s := &settings{
+		version: version,
+		values:  values,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
 }
 
 // New constructs a new CertManager instance that can used to install the
 // cert-manager chart.
-func New(config *rest.Config) (*CertManager, error) {
+func New(config *rest.Config, opts ...Option) (*CertManager, error) {
+	s := newSettings(opts)
 	mgr, err := helm.NewManager(config,
 		chartName,
 		certMgrURL,
-		helm.WithNamespace(chartName),
+		append([]helm.InstallerModifierFn{helm.WithNamespace(chartName)}, s.modifiers...)...,
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf(errFmtCreateHelmManager, chartName))
@@ -80,14 +128,39 @@ func New(config *rest.Config) (*CertManager, error) {
 		mgr:       mgr,
 		crdclient: crdclient,
 		kclient:   kclient,
+		version:   s.version,
+		values:    s.values,
 	}, nil
 }
 
+// Chart returns the chart and values installed by this prerequisite.
+func Chart(opts ...Option) install.ChartRef {
+	s := newSettings(opts)
+	return install.ChartRef{
+		Name:    chartName,
+		Version: s.version,
+		RepoURL: certMgrURL,
+		Values:  s.values,
+	}
+}
+
 // GetName returns the name of the cert-manager chart.
 func (c *CertManager) GetName() string {
 	return chartName
 }
 
+// GetVersion returns the version of the cert-manager chart that is installed
+// by this prerequisite.
+func (c *CertManager) GetVersion() string {
+	return c.version
+}
+
+// GetInstalledVersion returns the version of the cert-manager chart that is
+// installed in the target cluster.
+func (c *CertManager) GetInstalledVersion() (string, error) {
+	return c.mgr.GetCurrentVersion()
+}
+
 // Install performs a Helm install of the chart.
 func (c *CertManager) Install() error {
 	if c.IsInstalled() {
@@ -108,7 +181,12 @@ func (c *CertManager) Install() error {
 		return errors.Wrap(err, fmt.Sprintf(errFmtCreateNamespace, chartName))
 	}
 
-	return c.mgr.Install(version, values)
+	return c.mgr.Install(c.version, c.values)
+}
+
+// Upgrade performs a Helm upgrade of the chart.
+func (c *CertManager) Upgrade() error {
+	return c.mgr.Upgrade(c.version, c.values)
 }
 
 // IsInstalled checks if cert-manager has been installed in the target cluster.
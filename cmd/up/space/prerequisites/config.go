@@ -0,0 +1,141 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prerequisites
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
+	"sigs.k8s.io/yaml"
+
+	"github.com/upbound/up/cmd/up/space/prerequisites/certmanager"
+	"github.com/upbound/up/cmd/up/space/prerequisites/ingressnginx"
+	"github.com/upbound/up/cmd/up/space/prerequisites/providers/helm"
+	"github.com/upbound/up/cmd/up/space/prerequisites/providers/kubernetes"
+	"github.com/upbound/up/cmd/up/space/prerequisites/uxp"
+	"github.com/upbound/up/internal/install"
+)
+
+const (
+	errReadConfig          = "unable to read prerequisites config"
+	errFmtUnknownPrereq    = "unknown prerequisite %q"
+	errFmtValuesNotAllowed = "values can only be set for charts, %q is a provider"
+)
+
+// Config pins or overrides the versions and values of Prerequisites.
+type Config struct {
+	// Prerequisites maps the name of a Prerequisite, e.g. cert-manager, to
+	// its settings.
+	Prerequisites map[string]Settings `json:"prerequisites"`
+}
+
+// Settings overrides how a Prerequisite is installed.
+type Settings struct {
+	// Version replaces the pinned version of the Prerequisite.
+	Version string `json:"version,omitempty"`
+	// Values are merged over the default Helm values of a chart.
+	Values map[string]any `json:"values,omitempty"`
+}
+
+// ReadConfig reads and validates a prerequisites config file.
+func ReadConfig(file string) (*Config, error) {
+	b, err := os.ReadFile(file) //nolint:gosec
+	if err != nil {
+		return nil, errors.Wrap(err, errReadConfig)
+	}
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(b, cfg); err != nil {
+		return nil, errors.Wrap(err, errReadConfig)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, errors.Wrap(err, errReadConfig)
+	}
+	return cfg, nil
+}
+
+// Validate checks that the config only refers to known Prerequisites, and
+// only sets values for charts.
+func (c *Config) Validate() error {
+	charts := map[string]bool{}
+	for _, ch := range Charts(nil) {
+		charts[ch.Name] = true
+	}
+	providers := map[string]bool{}
+	for _, ref := range Packages(nil) {
+		providers[packageName(ref)] = true
+	}
+	for n, s := range c.Prerequisites {
+		switch {
+		case charts[n]:
+		case providers[n]:
+			if len(s.Values) > 0 {
+				return fmt.Errorf(errFmtValuesNotAllowed, n)
+			}
+		default:
+			return fmt.Errorf(errFmtUnknownPrereq, n)
+		}
+	}
+	return nil
+}
+
+// settings returns the settings of the named Prerequisite. It is safe to call
+// on a nil config.
+func (c *Config) settings(prereq string) Settings {
+	if c == nil {
+		return Settings{}
+	}
+	return c.Prerequisites[prereq]
+}
+
+// packageName returns the name of the provider installed from a package,
+// e.g. provider-helm.
+func packageName(ref name.Reference) string {
+	return path.Base(ref.Context().RepositoryStr())
+}
+
+// packageVersion returns the reference of a provider package with the version
+// pinned by settings.
+func packageVersion(ref name.Reference, s Settings) name.Reference {
+	if s.Version == "" {
+		return ref
+	}
+	return ref.Context().Tag(s.Version)
+}
+
+// Charts returns the charts installed by Prerequisites, with the versions and
+// values of the supplied config, which may be nil.
+func Charts(cfg *Config) []install.ChartRef {
+	cm := cfg.settings(certmanager.Chart().Name)
+	u := cfg.settings(uxp.Chart().Name)
+	in := cfg.settings(ingressnginx.Chart().Name)
+	return []install.ChartRef{
+		certmanager.Chart(certmanager.WithVersion(cm.Version), certmanager.WithValues(cm.Values)),
+		uxp.Chart(uxp.WithVersion(u.Version), uxp.WithValues(u.Values)),
+		ingressnginx.Chart(ingressnginx.WithVersion(in.Version), ingressnginx.WithValues(in.Values)),
+	}
+}
+
+// Packages returns the provider packages installed by Prerequisites, with the
+// versions of the supplied config, which may be nil.
+func Packages(cfg *Config) []name.Reference {
+	refs := []name.Reference{kubernetes.Package(), helm.Package()}
+	for i, ref := range refs {
+		refs[i] = packageVersion(ref, cfg.settings(packageName(ref)))
+	}
+	return refs
+}
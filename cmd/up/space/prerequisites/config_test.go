@@ -0,0 +1,162 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prerequisites
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadConfig(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		content string
+		want    *Config
+		wantErr bool
+	}{
+		"Valid": {
+			reason: "A config that pins known prerequisites should be read.",
+			content: `
+prerequisites:
+  cert-manager:
+    version: v1.12.0
+    values:
+      replicaCount: 2
+  provider-helm:
+    version: v0.15.0
+`,
+			want: &Config{Prerequisites: map[string]Settings{
+				"cert-manager":  {Version: "v1.12.0", Values: map[string]any{"replicaCount": float64(2)}},
+				"provider-helm": {Version: "v0.15.0"},
+			}},
+		},
+		"UnknownPrerequisite": {
+			reason:  "A config that refers to an unknown prerequisite should be rejected.",
+			content: "prerequisites:\n  cert-mgr:\n    version: v1.12.0\n",
+			wantErr: true,
+		},
+		"ProviderValues": {
+			reason:  "Values can only be set for charts.",
+			content: "prerequisites:\n  provider-kubernetes:\n    values:\n      a: b\n",
+			wantErr: true,
+		},
+		"UnknownField": {
+			reason:  "A config with unknown fields should be rejected.",
+			content: "prerequisites:\n  cert-manager:\n    versoin: v1.12.0\n",
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "prerequisites.yaml")
+			if err := os.WriteFile(file, []byte(tc.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadConfig(file)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("\n%s\nReadConfig(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nReadConfig(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	cfg := &Config{Prerequisites: map[string]Settings{
+		"cert-manager":        {Version: "v1.12.0", Values: map[string]any{"replicaCount": 2}},
+		"provider-kubernetes": {Version: "v0.9.0"},
+	}}
+
+	cases := map[string]struct {
+		reason   string
+		cfg      *Config
+		charts   map[string]string
+		packages []string
+	}{
+		"Pinned": {
+			reason:   "Without a config the pinned versions should be used.",
+			charts:   map[string]string{"cert-manager": "v1.11.0", "universal-crossplane": "1.13.2-up.2", "ingress-nginx": "4.7.1"},
+			packages: []string{"xpkg.upbound.io/crossplane-contrib/provider-kubernetes:v0.7.0", "xpkg.upbound.io/crossplane-contrib/provider-helm:v0.14.0"},
+		},
+		"Overridden": {
+			reason:   "The versions of a config should replace the pinned ones.",
+			cfg:      cfg,
+			charts:   map[string]string{"cert-manager": "v1.12.0", "universal-crossplane": "1.13.2-up.2", "ingress-nginx": "4.7.1"},
+			packages: []string{"xpkg.upbound.io/crossplane-contrib/provider-kubernetes:v0.9.0", "xpkg.upbound.io/crossplane-contrib/provider-helm:v0.14.0"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			charts := map[string]string{}
+			for _, ch := range Charts(tc.cfg) {
+				charts[ch.Name] = ch.Version
+			}
+			if diff := cmp.Diff(tc.charts, charts); diff != "" {
+				t.Errorf("\n%s\nCharts(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			packages := []string{}
+			for _, ref := range Packages(tc.cfg) {
+				packages = append(packages, ref.String())
+			}
+			if diff := cmp.Diff(tc.packages, packages); diff != "" {
+				t.Errorf("\n%s\nPackages(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+
+	// Values of a config should be merged over the default values.
+	want := map[string]any{"installCRDs": "true", "replicaCount": 2}
+	if diff := cmp.Diff(want, Charts(cfg)[0].Values); diff != "" {
+		t.Errorf("Charts(...): values: -want, +got:\n%s", diff)
+	}
+}
+
+func TestVersionStatusOutdated(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		v      VersionStatus
+		want   bool
+	}{
+		"NotInstalled": {
+			reason: "A prerequisite that is not installed is not outdated.",
+			v:      VersionStatus{Desired: "v1.11.0"},
+		},
+		"Unknown": {
+			reason: "A prerequisite whose installed version is unknown is not outdated.",
+			v:      VersionStatus{Installed: "v1.10.0", Desired: "v1.11.0", Err: os.ErrNotExist},
+		},
+		"SameVersion": {
+			reason: "Versions that only differ by their v prefix are the same.",
+			v:      VersionStatus{Installed: "1.11.0", Desired: "v1.11.0"},
+		},
+		"Outdated": {
+			reason: "A prerequisite with a different installed version is outdated.",
+			v:      VersionStatus{Installed: "v1.10.0", Desired: "v1.11.0"},
+			want:   true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := tc.v.Outdated(); got != tc.want {
+				t.Errorf("\n%s\nOutdated(): want %t, got %t", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"net/url"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -52,19 +53,66 @@ var (
 
 // IngressNginx represents a Helm manager
 type IngressNginx struct {
-	mgr     install.Manager
-	kclient kubernetes.Interface
-	dclient dynamic.Interface
-	values  map[string]any
+	mgr       install.Manager
+	kclient   kubernetes.Interface
+	dclient   dynamic.Interface
+	svc       ServiceType
+	version   string
+	overrides map[string]any
 }
 
-// New constructs a new CertManager instance that can used to install the
-// cert-manager chart.
-func New(config *rest.Config, svc ServiceType) (*IngressNginx, error) {
+type settings struct {
+	version   string
+	overrides map[string]any
+	modifiers []helm.InstallerModifierFn
+}
+
+// Option modifies how the ingress-nginx chart is installed.
+type Option func(*settings)
+
+// WithVersion installs the supplied version of the chart instead of the
+// pinned one. An empty version keeps the pinned one.
+func WithVersion(v string) Option {
+	return func(s *settings) {
+		if v != "" {
+			s.version = v
+		}
+	}
+}
+
+// WithValues merges the supplied values over the default values of the chart.
+func WithValues(v map[string]any) Option {
+	return func(s *settings) {
+		s.overrides = helm.MergeValues(s.overrides, v)
+	}
+}
+
+// WithHelmModifiers applies the supplied modifiers to the Helm manager of the
+// chart.
+func WithHelmModifiers(m ...helm.InstallerModifierFn) Option {
+	return func(s *settings) {
+		s.modifiers = append(s.modifiers, m...)
+	}
+}
+
+func newSettings(opts []Option) *settings {
+	s := &settings{
+		version: version,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// New constructs a new IngressNginx instance that can used to install the
+// ingress-nginx chart.
+func New(config *rest.Config, svc ServiceType, opts ...Option) (*IngressNginx, error) {
+	s := newSettings(opts)
 	mgr, err := helm.NewManager(config,
 		chartName,
 		nginxURL,
-		helm.WithNamespace(chartName),
+		append([]helm.InstallerModifierFn{helm.WithNamespace(chartName)}, s.modifiers...)...,
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf(errFmtCreateHelmManager, chartName))
@@ -79,18 +127,43 @@ func New(config *rest.Config, svc ServiceType) (*IngressNginx, error) {
 	}
 
 	return &IngressNginx{
-		mgr:     mgr,
-		dclient: dclient,
-		kclient: kclient,
-		values:  getValues(svc),
+		mgr:       mgr,
+		dclient:   dclient,
+		kclient:   kclient,
+		svc:       svc,
+		version:   s.version,
+		overrides: s.overrides,
 	}, nil
 }
 
+// Chart returns the chart and values installed by this prerequisite.
+func Chart(opts ...Option) install.ChartRef {
+	s := newSettings(opts)
+	return install.ChartRef{
+		Name:    chartName,
+		Version: s.version,
+		RepoURL: nginxURL,
+		Values:  helm.MergeValues(getValues(NodePort), s.overrides),
+	}
+}
+
 // GetName returns the name of the cert-manager chart.
 func (c *IngressNginx) GetName() string {
 	return chartName
 }
 
+// GetVersion returns the version of the ingress-nginx chart that is installed
+// by this prerequisite.
+func (c *IngressNginx) GetVersion() string {
+	return c.version
+}
+
+// GetInstalledVersion returns the version of the ingress-nginx chart that is
+// installed in the target cluster.
+func (c *IngressNginx) GetInstalledVersion() (string, error) {
+	return c.mgr.GetCurrentVersion()
+}
+
 // Install performs a Helm install of the chart.
 func (c *IngressNginx) Install() error { //nolint:gocyclo
 	if c.IsInstalled() {
@@ -111,7 +184,7 @@ func (c *IngressNginx) Install() error { //nolint:gocyclo
 		return errors.Wrap(err, fmt.Sprintf(errFmtCreateNamespace, chartName))
 	}
 
-	if err := c.mgr.Install(version, c.values); err != nil {
+	if err := c.mgr.Install(c.version, helm.MergeValues(getValues(c.svc), c.overrides)); err != nil {
 		return err
 	}
 
@@ -136,6 +209,18 @@ func (c *IngressNginx) Install() error { //nolint:gocyclo
 	return nil
 }
 
+// Upgrade performs a Helm upgrade of the chart. The service type of the
+// installed chart is kept.
+func (c *IngressNginx) Upgrade() error {
+	svc := c.svc
+	if current, err := c.mgr.GetValues(); err == nil {
+		if t, err := fieldpath.Pave(current).GetString("controller.service.type"); err == nil {
+			svc = ServiceType(t)
+		}
+	}
+	return c.mgr.Upgrade(c.version, helm.MergeValues(getValues(svc), c.overrides))
+}
+
 // IsInstalled checks if cert-manager has been installed in the target cluster.
 func (c *IngressNginx) IsInstalled() bool {
 	il, err := c.kclient.
@@ -0,0 +1,89 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/cmd/up/space/defaults"
+	"github.com/upbound/up/internal/install"
+)
+
+type fakeManager struct {
+	install.Manager
+
+	current map[string]any
+	values  map[string]any
+}
+
+func (m *fakeManager) GetValues() (map[string]any, error) {
+	return m.current, nil
+}
+
+func (m *fakeManager) Upgrade(_ string, values map[string]any, _ ...install.UpgradeOption) error {
+	m.values = values
+	return nil
+}
+
+func TestUpgrade(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		cloud  defaults.CloudType
+		want   map[string]any
+	}{
+		"Generic": {
+			reason: "An upgrade should keep the installed service type.",
+			cloud:  defaults.Generic,
+			want: map[string]any{
+				"controller.service.type":         "NodePort",
+				"controller.ingressClass":         nil,
+				"controller.ingressClassResource": nil,
+				"controller.hostPort.enabled":     true,
+				"controller.nodeSelector":         map[string]any{"ingress-ready": "true"},
+			},
+		},
+		"RKE2": {
+			reason: "An upgrade under the RKE2 profile should keep the settings of the profile.",
+			cloud:  defaults.RKE2,
+			want: map[string]any{
+				"controller.service.type":         "NodePort",
+				"controller.ingressClass":         "upbound-nginx",
+				"controller.ingressClassResource": map[string]any{"name": "upbound-nginx", "controllerValue": "k8s.io/upbound-nginx"},
+				"controller.hostPort.enabled":     false,
+				"controller.nodeSelector":         nil,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mgr := &fakeManager{current: map[string]any{"controller": map[string]any{"service": map[string]any{"type": "NodePort"}}}}
+			s := newSettings([]Option{WithValues(tc.cloud.Defaults().PrerequisiteValues[chartName])})
+			c := &IngressNginx{mgr: mgr, svc: LoadBalancer, version: s.version, overrides: s.overrides}
+			if err := c.Upgrade(); err != nil {
+				t.Fatalf("\n%s\nUpgrade(): %v", tc.reason, err)
+			}
+			p := fieldpath.Pave(mgr.values)
+			for path, want := range tc.want {
+				got, _ := p.GetValue(path)
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("\n%s\nUpgrade(): %s: -want, +got:\n%s", tc.reason, path, diff)
+				}
+			}
+		})
+	}
+}
@@ -15,7 +15,10 @@
 package prerequisites
 
 import (
+	"strings"
+
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
 	"k8s.io/client-go/rest"
 
 	"github.com/upbound/up/cmd/up/space/defaults"
@@ -24,6 +27,8 @@ import (
 	"github.com/upbound/up/cmd/up/space/prerequisites/providers/helm"
 	"github.com/upbound/up/cmd/up/space/prerequisites/providers/kubernetes"
 	"github.com/upbound/up/cmd/up/space/prerequisites/uxp"
+	"github.com/upbound/up/internal/install/bundle"
+	helmmgr "github.com/upbound/up/internal/install/helm"
 )
 
 var (
@@ -34,9 +39,12 @@ var (
 // prerequisite.
 type Prerequisite interface {
 	GetName() string
+	GetVersion() string
+	GetInstalledVersion() (string, error)
 
 	Install() error
 	IsInstalled() bool
+	Upgrade() error
 }
 
 // Manager provides APIs for interacting with Prerequisites within the target
@@ -51,16 +59,120 @@ type Status struct {
 	NotInstalled []Prerequisite
 }
 
+// VersionStatus represents the installed and desired versions of a
+// Prerequisite.
+type VersionStatus struct {
+	Prerequisite Prerequisite
+	// Installed is the version installed in the target cluster. It is empty
+	// if the Prerequisite is not installed.
+	Installed string
+	// Desired is the pinned or configured version of the Prerequisite.
+	Desired string
+	// Err is the reason the installed version could not be determined, e.g.
+	// because the Prerequisite was not installed by up.
+	Err error
+}
+
+// Outdated returns true if the installed version of the Prerequisite is known
+// and differs from the desired one.
+func (v VersionStatus) Outdated() bool {
+	if v.Installed == "" || v.Err != nil {
+		return false
+	}
+	return strings.TrimPrefix(v.Installed, "v") != strings.TrimPrefix(v.Desired, "v")
+}
+
+// Option modifies how Prerequisites are installed.
+type Option func(*options)
+
+type options struct {
+	bundle *bundle.Bundle
+	mirror *bundle.Mirror
+	config *Config
+}
+
+// FromBundle installs the charts of Prerequisites from a bundle, and replaces
+// the images and packages they install with the copies the mirror maps them
+// to.
+func FromBundle(b *bundle.Bundle, m *bundle.Mirror) Option {
+	return func(o *options) {
+		o.bundle = b
+		o.mirror = m
+	}
+}
+
+// WithConfig installs Prerequisites with the versions and values of the
+// supplied config.
+func WithConfig(cfg *Config) Option {
+	return func(o *options) {
+		o.config = cfg
+	}
+}
+
+// chartModifiers returns the modifiers of the Helm manager of the named
+// chart.
+func (o *options) chartModifiers(chartName string) ([]helmmgr.InstallerModifierFn, error) {
+	if o.bundle == nil {
+		return nil, nil
+	}
+	_, f, err := o.bundle.Chart(chartName)
+	if err != nil {
+		return nil, err
+	}
+	return []helmmgr.InstallerModifierFn{helmmgr.WithChart(f), helmmgr.WithPostRenderer(o.mirror)}, nil
+}
+
+// packageRef returns the reference a provider package is installed from.
+func (o *options) packageRef(ref name.Reference) string {
+	ref = packageVersion(ref, o.config.settings(packageName(ref)))
+	if o.mirror == nil {
+		return ref.String()
+	}
+	return o.mirror.Ref(ref.String())
+}
+
 // New constructs a new Manager for working with installation Prerequisites.
-func New(config *rest.Config, defs *defaults.CloudConfig) (*Manager, error) {
+func New(config *rest.Config, defs *defaults.CloudConfig, opts ...Option) (*Manager, error) { //nolint:gocyclo
+	o := &options{}
+	for _, fn := range opts {
+		fn(o)
+	}
+
+	// Values of the config are merged over the values of the cloud defaults.
+	values := func(chartName string) map[string]any {
+		s := o.config.settings(chartName)
+		if defs == nil {
+			return s.Values
+		}
+		return helmmgr.MergeValues(defs.PrerequisiteValues[chartName], s.Values)
+	}
+
 	prereqs := []Prerequisite{}
-	certmanager, err := certmanager.New(config)
+	s := o.config.settings(certmanager.Chart().Name)
+	mods, err := o.chartModifiers(certmanager.Chart().Name)
+	if err != nil {
+		return nil, errors.Wrap(err, errCreatePrerequisite)
+	}
+	certmanager, err := certmanager.New(config,
+		certmanager.WithVersion(s.Version),
+		certmanager.WithValues(values(certmanager.Chart().Name)),
+		certmanager.WithHelmModifiers(mods...),
+	)
 	if err != nil {
 		return nil, errors.Wrap(err, errCreatePrerequisite)
 	}
 	prereqs = append(prereqs, certmanager)
 
-	uxp, err := uxp.New(config)
+	s = o.config.settings(uxp.Chart().Name)
+	mods, err = o.chartModifiers(uxp.Chart().Name)
+	if err != nil {
+		return nil, errors.Wrap(err, errCreatePrerequisite)
+	}
+	uxp, err := uxp.New(config,
+		uxp.WithVersion(s.Version),
+		uxp.WithValues(values(uxp.Chart().Name)),
+		uxp.WithHelmModifiers(mods...),
+	)
 	if err != nil {
 		return nil, errors.Wrap(err, errCreatePrerequisite)
 	}
@@ -71,19 +183,34 @@ func New(config *rest.Config, defs *defaults.CloudConfig) (*Manager, error) {
 		svcType = ingressnginx.LoadBalancer
 	}
 
-	ingress, err := ingressnginx.New(config, svcType)
+	s = o.config.settings(ingressnginx.Chart().Name)
+	mods, err = o.chartModifiers(ingressnginx.Chart().Name)
+	if err != nil {
+		return nil, errors.Wrap(err, errCreatePrerequisite)
+	}
+	ingress, err := ingressnginx.New(config, svcType,
+		ingressnginx.WithVersion(s.Version),
+		ingressnginx.WithValues(values(ingressnginx.Chart().Name)),
+		ingressnginx.WithHelmModifiers(mods...),
+	)
 	if err != nil {
 		return nil, errors.Wrap(err, errCreatePrerequisite)
 	}
 	prereqs = append(prereqs, ingress)
 
-	pk8s, err := kubernetes.New(config)
+	pk8s, err := kubernetes.New(config,
+		kubernetes.WithVersion(o.config.settings(packageName(kubernetes.Package())).Version),
+		kubernetes.WithPackage(o.packageRef(kubernetes.Package())),
+	)
 	if err != nil {
 		return nil, errors.Wrap(err, errCreatePrerequisite)
 	}
 	prereqs = append(prereqs, pk8s)
 
-	phelm, err := helm.New(config)
+	phelm, err := helm.New(config,
+		helm.WithVersion(o.config.settings(packageName(helm.Package())).Version),
+		helm.WithPackage(o.packageRef(helm.Package())),
+	)
 	if err != nil {
 		return nil, errors.Wrap(err, errCreatePrerequisite)
 	}
@@ -94,6 +221,12 @@ func New(config *rest.Config, defs *defaults.CloudConfig) (*Manager, error) {
 	}, nil
 }
 
+// Prerequisites returns the Prerequisites of the target cluster in the order
+// they are installed.
+func (m *Manager) Prerequisites() []Prerequisite {
+	return m.prereqs
+}
+
 // Check performs IsInstalled checks for each of the Prerequisites against the
 // target cluster.
 func (m *Manager) Check() *Status {
@@ -108,3 +241,29 @@ func (m *Manager) Check() *Status {
 		NotInstalled: notInstalled,
 	}
 }
+
+// Versions returns the installed and desired versions of each of the
+// Prerequisites in the target cluster.
+func (m *Manager) Versions() []VersionStatus {
+	versions := make([]VersionStatus, 0, len(m.prereqs))
+	for _, p := range m.prereqs {
+		v := VersionStatus{Prerequisite: p, Desired: p.GetVersion()}
+		if p.IsInstalled() {
+			v.Installed, v.Err = p.GetInstalledVersion()
+		}
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+// Outdated returns the installed Prerequisites whose versions differ from the
+// desired ones.
+func (m *Manager) Outdated() []VersionStatus {
+	outdated := []VersionStatus{}
+	for _, v := range m.Versions() {
+		if v.Outdated() {
+			outdated = append(outdated, v)
+		}
+	}
+	return outdated
+}
@@ -59,10 +59,13 @@ var (
 
 	errFmtCreateK8sClient = "failed to create kubernetes client for requirement %s"
 	errFmtUXPRequired     = "UXP is required to install %s"
+	errFmtGetPackage      = "failed to get package of %s"
 )
 
 // Helm represents provider-helm manager.
 type Helm struct {
+	pkg       string
+	version   string
 	crdclient *apixv1client.ApiextensionsV1Client
 	dClient   dynamic.Interface
 	kclient   kubernetes.Interface
@@ -79,7 +82,7 @@ func init() {
 
 // New constructs a new CertManager instance that can used to install the
 // cert-manager chart.
-func New(config *rest.Config) (*Helm, error) {
+func New(config *rest.Config, opts ...Option) (*Helm, error) {
 	crdclient, err := apixv1client.NewForConfig(config)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf(errFmtCreateK8sClient, providerName))
@@ -93,11 +96,46 @@ func New(config *rest.Config) (*Helm, error) {
 		return nil, errors.Wrap(err, fmt.Sprintf(errFmtCreateK8sClient, providerName))
 	}
 
-	return &Helm{
+	h := &Helm{
+		pkg:       pkgRef.String(),
+		version:   version,
 		crdclient: crdclient,
 		dClient:   dclient,
 		kclient:   kclient,
-	}, nil
+	}
+	for _, o := range opts {
+		o(h)
+	}
+	return h, nil
+}
+
+// Option modifies the Helm prerequisite.
+type Option func(*Helm)
+
+// WithVersion installs the supplied version of the provider instead of the
+// pinned one. An empty version keeps the pinned one.
+func WithVersion(v string) Option {
+	return func(h *Helm) {
+		if v == "" {
+			return
+		}
+		h.version = v
+		h.pkg = pkgRef.Context().Tag(v).String()
+	}
+}
+
+// WithPackage installs the provider from the supplied package reference
+// instead of the default one, e.g. a copy in a private registry.
+func WithPackage(ref string) Option {
+	return func(h *Helm) {
+		h.pkg = ref
+	}
+}
+
+// Package returns the reference of the provider package installed by this
+// prerequisite by default.
+func Package() name.Reference {
+	return pkgRef
 }
 
 // GetName returns the name of the provider-helm provider.
@@ -105,6 +143,39 @@ func (h *Helm) GetName() string {
 	return providerName
 }
 
+// GetVersion returns the version of the provider-helm provider that is installed
+// by this prerequisite.
+func (h *Helm) GetVersion() string {
+	return h.version
+}
+
+// GetInstalledVersion returns the version of the provider-helm package that is
+// installed in the target cluster.
+func (h *Helm) GetInstalledVersion() (string, error) {
+	p, err := h.dClient.Resource(pkgGVR).Get(context.Background(), pkgName, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf(errFmtGetPackage, providerName))
+	}
+	pkg := resources.Package{Unstructured: *p}
+	ref, err := name.ParseReference(pkg.GetPackage())
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf(errFmtGetPackage, providerName))
+	}
+	return ref.Identifier(), nil
+}
+
+// Upgrade updates the package of the installed provider.
+func (h *Helm) Upgrade() error {
+	p, err := h.dClient.Resource(pkgGVR).Get(context.Background(), pkgName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf(errFmtGetPackage, providerName))
+	}
+	pkg := resources.Package{Unstructured: *p}
+	pkg.SetPackage(h.pkg)
+	_, err = h.dClient.Resource(pkgGVR).Update(context.Background(), pkg.GetUnstructured(), metav1.UpdateOptions{})
+	return err
+}
+
 // Install performs a kubectl apply of the package.
 func (h *Helm) Install() error { //nolint:gocyclo
 	if h.IsInstalled() {
@@ -134,7 +205,7 @@ func (h *Helm) Install() error { //nolint:gocyclo
 
 	p := &resources.Package{}
 	p.SetName(pkgName)
-	p.SetPackage(pkgRef.String())
+	p.SetPackage(h.pkg)
 	p.SetGroupVersionKind(xppkgv1.ProviderGroupVersionKind)
 	p.SetControllerConfigRef(xppkgv1.ControllerConfigReference{
 		Name: ccName,
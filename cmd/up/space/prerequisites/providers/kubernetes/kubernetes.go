@@ -58,10 +58,13 @@ var (
 
 	errFmtCreateK8sClient = "failed to create kubernetes client for requirement %s"
 	errFmtUXPRequired     = "UXP is required to install %s"
+	errFmtGetPackage      = "failed to get package of %s"
 )
 
 // Kubernetes represents a Helm manager.
 type Kubernetes struct {
+	pkg       string
+	version   string
 	crdclient *apixv1client.ApiextensionsV1Client
 	dClient   dynamic.Interface
 	kclient   kubernetes.Interface
@@ -78,7 +81,7 @@ func init() {
 
 // New constructs a new CertManager instance that can used to install the
 // cert-manager chart.
-func New(config *rest.Config) (*Kubernetes, error) {
+func New(config *rest.Config, opts ...Option) (*Kubernetes, error) {
 	crdclient, err := apixv1client.NewForConfig(config)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf(errFmtCreateK8sClient, providerName))
@@ -92,11 +95,46 @@ func New(config *rest.Config) (*Kubernetes, error) {
 		return nil, errors.Wrap(err, fmt.Sprintf(errFmtCreateK8sClient, providerName))
 	}
 
-	return &Kubernetes{
+	k := &Kubernetes{
+		pkg:       pkgRef.String(),
+		version:   version,
 		crdclient: crdclient,
 		dClient:   dclient,
 		kclient:   kclient,
-	}, nil
+	}
+	for _, o := range opts {
+		o(k)
+	}
+	return k, nil
+}
+
+// Option modifies the Kubernetes prerequisite.
+type Option func(*Kubernetes)
+
+// WithVersion installs the supplied version of the provider instead of the
+// pinned one. An empty version keeps the pinned one.
+func WithVersion(v string) Option {
+	return func(k *Kubernetes) {
+		if v == "" {
+			return
+		}
+		k.version = v
+		k.pkg = pkgRef.Context().Tag(v).String()
+	}
+}
+
+// WithPackage installs the provider from the supplied package reference
+// instead of the default one, e.g. a copy in a private registry.
+func WithPackage(ref string) Option {
+	return func(k *Kubernetes) {
+		k.pkg = ref
+	}
+}
+
+// Package returns the reference of the provider package installed by this
+// prerequisite by default.
+func Package() name.Reference {
+	return pkgRef
 }
 
 // GetName returns the name of the cert-manager chart.
@@ -104,6 +142,39 @@ func (k *Kubernetes) GetName() string {
 	return providerName
 }
 
+// GetVersion returns the version of the provider-kubernetes provider that is installed
+// by this prerequisite.
+func (k *Kubernetes) GetVersion() string {
+	return k.version
+}
+
+// GetInstalledVersion returns the version of the provider-kubernetes package that is
+// installed in the target cluster.
+func (k *Kubernetes) GetInstalledVersion() (string, error) {
+	p, err := k.dClient.Resource(pkgGVR).Get(context.Background(), pkgName, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf(errFmtGetPackage, providerName))
+	}
+	pkg := resources.Package{Unstructured: *p}
+	ref, err := name.ParseReference(pkg.GetPackage())
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf(errFmtGetPackage, providerName))
+	}
+	return ref.Identifier(), nil
+}
+
+// Upgrade updates the package of the installed provider.
+func (k *Kubernetes) Upgrade() error {
+	p, err := k.dClient.Resource(pkgGVR).Get(context.Background(), pkgName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf(errFmtGetPackage, providerName))
+	}
+	pkg := resources.Package{Unstructured: *p}
+	pkg.SetPackage(k.pkg)
+	_, err = k.dClient.Resource(pkgGVR).Update(context.Background(), pkg.GetUnstructured(), metav1.UpdateOptions{})
+	return err
+}
+
 // Install performs a Helm install of the chart.
 func (k *Kubernetes) Install() error { //nolint:gocyclo
 	if k.IsInstalled() {
@@ -133,7 +204,7 @@ func (k *Kubernetes) Install() error { //nolint:gocyclo
 
 	p := &resources.Package{}
 	p.SetName(pkgName)
-	p.SetPackage(pkgRef.String())
+	p.SetPackage(k.pkg)
 	p.SetGroupVersionKind(xppkgv1.ProviderGroupVersionKind)
 	p.SetControllerConfigRef(xppkgv1.ControllerConfigReference{
 		Name: ccName,
@@ -51,18 +51,68 @@ type UXP struct {
 	mgr       install.Manager
 	crdclient *apixv1client.ApiextensionsV1Client
 	kclient   kubernetes.Interface
+	version   string
+	values    map[string]any
+}
+
+type settings struct {
+	version   string
+	values    map[string]any
+	modifiers []helm.InstallerModifierFn
+}
+
+// Option modifies how the universal-crossplane chart is installed.
+type Option func(*settings)
+
+// WithVersion installs the supplied version of the chart instead of the
+// pinned one. An empty version keeps the pinned one.
+func WithVersion(v string) Option {
+	return func(s *settings) {
+		if v != "" {
+			s.version = v
+		}
+	}
+}
+
+// WithValues merges the supplied values over the default values of the chart.
+func WithValues(v map[string]any) Option {
+	return func(s *settings) {
+		s.values = helm.MergeValues(s.values, v)
+	}
+}
+
+// WithHelmModifiers applies the supplied modifiers to the Helm manager of the
+// chart.
+func WithHelmModifiers(m ...helm.InstallerModifierFn) Option {
+	return func(s *settings) {
+		s.modifiers = append(s.modifiers, m...)
+	}
+}
+
+func newSettings(opts []Option) *settings {
+	s := &settings{
+		version: version,
+		values:  map[string]any{},
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
 }
 
 // New constructs a new UXP instance that can used to install the
 // universal-crossplane chart.
-func New(config *rest.Config) (*UXP, error) {
+func New(config *rest.Config, opts ...Option) (*UXP, error) {
+	s := newSettings(opts)
 	mgr, err := helm.NewManager(config,
 		chartName,
 		uxp.RepoURL,
-		// The default namespace is upbound-system, but we set it in order to
-		// be explicit.
-		helm.WithNamespace(ns),
-		helm.Wait())
+		append([]helm.InstallerModifierFn{
+			// The default namespace is upbound-system, but we set it in order
+			// to be explicit.
+			helm.WithNamespace(ns),
+			helm.Wait(),
+		}, s.modifiers...)...)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf(errFmtCreateHelmManager, chartName))
 	}
@@ -79,14 +129,39 @@ func New(config *rest.Config) (*UXP, error) {
 		mgr:       mgr,
 		crdclient: crdclient,
 		kclient:   kclient,
+		version:   s.version,
+		values:    s.values,
 	}, nil
 }
 
+// Chart returns the chart and values installed by this prerequisite.
+func Chart(opts ...Option) install.ChartRef {
+	s := newSettings(opts)
+	return install.ChartRef{
+		Name:    chartName,
+		Version: s.version,
+		RepoURL: uxp.RepoURL,
+		Values:  s.values,
+	}
+}
+
 // GetName returns the name of the universal-crossplane chart.
 func (u *UXP) GetName() string {
 	return chartName
 }
 
+// GetVersion returns the version of the universal-crossplane chart that is
+// installed by this prerequisite.
+func (u *UXP) GetVersion() string {
+	return u.version
+}
+
+// GetInstalledVersion returns the version of the universal-crossplane chart
+// that is installed in the target cluster.
+func (u *UXP) GetInstalledVersion() (string, error) {
+	return u.mgr.GetCurrentVersion()
+}
+
 // Install performs a Helm install of the chart.
 func (u *UXP) Install() error {
 	if u.IsInstalled() {
@@ -105,7 +180,12 @@ func (u *UXP) Install() error {
 	if err != nil && !kerrors.IsAlreadyExists(err) {
 		return errors.Wrap(err, fmt.Sprintf(errFmtCreateNamespace, ns))
 	}
-	return u.mgr.Install(version, map[string]any{})
+	return u.mgr.Install(u.version, u.values)
+}
+
+// Upgrade performs a Helm upgrade of the chart.
+func (u *UXP) Upgrade() error {
+	return u.mgr.Upgrade(u.version, u.values)
 }
 
 // IsInstalled checks if UXP has been installed in the target cluster.
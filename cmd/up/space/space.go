@@ -18,13 +18,15 @@ import (
 	"github.com/alecthomas/kong"
 
 	"github.com/upbound/up/cmd/up/space/billing"
+	"github.com/upbound/up/cmd/up/space/usage"
 	"github.com/upbound/up/internal/feature"
 )
 
 const (
 	spacesChart = "spaces"
 
-	defaultRegistry = "us-west1-docker.pkg.dev/orchestration-build/upbound-environments"
+	defaultRegistry         = "us-west1-docker.pkg.dev/orchestration-build/upbound-environments"
+	defaultRegistryEndpoint = "https://us-west1-docker.pkg.dev"
 )
 
 // BeforeReset is the first hook to run.
@@ -37,8 +39,12 @@ type Cmd struct {
 	Init    initCmd    `cmd:"" help:"Initialize an Upbound Spaces deployment."`
 	Destroy destroyCmd `cmd:"" help:"Remove the Upbound Spaces deployment."`
 	Upgrade upgradeCmd `cmd:"" help:"Upgrade the Upbound Spaces deployment."`
+	Status  statusCmd  `cmd:"" help:"Show the health of the Upbound Spaces deployment."`
+	Doctor  doctorCmd  `cmd:"" help:"Diagnose common problems of the Upbound Spaces deployment."`
+	Bundle  bundleCmd  `cmd:"" help:"Manage installation bundles for clusters without internet access."`
 
 	Billing billing.Cmd `cmd:""`
+	Usage   usage.Cmd   `cmd:"" help:"View the usage of the Upbound Spaces deployment."`
 }
 
 // overrideRegistry is a common function that takes the candidate registry,
@@ -0,0 +1,171 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package space
+
+import (
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"github.com/upbound/up/cmd/up/space/defaults"
+	"github.com/upbound/up/cmd/up/space/prerequisites"
+)
+
+const (
+	spaceConfigGroup      = "up.upbound.io"
+	spaceConfigAPIVersion = spaceConfigGroup + "/v1alpha1"
+	spaceConfigKind       = "SpaceConfig"
+
+	errFmtSpaceConfigVersion = "unsupported apiVersion %q of SpaceConfig, supported is %q"
+	errParseSpaceConfig      = "unable to parse SpaceConfig"
+	errParseRegistry         = "invalid registry"
+	errOpenTokenFile         = "unable to open token file"
+	errVersionRequired       = "a version is required, either as argument or in the SpaceConfig"
+)
+
+// spaceConfig is the declarative configuration of a Space. It is read from
+// the parameters file if the file is of kind SpaceConfig, so that the same
+// file can be applied with both init and upgrade.
+type spaceConfig struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+
+	// Version of Spaces to install or upgrade to.
+	Version string `json:"version,omitempty"`
+	// Registry Spaces is pulled from.
+	Registry spaceConfigRegistry `json:"registry,omitempty"`
+	// Cloud is the type of the cluster, e.g. eks, aks, gke or kind.
+	Cloud string `json:"cloud,omitempty"`
+	// PublicIngress exposes the ingress of the Space publicly.
+	PublicIngress bool `json:"publicIngress,omitempty"`
+	// Prerequisites pins or overrides the versions and Helm values of
+	// prerequisites, like --prerequisites-config.
+	Prerequisites map[string]prerequisites.Settings `json:"prerequisites,omitempty"`
+	// Values are the Helm values of Spaces.
+	Values map[string]any `json:"values,omitempty"`
+	// Profile configures the profile that is created for the Space.
+	Profile spaceConfigProfile `json:"profile,omitempty"`
+}
+
+// spaceConfigRegistry configures the registry Spaces is pulled from.
+type spaceConfigRegistry struct {
+	Repository string `json:"repository,omitempty"`
+	Endpoint   string `json:"endpoint,omitempty"`
+	// TokenFile is the path of the registry token, relative to the
+	// SpaceConfig.
+	TokenFile string `json:"tokenFile,omitempty"`
+}
+
+// spaceConfigProfile configures the profile that is created for the Space.
+type spaceConfigProfile struct {
+	// Name of the profile. Defaults to the active profile.
+	Name string `json:"name,omitempty"`
+	// Disabled skips the creation of the profile.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// readParametersFile reads the file passed with --file. It returns the Helm
+// values of the file, and the SpaceConfig if the file is one. Files of other
+// kinds are plain Helm values.
+func readParametersFile(f *os.File) (map[string]any, *spaceConfig, error) {
+	base := map[string]any{}
+	if f == nil {
+		return base, nil, nil
+	}
+	defer f.Close() //nolint:errcheck,gosec
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errReadParametersFile)
+	}
+	if err := yaml.Unmarshal(b, &base); err != nil {
+		return nil, nil, errors.Wrap(err, errReadParametersFile)
+	}
+	if err := f.Close(); err != nil {
+		return nil, nil, errors.Wrap(err, errReadParametersFile)
+	}
+	if base["kind"] != spaceConfigKind {
+		return base, nil, nil
+	}
+	cfg, err := parseSpaceConfig(b, filepath.Dir(f.Name()))
+	if err != nil {
+		return nil, nil, err
+	}
+	values := cfg.Values
+	if values == nil {
+		values = map[string]any{}
+	}
+	return values, cfg, nil
+}
+
+// parseSpaceConfig parses and validates a SpaceConfig. The path of the token
+// file is resolved relative to dir.
+func parseSpaceConfig(b []byte, dir string) (*spaceConfig, error) {
+	cfg := &spaceConfig{}
+	if err := yaml.UnmarshalStrict(b, cfg); err != nil {
+		return nil, errors.Wrap(err, errParseSpaceConfig)
+	}
+	if cfg.APIVersion != spaceConfigAPIVersion {
+		return nil, errors.Errorf(errFmtSpaceConfigVersion, cfg.APIVersion, spaceConfigAPIVersion)
+	}
+	if err := (&prerequisites.Config{Prerequisites: cfg.Prerequisites}).Validate(); err != nil {
+		return nil, errors.Wrap(err, errParseSpaceConfig)
+	}
+	if cfg.Registry.TokenFile != "" && !filepath.IsAbs(cfg.Registry.TokenFile) {
+		cfg.Registry.TokenFile = filepath.Join(dir, cfg.Registry.TokenFile)
+	}
+	return cfg, nil
+}
+
+// apply sets the settings of the SpaceConfig that were not set by flags. It
+// must be called before the registry and prerequisites flags are applied.
+func (s *spaceConfig) apply(version *string, set map[string]string, registry *authorizedRegistryFlags, prereqs *prerequisitesFlags) error { //nolint:gocyclo
+	if *version == "" {
+		*version = s.Version
+	}
+	if s.Cloud != "" {
+		if _, ok := set[defaults.ClusterTypeStr]; !ok {
+			set[defaults.ClusterTypeStr] = s.Cloud
+		}
+	}
+	if s.Registry.Repository != "" && registry.Repository.String() == defaultRegistry {
+		u, err := url.Parse(s.Registry.Repository)
+		if err != nil {
+			return errors.Wrap(err, errParseRegistry)
+		}
+		registry.Repository = u
+	}
+	if s.Registry.Endpoint != "" && registry.Endpoint.String() == defaultRegistryEndpoint {
+		u, err := url.Parse(s.Registry.Endpoint)
+		if err != nil {
+			return errors.Wrap(err, errParseRegistry)
+		}
+		registry.Endpoint = u
+	}
+	if s.Registry.TokenFile != "" && registry.TokenFile == nil && registry.Username == "" {
+		f, err := os.Open(s.Registry.TokenFile)
+		if err != nil {
+			return errors.Wrap(err, errOpenTokenFile)
+		}
+		registry.TokenFile = f
+	}
+	if len(s.Prerequisites) > 0 && prereqs.PrerequisitesConfig == "" {
+		prereqs.config = &prerequisites.Config{Prerequisites: s.Prerequisites}
+	}
+	return nil
+}
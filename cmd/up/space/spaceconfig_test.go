@@ -0,0 +1,183 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package space
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/cmd/up/space/prerequisites"
+)
+
+func TestReadParametersFile(t *testing.T) {
+	dir := t.TempDir()
+
+	type want struct {
+		values map[string]any
+		cfg    *spaceConfig
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		file   string
+		want   want
+	}{
+		"HelmValues": {
+			reason: "Files that are not a SpaceConfig should be read as Helm values.",
+			file:   "account: my-org\n",
+			want:   want{values: map[string]any{"account": "my-org"}},
+		},
+		"SpaceConfig": {
+			reason: "The values of a SpaceConfig should be returned, and its token file resolved relative to it.",
+			file: `
+apiVersion: up.upbound.io/v1alpha1
+kind: SpaceConfig
+version: 1.2.0
+registry:
+  tokenFile: key.json
+cloud: kind
+prerequisites:
+  cert-manager:
+    version: v1.13.1
+values:
+  account: my-org
+profile:
+  name: my-space
+`,
+			want: want{
+				values: map[string]any{"account": "my-org"},
+				cfg: &spaceConfig{
+					APIVersion:    spaceConfigAPIVersion,
+					Kind:          spaceConfigKind,
+					Version:       "1.2.0",
+					Registry:      spaceConfigRegistry{TokenFile: filepath.Join(dir, "key.json")},
+					Cloud:         "kind",
+					Prerequisites: map[string]prerequisites.Settings{"cert-manager": {Version: "v1.13.1"}},
+					Values:        map[string]any{"account": "my-org"},
+					Profile:       spaceConfigProfile{Name: "my-space"},
+				},
+			},
+		},
+		"UnsupportedVersion": {
+			reason: "SpaceConfigs of an unknown apiVersion should be rejected.",
+			file:   "apiVersion: up.upbound.io/v2\nkind: SpaceConfig\n",
+			want:   want{err: errors.Errorf(errFmtSpaceConfigVersion, "up.upbound.io/v2", spaceConfigAPIVersion)},
+		},
+		"UnknownPrerequisite": {
+			reason: "SpaceConfigs should be validated.",
+			file:   "apiVersion: up.upbound.io/v1alpha1\nkind: SpaceConfig\nprerequisites:\n  foo: {}\n",
+			want:   want{err: errors.Wrap(errors.New(`unknown prerequisite "foo"`), errParseSpaceConfig)},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, "space.yaml")
+			if err := os.WriteFile(path, []byte(tc.file), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			values, cfg, err := readParametersFile(f)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nreadParametersFile(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.values, values); diff != "" {
+				t.Errorf("\n%s\nreadParametersFile(...): -want values, +got values:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cfg, cfg); diff != "" {
+				t.Errorf("\n%s\nreadParametersFile(...): -want config, +got config:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSpaceConfigApply(t *testing.T) {
+	mustURL := func(s string) *url.URL {
+		u, _ := url.Parse(s)
+		return u
+	}
+	cfg := &spaceConfig{
+		Version:       "1.2.0",
+		Cloud:         "kind",
+		Registry:      spaceConfigRegistry{Repository: "registry.example.com/upbound", Endpoint: "https://registry.example.com"},
+		Prerequisites: map[string]prerequisites.Settings{"cert-manager": {Version: "v1.13.1"}},
+	}
+
+	type want struct {
+		version  string
+		set      map[string]string
+		registry registryFlags
+		prereqs  *prerequisites.Config
+	}
+	cases := map[string]struct {
+		reason   string
+		version  string
+		set      map[string]string
+		registry authorizedRegistryFlags
+		prereqs  prerequisitesFlags
+		want     want
+	}{
+		"Defaults": {
+			reason:   "Settings of the SpaceConfig should be applied if flags are not set.",
+			set:      map[string]string{},
+			registry: authorizedRegistryFlags{registryFlags: registryFlags{Repository: mustURL(defaultRegistry), Endpoint: mustURL(defaultRegistryEndpoint)}},
+			want: want{
+				version:  "1.2.0",
+				set:      map[string]string{"clusterType": "kind"},
+				registry: registryFlags{Repository: mustURL("registry.example.com/upbound"), Endpoint: mustURL("https://registry.example.com")},
+				prereqs:  &prerequisites.Config{Prerequisites: cfg.Prerequisites},
+			},
+		},
+		"FlagsTakePrecedence": {
+			reason:   "Flags that are set should take precedence over the SpaceConfig.",
+			version:  "1.3.0",
+			set:      map[string]string{"clusterType": "eks"},
+			registry: authorizedRegistryFlags{registryFlags: registryFlags{Repository: mustURL("other.example.com/upbound"), Endpoint: mustURL("https://other.example.com")}},
+			prereqs:  prerequisitesFlags{PrerequisitesConfig: "prereqs.yaml"},
+			want: want{
+				version:  "1.3.0",
+				set:      map[string]string{"clusterType": "eks"},
+				registry: registryFlags{Repository: mustURL("other.example.com/upbound"), Endpoint: mustURL("https://other.example.com")},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if err := cfg.apply(&tc.version, tc.set, &tc.registry, &tc.prereqs); err != nil {
+				t.Fatalf("apply(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want.version, tc.version); diff != "" {
+				t.Errorf("\n%s\napply(...): -want version, +got version:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.set, tc.set); diff != "" {
+				t.Errorf("\n%s\napply(...): -want set, +got set:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.registry, tc.registry.registryFlags); diff != "" {
+				t.Errorf("\n%s\napply(...): -want registry, +got registry:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.prereqs, tc.prereqs.config); diff != "" {
+				t.Errorf("\n%s\napply(...): -want prerequisites, +got prerequisites:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package space
+
+import (
+	"context"
+	"net"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/pterm/pterm"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/upbound/up/cmd/up/space/prerequisites"
+	"github.com/upbound/up/internal/config"
+	ctpspace "github.com/upbound/up/internal/controlplane/space"
+	"github.com/upbound/up/internal/install/helm"
+	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/upterm"
+)
+
+const (
+	errCreateHealthChecker = "unable to create health checker"
+)
+
+var (
+	statusFieldNames = []string{"COMPONENT", "STATUS", "MESSAGE"}
+	doctorFieldNames = []string{"COMPONENT", "STATUS", "MESSAGE", "FIX"}
+)
+
+// statusCmd reports the health of a Space.
+type statusCmd struct {
+	Upbound       upbound.Flags      `embed:""`
+	Kube          upbound.KubeFlags  `embed:""`
+	Prerequisites prerequisitesFlags `embed:""`
+
+	checker *healthChecker
+}
+
+// AfterApply sets default values in command after assignment and validation.
+func (c *statusCmd) AfterApply() error {
+	if err := c.Prerequisites.AfterApply(); err != nil {
+		return err
+	}
+	checker, err := newHealthChecker(&c.Kube, c.Upbound, c.Prerequisites.config)
+	if err != nil {
+		return err
+	}
+	c.checker = checker
+	return nil
+}
+
+func (c *statusCmd) Help() string {
+	return `
+The status command checks that the cluster is reachable and reports the
+installed Spaces version, the installation state and version of each
+prerequisite, the readiness of the host cluster and of the Spaces deployments,
+whether the ingress controller has ready endpoints, and how many control planes
+are ready. Prerequisites whose versions differ from the pinned ones, or from
+those set with --prerequisites-config, are reported as warnings.
+
+The cluster of the current Space profile is checked, unless --kubeconfig or
+--kubecontext are set or the current profile is not a Space profile, in which
+case the cluster of the kubeconfig is checked.
+
+Run 'up space doctor' for more detailed checks and suggested fixes.`
+}
+
+// Run executes the status command.
+func (c *statusCmd) Run(ctx context.Context, printer upterm.ObjectPrinter) error {
+	checks := c.checker.status(ctx)
+	if printer.Format != config.Default {
+		return printer.Print(checks, nil, nil)
+	}
+	return printer.Print(checks, statusFieldNames, extractStatusFields)
+}
+
+// newHealthChecker builds a health checker for the cluster selected by the
+// kube flags, the current Space profile or the default kubeconfig, in that
+// order.
+func newHealthChecker(kube *upbound.KubeFlags, flags upbound.Flags, cfg *prerequisites.Config) (*healthChecker, error) {
+	kubeconfig, err := healthKubeconfig(kube, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE(tnthornton) we currently only have support for stylized output.
+	pterm.EnableStyling()
+	upterm.DefaultObjPrinter.Pretty = true
+
+	return buildHealthChecker(kubeconfig, cfg)
+}
+
+func healthKubeconfig(kube *upbound.KubeFlags, flags upbound.Flags) (*rest.Config, error) {
+	if kube.Kubeconfig == "" && kube.Context == "" {
+		upCtx, err := upbound.NewFromFlags(flags)
+		if err != nil {
+			return nil, err
+		}
+		if upCtx.Profile.IsSpace() {
+			return upCtx.Profile.GetKubeClientConfig()
+		}
+	}
+	if err := kube.AfterApply(); err != nil {
+		return nil, err
+	}
+	return kube.GetConfig(), nil
+}
+
+func buildHealthChecker(kubeconfig *rest.Config, cfg *prerequisites.Config) (*healthChecker, error) {
+	mgr, err := helm.NewManager(kubeconfig,
+		spacesChart,
+		nil,
+		helm.WithNamespace(ns),
+		helm.IsOCI(),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, errCreateHealthChecker)
+	}
+	prereqs, err := prerequisites.New(kubeconfig, nil, prerequisites.WithConfig(cfg))
+	if err != nil {
+		return nil, errors.Wrap(err, errCreateHealthChecker)
+	}
+	kClient, err := kubernetes.NewForConfig(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrap(err, errCreateHealthChecker)
+	}
+	dClient, err := dynamic.NewForConfig(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrap(err, errCreateHealthChecker)
+	}
+	return &healthChecker{
+		version: mgr,
+		prereqs: prereqs.Prerequisites(),
+		kClient: kClient,
+		dClient: dClient,
+		dial:    (&net.Dialer{}).DialContext,
+
+		ctpClient: controlPlaneClient(dClient),
+	}, nil
+}
+
+// controlPlaneClient returns a function that builds a client for a control
+// plane from its kubeconfig secret.
+func controlPlaneClient(dClient dynamic.Interface) func(ctx context.Context, name string) (dynamic.Interface, error) {
+	spaces := ctpspace.New(dClient)
+	return func(ctx context.Context, name string) (dynamic.Interface, error) {
+		kubeconfig, err := spaces.GetKubeConfig(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		cfg, err := clientcmd.NewDefaultClientConfig(*kubeconfig, &clientcmd.ConfigOverrides{}).ClientConfig()
+		if err != nil {
+			return nil, err
+		}
+		return dynamic.NewForConfig(cfg)
+	}
+}
+
+func extractStatusFields(obj any) []string {
+	c, ok := obj.(check)
+	if !ok {
+		return []string{"unknown", "unknown", ""}
+	}
+	return []string{c.Component, string(c.Status), c.Message}
+}
+
+func extractDoctorFields(obj any) []string {
+	c, ok := obj.(check)
+	if !ok {
+		return []string{"unknown", "unknown", "", ""}
+	}
+	return []string{c.Component, string(c.Status), c.Message, c.Fix}
+}
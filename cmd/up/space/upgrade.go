@@ -19,15 +19,21 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
+	"github.com/alecthomas/kong"
 	"github.com/blang/semver/v4"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
 	"github.com/pterm/pterm"
+	"golang.org/x/exp/maps"
 	"helm.sh/helm/v3/pkg/chart"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"sigs.k8s.io/yaml"
 
+	"github.com/upbound/up/cmd/up/space/defaults"
+	"github.com/upbound/up/cmd/up/space/prerequisites"
 	"github.com/upbound/up/internal/config"
 	"github.com/upbound/up/internal/input"
 	"github.com/upbound/up/internal/install"
@@ -41,46 +47,100 @@ const (
 	errParseUpgradeParameters      = "unable to parse upgrade parameters"
 	errFailedGettingCurrentVersion = "failed to retrieve current version"
 	errInvalidVersionFmt           = "invalid version %q"
-	errAborted                     = "aborted"
+	errFmtPreflightChecksFailed    = "%d preflight checks failed"
+	errFmtVerifyUpgrade            = "upgrade did not become healthy within %s, run 'up space doctor' for details or use --rollback to roll back automatically"
+	errFmtRolledBack               = "upgrade did not become healthy within %s, rolled back to v%s"
+	errRollback                    = "failed to roll back upgrade"
 )
 
 // upgradeCmd upgrades Upbound.
 type upgradeCmd struct {
-	Upbound  upbound.Flags           `embed:""`
-	Kube     upbound.KubeFlags       `embed:""`
-	Registry authorizedRegistryFlags `embed:""`
+	Upbound       upbound.Flags           `embed:""`
+	Kube          upbound.KubeFlags       `embed:""`
+	Registry      authorizedRegistryFlags `embed:""`
+	Prerequisites prerequisitesFlags      `embed:""`
 	install.CommonParams
 
 	// NOTE(hasheddan): version is currently required for upgrade with OCI image
 	// as latest strategy is undetermined.
-	Version string `arg:"" help:"Upbound Spaces version to upgrade to."`
+	Version string `arg:"" optional:"" help:"Upbound Spaces version to upgrade to. Required unless set in the SpaceConfig passed with --file."`
 
-	Rollback bool `help:"Rollback to previously installed version on failed upgrade."`
+	Rollback             bool          `help:"Rollback to previously installed version if the upgrade fails, or if the Space and its control planes do not become ready within --verify-timeout."`
+	DryRun               bool          `help:"Print the Helm values and the diff of the rendered resources against the current release without changing the cluster."`
+	UpgradePrerequisites bool          `help:"Upgrade installed prerequisites whose versions differ from the pinned ones, or from those set with --prerequisites-config, before upgrading Spaces."`
+	SkipPreflightChecks  bool          `help:"Skip the checks of the Kubernetes version, cluster capacity, deprecated API usage and prerequisites before the upgrade."`
+	VerifyTimeout        time.Duration `default:"10m" help:"How long to wait for the Spaces deployments and all control planes to become ready after the upgrade. With --rollback, the upgrade is rolled back if they do not."`
+	VendorProfiles       string        `name:"vendor-profiles" type:"existingfile" help:"File with the custom profiles of Kubernetes distributions passed to 'up space init', whose settings are kept when Spaces and its prerequisites are upgraded."`
 
+	stdout     io.Writer
 	helmMgr    install.Manager
+	prereqs    *prerequisites.Manager
 	parser     install.ParameterParser
-	prompter   input.Prompter
+	confirmer  *input.Confirmer
+	profile    string
 	pullSecret *kube.ImagePullApplicator
 	kClient    kubernetes.Interface
+	checker    *healthChecker
 	quiet      config.QuietFlag
 	oldVersion string
 	downgrade  bool
+
+	// timeout bounds each individual API call, while the verification as a
+	// whole is bounded by VerifyTimeout.
+	timeout time.Duration
+	// interval is how often the Space and control planes are checked during
+	// verification.
+	interval time.Duration
 }
 
-// BeforeApply sets default values in login before assignment and validation.
-func (c *upgradeCmd) BeforeApply() error {
-	c.prompter = input.NewPrompter()
-	return nil
+// Help returns the help text of the upgrade command.
+func (c *upgradeCmd) Help() string {
+	return `
+The upgrade command runs preflight checks, upgrades Spaces and then waits for
+the Spaces deployments and all control planes to become ready.
+
+The version, registry, prerequisites and Helm values can be read from the
+SpaceConfig that was passed to 'up space init --file'. Its profile and public
+ingress settings only apply to init.
+
+Examples:
+  # Upgrade Spaces to v1.3.0.
+  up space upgrade v1.3.0 --token-file=key.json
+
+  # Upgrade Spaces to the version of a SpaceConfig, rolling back on failure.
+  up space upgrade -f space.yaml --rollback`
 }
 
 // AfterApply sets default values in command after assignment and validation.
-func (c *upgradeCmd) AfterApply(quiet config.QuietFlag) error { //nolint:gocyclo
+func (c *upgradeCmd) AfterApply(kongCtx *kong.Context, quiet config.QuietFlag, confirmer *input.Confirmer) error { //nolint:gocyclo
+	c.stdout = kongCtx.Stdout
+	c.confirmer = confirmer
+	c.timeout = defaultTimeout
+	c.interval = 5 * time.Second
+	base, spaceCfg, err := readParametersFile(c.File)
+	if err != nil {
+		return err
+	}
+	if spaceCfg != nil {
+		if c.Set == nil {
+			c.Set = map[string]string{}
+		}
+		if err := spaceCfg.apply(&c.Version, c.Set, &c.Registry, &c.Prerequisites); err != nil {
+			return err
+		}
+	}
+	if c.Version == "" {
+		return errors.New(errVersionRequired)
+	}
 	if err := c.Kube.AfterApply(); err != nil {
 		return err
 	}
 	if err := c.Registry.AfterApply(); err != nil {
 		return err
 	}
+	if err := c.Prerequisites.AfterApply(); err != nil {
+		return err
+	}
 
 	// NOTE(tnthornton) we currently only have support for stylized output.
 	pterm.EnableStyling()
@@ -91,6 +151,8 @@ func (c *upgradeCmd) AfterApply(quiet config.QuietFlag) error { //nolint:gocyclo
 		return err
 	}
 
+	c.profile = upCtx.ProfileName
+
 	kubeconfig, err := c.getKubeconfig(upCtx)
 	if err != nil {
 		return err
@@ -116,19 +178,33 @@ func (c *upgradeCmd) AfterApply(quiet config.QuietFlag) error { //nolint:gocyclo
 		return err
 	}
 	c.helmMgr = ins
-	base := map[string]any{}
-	if c.File != nil {
-		defer c.File.Close() //nolint:errcheck,gosec
-		b, err := io.ReadAll(c.File)
-		if err != nil {
-			return errors.Wrap(err, errReadParametersFile)
-		}
-		if err := yaml.Unmarshal(b, &base); err != nil {
-			return errors.Wrap(err, errReadParametersFile)
+
+	var profiles []defaults.Profile
+	if c.VendorProfiles != "" {
+		if profiles, err = defaults.ReadProfiles(c.VendorProfiles); err != nil {
+			return err
 		}
-		if err := c.File.Close(); err != nil {
-			return errors.Wrap(err, errReadParametersFile)
+	}
+	// The values of the installed release are only used to find the cluster
+	// type the Space was installed with.
+	installed, _ := ins.GetValues()
+	defs, err := c.cloudDefaults(kClient, installed, profiles)
+	if err != nil {
+		return err
+	}
+
+	checker, err := buildHealthChecker(kubeconfig, c.Prerequisites.config)
+	if err != nil {
+		return err
+	}
+	checker.timeout = c.timeout
+	c.checker = checker
+	if c.UpgradePrerequisites {
+		prereqs, err := prerequisites.New(kubeconfig, defs, prerequisites.WithConfig(c.Prerequisites.config))
+		if err != nil {
+			return err
 		}
+		c.prereqs = prereqs
 	}
 	c.parser = helm.NewParser(base, c.Set)
 	c.quiet = quiet
@@ -157,6 +233,25 @@ func (c *upgradeCmd) AfterApply(quiet config.QuietFlag) error { //nolint:gocyclo
 	return nil
 }
 
+// cloudDefaults returns the defaults of the profile of the cluster, so that
+// an upgrade keeps the settings the Space was installed with. The cluster type
+// is the one set with --set or in the SpaceConfig, otherwise the one of the
+// installed release, otherwise the detected one. User supplied values
+// override the Spaces values of the profile.
+func (c *upgradeCmd) cloudDefaults(kClient kubernetes.Interface, installed map[string]any, profiles []defaults.Profile) (*defaults.CloudConfig, error) {
+	cloud := c.Set[defaults.ClusterTypeStr]
+	if cloud == "" {
+		cloud, _ = fieldpath.Pave(installed).GetString(defaults.ClusterTypeStr)
+	}
+	defs, err := defaults.GetConfig(kClient, cloud, profiles...)
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(defs.SpacesValues, c.Set)
+	c.Set = defs.SpacesValues
+	return defs, nil
+}
+
 // getKubeconfig returns the kubeconfig from flags if provided, otherwise the
 // kubeconfig from the active profile.
 func (c *upgradeCmd) getKubeconfig(upCtx *upbound.Context) (*rest.Config, error) {
@@ -171,24 +266,146 @@ func (c *upgradeCmd) getKubeconfig(upCtx *upbound.Context) (*rest.Config, error)
 
 // Run executes the upgrade command.
 func (c *upgradeCmd) Run(ctx context.Context) error {
-	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
-	defer cancel()
-
 	params, err := c.parser.Parse()
 	if err != nil {
 		return errors.Wrap(err, errParseUpgradeParameters)
 	}
 	overrideRegistry(c.Registry.Repository.String(), params)
 
+	if !c.SkipPreflightChecks {
+		if err := c.preflight(ctx); err != nil {
+			return err
+		}
+	}
+
+	if c.DryRun {
+		return c.plan(params)
+	}
+
 	// Create or update image pull secret.
-	if err := c.pullSecret.Apply(ctx, defaultImagePullSecret, ns, c.Registry.Username, c.Registry.Password, c.Registry.Endpoint.String()); err != nil {
+	if err := c.applyPullSecret(ctx); err != nil {
 		return errors.Wrap(err, errCreateImagePullSecret)
 	}
 
+	if c.prereqs != nil {
+		if err := c.upgradePrereqs(); err != nil {
+			return err
+		}
+	}
+
 	if err := c.upgradeUpbound(params); err != nil {
 		return err
 	}
 
+	return c.verify(ctx)
+}
+
+func (c *upgradeCmd) applyPullSecret(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.pullSecret.Apply(ctx, defaultImagePullSecret, ns, c.Registry.Username, c.Registry.Password, c.Registry.Endpoint.String())
+}
+
+// preflight prints the results of the preflight checks. It fails if any check
+// failed, and asks for confirmation if any check warned.
+func (c *upgradeCmd) preflight(ctx context.Context) error {
+	pterm.Println("Running preflight checks...")
+	checks := c.checker.preflight(ctx, c.UpgradePrerequisites)
+	failed, warned := 0, 0
+	for _, chk := range checks {
+		switch chk.Status {
+		case checkOK:
+			pterm.Success.Printfln("%s: %s", chk.Component, chk.Message)
+		case checkWarning:
+			warned++
+			pterm.Warning.Printfln("%s: %s", chk.Component, chk.Message)
+		case checkFailed:
+			failed++
+			pterm.Error.Printfln("%s: %s", chk.Component, chk.Message)
+		}
+		if chk.Fix != "" {
+			pterm.Printfln("  %s", chk.Fix)
+		}
+	}
+	pterm.Println()
+	switch {
+	case failed > 0:
+		return errors.Errorf(errFmtPreflightChecksFailed, failed)
+	case warned == 0 || c.DryRun:
+		return nil
+	}
+	return c.warnAndConfirm(fmt.Sprintf("%d preflight checks reported warnings.", warned))
+}
+
+// verify waits for the Spaces deployments and all control planes to become
+// ready, and rolls the upgrade back if they do not and --rollback is set. Only
+// each round of checks is bounded by the API call timeout, so verification
+// may run for up to --verify-timeout.
+func (c *upgradeCmd) verify(ctx context.Context) error {
+	var pending []check
+	round := func(ctx context.Context) []check {
+		ctx, cancel := context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+		return c.checker.verify(ctx)
+	}
+	poll := func() error {
+		err := wait.PollUntilContextTimeout(ctx, c.interval, c.VerifyTimeout, true, func(ctx context.Context) (bool, error) {
+			pending = pending[:0]
+			for _, chk := range round(ctx) {
+				if chk.Status != checkOK {
+					pending = append(pending, chk)
+				}
+			}
+			return len(pending) == 0, nil
+		})
+		if wait.Interrupted(err) {
+			return errors.Errorf("%d components not ready", len(pending))
+		}
+		return err
+	}
+	if err := upterm.WrapWithSuccessSpinner(
+		"Verifying Space and control planes are ready",
+		upterm.CheckmarkSuccessSpinner,
+		poll,
+	); err == nil {
+		return nil
+	}
+	fmt.Println()
+	for _, chk := range pending {
+		pterm.Error.Printfln("%s: %s", chk.Component, chk.Message)
+	}
+	pterm.Println()
+
+	if !c.Rollback {
+		return errors.Errorf(errFmtVerifyUpgrade, c.VerifyTimeout)
+	}
+	if err := upterm.WrapWithSuccessSpinner(
+		fmt.Sprintf("Rolling back Space to v%s", c.oldVersion),
+		upterm.CheckmarkSuccessSpinner,
+		c.helmMgr.Rollback,
+	); err != nil {
+		fmt.Println()
+		fmt.Println()
+		return errors.Wrap(err, errRollback)
+	}
+	return errors.Errorf(errFmtRolledBack, c.VerifyTimeout, c.oldVersion)
+}
+
+// plan prints the changes the upgrade would make.
+func (c *upgradeCmd) plan(params map[string]any) error {
+	plan, err := c.helmMgr.PlanUpgrade(strings.TrimPrefix(c.Version, "v"), params, upgradeUpVersionBounds, upgradeFromVersionBounds, upgradeVersionBounds)
+	if err != nil {
+		return err
+	}
+	var outdated []prerequisites.VersionStatus
+	if c.prereqs != nil {
+		outdated = c.prereqs.Outdated()
+	}
+	if err := writePlan(c.stdout, outdated, nil, plan); err != nil {
+		return err
+	}
+	pterm.Println()
+	pterm.Info.Println("Dry run complete, no changes have been made to the cluster.")
 	return nil
 }
 
@@ -204,6 +421,26 @@ func upgradeUpVersionBounds(_ string, ch *chart.Chart) error {
 	return upVersionBounds(ch)
 }
 
+func (c *upgradeCmd) upgradePrereqs() error {
+	outdated := c.prereqs.Outdated()
+	for i, v := range outdated {
+		if err := upterm.WrapWithSuccessSpinner(
+			upterm.StepCounter(
+				fmt.Sprintf("Upgrading %s from %s to %s", v.Prerequisite.GetName(), v.Installed, v.Desired),
+				i+1,
+				len(outdated),
+			),
+			upterm.CheckmarkSuccessSpinner,
+			v.Prerequisite.Upgrade,
+		); err != nil {
+			fmt.Println()
+			fmt.Println()
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *upgradeCmd) upgradeUpbound(params map[string]any) error {
 	version := strings.TrimPrefix(c.Version, "v")
 	upgrade := func() error {
@@ -232,35 +469,37 @@ func (c *upgradeCmd) upgradeUpbound(params map[string]any) error {
 }
 
 func (c *upgradeCmd) validateVersions(from, to semver.Version) error {
+	var warning string
 	switch {
 	case c.downgrade:
-		if err := warnAndConfirm("Downgrades are not supported."); err != nil {
-			return err
-		}
+		warning = "Downgrades are not supported."
 	case to.Major > from.Major:
-		if err := warnAndConfirm("Upgrades to a new major version are only supported for explicitly documented releases."); err != nil {
-			return err
-		}
+		warning = "Upgrades to a new major version are only supported for explicitly documented releases."
 	case to.Minor > from.Minor+1:
-		if err := warnAndConfirm("Upgrades which skip a minor version are not supported."); err != nil {
-			return err
-		}
+		warning = "Upgrades which skip a minor version are not supported."
 	default:
+		return nil
 	}
 
-	return nil
+	// A dry run does not change the cluster, so there is nothing to confirm.
+	if c.DryRun {
+		pterm.Warning.Println(warning)
+		return nil
+	}
+	return c.warnAndConfirm(warning)
 }
 
-func warnAndConfirm(warning string, args ...any) error {
+// warnAndConfirm prints the warning and asks for confirmation to proceed with
+// the upgrade.
+func (c *upgradeCmd) warnAndConfirm(warning string) error {
 	pterm.Println()
-	pterm.Warning.Printfln(warning, args...)
+	pterm.Warning.Println(warning)
 	pterm.Println() // Blank line
-	confirm := pterm.DefaultInteractiveConfirm
-	confirm.DefaultText = "Are you sure you want to proceed?"
-	result, _ := confirm.Show()
+	err := c.confirmer.Confirm(input.Confirmation{
+		Target:  fmt.Sprintf("Space upgrade from v%s to %s: %s", c.oldVersion, c.Version, warning),
+		Profile: c.profile,
+		Prompt:  "Are you sure you want to proceed?",
+	})
 	pterm.Println() // Blank line
-	if !result {
-		return errors.New(errAborted)
-	}
-	return nil
+	return err
 }
@@ -0,0 +1,169 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package space
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clientgotesting "k8s.io/client-go/testing"
+
+	"github.com/upbound/up/cmd/up/space/defaults"
+	"github.com/upbound/up/internal/install"
+	"github.com/upbound/up/internal/kube"
+)
+
+type fakeParser struct{}
+
+func (fakeParser) Parse() (map[string]any, error) {
+	return map[string]any{}, nil
+}
+
+type fakeManager struct {
+	install.Manager
+
+	upgrade  func() error
+	rollback func() error
+}
+
+func (m *fakeManager) Upgrade(_ string, _ map[string]any, _ ...install.UpgradeOption) error {
+	return m.upgrade()
+}
+
+func (m *fakeManager) Rollback() error {
+	return m.rollback()
+}
+
+func TestUpgradeVerifyAfterTimeout(t *testing.T) {
+	done := deployment("mxe-controller", corev1.ConditionTrue, 2)
+	done.Status.Replicas = 2
+	done.Status.UpdatedReplicas = 2
+	kClient := kubefake.NewSimpleClientset(done)
+
+	// The control plane only becomes ready in the second round of checks.
+	dClient := newFakeDynamicClient(controlPlane("ctp1", "False", "creating"))
+	rounds := 0
+	dClient.PrependReactor("list", "controlplanes", func(_ clientgotesting.Action) (bool, runtime.Object, error) {
+		rounds++
+		if rounds < 2 {
+			return false, nil, nil
+		}
+		l := &unstructured.UnstructuredList{}
+		l.SetAPIVersion(controlPlaneGVR.GroupVersion().String())
+		l.SetKind("ControlPlaneList")
+		l.Items = []unstructured.Unstructured{*controlPlane("ctp1", "True", "")}
+		return true, l, nil
+	})
+
+	rolledBack := false
+	c := &upgradeCmd{
+		Version:             "v1.3.0",
+		Rollback:            true,
+		SkipPreflightChecks: true,
+		VerifyTimeout:       time.Minute,
+		Registry: authorizedRegistryFlags{registryFlags: registryFlags{
+			Repository: &url.URL{Host: "registry.example.com"},
+			Endpoint:   &url.URL{Scheme: "https", Host: "registry.example.com"},
+		}},
+		helmMgr: &fakeManager{
+			// The upgrade outlasts the API call timeout.
+			upgrade: func() error {
+				time.Sleep(50 * time.Millisecond)
+				return nil
+			},
+			rollback: func() error {
+				rolledBack = true
+				return nil
+			},
+		},
+		parser:     fakeParser{},
+		pullSecret: kube.NewImagePullApplicator(kube.NewSecretApplicator(kClient)),
+		checker:    &healthChecker{kClient: kClient, dClient: dClient},
+		oldVersion: "1.2.0",
+		timeout:    10 * time.Millisecond,
+		interval:   10 * time.Millisecond,
+	}
+
+	err := c.Run(context.Background())
+	if diff := cmp.Diff(nil, err, test.EquateErrors()); diff != "" {
+		t.Errorf("\nVerification should outlive the API call timeout.\nRun(...): -want err, +got err:\n%s", diff)
+	}
+	if rolledBack {
+		t.Errorf("\nA healthy upgrade should not be rolled back.")
+	}
+	if rounds < 2 {
+		t.Errorf("\nVerification should poll until the control plane is ready, got %d rounds.", rounds)
+	}
+}
+
+func TestUpgradeCloudDefaults(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		set       map[string]string
+		installed map[string]any
+		nodes     []runtime.Object
+		want      map[string]map[string]any
+		wantSet   map[string]string
+	}{
+		"Installed": {
+			reason:    "The cluster type of the installed release should select the profile.",
+			installed: map[string]any{defaults.ClusterTypeStr: "vcluster"},
+			want: map[string]map[string]any{
+				"ingress-nginx": {"controller": map[string]any{"admissionWebhooks": map[string]any{"enabled": false}}},
+			},
+			wantSet: map[string]string{},
+		},
+		"Set": {
+			reason:    "A cluster type set by the user should take precedence over the installed one.",
+			set:       map[string]string{defaults.ClusterTypeStr: "vcluster"},
+			installed: map[string]any{defaults.ClusterTypeStr: "eks"},
+			want: map[string]map[string]any{
+				"ingress-nginx": {"controller": map[string]any{"admissionWebhooks": map[string]any{"enabled": false}}},
+			},
+			wantSet: map[string]string{defaults.ClusterTypeStr: "vcluster"},
+		},
+		"Detected": {
+			reason:  "The profile should be detected if no cluster type is known.",
+			nodes:   []runtime.Object{&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n1", Labels: map[string]string{"vcluster.loft.sh/fake-node": "true"}}}},
+			want:    map[string]map[string]any{"ingress-nginx": {"controller": map[string]any{"admissionWebhooks": map[string]any{"enabled": false}}}},
+			wantSet: map[string]string{},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := &upgradeCmd{}
+			c.Set = tc.set
+			defs, err := c.cloudDefaults(kubefake.NewSimpleClientset(tc.nodes...), tc.installed, nil)
+			if err != nil {
+				t.Fatalf("\n%s\ncloudDefaults(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, defs.PrerequisiteValues); diff != "" {
+				t.Errorf("\n%s\ncloudDefaults(...): -want prerequisite values, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.wantSet, c.Set); diff != "" {
+				t.Errorf("\n%s\ncloudDefaults(...): -want set, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
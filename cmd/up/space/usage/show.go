@@ -0,0 +1,190 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/upterm"
+	"github.com/upbound/up/internal/usage/aggregate"
+	"github.com/upbound/up/internal/usage/event"
+	"github.com/upbound/up/internal/usage/storage"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+const (
+	errReadUsage = "error reading usage data"
+)
+
+var resourceCountFieldNames = []string{"CONTROL PLANE", "GVK", "CURRENT", "PEAK", "LAST SEEN"}
+
+// AfterApply reads missing storage settings from the Spaces cluster and
+// validates the flags.
+func (c *showCmd) AfterApply(kongCtx *kong.Context) error {
+	if c.Since < time.Hour {
+		return fmt.Errorf("--since must be at least 1h")
+	}
+	if c.Refresh < 0 {
+		return fmt.Errorf("--refresh must not be negative")
+	}
+	if c.ObjectConcurrency < 1 {
+		return fmt.Errorf("--object-concurrency must be at least 1")
+	}
+	return c.Storage.Resolve(storage.EndpointSet(kongCtx))
+}
+
+// showCmd shows current and peak managed resource counts read from the usage
+// data of a Space.
+type showCmd struct {
+	Storage storage.Flags `embed:""`
+
+	Since             time.Duration `default:"24h" group:"Usage" help:"Time range of usage data to read, ending now. Rounded up to whole hours."`
+	Refresh           time.Duration `group:"Usage" help:"Read usage data again and print it at this interval until interrupted, e.g. 5m. Usage data is read once if not set."`
+	ObjectConcurrency int           `default:"8" group:"Performance" help:"Number of storage objects read at once."`
+}
+
+func (c *showCmd) Help() string {
+	return `
+The show command reads the managed resource count events Spaces records in its
+usage storage for a recent time range, and prints the current and peak number
+of managed resources per control plane and GVK. Use it to review usage before
+a billing period closes.
+
+The current count of a GVK is its most recently recorded count. Usage data is
+stored by the hour and may lag behind by several minutes.
+
+The storage location is selected the same way as for the billing export
+command: storage flags that are not set are read from the Spaces cluster.
+
+Examples:
+  # Show usage of the last 24 hours, reading storage settings from the
+  # Spaces cluster of the current profile.
+  up space usage show
+
+  # Show usage of the last week, refreshing every 10 minutes.
+  up space usage show --since=168h --refresh=10m
+
+  # Show usage from a local copy of the usage bucket.
+  up space usage show --provider=file --directory=./usage --account=my-org`
+}
+
+// Run executes the show command.
+func (c *showCmd) Run(ctx context.Context, printer upterm.ObjectPrinter, p pterm.TextPrinter) error {
+	for {
+		if err := c.show(ctx, printer, p, time.Now()); err != nil {
+			return err
+		}
+		if c.Refresh == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(c.Refresh):
+		}
+	}
+}
+
+func (c *showCmd) show(ctx context.Context, printer upterm.ObjectPrinter, p pterm.TextPrinter, now time.Time) error {
+	tr := timeRange(now, c.Since)
+	iter, err := c.Storage.WindowIterator(ctx, tr, time.Hour, c.ObjectConcurrency)
+	if err != nil {
+		return errors.Wrap(err, errReadUsage)
+	}
+	counts, err := readResourceCounts(ctx, iter)
+	if err != nil {
+		return errors.Wrap(err, errReadUsage)
+	}
+
+	if printer.Format != config.Default {
+		return printer.Print(counts, nil, nil)
+	}
+	p.Printfln("Usage of account %s from %s to %s:", c.Storage.Account, formatTimestamp(tr.Start), formatTimestamp(now))
+	if len(counts) == 0 {
+		p.Printfln("No usage found\n")
+		return nil
+	}
+	return printer.Print(counts, resourceCountFieldNames, extractResourceCountFields)
+}
+
+// timeRange returns the range of whole hours covering the supplied duration
+// before now, including the current hour.
+func timeRange(now time.Time, since time.Duration) usagetime.Range {
+	end := now.UTC().Truncate(time.Hour).Add(time.Hour)
+	hours := (since + time.Hour - 1) / time.Hour
+	return usagetime.Range{
+		Start: end.Add(-hours * time.Hour),
+		End:   end,
+	}
+}
+
+// readResourceCounts reads all usage events from iter and returns the
+// current and peak resource counts per GVK per MXP.
+func readResourceCounts(ctx context.Context, iter event.WindowIterator) ([]aggregate.ResourceCount, error) {
+	ag := &aggregate.CurrentResourceCountPerGVKPerMXP{}
+	for iter.More() {
+		r, _, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		if err := readInto(ctx, r, ag); err != nil {
+			return nil, err
+		}
+	}
+	return ag.ResourceCounts(), nil
+}
+
+func readInto(ctx context.Context, r event.Reader, ag *aggregate.CurrentResourceCountPerGVKPerMXP) error {
+	defer r.Close() // nolint:errcheck
+	for {
+		e, err := r.Read(ctx)
+		if errors.Is(err, event.ErrEOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := ag.Add(e); err != nil {
+			return err
+		}
+	}
+}
+
+func extractResourceCountFields(obj any) []string {
+	rc := obj.(aggregate.ResourceCount)
+	lastSeen := ""
+	if !rc.LastSeen.IsZero() {
+		lastSeen = formatTimestamp(rc.LastSeen)
+	}
+	return []string{
+		rc.MXPID,
+		fmt.Sprintf("%s.%s.%s", rc.Kind, rc.Version, rc.Group),
+		strconv.Itoa(rc.Current),
+		strconv.Itoa(rc.Peak),
+		lastSeen,
+	}
+}
+
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
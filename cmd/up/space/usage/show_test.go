@@ -0,0 +1,130 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/usage/aggregate"
+	"github.com/upbound/up/internal/usage/model"
+	usagetesting "github.com/upbound/up/internal/usage/testing"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+func TestTimeRange(t *testing.T) {
+	now := time.Date(2023, 9, 1, 10, 30, 0, 0, time.UTC)
+	cases := map[string]struct {
+		reason string
+		since  time.Duration
+		want   usagetime.Range
+	}{
+		"WholeHours": {
+			reason: "The range should end after the current hour.",
+			since:  2 * time.Hour,
+			want: usagetime.Range{
+				Start: time.Date(2023, 9, 1, 9, 0, 0, 0, time.UTC),
+				End:   time.Date(2023, 9, 1, 11, 0, 0, 0, time.UTC),
+			},
+		},
+		"PartialHours": {
+			reason: "The duration should be rounded up to whole hours.",
+			since:  90 * time.Minute,
+			want: usagetime.Range{
+				Start: time.Date(2023, 9, 1, 9, 0, 0, 0, time.UTC),
+				End:   time.Date(2023, 9, 1, 11, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := timeRange(now, tc.since)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ntimeRange(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReadResourceCounts(t *testing.T) {
+	errBoom := errors.New("boom")
+	event := func(mxp string, value float64, hour int) usagetesting.ReadResult {
+		return usagetesting.ReadResult{Event: model.MXPGVKEvent{
+			Name:      "kube_managedresource_uid",
+			Value:     value,
+			Timestamp: time.Date(2023, 9, 1, hour, 0, 0, 0, time.UTC),
+			Tags: model.MXPGVKEventTags{
+				MXPID:   mxp,
+				Group:   "example.com",
+				Version: "v1",
+				Kind:    "Thing",
+			},
+		}}
+	}
+
+	type want struct {
+		counts []aggregate.ResourceCount
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		iter   *usagetesting.MockWindowIterator
+		want   want
+	}{
+		"Windows": {
+			reason: "Events of all windows should be counted.",
+			iter: &usagetesting.MockWindowIterator{Windows: []usagetesting.Window{
+				{Reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{event("mxp-1", 3, 0), event("mxp-2", 1, 0)}}},
+				{Reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{event("mxp-1", 2, 1)}}},
+			}},
+			want: want{
+				counts: []aggregate.ResourceCount{
+					{MXPID: "mxp-1", Group: "example.com", Version: "v1", Kind: "Thing", Current: 2, Peak: 3, LastSeen: time.Date(2023, 9, 1, 1, 0, 0, 0, time.UTC)},
+					{MXPID: "mxp-2", Group: "example.com", Version: "v1", Kind: "Thing", Current: 1, Peak: 1, LastSeen: time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)},
+				},
+			},
+		},
+		"ErrWindow": {
+			reason: "Errors getting a window should be returned.",
+			iter: &usagetesting.MockWindowIterator{Windows: []usagetesting.Window{
+				{Err: errBoom},
+			}},
+			want: want{err: errBoom},
+		},
+		"ErrRead": {
+			reason: "Errors reading events should be returned.",
+			iter: &usagetesting.MockWindowIterator{Windows: []usagetesting.Window{
+				{Reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{{Err: errBoom}}}},
+			}},
+			want: want{err: errBoom},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := readResourceCounts(context.Background(), tc.iter)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nreadResourceCounts(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.counts, got); diff != "" {
+				t.Errorf("\n%s\nreadResourceCounts(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
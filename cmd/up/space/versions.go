@@ -57,6 +57,12 @@ var (
 	// checked on up upgrade against the installed version on the customer
 	// host cluster.
 	upgradeFromVersionConstraints = []constraint{}
+
+	// kubernetesVersionConstraints is the list of version constraints that are
+	// checked against the version of the Kubernetes cluster before an upgrade.
+	kubernetesVersionConstraints = []constraint{
+		{semver: ">= 1.25-0", message: "Kubernetes 1.25 or later is required."},
+	}
 )
 
 func parseChartUpConstraints(s string) ([]constraint, error) {
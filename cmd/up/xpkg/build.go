@@ -55,6 +55,11 @@ func (c *buildCmd) AfterApply() error {
 		return err
 	}
 
+	tests, err := filepath.Abs(c.TestsRoot)
+	if err != nil {
+		return err
+	}
+
 	var authBE parser.Backend
 	if ax, err := filepath.Abs(c.AuthExt); err == nil {
 		if axf, err := c.fs.Open(ax); err == nil {
@@ -79,7 +84,7 @@ func (c *buildCmd) AfterApply() error {
 			parser.FsFilters(
 				append(
 					buildFilters(root, c.Ignore),
-					xpkg.SkipContains(c.ExamplesRoot), xpkg.SkipContains(c.AuthExt))...),
+					xpkg.SkipContains(c.ExamplesRoot), xpkg.SkipContains(c.AuthExt), xpkg.SkipContains(tests))...),
 		),
 		authBE,
 		parser.NewFsBackend(
@@ -112,6 +117,7 @@ type buildCmd struct {
 	PackageRoot  string   `short:"f" help:"Path to package directory." default:"."`
 	ExamplesRoot string   `short:"e" help:"Path to package examples directory." default:"./examples"`
 	AuthExt      string   `short:"a" help:"Path to an authentication extension file." default:"auth.yaml"`
+	TestsRoot    string   `help:"Path to package tests directory, which is excluded from the package." default:"./tests"`
 	Ignore       []string `help:"Paths, specified relative to --package-root, to exclude from the package."`
 }
 
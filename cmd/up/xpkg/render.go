@@ -0,0 +1,185 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	k8syaml "sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/upbound/up/internal/xpkg/render"
+	"github.com/upbound/up/internal/xpkg/workspace"
+)
+
+const (
+	errParseWorkspace = "failed to parse package workspace"
+	errReadExample    = "failed to read composite resource or claim"
+	errRender         = "failed to render composition"
+)
+
+// AfterApply constructs and binds context to any subcommands
+// that have Run() methods that receive it.
+func (c *renderCmd) AfterApply() error {
+	c.fs = afero.NewOsFs()
+
+	root, err := filepath.Abs(c.PackageRoot)
+	if err != nil {
+		return err
+	}
+	c.root = root
+	return nil
+}
+
+// renderCmd renders the resources composed for a composite resource or
+// claim.
+type renderCmd struct {
+	fs   afero.Fs
+	root string
+
+	Resource    string `arg:"" type:"path" help:"Path to a file containing a composite resource or claim, typically found in the examples directory."`
+	PackageRoot string `short:"f" help:"Path to package directory." default:"."`
+	Composition string `short:"c" help:"Name of the Composition to render. Selected by the resource's composition reference or selector if not specified."`
+}
+
+func (c *renderCmd) Help() string {
+	return `
+The render command renders the resources a Composition in the package would
+compose for the supplied composite resource (XR) or claim, and prints them as
+YAML. Patches and transforms are applied locally; no cluster is required.
+
+Claims are converted to the composite resource defined by the package's
+CompositeResourceDefinition before rendering. Composed resources are not
+named, only their generateName is set.
+
+Examples:
+  # Render the resources composed for an example claim.
+  up xpkg render examples/cluster.yaml
+
+  # Render using a specific Composition.
+  up xpkg render examples/cluster.yaml --composition=xclusters.aws.example.org`
+}
+
+// Run executes the render command.
+func (c *renderCmd) Run(ctx context.Context, p pterm.TextPrinter) error {
+	ws, err := workspace.New(c.root, workspace.WithFS(c.fs), workspace.WithPermissiveParser())
+	if err != nil {
+		return err
+	}
+	if err := ws.Parse(ctx); err != nil {
+		return errors.Wrap(err, errParseWorkspace)
+	}
+
+	b, err := afero.ReadFile(c.fs, c.Resource)
+	if err != nil {
+		return errors.Wrap(err, errReadExample)
+	}
+	res := &unstructured.Unstructured{}
+	if err := k8syaml.Unmarshal(b, res); err != nil {
+		return errors.Wrap(err, errReadExample)
+	}
+
+	xr, comp, err := resolveComposition(ws.View(), res, c.Composition)
+	if err != nil {
+		return err
+	}
+
+	cds, err := render.Render(ctx, xr, comp)
+	if err != nil {
+		return errors.Wrap(err, errRender)
+	}
+
+	var errs []error
+	for _, cd := range cds {
+		if cd.Err != nil {
+			errs = append(errs, cd.Err)
+			continue
+		}
+		out, err := k8syaml.Marshal(cd.Resource.Object)
+		if err != nil {
+			return err
+		}
+		p.Printf("---\n%s", out)
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+// resolveComposition returns the composite resource for the supplied
+// composite resource or claim, and the Composition from the workspace to
+// render it with.
+func resolveComposition(v *workspace.View, res *unstructured.Unstructured, name string) (*unstructured.Unstructured, *xpextv1.Composition, error) {
+	xr := res
+	if xrGVK, ok := compositeForClaim(v, res.GroupVersionKind()); ok {
+		var err error
+		if xr, err = render.CompositeFromClaim(res, xrGVK); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	comps, err := workspaceCompositions(v)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if name != "" {
+		meta := xr.DeepCopy()
+		if err := unstructured.SetNestedField(meta.Object, name, "spec", "compositionRef", "name"); err != nil {
+			return nil, nil, err
+		}
+		comp, err := render.SelectComposition(meta, comps)
+		return xr, comp, err
+	}
+	comp, err := render.SelectComposition(xr, comps)
+	return xr, comp, err
+}
+
+// compositeForClaim returns the composite resource kind of the supplied claim
+// kind, if it is defined by an XRD in the workspace.
+func compositeForClaim(v *workspace.View, claim schema.GroupVersionKind) (schema.GroupVersionKind, bool) {
+	for xr, c := range v.XRClaimsRefs() {
+		if c == claim {
+			return xr, true
+		}
+	}
+	return schema.GroupVersionKind{}, false
+}
+
+// workspaceCompositions returns all Compositions found in the workspace.
+func workspaceCompositions(v *workspace.View) ([]xpextv1.Composition, error) {
+	comps := []xpextv1.Composition{}
+	for _, n := range v.Nodes() {
+		if n.GetGVK() != xpextv1.CompositionGroupVersionKind {
+			continue
+		}
+		u, ok := n.GetObject().(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		comp, err := render.CompositionFromUnstructured(u)
+		if err != nil {
+			return nil, err
+		}
+		comps = append(comps, *comp)
+	}
+	return comps, nil
+}
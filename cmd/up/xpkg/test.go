@@ -0,0 +1,163 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up/internal/xpkg/render"
+	"github.com/upbound/up/internal/xpkg/workspace"
+)
+
+const (
+	errNoTests       = "no test cases found"
+	errCreateJUnit   = "failed to create JUnit report"
+	errFmtTestsFail  = "%d of %d tests failed"
+	errFmtUpdateTest = "failed to update expected output of %s"
+
+	junitSuiteName = "up xpkg test"
+)
+
+// AfterApply constructs and binds context to any subcommands
+// that have Run() methods that receive it.
+func (c *testCmd) AfterApply() error {
+	c.fs = afero.NewOsFs()
+
+	root, err := filepath.Abs(c.PackageRoot)
+	if err != nil {
+		return err
+	}
+	c.root = root
+
+	tests, err := filepath.Abs(c.TestsRoot)
+	if err != nil {
+		return err
+	}
+	c.tests = tests
+	return nil
+}
+
+// testCmd runs golden-file tests for the Compositions of a package.
+type testCmd struct {
+	fs    afero.Fs
+	root  string
+	tests string
+
+	PackageRoot string `short:"f" help:"Path to package directory." default:"."`
+	TestsRoot   string `short:"t" help:"Path to package tests directory." default:"./tests"`
+	JUnit       string `name:"junit" type:"path" help:"Path to write a JUnit XML report of the test results to."`
+	Update      bool   `help:"Write the rendered composed resources as the expected output of every test instead of comparing them."`
+}
+
+func (c *testCmd) Help() string {
+	return `
+The test command runs golden-file tests for the Compositions in a package. Every
+directory below the tests directory that contains an xr.yaml file is a test
+case, made of:
+
+  - xr.yaml: the composite resource (XR) or claim to render.
+  - observed.yaml: optionally, the observed state of composed resources. Their
+    state is patched to the XR before rendering. Resources are matched to
+    Composition templates by their crossplane.io/composition-resource-name
+    annotation.
+  - expected.yaml: the expected composed resources.
+
+The Composition is selected the same way as for the render command. The
+rendered composed resources are compared with the expected ones and a diff is
+reported for every test that fails.
+
+Examples:
+  # Run all tests of the package in the current directory.
+  up xpkg test
+
+  # Write a JUnit report for CI.
+  up xpkg test --junit=report.xml
+
+  # Regenerate the expected output of all tests after reviewing a change.
+  up xpkg test --update`
+}
+
+// Run executes the test command.
+func (c *testCmd) Run(ctx context.Context, p pterm.TextPrinter) error { //nolint:gocyclo
+	ws, err := workspace.New(c.root, workspace.WithFS(c.fs), workspace.WithPermissiveParser())
+	if err != nil {
+		return err
+	}
+	if err := ws.Parse(ctx); err != nil {
+		return errors.Wrap(err, errParseWorkspace)
+	}
+
+	cases, err := render.LoadCases(c.fs, c.tests)
+	if err != nil {
+		return err
+	}
+	if len(cases) == 0 {
+		return errors.New(errNoTests)
+	}
+
+	results := make([]render.Result, len(cases))
+	failed := 0
+	for i, tc := range cases {
+		xr, comp, err := resolveComposition(ws.View(), tc.Resource, "")
+		if err != nil {
+			results[i] = render.Result{Name: tc.Name, Err: err}
+		} else if c.Update {
+			if err := render.WriteExpected(ctx, c.fs, tc, xr, comp); err != nil {
+				return errors.Wrapf(err, errFmtUpdateTest, tc.Name)
+			}
+			p.Printfln("UPDATED %s", tc.Name)
+			continue
+		} else {
+			results[i] = render.Run(ctx, tc, xr, comp)
+		}
+
+		r := results[i]
+		switch {
+		case r.Err != nil:
+			failed++
+			p.Printfln("ERROR %s: %v", r.Name, r.Err)
+		case !r.Passed():
+			failed++
+			p.Printfln("FAIL  %s", r.Name)
+			for _, f := range r.Failures {
+				p.Printfln("    %s", f)
+			}
+		default:
+			p.Printfln("PASS  %s (%s)", r.Name, r.Duration)
+		}
+	}
+
+	if c.JUnit != "" && !c.Update {
+		f, err := c.fs.Create(c.JUnit)
+		if err != nil {
+			return errors.Wrap(err, errCreateJUnit)
+		}
+		defer func() { _ = f.Close() }()
+		if err := render.WriteJUnit(f, junitSuiteName, results); err != nil {
+			return errors.Wrap(err, errCreateJUnit)
+		}
+	}
+
+	if failed > 0 {
+		return errors.Errorf(errFmtTestsFail, failed, len(cases))
+	}
+	return nil
+}
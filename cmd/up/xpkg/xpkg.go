@@ -33,6 +33,8 @@ type Cmd struct {
 	Dep       depCmd       `cmd:"" help:"Manage package dependencies in the filesystem and populate the cache, e.g. used by the Crossplane Language Server."`
 	Push      pushCmd      `cmd:"" help:"Push a package."`
 	Batch     batchCmd     `cmd:"" maturity:"alpha" help:"Batch build and push a family of service-scoped provider packages."`
+	Render    renderCmd    `cmd:"" maturity:"alpha" help:"Render the resources composed for a composite resource or claim locally."`
+	Test      testCmd      `cmd:"" maturity:"alpha" help:"Run golden-file tests for the Compositions of a package."`
 }
 
 func (c *Cmd) Help() string {
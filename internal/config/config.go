@@ -30,6 +30,7 @@ import (
 const (
 	ConfigDir  = ".up"
 	ConfigFile = "config.json"
+	AuditFile  = "audit.log"
 )
 
 const (
@@ -43,6 +44,9 @@ const (
 // QuietFlag provides a named boolean type for the QuietFlag.
 type QuietFlag bool
 
+// YesFlag provides a named boolean type for the YesFlag.
+type YesFlag bool
+
 // Allowed values for the global format option
 type Format string
 
@@ -50,6 +54,7 @@ const (
 	Default Format = "default"
 	JSON    Format = "json"
 	YAML    Format = "yaml"
+	CSV     Format = "csv"
 )
 
 // Config is format for the up configuration file.
@@ -75,6 +80,16 @@ func GetDefaultPath() (string, error) {
 	return filepath.Join(h, ConfigDir, ConfigFile), nil
 }
 
+// GetAuditLogPath returns the path of the log confirmations of destructive
+// commands are recorded in.
+func GetAuditLogPath() (string, error) {
+	h, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(h, ConfigDir, AuditFile), nil
+}
+
 // Upbound contains configuration information for Upbound.
 type Upbound struct {
 	// Default indicates the default profile.
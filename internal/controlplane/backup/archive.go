@@ -0,0 +1,203 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// FormatVersion is the version of the archive format.
+	FormatVersion = 1
+
+	metadataFile = "backup.json"
+	resourcesDir = "resources"
+
+	errWriteArchive       = "failed to write backup archive"
+	errReadArchive        = "failed to read backup archive"
+	errMissingMetadata    = "backup archive has no metadata"
+	errFmtFormatVersion   = "unsupported backup format version %d"
+	errFmtUnknownCategory = "unknown category of %s"
+)
+
+// Metadata describes a backup.
+type Metadata struct {
+	FormatVersion int              `json:"formatVersion"`
+	ControlPlane  string           `json:"controlPlane"`
+	Created       time.Time        `json:"created"`
+	Secrets       bool             `json:"secrets"`
+	Counts        map[Category]int `json:"counts"`
+}
+
+// Archive is a backup read from an archive.
+type Archive struct {
+	Metadata  Metadata
+	Resources []Resource
+}
+
+// Write writes a gzipped tar archive of the resources to w. Each resource is
+// written as a YAML file named after its category, resource, namespace and
+// name, so that the archive can be inspected with standard tools.
+func Write(w io.Writer, m Metadata, resources []Resource) error {
+	m.FormatVersion = FormatVersion
+	m.Counts = map[Category]int{}
+	for _, r := range resources {
+		m.Counts[r.Category]++
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, errWriteArchive)
+	}
+	if err := writeFile(tw, metadataFile, b, m.Created); err != nil {
+		return errors.Wrap(err, errWriteArchive)
+	}
+	for _, r := range resources {
+		b, err := yaml.Marshal(r.Object.Object)
+		if err != nil {
+			return errors.Wrap(err, errWriteArchive)
+		}
+		if err := writeFile(tw, resourcePath(r), b, m.Created); err != nil {
+			return errors.Wrap(err, errWriteArchive)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return errors.Wrap(err, errWriteArchive)
+	}
+	return errors.Wrap(gw.Close(), errWriteArchive)
+}
+
+func writeFile(tw *tar.Writer, name string, b []byte, modTime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o600,
+		Size:     int64(len(b)),
+		ModTime:  modTime,
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(b)
+	return err
+}
+
+// resourcePath returns the path of a resource in an archive, e.g.
+// resources/04-packages/providers.pkg.crossplane.io/provider-aws.yaml. The
+// index of the category preserves the restore order when the archive is
+// listed.
+func resourcePath(r Resource) string {
+	idx := 0
+	for i, c := range Categories {
+		if c == r.Category {
+			idx = i
+		}
+	}
+	parts := []string{resourcesDir, fmt.Sprintf("%02d-%s", idx, r.Category), r.Resource.String()}
+	if ns := r.Object.GetNamespace(); ns != "" {
+		parts = append(parts, ns)
+	}
+	return path.Join(append(parts, r.Object.GetName()+".yaml")...)
+}
+
+// Read reads a backup archive written by Write. Its resources are returned in
+// the order they are restored.
+func Read(r io.Reader) (*Archive, error) { //nolint:gocyclo
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, errReadArchive)
+	}
+	defer gr.Close() //nolint:errcheck
+
+	a := &Archive{}
+	found := false
+	byCategory := map[Category][]Resource{}
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, errReadArchive)
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, errors.Wrap(err, errReadArchive)
+		}
+		if h.Name == metadataFile {
+			if err := json.Unmarshal(b, &a.Metadata); err != nil {
+				return nil, errors.Wrap(err, errReadArchive)
+			}
+			found = true
+			continue
+		}
+		res, err := parseResource(h.Name, b)
+		if err != nil {
+			return nil, errors.Wrap(err, errReadArchive)
+		}
+		byCategory[res.Category] = append(byCategory[res.Category], res)
+	}
+	if !found {
+		return nil, errors.New(errMissingMetadata)
+	}
+	if a.Metadata.FormatVersion != FormatVersion {
+		return nil, errors.Errorf(errFmtFormatVersion, a.Metadata.FormatVersion)
+	}
+	for _, c := range Categories {
+		a.Resources = append(a.Resources, byCategory[c]...)
+	}
+	return a, nil
+}
+
+func parseResource(name string, b []byte) (Resource, error) {
+	parts := strings.Split(name, "/")
+	if len(parts) < 4 || parts[0] != resourcesDir {
+		return Resource{}, errors.Errorf(errFmtUnknownCategory, name)
+	}
+	_, category, _ := strings.Cut(parts[1], "-")
+	known := false
+	for _, c := range Categories {
+		known = known || c == Category(category)
+	}
+	if !known {
+		return Resource{}, errors.Errorf(errFmtUnknownCategory, name)
+	}
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(b, &obj.Object); err != nil {
+		return Resource{}, errors.Wrap(err, name)
+	}
+	return Resource{
+		Category: Category(category),
+		Resource: schema.ParseGroupResource(parts[2]),
+		Object:   obj,
+	}, nil
+}
@@ -0,0 +1,416 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package backup exports the Crossplane state of a control plane to an
+// archive, and restores it to another control plane.
+package backup
+
+import (
+	"context"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	corev1 "k8s.io/api/core/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	errFmtListResources = "failed to list %s"
+	errFmtConvert       = "failed to convert %s %q"
+	errFmtGetSecret     = "failed to get secret %s/%s"
+
+	lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+)
+
+// Category is a group of resources that are restored together.
+type Category string
+
+const (
+	// CategoryNamespaces are the namespaces of namespaced resources.
+	CategoryNamespaces Category = "namespaces"
+	// CategorySecrets are the secrets referenced by other resources.
+	CategorySecrets Category = "secrets"
+	// CategoryRuntimeConfigs are ControllerConfigs and
+	// DeploymentRuntimeConfigs.
+	CategoryRuntimeConfigs Category = "runtimeconfigs"
+	// CategoryPackages are Providers, Configurations and Functions.
+	CategoryPackages Category = "packages"
+	// CategoryProviderConfigs are the ProviderConfigs of all providers.
+	CategoryProviderConfigs Category = "providerconfigs"
+	// CategoryDefinitions are CompositeResourceDefinitions.
+	CategoryDefinitions Category = "definitions"
+	// CategoryCompositions are Compositions and EnvironmentConfigs.
+	CategoryCompositions Category = "compositions"
+	// CategoryManaged are managed resources.
+	CategoryManaged Category = "managed"
+	// CategoryComposites are composite resources.
+	CategoryComposites Category = "composites"
+	// CategoryClaims are claims.
+	CategoryClaims Category = "claims"
+)
+
+// Categories are all categories, in the order they are restored. Managed
+// resources are restored before the composite resources and claims that
+// compose them, so that they are not composed again.
+var Categories = []Category{
+	CategoryNamespaces,
+	CategorySecrets,
+	CategoryRuntimeConfigs,
+	CategoryPackages,
+	CategoryProviderConfigs,
+	CategoryDefinitions,
+	CategoryCompositions,
+	CategoryManaged,
+	CategoryComposites,
+	CategoryClaims,
+}
+
+var (
+	crdGVR       = extv1.SchemeGroupVersion.WithResource("customresourcedefinitions")
+	xrdGVR       = xpextv1.SchemeGroupVersion.WithResource("compositeresourcedefinitions")
+	secretGVR    = corev1.SchemeGroupVersion.WithResource("secrets")
+	namespaceGVK = corev1.SchemeGroupVersion.WithKind("Namespace")
+
+	// crossplaneTypes are the Crossplane types that are exported, in the
+	// order they are restored.
+	crossplaneTypes = []struct {
+		category Category
+		gvr      schema.GroupVersionResource
+	}{
+		{CategoryRuntimeConfigs, schema.GroupVersionResource{Group: "pkg.crossplane.io", Version: "v1alpha1", Resource: "controllerconfigs"}},
+		{CategoryRuntimeConfigs, schema.GroupVersionResource{Group: "pkg.crossplane.io", Version: "v1beta1", Resource: "deploymentruntimeconfigs"}},
+		{CategoryPackages, schema.GroupVersionResource{Group: "pkg.crossplane.io", Version: "v1", Resource: "providers"}},
+		{CategoryPackages, schema.GroupVersionResource{Group: "pkg.crossplane.io", Version: "v1", Resource: "configurations"}},
+		{CategoryPackages, schema.GroupVersionResource{Group: "pkg.crossplane.io", Version: "v1beta1", Resource: "functions"}},
+		{CategoryDefinitions, xrdGVR},
+		{CategoryCompositions, schema.GroupVersionResource{Group: "apiextensions.crossplane.io", Version: "v1", Resource: "compositions"}},
+		{CategoryCompositions, schema.GroupVersionResource{Group: "apiextensions.crossplane.io", Version: "v1alpha1", Resource: "environmentconfigs"}},
+	}
+
+	// skippedNamespaces are not exported, because they exist in every
+	// control plane.
+	skippedNamespaces = map[string]bool{
+		"default":         true,
+		"kube-system":     true,
+		"kube-public":     true,
+		"kube-node-lease": true,
+		"upbound-system":  true,
+	}
+)
+
+// Resource is an exported resource.
+type Resource struct {
+	Category Category
+	// Resource is the group and resource of the object, e.g. providers in
+	// group pkg.crossplane.io.
+	Resource schema.GroupResource
+	Object   *unstructured.Unstructured
+}
+
+// GroupVersionResource returns the resource of the object with the version of
+// its API version.
+func (r Resource) GroupVersionResource() schema.GroupVersionResource {
+	gv, _ := schema.ParseGroupVersion(r.Object.GetAPIVersion())
+	return r.Resource.WithVersion(gv.Version)
+}
+
+// HasExternalName returns true if the object is annotated with its external
+// name. Managed resources without one may be created again when they are
+// restored.
+func (r Resource) HasExternalName() bool {
+	return meta.GetExternalName(r.Object) != ""
+}
+
+// Exporter exports the Crossplane state of a control plane.
+type Exporter struct {
+	client  dynamic.Interface
+	secrets bool
+}
+
+// ExportOption modifies an Exporter.
+type ExportOption func(*Exporter)
+
+// WithSecrets exports the secrets referenced by the exported resources, e.g.
+// the credentials of ProviderConfigs.
+func WithSecrets(include bool) ExportOption {
+	return func(e *Exporter) {
+		e.secrets = include
+	}
+}
+
+// NewExporter constructs an Exporter for the control plane of the client.
+func NewExporter(c dynamic.Interface, opts ...ExportOption) *Exporter {
+	e := &Exporter{client: c}
+	for _, o := range opts {
+		o(e)
+	}
+	return e
+}
+
+// Export returns the resources of the control plane, in the order they are
+// restored.
+func (e *Exporter) Export(ctx context.Context) ([]Resource, error) { //nolint:gocyclo
+	byCategory := map[Category][]Resource{}
+	add := func(c Category, gvr schema.GroupVersionResource, objs []unstructured.Unstructured) {
+		for i := range objs {
+			if installedByPackage(&objs[i]) {
+				continue
+			}
+			sanitize(&objs[i])
+			byCategory[c] = append(byCategory[c], Resource{Category: c, Resource: gvr.GroupResource(), Object: &objs[i]})
+		}
+	}
+
+	var xrds []unstructured.Unstructured
+	for _, t := range crossplaneTypes {
+		objs, err := e.list(ctx, t.gvr)
+		if err != nil {
+			return nil, err
+		}
+		if t.gvr == xrdGVR {
+			xrds = objs
+		}
+		add(t.category, t.gvr, objs)
+	}
+
+	crds, err := e.list(ctx, crdGVR)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range crds {
+		crd := &extv1.CustomResourceDefinition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, crd); err != nil {
+			return nil, errors.Wrapf(err, errFmtConvert, "CustomResourceDefinition", u.GetName())
+		}
+		c, ok := crdCategory(crd)
+		if !ok {
+			continue
+		}
+		gvr := schema.GroupVersionResource{Group: crd.Spec.Group, Version: storageVersion(crd), Resource: crd.Spec.Names.Plural}
+		objs, err := e.list(ctx, gvr)
+		if err != nil {
+			return nil, err
+		}
+		add(c, gvr, objs)
+	}
+
+	for _, u := range xrds {
+		xrd := &xpextv1.CompositeResourceDefinition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, xrd); err != nil {
+			return nil, errors.Wrapf(err, errFmtConvert, "CompositeResourceDefinition", u.GetName())
+		}
+		version := referenceableVersion(xrd)
+		gvr := schema.GroupVersionResource{Group: xrd.Spec.Group, Version: version, Resource: xrd.Spec.Names.Plural}
+		objs, err := e.list(ctx, gvr)
+		if err != nil {
+			return nil, err
+		}
+		add(CategoryComposites, gvr, objs)
+		if xrd.Spec.ClaimNames == nil {
+			continue
+		}
+		gvr.Resource = xrd.Spec.ClaimNames.Plural
+		objs, err = e.list(ctx, gvr)
+		if err != nil {
+			return nil, err
+		}
+		add(CategoryClaims, gvr, objs)
+	}
+
+	if e.secrets {
+		secrets, err := e.referencedSecrets(ctx, byCategory)
+		if err != nil {
+			return nil, err
+		}
+		add(CategorySecrets, secretGVR, secrets)
+	}
+	byCategory[CategoryNamespaces] = namespaces(byCategory)
+
+	out := []Resource{}
+	for _, c := range Categories {
+		out = append(out, byCategory[c]...)
+	}
+	return out, nil
+}
+
+// list lists all objects of a resource. Resources that are not served by the
+// control plane have no objects.
+func (e *Exporter) list(ctx context.Context, gvr schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+	l, err := e.client.Resource(gvr).List(ctx, metav1.ListOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtListResources, gvr.GroupResource())
+	}
+	return l.Items, nil
+}
+
+// referencedSecrets returns the secrets referenced by the secretRefs of the
+// exported resources. Secrets that are written by Crossplane, e.g. connection
+// secrets, are not included.
+func (e *Exporter) referencedSecrets(ctx context.Context, byCategory map[Category][]Resource) ([]unstructured.Unstructured, error) {
+	seen := map[string]bool{}
+	secrets := []unstructured.Unstructured{}
+	for _, c := range Categories {
+		for _, r := range byCategory[c] {
+			spec, ok := r.Object.Object["spec"].(map[string]any)
+			if !ok {
+				continue
+			}
+			for _, ref := range secretRefs(spec, r.Object.GetNamespace()) {
+				key := ref.Namespace + "/" + ref.Name
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				s, err := e.client.Resource(secretGVR).Namespace(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+				if kerrors.IsNotFound(err) {
+					continue
+				}
+				if err != nil {
+					return nil, errors.Wrapf(err, errFmtGetSecret, ref.Namespace, ref.Name)
+				}
+				secrets = append(secrets, *s)
+			}
+		}
+	}
+	return secrets, nil
+}
+
+// secretRefs walks an object and returns every secretRef, or field ending in
+// SecretRef, that names a secret. References without a namespace default to
+// the supplied one.
+func secretRefs(obj map[string]any, namespace string) []types.NamespacedName {
+	refs := []types.NamespacedName{}
+	for k, v := range obj {
+		switch val := v.(type) {
+		case map[string]any:
+			if isSecretRef(k) {
+				name, _ := val["name"].(string)
+				ns, _ := val["namespace"].(string)
+				if ns == "" {
+					ns = namespace
+				}
+				if name != "" && ns != "" {
+					refs = append(refs, types.NamespacedName{Namespace: ns, Name: name})
+				}
+				continue
+			}
+			refs = append(refs, secretRefs(val, namespace)...)
+		case []any:
+			for _, i := range val {
+				if m, ok := i.(map[string]any); ok {
+					refs = append(refs, secretRefs(m, namespace)...)
+				}
+			}
+		}
+	}
+	return refs
+}
+
+func isSecretRef(field string) bool {
+	switch field {
+	case "writeConnectionSecretToRef", "publishConnectionDetailsTo":
+		// Written by Crossplane, not read.
+		return false
+	}
+	return field == "secretRef" || strings.HasSuffix(field, "SecretRef")
+}
+
+// namespaces returns the namespaces of the exported namespaced resources.
+func namespaces(byCategory map[Category][]Resource) []Resource {
+	seen := map[string]bool{}
+	out := []Resource{}
+	for _, c := range Categories {
+		for _, r := range byCategory[c] {
+			ns := r.Object.GetNamespace()
+			if ns == "" || seen[ns] || skippedNamespaces[ns] {
+				continue
+			}
+			seen[ns] = true
+			u := &unstructured.Unstructured{}
+			u.SetGroupVersionKind(namespaceGVK)
+			u.SetName(ns)
+			out = append(out, Resource{Category: CategoryNamespaces, Resource: corev1.SchemeGroupVersion.WithResource("namespaces").GroupResource(), Object: u})
+		}
+	}
+	return out
+}
+
+// crdCategory returns the category of the custom resources of a CRD, if they
+// are exported.
+func crdCategory(crd *extv1.CustomResourceDefinition) (Category, bool) {
+	if crd.Spec.Names.Kind == "ProviderConfig" {
+		return CategoryProviderConfigs, true
+	}
+	for _, c := range crd.Spec.Names.Categories {
+		if c == "managed" {
+			return CategoryManaged, true
+		}
+	}
+	return "", false
+}
+
+func storageVersion(crd *extv1.CustomResourceDefinition) string {
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			return v.Name
+		}
+	}
+	return crd.Spec.Versions[0].Name
+}
+
+func referenceableVersion(xrd *xpextv1.CompositeResourceDefinition) string {
+	for _, v := range xrd.Spec.Versions {
+		if v.Referenceable {
+			return v.Name
+		}
+	}
+	return xrd.Spec.Versions[0].Name
+}
+
+// installedByPackage returns true if the object is controlled by a package
+// revision. Such objects are installed again when their package is restored.
+func installedByPackage(u *unstructured.Unstructured) bool {
+	ref := metav1.GetControllerOf(u)
+	return ref != nil && strings.HasSuffix(ref.Kind, "Revision") && strings.HasPrefix(ref.APIVersion, "pkg.crossplane.io/")
+}
+
+// sanitize removes the fields of an object that are set by the API server or
+// by controllers, so that it can be created in another control plane. Owner
+// references are removed because the UIDs of the owners change; Crossplane
+// sets them again when it reconciles the owners.
+func sanitize(u *unstructured.Unstructured) {
+	for _, f := range []string{"uid", "resourceVersion", "creationTimestamp", "generation", "managedFields", "ownerReferences", "finalizers", "deletionTimestamp", "deletionGracePeriodSeconds", "selfLink"} {
+		unstructured.RemoveNestedField(u.Object, "metadata", f)
+	}
+	unstructured.RemoveNestedField(u.Object, "status")
+	if a := u.GetAnnotations(); a != nil {
+		delete(a, lastAppliedAnnotation)
+		if len(a) == 0 {
+			a = nil
+		}
+		u.SetAnnotations(a)
+	}
+}
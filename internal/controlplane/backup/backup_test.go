@@ -0,0 +1,282 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/fake"
+	cgotesting "k8s.io/client-go/testing"
+)
+
+func obj(apiVersion, kind, namespace, name string, fields map[string]any) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: fields}
+	if u.Object == nil {
+		u.Object = map[string]any{}
+	}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	u.SetUID(types.UID("uid-" + name))
+	u.SetResourceVersion("42")
+	return u
+}
+
+func crd(group, kind, plural string, categories ...any) *unstructured.Unstructured {
+	return obj("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", plural+"."+group, map[string]any{
+		"spec": map[string]any{
+			"group": group,
+			"names": map[string]any{"kind": kind, "plural": plural, "categories": categories},
+			"scope": "Cluster",
+			"versions": []any{
+				map[string]any{"name": "v1alpha1", "served": true, "storage": false},
+				map[string]any{"name": "v1beta1", "served": true, "storage": true},
+			},
+		},
+	})
+}
+
+func controlPlaneObjects() []runtime.Object {
+	mr := obj("s3.aws.upbound.io/v1beta1", "Bucket", "", "net-abc-bucket", map[string]any{
+		"spec":   map[string]any{"forProvider": map[string]any{"region": "us-east-1"}},
+		"status": map[string]any{"atProvider": map[string]any{"arn": "arn"}},
+	})
+	mr.SetAnnotations(map[string]string{"crossplane.io/external-name": "net-abc-bucket", lastAppliedAnnotation: "{}"})
+	mr.SetFinalizers([]string{"finalizer.managedresource.crossplane.io"})
+	mr.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "example.org/v1alpha1", Kind: "XNetwork", Name: "net-abc", UID: "uid-net-abc", Controller: ptr(true)}})
+
+	pkgComposition := obj("apiextensions.crossplane.io/v1", "Composition", "", "from-package", nil)
+	pkgComposition.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "pkg.crossplane.io/v1", Kind: "ConfigurationRevision", Name: "cfg-123", UID: "uid-cfg-123", Controller: ptr(true)}})
+
+	return []runtime.Object{
+		crd("s3.aws.upbound.io", "Bucket", "buckets", "crossplane", "managed", "aws"),
+		crd("aws.upbound.io", "ProviderConfig", "providerconfigs", "crossplane", "provider", "aws"),
+		crd("aws.upbound.io", "ProviderConfigUsage", "providerconfigusages", "crossplane", "provider", "aws"),
+		obj("pkg.crossplane.io/v1", "Provider", "", "provider-aws", map[string]any{
+			"spec": map[string]any{"package": "xpkg.upbound.io/upbound/provider-aws-s3:v0.40.0"},
+		}),
+		obj("apiextensions.crossplane.io/v1", "CompositeResourceDefinition", "", "xnetworks.example.org", map[string]any{
+			"spec": map[string]any{
+				"group":      "example.org",
+				"names":      map[string]any{"kind": "XNetwork", "plural": "xnetworks"},
+				"claimNames": map[string]any{"kind": "Network", "plural": "networks"},
+				"versions":   []any{map[string]any{"name": "v1alpha1", "served": true, "referenceable": true}},
+			},
+		}),
+		obj("apiextensions.crossplane.io/v1", "Composition", "", "xnetworks", nil),
+		pkgComposition,
+		mr,
+		obj("aws.upbound.io/v1beta1", "ProviderConfig", "", "default", map[string]any{
+			"spec": map[string]any{"credentials": map[string]any{
+				"source":    "Secret",
+				"secretRef": map[string]any{"namespace": "upbound-system", "name": "aws-creds", "key": "creds"},
+			}},
+		}),
+		obj("v1", "Secret", "upbound-system", "aws-creds", map[string]any{"data": map[string]any{"creds": "c2VjcmV0"}}),
+		obj("example.org/v1alpha1", "XNetwork", "", "net-abc", map[string]any{
+			"spec": map[string]any{
+				"claimRef":                   map[string]any{"namespace": "team-a", "name": "net"},
+				"writeConnectionSecretToRef": map[string]any{"namespace": "upbound-system", "name": "net-conn"},
+			},
+		}),
+		obj("example.org/v1alpha1", "Network", "team-a", "net", map[string]any{
+			"spec": map[string]any{"resourceRef": map[string]any{"apiVersion": "example.org/v1alpha1", "kind": "XNetwork", "name": "net-abc"}},
+		}),
+	}
+}
+
+func newFakeClient(objs ...runtime.Object) *fake.FakeDynamicClient {
+	return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		crdGVR: "CustomResourceDefinitionList",
+		{Group: "pkg.crossplane.io", Version: "v1alpha1", Resource: "controllerconfigs"}:       "ControllerConfigList",
+		{Group: "pkg.crossplane.io", Version: "v1beta1", Resource: "deploymentruntimeconfigs"}: "DeploymentRuntimeConfigList",
+		{Group: "pkg.crossplane.io", Version: "v1", Resource: "providers"}:                     "ProviderList",
+		{Group: "pkg.crossplane.io", Version: "v1", Resource: "configurations"}:                "ConfigurationList",
+		{Group: "pkg.crossplane.io", Version: "v1beta1", Resource: "functions"}:                "FunctionList",
+		xrdGVR: "CompositeResourceDefinitionList",
+		{Group: "apiextensions.crossplane.io", Version: "v1", Resource: "compositions"}:             "CompositionList",
+		{Group: "apiextensions.crossplane.io", Version: "v1alpha1", Resource: "environmentconfigs"}: "EnvironmentConfigList",
+		{Group: "s3.aws.upbound.io", Version: "v1beta1", Resource: "buckets"}:                       "BucketList",
+		{Group: "aws.upbound.io", Version: "v1beta1", Resource: "providerconfigs"}:                  "ProviderConfigList",
+		{Group: "example.org", Version: "v1alpha1", Resource: "xnetworks"}:                          "XNetworkList",
+		{Group: "example.org", Version: "v1alpha1", Resource: "networks"}:                           "NetworkList",
+	}, objs...)
+}
+
+func ptr[T any](v T) *T { return &v }
+
+// summary returns the category, resource and namespaced name of each
+// resource.
+func summary(rs []Resource) []string {
+	out := make([]string, 0, len(rs))
+	for _, r := range rs {
+		out = append(out, string(r.Category)+" "+r.Resource.String()+" "+r.Object.GetNamespace()+"/"+r.Object.GetName())
+	}
+	return out
+}
+
+func TestExport(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		secrets bool
+		want    []string
+	}{
+		"WithoutSecrets": {
+			reason: "Resources should be exported in restore order, without resources installed by packages.",
+			want: []string{
+				"namespaces namespaces /team-a",
+				"packages providers.pkg.crossplane.io /provider-aws",
+				"providerconfigs providerconfigs.aws.upbound.io /default",
+				"definitions compositeresourcedefinitions.apiextensions.crossplane.io /xnetworks.example.org",
+				"compositions compositions.apiextensions.crossplane.io /xnetworks",
+				"managed buckets.s3.aws.upbound.io /net-abc-bucket",
+				"composites xnetworks.example.org /net-abc",
+				"claims networks.example.org team-a/net",
+			},
+		},
+		"WithSecrets": {
+			reason:  "Secrets referenced by exported resources should be exported, but not connection secrets.",
+			secrets: true,
+			want: []string{
+				"namespaces namespaces /team-a",
+				"secrets secrets upbound-system/aws-creds",
+				"packages providers.pkg.crossplane.io /provider-aws",
+				"providerconfigs providerconfigs.aws.upbound.io /default",
+				"definitions compositeresourcedefinitions.apiextensions.crossplane.io /xnetworks.example.org",
+				"compositions compositions.apiextensions.crossplane.io /xnetworks",
+				"managed buckets.s3.aws.upbound.io /net-abc-bucket",
+				"composites xnetworks.example.org /net-abc",
+				"claims networks.example.org team-a/net",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewExporter(newFakeClient(controlPlaneObjects()...), WithSecrets(tc.secrets)).Export(context.Background())
+			if err != nil {
+				t.Fatalf("\n%s\nExport(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, summary(got)); diff != "" {
+				t.Errorf("\n%s\nExport(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestExportSanitizesManagedResources(t *testing.T) {
+	got, err := NewExporter(newFakeClient(controlPlaneObjects()...)).Export(context.Background())
+	if err != nil {
+		t.Fatalf("Export(...): unexpected error: %v", err)
+	}
+	var mr *unstructured.Unstructured
+	for _, r := range got {
+		if r.Category == CategoryManaged {
+			mr = r.Object
+			if !r.HasExternalName() {
+				t.Errorf("HasExternalName(): want true, got false")
+			}
+		}
+	}
+	want := map[string]any{
+		"apiVersion": "s3.aws.upbound.io/v1beta1",
+		"kind":       "Bucket",
+		"metadata": map[string]any{
+			"name":        "net-abc-bucket",
+			"annotations": map[string]any{"crossplane.io/external-name": "net-abc-bucket"},
+		},
+		"spec": map[string]any{"forProvider": map[string]any{"region": "us-east-1"}},
+	}
+	if diff := cmp.Diff(want, mr.Object); diff != "" {
+		t.Errorf("Export(...): managed resource: -want, +got:\n%s", diff)
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	resources, err := NewExporter(newFakeClient(controlPlaneObjects()...), WithSecrets(true)).Export(context.Background())
+	if err != nil {
+		t.Fatalf("Export(...): unexpected error: %v", err)
+	}
+	created := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	buf := &bytes.Buffer{}
+	if err := Write(buf, Metadata{ControlPlane: "ctp1", Created: created, Secrets: true}, resources); err != nil {
+		t.Fatalf("Write(...): unexpected error: %v", err)
+	}
+	a, err := Read(buf)
+	if err != nil {
+		t.Fatalf("Read(...): unexpected error: %v", err)
+	}
+
+	wantMeta := Metadata{
+		FormatVersion: FormatVersion,
+		ControlPlane:  "ctp1",
+		Created:       created,
+		Secrets:       true,
+		Counts: map[Category]int{
+			CategoryNamespaces: 1, CategorySecrets: 1, CategoryPackages: 1, CategoryProviderConfigs: 1,
+			CategoryDefinitions: 1, CategoryCompositions: 1, CategoryManaged: 1, CategoryComposites: 1, CategoryClaims: 1,
+		},
+	}
+	if diff := cmp.Diff(wantMeta, a.Metadata); diff != "" {
+		t.Errorf("Read(...): metadata: -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(resources, a.Resources); diff != "" {
+		t.Errorf("Read(...): resources: -want, +got:\n%s", diff)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	resources, err := NewExporter(newFakeClient(controlPlaneObjects()...)).Export(context.Background())
+	if err != nil {
+		t.Fatalf("Export(...): unexpected error: %v", err)
+	}
+
+	// The target control plane already has the provider, and serves buckets
+	// only after the first attempt to create one.
+	target := newFakeClient(obj("pkg.crossplane.io/v1", "Provider", "", "provider-aws", nil))
+	attempts := 0
+	target.PrependReactor("create", "buckets", func(cgotesting.Action) (bool, runtime.Object, error) {
+		attempts++
+		if attempts == 1 {
+			return true, nil, kerrors.NewNotFound(schema.GroupResource{Group: "s3.aws.upbound.io", Resource: "buckets"}, "")
+		}
+		return false, nil, nil
+	})
+
+	got, err := NewRestorer(target, WithInterval(time.Millisecond)).Restore(context.Background(), resources)
+	if err != nil {
+		t.Fatalf("Restore(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(Result{Created: len(resources) - 1, Existing: 1}, got); diff != "" {
+		t.Errorf("Restore(...): -want, +got:\n%s", diff)
+	}
+	if attempts != 2 {
+		t.Errorf("Restore(...): want 2 attempts to create the bucket, got %d", attempts)
+	}
+	if _, err := target.Resource(schema.GroupVersionResource{Group: "example.org", Version: "v1alpha1", Resource: "networks"}).Namespace("team-a").Get(context.Background(), "net", metav1.GetOptions{}); err != nil {
+		t.Errorf("Restore(...): claim was not restored: %v", err)
+	}
+}
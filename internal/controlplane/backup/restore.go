@@ -0,0 +1,124 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	errFmtRestore  = "failed to restore %s %q"
+	errTypeMissing = "timed out waiting for the type of the resource to be served"
+
+	defaultInterval = 2 * time.Second
+)
+
+// Result counts the restored resources.
+type Result struct {
+	// Created resources did not exist in the control plane.
+	Created int
+	// Existing resources already existed and were left unchanged.
+	Existing int
+}
+
+// Restorer restores exported resources to a control plane.
+type Restorer struct {
+	client   dynamic.Interface
+	timeout  time.Duration
+	interval time.Duration
+}
+
+// RestoreOption modifies a Restorer.
+type RestoreOption func(*Restorer)
+
+// WithTimeout sets how long a resource is retried while its type is not
+// served yet, e.g. while the provider that defines it is being installed.
+func WithTimeout(d time.Duration) RestoreOption {
+	return func(r *Restorer) {
+		r.timeout = d
+	}
+}
+
+// WithInterval sets the interval between retries.
+func WithInterval(d time.Duration) RestoreOption {
+	return func(r *Restorer) {
+		r.interval = d
+	}
+}
+
+// NewRestorer constructs a Restorer for the control plane of the client.
+func NewRestorer(c dynamic.Interface, opts ...RestoreOption) *Restorer {
+	r := &Restorer{
+		client:   c,
+		timeout:  10 * time.Minute,
+		interval: defaultInterval,
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Restore creates the supplied resources in order. Resources that already
+// exist are left unchanged, so that a restore can be repeated. Creating a
+// resource whose type is not served yet is retried until the timeout.
+func (r *Restorer) Restore(ctx context.Context, resources []Resource) (Result, error) {
+	res := Result{}
+	for _, rs := range resources {
+		created, err := r.create(ctx, rs)
+		if err != nil {
+			return res, errors.Wrapf(err, errFmtRestore, rs.Object.GetKind(), rs.Object.GetName())
+		}
+		if created {
+			res.Created++
+			continue
+		}
+		res.Existing++
+	}
+	return res, nil
+}
+
+func (r *Restorer) create(ctx context.Context, rs Resource) (bool, error) {
+	created := false
+	err := wait.PollUntilContextTimeout(ctx, r.interval, r.timeout, true, func(ctx context.Context) (bool, error) {
+		_, err := r.client.
+			Resource(rs.GroupVersionResource()).
+			Namespace(rs.Object.GetNamespace()).
+			Create(ctx, rs.Object, metav1.CreateOptions{})
+		switch {
+		case err == nil:
+			created = true
+			return true, nil
+		case kerrors.IsAlreadyExists(err):
+			return true, nil
+		case kerrors.IsNotFound(err):
+			// The type of the resource is not served yet.
+			return false, nil
+		default:
+			return false, err
+		}
+	})
+	if wait.Interrupted(err) {
+		return false, errors.New(errTypeMissing)
+	}
+	return created, err
+}
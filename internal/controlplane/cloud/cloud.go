@@ -18,7 +18,10 @@ import (
 	"context"
 	"net/url"
 	"path"
+	"time"
 
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/clientcmd/api"
 
 	sdkerrs "github.com/upbound/up-sdk-go/errors"
@@ -33,7 +36,11 @@ import (
 const (
 	maxItems = 100
 
+	defaultPollInterval = 5 * time.Second
+
 	notAvailable = "n/a"
+
+	errLabelsNotSupported = "label selectors are not supported for Upbound Cloud control planes"
 )
 
 type ctpClient interface {
@@ -61,6 +68,14 @@ func WithProxyEndpoint(p *url.URL) Option {
 	}
 }
 
+// WithPollInterval sets how often control planes are polled while they are
+// watched.
+func WithPollInterval(d time.Duration) Option {
+	return func(c *Client) {
+		c.interval = d
+	}
+}
+
 // Client is the client used for interacting with the ControlPlanes API in
 // Upbound Cloud.
 type Client struct {
@@ -73,14 +88,17 @@ type Client struct {
 	token string
 	// Proxy Endppint corresponding to Upbound Cloud's Proxy.
 	proxy *url.URL
+	// Interval control planes are polled at while they are watched.
+	interval time.Duration
 }
 
 // New instantiates a new Client.
 func New(ctp ctpClient, cfg cfgGetter, account string, opts ...Option) *Client {
 	c := &Client{
-		ctp:     ctp,
-		cfg:     cfg,
-		account: account,
+		ctp:      ctp,
+		cfg:      cfg,
+		account:  account,
+		interval: defaultPollInterval,
 	}
 
 	for _, o := range opts {
@@ -104,8 +122,29 @@ func (c *Client) Get(ctx context.Context, name string) (*controlplane.Response,
 	return convert(resp), nil
 }
 
-// List all ControlPlanes within the Upbound Cloud account.
-func (c *Client) List(ctx context.Context) ([]*controlplane.Response, error) {
+// Describe the ControlPlane corresponding to the given ControlPlane name.
+// Cloud control planes have no events.
+func (c *Client) Describe(ctx context.Context, name string) (*controlplane.Description, error) {
+	resp, err := c.ctp.Get(ctx, c.account, name)
+	if sdkerrs.IsNotFound(err) {
+		return nil, controlplane.NewNotFound(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &controlplane.Description{
+		Response: *convert(resp),
+		Created:  resp.ControlPlane.CreatedAt,
+	}, nil
+}
+
+// List all ControlPlanes within the Upbound Cloud account that are selected
+// by the filter. Cloud control planes have no labels.
+func (c *Client) List(ctx context.Context, f controlplane.Filter) ([]*controlplane.Response, error) {
+	if f.Labels != nil && !f.Labels.Empty() {
+		return nil, errors.New(errLabelsNotSupported)
+	}
 	l, err := c.ctp.List(ctx, c.account, common.WithSize(maxItems))
 	if err != nil {
 		return nil, err
@@ -115,7 +154,7 @@ func (c *Client) List(ctx context.Context) ([]*controlplane.Response, error) {
 		cp := r
 		resps = append(resps, convert(&cp))
 	}
-	return resps, nil
+	return f.Apply(resps), nil
 }
 
 // Create a new ControlPlane with the given name and the supplied Options.
@@ -147,6 +186,32 @@ func (c *Client) Delete(ctx context.Context, name string) error {
 	return err
 }
 
+// Watch polls the ControlPlane corresponding to the given ControlPlane name
+// and calls fn with it, or with nil once it was deleted, until fn returns true
+// or an error, or ctx is done.
+func (c *Client) Watch(ctx context.Context, name string, fn controlplane.WatchFn) error {
+	t := time.NewTicker(c.interval)
+	defer t.Stop()
+	for {
+		r, err := c.Get(ctx, name)
+		if controlplane.IsNotFound(err) {
+			_, err := fn(nil)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if done, err := fn(r); done || err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
 // GetKubeConfig for the given Control Plane.
 func (c *Client) GetKubeConfig(ctx context.Context, name string) (*api.Config, error) {
 	return kube.BuildControlPlaneKubeconfig(
@@ -170,12 +235,24 @@ func convert(ctp *controlplanes.ControlPlaneResponse) *controlplane.Response {
 		cfgName, cfgStatus = notAvailable, notAvailable
 	}
 
+	// NOTE: cloud control planes have no conditions, so their status is
+	// mapped to the Ready condition of Space control planes.
+	ready := string(corev1.ConditionFalse)
+	if ctp.Status == controlplanes.StatusReady {
+		ready = string(corev1.ConditionTrue)
+	}
+
 	return &controlplane.Response{
 		ID:        ctp.ControlPlane.ID.String(),
 		Name:      ctp.ControlPlane.Name,
 		Status:    string(ctp.Status),
 		Cfg:       cfgName,
 		CfgStatus: cfgStatus,
+		Conditions: []controlplane.Condition{{
+			Type:   "Ready",
+			Status: ready,
+			Reason: string(ctp.Status),
+		}},
 	}
 }
 
@@ -23,6 +23,8 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/utils/pointer"
 
 	sdkerrs "github.com/upbound/up-sdk-go/errors"
@@ -60,17 +62,19 @@ var (
 	}
 
 	ctp1Resp = &controlplane.Response{
-		Name:      "ctp1",
-		ID:        "00000000-0000-0000-0000-000000000000",
-		Cfg:       "cfg1",
-		CfgStatus: string(controlplanes.ConfigurationReady),
+		Name:       "ctp1",
+		ID:         "00000000-0000-0000-0000-000000000000",
+		Cfg:        "cfg1",
+		CfgStatus:  string(controlplanes.ConfigurationReady),
+		Conditions: []controlplane.Condition{{Type: "Ready", Status: "False"}},
 	}
 
 	ctp2Resp = &controlplane.Response{
-		Name:      "ctp2",
-		ID:        "00000000-0000-0000-0000-000000000001",
-		Cfg:       "cfg1",
-		CfgStatus: string(controlplanes.ConfigurationReady),
+		Name:       "ctp2",
+		ID:         "00000000-0000-0000-0000-000000000001",
+		Cfg:        "cfg1",
+		CfgStatus:  string(controlplanes.ConfigurationReady),
+		Conditions: []controlplane.Condition{{Type: "Ready", Status: "False"}},
 	}
 )
 
@@ -217,8 +221,9 @@ func TestDelete(t *testing.T) {
 
 func TestList(t *testing.T) {
 	type args struct {
-		ctp ctpClient
-		cfg cfgGetter
+		ctp    ctpClient
+		cfg    cfgGetter
+		filter controlplane.Filter
 	}
 	type want struct {
 		resp []*controlplane.Response
@@ -291,12 +296,41 @@ func TestList(t *testing.T) {
 				},
 			},
 		},
+		"FieldSelector": {
+			reason: "Only the control planes selected by the field selector are returned.",
+			args: args{
+				ctp: &mockCTPClient{
+					ListFn: func(ctx context.Context, account string, opts ...common.ListOption) (*controlplanes.ControlPlaneListResponse, error) {
+						return &controlplanes.ControlPlaneListResponse{
+							ControlPlanes: []controlplanes.ControlPlaneResponse{
+								{
+									ControlPlane: ctp1,
+								},
+							},
+						}, nil
+					},
+				},
+				filter: controlplane.Filter{Fields: fields.OneTermNotEqualSelector(controlplane.FieldConfiguration, "cfg1")},
+			},
+			want: want{
+				resp: []*controlplane.Response{},
+			},
+		},
+		"ErrorLabelSelector": {
+			reason: "Label selectors are not supported for cloud control planes.",
+			args: args{
+				filter: controlplane.Filter{Labels: labels.SelectorFromSet(labels.Set{"env": "prod"})},
+			},
+			want: want{
+				err: errors.New(errLabelsNotSupported),
+			},
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 
 			c := New(tc.args.ctp, tc.args.cfg, acct)
-			got, err := c.List(context.Background())
+			got, err := c.List(context.Background(), tc.args.filter)
 
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nList(...): -want error, +got error:\n%s", tc.reason, diff)
@@ -345,10 +379,11 @@ func TestConvert(t *testing.T) {
 			},
 			want: want{
 				resp: &controlplane.Response{
-					Name:      "ctp1",
-					ID:        "00000000-0000-0000-0000-000000000000",
-					Cfg:       notAvailable,
-					CfgStatus: notAvailable,
+					Name:       "ctp1",
+					ID:         "00000000-0000-0000-0000-000000000000",
+					Cfg:        notAvailable,
+					CfgStatus:  notAvailable,
+					Conditions: []controlplane.Condition{{Type: "Ready", Status: "False"}},
 				},
 			},
 		},
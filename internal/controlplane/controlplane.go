@@ -14,6 +14,8 @@
 
 package controlplane
 
+import "strings"
+
 // Response is a normalized ControlPlane response.
 // NOTE(tnthornton) this is expected to be different in the near future as
 // cloud and spaces APIs converge.
@@ -28,4 +30,26 @@ type Response struct {
 
 	ConnName      string
 	ConnNamespace string
+
+	Labels     map[string]string
+	Conditions []Condition
+}
+
+// Condition is a normalized condition of a ControlPlane.
+type Condition struct {
+	Type    string
+	Status  string
+	Reason  string
+	Message string
+}
+
+// GetCondition returns the condition of the supplied type, and false if the
+// ControlPlane has no such condition.
+func (r *Response) GetCondition(t string) (Condition, bool) {
+	for _, c := range r.Conditions {
+		if strings.EqualFold(c.Type, t) {
+			return c, true
+		}
+	}
+	return Condition{}, false
 }
@@ -0,0 +1,96 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controlplane
+
+import (
+	"context"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/upbound/up/internal/resources"
+)
+
+const (
+	errFmtListPackages = "failed to list %s"
+)
+
+// packageResources are the Crossplane packages that are described. Functions
+// are only served by Crossplane 1.14 and later.
+var packageResources = []schema.GroupVersionResource{
+	{Group: "pkg.crossplane.io", Version: "v1", Resource: "providers"},
+	{Group: "pkg.crossplane.io", Version: "v1", Resource: "configurations"},
+	{Group: "pkg.crossplane.io", Version: "v1beta1", Resource: "functions"},
+}
+
+// Description is a detailed view of a ControlPlane.
+type Description struct {
+	Response
+
+	Created           *time.Time
+	CrossplaneVersion string
+	Endpoint          string
+
+	Packages []Package
+	Events   []Event
+}
+
+// Package is a Crossplane package installed in a ControlPlane.
+type Package struct {
+	Kind      string
+	Name      string
+	Package   string
+	Installed bool
+	Healthy   bool
+}
+
+// Event is a Kubernetes event of a ControlPlane.
+type Event struct {
+	Type     string
+	Reason   string
+	Message  string
+	Count    int32
+	LastSeen time.Time
+}
+
+// GetPackages returns the Crossplane packages installed in the control plane
+// served by the supplied client.
+func GetPackages(ctx context.Context, c dynamic.Interface) ([]Package, error) {
+	pkgs := []Package{}
+	for _, gvr := range packageResources {
+		l, err := c.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if kerrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtListPackages, gvr.Resource)
+		}
+		for _, u := range l.Items {
+			p := &resources.Package{Unstructured: u}
+			pkgs = append(pkgs, Package{
+				Kind:      p.GetKind(),
+				Name:      p.GetName(),
+				Package:   p.GetPackage(),
+				Installed: p.GetInstalled(),
+				Healthy:   p.GetHealthy(),
+			})
+		}
+	}
+	return pkgs, nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controlplane
+
+import (
+	"context"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+	cgotesting "k8s.io/client-go/testing"
+)
+
+func pkg(kind, name, ref string, conditions ...xpv1.Condition) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "pkg.crossplane.io/v1",
+		"kind":       kind,
+		"metadata":   map[string]any{"name": name},
+		"spec":       map[string]any{"package": ref},
+	}}
+	if kind == "Function" {
+		u.SetAPIVersion("pkg.crossplane.io/v1beta1")
+	}
+	cs := []any{}
+	for _, c := range conditions {
+		cs = append(cs, map[string]any{"type": string(c.Type), "status": string(c.Status)})
+	}
+	u.Object["status"] = map[string]any{"conditions": cs}
+	return u
+}
+
+func TestGetPackages(t *testing.T) {
+	installed := xpv1.Condition{Type: "Installed", Status: "True"}
+	healthy := xpv1.Condition{Type: "Healthy", Status: "True"}
+	unhealthy := xpv1.Condition{Type: "Healthy", Status: "False"}
+
+	listKinds := map[schema.GroupVersionResource]string{
+		packageResources[0]: "ProviderList",
+		packageResources[1]: "ConfigurationList",
+		packageResources[2]: "FunctionList",
+	}
+
+	type want struct {
+		pkgs []Package
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		client dynamic.Interface
+		want   want
+	}{
+		"Packages": {
+			reason: "All kinds of packages should be returned with their health.",
+			client: fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+				pkg("Provider", "provider-aws", "xpkg.upbound.io/upbound/provider-aws:v0.40.0", installed, unhealthy),
+				pkg("Configuration", "platform-ref-aws", "xpkg.upbound.io/upbound/platform-ref-aws:v0.8.0", installed, healthy),
+				pkg("Function", "function-patch-and-transform", "xpkg.upbound.io/crossplane-contrib/function-patch-and-transform:v0.2.1"),
+			),
+			want: want{
+				pkgs: []Package{
+					{Kind: "Provider", Name: "provider-aws", Package: "xpkg.upbound.io/upbound/provider-aws:v0.40.0", Installed: true},
+					{Kind: "Configuration", Name: "platform-ref-aws", Package: "xpkg.upbound.io/upbound/platform-ref-aws:v0.8.0", Installed: true, Healthy: true},
+					{Kind: "Function", Name: "function-patch-and-transform", Package: "xpkg.upbound.io/crossplane-contrib/function-patch-and-transform:v0.2.1"},
+				},
+			},
+		},
+		"FunctionsNotServed": {
+			reason: "Control planes that do not serve functions should be supported.",
+			client: func() dynamic.Interface {
+				c := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+				c.PrependReactor("list", "functions", func(action cgotesting.Action) (bool, runtime.Object, error) {
+					return true, nil, kerrors.NewNotFound(schema.GroupResource{Group: "pkg.crossplane.io", Resource: "functions"}, "")
+				})
+				return c
+			}(),
+			want: want{
+				pkgs: []Package{},
+			},
+		},
+		"Error": {
+			reason: "Errors listing packages should be returned.",
+			client: func() dynamic.Interface {
+				c := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+				c.PrependReactor("list", "providers", func(action cgotesting.Action) (bool, runtime.Object, error) {
+					return true, nil, errors.New("boom")
+				})
+				return c
+			}(),
+			want: want{
+				err: errors.Wrapf(errors.New("boom"), errFmtListPackages, "providers"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := GetPackages(context.Background(), tc.client)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGetPackages(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.pkgs, got); diff != "" {
+				t.Errorf("\n%s\nGetPackages(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
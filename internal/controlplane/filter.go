@@ -0,0 +1,109 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controlplane
+
+import (
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// FieldStatus selects ControlPlanes by their status.
+	FieldStatus = "status"
+	// FieldConfiguration selects ControlPlanes by the name of their
+	// Configuration.
+	FieldConfiguration = "configuration"
+
+	errParseLabelSelector  = "failed to parse label selector"
+	errParseFieldSelector  = "failed to parse field selector"
+	errFmtUnsupportedField = "unsupported field %q in field selector, must be status or configuration"
+)
+
+// Filter selects ControlPlanes by their labels, status and Configuration. The
+// zero value selects all ControlPlanes.
+type Filter struct {
+	Labels labels.Selector
+	Fields fields.Selector
+}
+
+// ParseFilter parses a label selector and a field selector in the format of
+// kubectl, e.g. "env=prod,team!=a" and "status=Available". Only the status and
+// configuration fields are supported.
+func ParseFilter(labelSelector, fieldSelector string) (Filter, error) {
+	ls, err := labels.Parse(labelSelector)
+	if err != nil {
+		return Filter{}, errors.Wrap(err, errParseLabelSelector)
+	}
+	fs, err := fields.ParseSelector(fieldSelector)
+	if err != nil {
+		return Filter{}, errors.Wrap(err, errParseFieldSelector)
+	}
+	for _, r := range fs.Requirements() {
+		if r.Field != FieldStatus && r.Field != FieldConfiguration {
+			return Filter{}, errors.Errorf(errFmtUnsupportedField, r.Field)
+		}
+	}
+	return Filter{Labels: ls, Fields: fs}, nil
+}
+
+// Empty returns true if the filter selects all ControlPlanes.
+func (f Filter) Empty() bool {
+	return f.labels().Empty() && f.fields().Empty()
+}
+
+// Matches returns true if the filter selects the ControlPlane.
+func (f Filter) Matches(r *Response) bool {
+	return f.labels().Matches(labels.Set(r.Labels)) &&
+		f.fields().Matches(fields.Set{FieldStatus: r.Status, FieldConfiguration: r.Cfg})
+}
+
+// Apply returns the ControlPlanes selected by the filter.
+func (f Filter) Apply(rs []*Response) []*Response {
+	selected := []*Response{}
+	for _, r := range rs {
+		if f.Matches(r) {
+			selected = append(selected, r)
+		}
+	}
+	return selected
+}
+
+// String returns the filter in the format of kubectl selectors.
+func (f Filter) String() string {
+	ls, fs := f.labels().String(), f.fields().String()
+	switch {
+	case ls == "":
+		return fs
+	case fs == "":
+		return ls
+	default:
+		return ls + "," + fs
+	}
+}
+
+func (f Filter) labels() labels.Selector {
+	if f.Labels == nil {
+		return labels.Everything()
+	}
+	return f.Labels
+}
+
+func (f Filter) fields() fields.Selector {
+	if f.Fields == nil {
+		return fields.Everything()
+	}
+	return f.Fields
+}
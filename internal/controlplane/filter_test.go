@@ -0,0 +1,115 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controlplane
+
+import (
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFilter(t *testing.T) {
+	ctps := []*Response{
+		{Name: "ctp1", Status: "Available", Labels: map[string]string{"env": "prod", "team": "a"}},
+		{Name: "ctp2", Status: "Creating", Labels: map[string]string{"env": "prod", "team": "b"}},
+		{Name: "ctp3", Status: "ready", Cfg: "platform-ref-aws"},
+	}
+
+	type args struct {
+		labelSelector string
+		fieldSelector string
+	}
+	type want struct {
+		names  []string
+		filter string
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Empty": {
+			reason: "An empty filter should select all control planes.",
+			want: want{
+				names: []string{"ctp1", "ctp2", "ctp3"},
+			},
+		},
+		"Labels": {
+			reason: "Control planes should be selected by their labels.",
+			args: args{
+				labelSelector: "env=prod,team!=a",
+			},
+			want: want{
+				names:  []string{"ctp2"},
+				filter: "env=prod,team!=a",
+			},
+		},
+		"Fields": {
+			reason: "Control planes should be selected by their status and configuration.",
+			args: args{
+				fieldSelector: "status=ready,configuration=platform-ref-aws",
+			},
+			want: want{
+				names:  []string{"ctp3"},
+				filter: "configuration=platform-ref-aws,status=ready",
+			},
+		},
+		"LabelsAndFields": {
+			reason: "Control planes should be selected by both labels and fields.",
+			args: args{
+				labelSelector: "env",
+				fieldSelector: "status!=Creating",
+			},
+			want: want{
+				names:  []string{"ctp1"},
+				filter: "env,status!=Creating",
+			},
+		},
+		"ErrorUnsupportedField": {
+			reason: "Fields other than status and configuration should be rejected.",
+			args: args{
+				fieldSelector: "metadata.name=ctp1",
+			},
+			want: want{
+				err: errors.Errorf(errFmtUnsupportedField, "metadata.name"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f, err := ParseFilter(tc.args.labelSelector, tc.args.fieldSelector)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParseFilter(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			var names []string
+			for _, r := range f.Apply(ctps) {
+				names = append(names, r.Name)
+			}
+			if diff := cmp.Diff(tc.want.names, names); diff != "" {
+				t.Errorf("\n%s\nApply(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.filter, f.String()); diff != "" {
+				t.Errorf("\n%s\nString(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -23,4 +23,14 @@ type Options struct {
 	Description string
 
 	ConfigurationName string
+
+	// Labels and Annotations of the ControlPlane. Only applicable for Space
+	// control planes.
+	Labels      map[string]string
+	Annotations map[string]string
+
+	// Spec of the ControlPlane, e.g. its Crossplane version. The connection
+	// secret options take precedence. Only applicable for Space control
+	// planes.
+	Spec map[string]any
 }
@@ -0,0 +1,138 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package proxy serves local, authenticated proxies to the API servers of
+// control planes.
+package proxy
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd/api"
+	kproxy "k8s.io/kubectl/pkg/proxy"
+)
+
+const (
+	// tokenBytes is the number of random bytes of a proxy token.
+	tokenBytes = 32
+
+	readHeaderTimeout = 30 * time.Second
+
+	errGenerateToken = "failed to generate proxy token"
+	errCreateHandler = "failed to create proxy handler"
+	errListen        = "failed to listen"
+)
+
+// Server is a proxy on localhost to the API server of a control plane. Clients
+// authenticate to it with a random bearer token that is only valid for this
+// proxy. Requests are forwarded with the credentials of the control plane.
+type Server struct {
+	name     string
+	token    string
+	listener net.Listener
+	server   *http.Server
+}
+
+// New returns a proxy to the API server of the named control plane that
+// listens on the supplied port of localhost, or on a random port if it is 0.
+func New(name string, cfg *rest.Config, port int) (*Server, error) {
+	h, err := kproxy.NewProxyHandler("/", nil, cfg, 0, false)
+	if err != nil {
+		return nil, errors.Wrap(err, errCreateHandler)
+	}
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return nil, errors.Wrap(err, errGenerateToken)
+	}
+	l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		return nil, errors.Wrap(err, errListen)
+	}
+
+	s := &Server{
+		name:     name,
+		token:    hex.EncodeToString(b),
+		listener: l,
+	}
+	s.server = &http.Server{
+		Handler:           s.authenticate(h),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	return s, nil
+}
+
+// Name returns the name of the control plane.
+func (s *Server) Name() string {
+	return s.name
+}
+
+// URL returns the URL of the proxy.
+func (s *Server) URL() string {
+	return "http://" + s.listener.Addr().String()
+}
+
+// Serve serves the proxy until it is shut down.
+func (s *Server) Serve() error {
+	if err := s.server.Serve(s.listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops the proxy gracefully. Connections that are still active when
+// ctx is done, e.g. watches, are closed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if err := s.server.Shutdown(ctx); err != nil {
+		return s.server.Close()
+	}
+	return nil
+}
+
+// authenticate only passes requests with the token of the proxy on to h. The
+// token is removed, so that the credentials of the control plane are used.
+func (s *Server) authenticate(h http.Handler) http.Handler {
+	want := []byte("Bearer " + s.token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		r.Header.Del("Authorization")
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Kubeconfig returns a kubeconfig with a context for each of the proxies,
+// named after its control plane. The context of the first proxy is current.
+func Kubeconfig(servers ...*Server) *api.Config {
+	cfg := api.NewConfig()
+	for _, s := range servers {
+		cfg.Clusters[s.name] = &api.Cluster{Server: s.URL()}
+		cfg.AuthInfos[s.name] = &api.AuthInfo{Token: s.token}
+		cfg.Contexts[s.name] = &api.Context{Cluster: s.name, AuthInfo: s.name}
+	}
+	if len(servers) > 0 {
+		cfg.CurrentContext = servers[0].name
+	}
+	return cfg
+}
@@ -0,0 +1,122 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/client-go/rest"
+)
+
+func TestServer(t *testing.T) {
+	var gotAuth, gotPath string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotPath = r.Header.Get("Authorization"), r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	s, err := New("ctp1", &rest.Config{Host: upstream.URL, BearerToken: "ctp-token"}, 0)
+	if err != nil {
+		t.Fatalf("New(...): %v", err)
+	}
+	go s.Serve()                           //nolint:errcheck
+	defer s.Shutdown(context.Background()) //nolint:errcheck
+
+	type want struct {
+		status int
+		auth   string
+		path   string
+	}
+
+	cases := map[string]struct {
+		reason string
+		token  string
+		want   want
+	}{
+		"NoToken": {
+			reason: "Requests without a token should be rejected.",
+			want: want{
+				status: http.StatusUnauthorized,
+			},
+		},
+		"WrongToken": {
+			reason: "Requests with a token other than the one of the proxy should be rejected.",
+			token:  "ctp-token",
+			want: want{
+				status: http.StatusUnauthorized,
+			},
+		},
+		"Token": {
+			reason: "Requests with the token of the proxy should be forwarded with the credentials of the control plane.",
+			token:  s.token,
+			want: want{
+				status: http.StatusOK,
+				auth:   "Bearer ctp-token",
+				path:   "/api/v1/namespaces",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			gotAuth, gotPath = "", ""
+			req, _ := http.NewRequest(http.MethodGet, s.URL()+"/api/v1/namespaces", nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Do(...): %v", err)
+			}
+			resp.Body.Close() //nolint:errcheck
+
+			got := want{status: resp.StatusCode, auth: gotAuth, path: gotPath}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nServeHTTP(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestKubeconfig(t *testing.T) {
+	listen := func() net.Listener {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { l.Close() }) //nolint:errcheck
+		return l
+	}
+	s1 := &Server{name: "ctp1", token: "t1", listener: listen()}
+	s2 := &Server{name: "ctp2", token: "t2", listener: listen()}
+
+	cfg := Kubeconfig(s1, s2)
+
+	if diff := cmp.Diff("ctp1", cfg.CurrentContext); diff != "" {
+		t.Errorf("Kubeconfig(...): -want current context, +got:\n%s", diff)
+	}
+	for _, s := range []*Server{s1, s2} {
+		got := []string{cfg.Clusters[s.name].Server, cfg.AuthInfos[s.name].Token, cfg.Contexts[s.name].Cluster, cfg.Contexts[s.name].AuthInfo}
+		want := []string{s.URL(), s.token, s.name, s.name}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Kubeconfig(...): -want %s, +got:\n%s", s.name, diff)
+		}
+	}
+}
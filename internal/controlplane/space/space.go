@@ -17,13 +17,20 @@ package space
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	xpcommonv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
@@ -32,21 +39,31 @@ import (
 	"github.com/upbound/up/internal/resources"
 )
 
+const (
+	// maxEvents is the number of most recent events that are described.
+	maxEvents = 10
+)
+
 var (
-	resource      = resources.ControlPlaneGVK.GroupVersion().WithResource("controlplanes")
-	kubeconfigFmt = "kubeconfig-%s"
+	resource       = resources.ControlPlaneGVK.GroupVersion().WithResource("controlplanes")
+	eventsResource = corev1.SchemeGroupVersion.WithResource("events")
+	kubeconfigFmt  = "kubeconfig-%s"
 )
 
 // Client is the client used for interacting with the ControlPlanes API in an
 // Upbound Space.
 type Client struct {
 	c dynamic.Interface
+
+	// backoff is the delay before a watch is started over.
+	backoff wait.Backoff
 }
 
 // New instantiates a new Client.
 func New(c dynamic.Interface) *Client {
 	return &Client{
-		c: c,
+		c:       c,
+		backoff: wait.Backoff{Duration: time.Second, Factor: 2, Steps: 6, Cap: 30 * time.Second},
 	}
 }
 
@@ -70,13 +87,101 @@ func (c *Client) Get(ctx context.Context, name string) (*controlplane.Response,
 	return convert(&resources.ControlPlane{Unstructured: *u}), nil
 }
 
-// List all ControlPlanes within the Space.
-func (c *Client) List(ctx context.Context) ([]*controlplane.Response, error) {
+// Describe the ControlPlane corresponding to the given ControlPlane name,
+// including its most recent events.
+func (c *Client) Describe(ctx context.Context, name string) (*controlplane.Description, error) {
+	u, err := c.c.Resource(resource).Get(ctx, name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, controlplane.NewNotFound(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := c.events(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	ctp := &resources.ControlPlane{Unstructured: *u}
+	d := &controlplane.Description{
+		Response:          *convert(ctp),
+		CrossplaneVersion: ctp.GetCrossplaneVersion(),
+		Events:            events,
+	}
+	if t := u.GetCreationTimestamp(); !t.IsZero() {
+		d.Created = &t.Time
+	}
+	return d, nil
+}
+
+// events returns the most recent events of the named ControlPlane, oldest
+// first.
+func (c *Client) events(ctx context.Context, name string) ([]controlplane.Event, error) {
+	l, err := c.c.Resource(eventsResource).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.Set{
+			"involvedObject.kind": resources.ControlPlaneGVK.Kind,
+			"involvedObject.name": name,
+		}.String(),
+	})
+	if kerrors.IsForbidden(err) {
+		// Events are informational only, don't fail if we may not see them.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	events := []controlplane.Event{}
+	for _, u := range l.Items {
+		var e corev1.Event
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &e); err != nil {
+			return nil, err
+		}
+		if e.InvolvedObject.Kind != resources.ControlPlaneGVK.Kind || e.InvolvedObject.Name != name {
+			continue
+		}
+		events = append(events, controlplane.Event{
+			Type:     e.Type,
+			Reason:   e.Reason,
+			Message:  e.Message,
+			Count:    e.Count,
+			LastSeen: lastSeen(e),
+		})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].LastSeen.Before(events[j].LastSeen)
+	})
+	if len(events) > maxEvents {
+		events = events[len(events)-maxEvents:]
+	}
+	return events, nil
+}
+
+// lastSeen returns when the event was last seen. Events recorded with the
+// events.k8s.io API only have an event time.
+func lastSeen(e corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
+
+// List all ControlPlanes within the Space that are selected by the filter.
+func (c *Client) List(ctx context.Context, f controlplane.Filter) ([]*controlplane.Response, error) {
+	opts := metav1.ListOptions{}
+	if f.Labels != nil {
+		opts.LabelSelector = f.Labels.String()
+	}
 	list, err := c.c.
 		Resource(resource).
 		List(
 			ctx,
-			metav1.ListOptions{},
+			opts,
 		)
 	if err != nil {
 		return nil, err
@@ -87,7 +192,7 @@ func (c *Client) List(ctx context.Context) ([]*controlplane.Response, error) {
 		resps = append(resps, convert(&resources.ControlPlane{Unstructured: u}))
 	}
 
-	return resps, nil
+	return f.Apply(resps), nil
 }
 
 // Create a new ControlPlane with the given name and the supplied Options.
@@ -95,12 +200,24 @@ func (c *Client) Create(ctx context.Context, name string, opts controlplane.Opti
 	o := calculateSecret(name, opts)
 
 	ctp := &resources.ControlPlane{}
+	if len(o.Spec) > 0 {
+		ctp.Object = map[string]any{"spec": runtime.DeepCopyJSON(o.Spec)}
+	}
 	ctp.SetName(name)
+	ctp.SetLabels(o.Labels)
+	ctp.SetAnnotations(o.Annotations)
 	ctp.SetWriteConnectionSecretToReference(&xpcommonv1.SecretReference{
 		Name:      o.SecretName,
 		Namespace: o.SecretNamespace,
 	})
 
+	// Without a custom spec there is nothing to validate.
+	if len(o.Spec) > 0 {
+		if err := c.validate(ctx, ctp.GetUnstructured()); err != nil {
+			return nil, err
+		}
+	}
+
 	u, err := c.c.
 		Resource(resource).
 		Create(
@@ -131,6 +248,86 @@ func (c *Client) Delete(ctx context.Context, name string) error {
 	return err
 }
 
+// Watch calls fn with the ControlPlane corresponding to the given
+// ControlPlane name on every change, and with nil once it was deleted, until
+// fn returns true or an error, or ctx is done.
+func (c *Client) Watch(ctx context.Context, name string, fn controlplane.WatchFn) error {
+	backoff := c.backoff
+	for {
+		u, err := c.c.Resource(resource).Get(ctx, name, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			_, err := fn(nil)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if done, err := fn(convert(&resources.ControlPlane{Unstructured: *u})); done || err != nil {
+			return err
+		}
+
+		w, err := c.c.Resource(resource).Watch(ctx, metav1.ListOptions{
+			FieldSelector:   fields.OneTermEqualSelector("metadata.name", name).String(),
+			ResourceVersion: u.GetResourceVersion(),
+		})
+		if err != nil {
+			return err
+		}
+		done, err := watchEvents(ctx, w, name, fn)
+		w.Stop()
+		switch {
+		case errors.As(err, &watchError{}):
+			// The watch failed, e.g. because the resource version expired.
+			// Start over after a delay that grows while it keeps failing.
+		case done || err != nil:
+			return err
+		default:
+			// The watch was closed by the server, start over.
+			backoff = c.backoff
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff.Step()):
+		}
+	}
+}
+
+// watchError is the error of an error event of a watch.
+type watchError struct {
+	error
+}
+
+// watchEvents calls fn with the ControlPlane of every event of the watch
+// until fn returns true or an error, or ctx is done. It returns false if the
+// watch was closed, and a watchError if it failed.
+func watchEvents(ctx context.Context, w watch.Interface, name string, fn controlplane.WatchFn) (bool, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				return false, nil
+			}
+			if e.Type == watch.Error {
+				return false, watchError{kerrors.FromObject(e.Object)}
+			}
+			u, ok := e.Object.(*unstructured.Unstructured)
+			if !ok || u.GetName() != name {
+				continue
+			}
+			var r *controlplane.Response
+			if e.Type != watch.Deleted {
+				r = convert(&resources.ControlPlane{Unstructured: *u})
+			}
+			if done, err := fn(r); done || err != nil || r == nil {
+				return true, err
+			}
+		}
+	}
+}
+
 // GetKubeConfig for the given Control Plane.
 func (c *Client) GetKubeConfig(ctx context.Context, name string) (*api.Config, error) {
 
@@ -156,6 +353,9 @@ func (c *Client) GetKubeConfig(ctx context.Context, name string) (*api.Config, e
 	if kerrors.IsNotFound(err) {
 		return nil, controlplane.NewNotFound(err)
 	}
+	if err != nil {
+		return nil, err
+	}
 
 	// marshal into secret
 	var s corev1.Secret
@@ -173,6 +373,16 @@ func convert(ctp *resources.ControlPlane) *controlplane.Response {
 		ref = &xpcommonv1.SecretReference{}
 	}
 
+	var conditions []controlplane.Condition
+	for _, c := range ctp.GetConditions() {
+		conditions = append(conditions, controlplane.Condition{
+			Type:    string(c.Type),
+			Status:  string(c.Status),
+			Reason:  string(c.Reason),
+			Message: c.Message,
+		})
+	}
+
 	return &controlplane.Response{
 		ID:            ctp.GetControlPlaneID(),
 		Name:          ctp.GetName(),
@@ -180,6 +390,8 @@ func convert(ctp *resources.ControlPlane) *controlplane.Response {
 		Status:        string(cnd.Reason),
 		ConnName:      ref.Name,
 		ConnNamespace: ref.Namespace,
+		Labels:        ctp.GetLabels(),
+		Conditions:    conditions,
 	}
 }
 
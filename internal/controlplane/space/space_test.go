@@ -17,15 +17,22 @@ package space
 import (
 	"context"
 	"errors"
+	"net/http"
 	"testing"
+	"time"
 
 	xpcommonv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/google/go-cmp/cmp"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/fake"
 	cgotesting "k8s.io/client-go/testing"
@@ -118,6 +125,157 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestCreate(t *testing.T) {
+	crd := &extv1.CustomResourceDefinition{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition"},
+		ObjectMeta: metav1.ObjectMeta{Name: "controlplanes.spaces.upbound.io"},
+		Spec: extv1.CustomResourceDefinitionSpec{
+			Group: "spaces.upbound.io",
+			Names: extv1.CustomResourceDefinitionNames{Kind: "ControlPlane", Plural: "controlplanes"},
+			Scope: extv1.ClusterScoped,
+			Versions: []extv1.CustomResourceDefinitionVersion{{
+				Name: "v1beta1",
+				Schema: &extv1.CustomResourceValidation{OpenAPIV3Schema: &extv1.JSONSchemaProps{
+					Type: "object",
+					Properties: map[string]extv1.JSONSchemaProps{
+						"apiVersion": {Type: "string"},
+						"kind":       {Type: "string"},
+						"metadata":   {Type: "object"},
+						"spec": {
+							Type: "object",
+							Properties: map[string]extv1.JSONSchemaProps{
+								"crossplane": {
+									Type: "object",
+									Properties: map[string]extv1.JSONSchemaProps{
+										"version": {Type: "string"},
+										"autoUpgrade": {
+											Type: "object",
+											Properties: map[string]extv1.JSONSchemaProps{
+												"channel": {Type: "string", Enum: []extv1.JSON{{Raw: []byte(`"None"`)}, {Raw: []byte(`"Stable"`)}}},
+											},
+										},
+									},
+								},
+								"writeConnectionSecretToRef": {
+									Type: "object",
+									Properties: map[string]extv1.JSONSchemaProps{
+										"name":      {Type: "string"},
+										"namespace": {Type: "string"},
+									},
+								},
+							},
+						},
+					},
+				}},
+			}},
+		},
+	}
+	crdu, _ := runtime.DefaultUnstructuredConverter.ToUnstructured(crd)
+
+	type args struct {
+		client dynamic.Interface
+		opts   controlplane.Options
+	}
+	type want struct {
+		spec map[string]any
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoSpec": {
+			reason: "Without a spec only the connection secret is set and nothing is validated.",
+			args: args{
+				client: fake.NewSimpleDynamicClient(scheme),
+				opts:   controlplane.Options{SecretNamespace: "default"},
+			},
+			want: want{
+				spec: map[string]any{
+					"writeConnectionSecretToRef": map[string]any{"name": "kubeconfig-ctp1", "namespace": "default"},
+				},
+			},
+		},
+		"ValidSpec": {
+			reason: "A valid spec is merged with the connection secret.",
+			args: args{
+				client: fake.NewSimpleDynamicClient(scheme, &unstructured.Unstructured{Object: crdu}),
+				opts: controlplane.Options{
+					SecretNamespace: "default",
+					Spec: map[string]any{
+						"crossplane": map[string]any{"version": "1.14.1-up.1", "autoUpgrade": map[string]any{"channel": "None"}},
+					},
+				},
+			},
+			want: want{
+				spec: map[string]any{
+					"crossplane":                 map[string]any{"version": "1.14.1-up.1", "autoUpgrade": map[string]any{"channel": "None"}},
+					"writeConnectionSecretToRef": map[string]any{"name": "kubeconfig-ctp1", "namespace": "default"},
+				},
+			},
+		},
+		"InvalidSpec": {
+			reason: "Unknown fields and invalid values are rejected before the control plane is created.",
+			args: args{
+				client: fake.NewSimpleDynamicClient(scheme, &unstructured.Unstructured{Object: crdu}),
+				opts: controlplane.Options{
+					Spec: map[string]any{
+						"crossplane": map[string]any{"autoUpgrade": map[string]any{"channel": "Fast"}},
+						"size":       "large",
+					},
+				},
+			},
+			want: want{
+				err: errors.New("invalid control plane spec:\n" +
+					"  unknown field \"spec.size\"\n" +
+					"  spec.crossplane.autoUpgrade.channel: Unsupported value: \"Fast\": supported values: \"None\", \"Stable\""),
+			},
+		},
+		"CRDForbidden": {
+			reason: "Validation is skipped if the CRD may not be read.",
+			args: args{
+				client: func() dynamic.Interface {
+					c := fake.NewSimpleDynamicClient(scheme)
+					c.PrependReactor("get", "customresourcedefinitions", func(action cgotesting.Action) (bool, runtime.Object, error) {
+						return true, nil, kerrors.NewForbidden(schema.GroupResource{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"}, "controlplanes.spaces.upbound.io", errors.New("denied"))
+					})
+					return c
+				}(),
+				opts: controlplane.Options{
+					SecretNamespace: "default",
+					Spec:            map[string]any{"size": "large"},
+				},
+			},
+			want: want{
+				spec: map[string]any{
+					"size":                       "large",
+					"writeConnectionSecretToRef": map[string]any{"name": "kubeconfig-ctp1", "namespace": "default"},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(tc.args.client).Create(context.Background(), "ctp1", tc.args.opts)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCreate(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			u, err := tc.args.client.Resource(resource).Get(context.Background(), "ctp1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Get(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want.spec, u.Object["spec"]); diff != "" {
+				t.Errorf("\n%s\nCreate(...): -want spec, +got spec:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestDelete(t *testing.T) {
 	ctp1 := &resources.ControlPlane{}
 	ctp1.SetName("ctp1")
@@ -198,6 +356,7 @@ func TestList(t *testing.T) {
 
 	ctp2 := &resources.ControlPlane{}
 	ctp2.SetName("ctp2")
+	ctp2.SetLabels(map[string]string{"env": "prod"})
 	ctp2.SetWriteConnectionSecretToReference(&xpcommonv1.SecretReference{
 		Name:      "kubeconfig-ctp2",
 		Namespace: "default",
@@ -205,6 +364,7 @@ func TestList(t *testing.T) {
 
 	type args struct {
 		client dynamic.Interface
+		filter controlplane.Filter
 	}
 	type want struct {
 		resp []*controlplane.Response
@@ -263,16 +423,52 @@ func TestList(t *testing.T) {
 						Name:          "ctp2",
 						ConnName:      "kubeconfig-ctp2",
 						ConnNamespace: "default",
+						Labels:        map[string]string{"env": "prod"},
 					},
 				},
 			},
 		},
+		"LabelSelector": {
+			reason: "Only the control planes selected by the label selector are returned.",
+			args: args{
+				client: fake.NewSimpleDynamicClient(
+					scheme,
+					ctp1.GetUnstructured(),
+					ctp2.GetUnstructured(),
+				),
+				filter: controlplane.Filter{Labels: labels.SelectorFromSet(labels.Set{"env": "prod"})},
+			},
+			want: want{
+				resp: []*controlplane.Response{
+					{
+						Name:          "ctp2",
+						ConnName:      "kubeconfig-ctp2",
+						ConnNamespace: "default",
+						Labels:        map[string]string{"env": "prod"},
+					},
+				},
+			},
+		},
+		"FieldSelector": {
+			reason: "Only the control planes selected by the field selector are returned.",
+			args: args{
+				client: fake.NewSimpleDynamicClient(
+					scheme,
+					ctp1.GetUnstructured(),
+					ctp2.GetUnstructured(),
+				),
+				filter: controlplane.Filter{Fields: fields.OneTermEqualSelector(controlplane.FieldStatus, "Available")},
+			},
+			want: want{
+				resp: []*controlplane.Response{},
+			},
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 
 			c := New(tc.args.client)
-			got, err := c.List(context.Background())
+			got, err := c.List(context.Background(), tc.args.filter)
 
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nList(...): -want error, +got error:\n%s", tc.reason, diff)
@@ -284,6 +480,200 @@ func TestList(t *testing.T) {
 	}
 }
 
+func TestDescribe(t *testing.T) {
+	ctp1 := &resources.ControlPlane{}
+	ctp1.SetName("ctp1")
+	ctp1.SetCrossplaneVersion("1.14.1-up.1")
+	ctp1.SetConditions(xpcommonv1.Available())
+
+	event := func(name, object, reason string, last time.Time) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Event",
+			"metadata":   map[string]any{"name": name, "namespace": "default"},
+			"involvedObject": map[string]any{
+				"kind": "ControlPlane",
+				"name": object,
+			},
+			"type":          "Normal",
+			"reason":        reason,
+			"count":         int64(1),
+			"lastTimestamp": last.Format(time.RFC3339),
+		}}
+	}
+	now := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	type want struct {
+		d   *controlplane.Description
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		client dynamic.Interface
+		want   want
+	}{
+		"ErrorControlPlaneNotFound": {
+			reason: "If the requested control plane does not exist, a not found error is returned.",
+			client: fake.NewSimpleDynamicClient(scheme),
+			want: want{
+				err: controlplane.NewNotFound(errors.New(`controlplanes.spaces.upbound.io "ctp1" not found`)),
+			},
+		},
+		"Success": {
+			reason: "The events of the control plane should be returned oldest first.",
+			client: fake.NewSimpleDynamicClient(scheme,
+				ctp1.GetUnstructured(),
+				event("e1", "ctp1", "Synced", now),
+				event("e2", "ctp1", "Created", now.Add(-time.Minute)),
+				event("e3", "ctp2", "Created", now),
+			),
+			want: want{
+				d: &controlplane.Description{
+					Response: controlplane.Response{
+						Name:       "ctp1",
+						Status:     "Available",
+						Conditions: []controlplane.Condition{{Type: "Ready", Status: "True", Reason: "Available"}},
+					},
+					CrossplaneVersion: "1.14.1-up.1",
+					Events: []controlplane.Event{
+						{Type: "Normal", Reason: "Created", Count: 1, LastSeen: now.Add(-time.Minute)},
+						{Type: "Normal", Reason: "Synced", Count: 1, LastSeen: now},
+					},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := New(tc.client).Describe(context.Background(), "ctp1")
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDescribe(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.d, got); diff != "" {
+				t.Errorf("\n%s\nDescribe(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWatch(t *testing.T) {
+	ctp1 := &resources.ControlPlane{}
+	ctp1.SetName("ctp1")
+	ctp1.SetConditions(xpcommonv1.Creating())
+
+	ready := &resources.ControlPlane{}
+	ready.SetName("ctp1")
+	ready.SetConditions(xpcommonv1.Available())
+
+	type args struct {
+		client dynamic.Interface
+		done   func(r *controlplane.Response) bool
+	}
+	type want struct {
+		statuses []string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NotFound": {
+			reason: "If the control plane does not exist, fn is called with nil.",
+			args: args{
+				client: fake.NewSimpleDynamicClient(scheme),
+				done:   func(r *controlplane.Response) bool { return false },
+			},
+			want: want{
+				statuses: []string{"deleted"},
+			},
+		},
+		"DoneOnGet": {
+			reason: "If fn is done with the current state, no watch is started.",
+			args: args{
+				client: fake.NewSimpleDynamicClient(scheme, ctp1.GetUnstructured()),
+				done:   func(r *controlplane.Response) bool { return true },
+			},
+			want: want{
+				statuses: []string{"Creating"},
+			},
+		},
+		"Events": {
+			reason: "Every event of the watch is passed to fn until the control plane is deleted.",
+			args: args{
+				client: func() dynamic.Interface {
+					c := fake.NewSimpleDynamicClient(scheme, ctp1.GetUnstructured())
+					c.PrependWatchReactor(ctpresource, func(action cgotesting.Action) (handled bool, ret watch.Interface, err error) {
+						w, u := watch.NewFake(), ready.GetUnstructured()
+						go func() {
+							w.Modify(u)
+							w.Delete(u)
+						}()
+						return true, w, nil
+					})
+					return c
+				}(),
+				done: func(r *controlplane.Response) bool { return false },
+			},
+			want: want{
+				statuses: []string{"Creating", "Available", "deleted"},
+			},
+		},
+		"WatchError": {
+			reason: "If the watch fails, the control plane is got and watched again.",
+			args: args{
+				client: func() dynamic.Interface {
+					c := fake.NewSimpleDynamicClient(scheme, ctp1.GetUnstructured())
+					watches := 0
+					c.PrependWatchReactor(ctpresource, func(action cgotesting.Action) (handled bool, ret watch.Interface, err error) {
+						watches++
+						w, u := watch.NewFake(), ready.GetUnstructured()
+						if watches == 1 {
+							go w.Error(&metav1.Status{Status: metav1.StatusFailure, Reason: metav1.StatusReasonExpired, Code: http.StatusGone})
+							return true, w, nil
+						}
+						go func() {
+							w.Modify(u)
+							w.Delete(u)
+						}()
+						return true, w, nil
+					})
+					return c
+				}(),
+				done: func(r *controlplane.Response) bool { return false },
+			},
+			want: want{
+				statuses: []string{"Creating", "Creating", "Available", "deleted"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var statuses []string
+			c := New(tc.args.client)
+			c.backoff.Duration = time.Millisecond
+			err := c.Watch(context.Background(), "ctp1", func(r *controlplane.Response) (bool, error) {
+				if r == nil {
+					statuses = append(statuses, "deleted")
+					return true, nil
+				}
+				statuses = append(statuses, r.Status)
+				return tc.args.done(r), nil
+			})
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nWatch(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.statuses, statuses); diff != "" {
+				t.Errorf("\n%s\nWatch(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestGetKubeConfig(t *testing.T) {
 	ctp1 := &resources.ControlPlane{}
 	ctp1.SetName("ctp1")
@@ -437,6 +827,7 @@ func TestConvert(t *testing.T) {
 					Status:        string(xpcommonv1.Available().Reason),
 					ConnName:      "kubeconfig-ctp1",
 					ConnNamespace: "default",
+					Conditions:    []controlplane.Condition{{Type: "Ready", Status: "True", Reason: "Available"}},
 				},
 			},
 		},
@@ -466,6 +857,7 @@ func TestConvert(t *testing.T) {
 					Message:       "creating...",
 					ConnName:      "kubeconfig-ctp1",
 					ConnNamespace: "default",
+					Conditions:    []controlplane.Condition{{Type: "Ready", Status: "False", Reason: "Creating", Message: "creating..."}},
 				},
 			},
 		},
@@ -483,9 +875,10 @@ func TestConvert(t *testing.T) {
 			},
 			want: want{
 				resp: &controlplane.Response{
-					Name:   "ctp1",
-					ID:     "mxp1",
-					Status: string(xpcommonv1.Available().Reason),
+					Name:       "ctp1",
+					ID:         "mxp1",
+					Status:     string(xpcommonv1.Available().Reason),
+					Conditions: []controlplane.Condition{{Type: "Ready", Status: "True", Reason: "Available"}},
 				},
 			},
 		},
@@ -0,0 +1,99 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package space
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/pruning"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/upbound/up/internal/resources"
+)
+
+const (
+	errGetCRD        = "failed to get the ControlPlane CustomResourceDefinition of the Space"
+	errParseSchema   = "failed to parse the ControlPlane schema of the Space"
+	errFmtNoSchema   = "the ControlPlane CustomResourceDefinition of the Space has no schema for version %s"
+	errFmtInvalidCTP = "invalid control plane spec:\n%s"
+)
+
+var (
+	crdResource = extv1.SchemeGroupVersion.WithResource("customresourcedefinitions")
+	crdName     = resource.GroupResource().String()
+)
+
+// validate validates the ControlPlane against the schema of the ControlPlane
+// CRD installed in the Space. Unknown fields are rejected as well, because the
+// API server would silently drop them. Validation is skipped if the CRD may
+// not be read, the API server validates the ControlPlane anyway.
+func (c *Client) validate(ctx context.Context, u *unstructured.Unstructured) error {
+	cu, err := c.c.Resource(crdResource).Get(ctx, crdName, metav1.GetOptions{})
+	if kerrors.IsForbidden(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, errGetCRD)
+	}
+	crd := &extv1.CustomResourceDefinition{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(cu.Object, crd); err != nil {
+		return errors.Wrap(err, errGetCRD)
+	}
+
+	var props *extv1.JSONSchemaProps
+	for _, v := range crd.Spec.Versions {
+		if v.Name == resources.ControlPlaneGVK.Version && v.Schema != nil {
+			props = v.Schema.OpenAPIV3Schema
+		}
+	}
+	if props == nil {
+		return errors.Errorf(errFmtNoSchema, resources.ControlPlaneGVK.Version)
+	}
+	internal := &apiextensions.JSONSchemaProps{}
+	if err := extv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(props, internal, nil); err != nil {
+		return errors.Wrap(err, errParseSchema)
+	}
+	structural, err := structuralschema.NewStructural(internal)
+	if err != nil {
+		return errors.Wrap(err, errParseSchema)
+	}
+	validator, _, err := validation.NewSchemaValidator(internal)
+	if err != nil {
+		return errors.Wrap(err, errParseSchema)
+	}
+
+	msgs := []string{}
+	unknown := pruning.PruneWithOptions(u.DeepCopy().Object, structural, true, structuralschema.UnknownFieldPathOptions{TrackUnknownFieldPaths: true})
+	for _, p := range unknown {
+		msgs = append(msgs, fmt.Sprintf("  unknown field %q", p))
+	}
+	for _, e := range validation.ValidateCustomResource(nil, u.Object, validator) {
+		msgs = append(msgs, "  "+e.Error())
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return errors.Errorf(errFmtInvalidCTP, strings.Join(msgs, "\n"))
+}
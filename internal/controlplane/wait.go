@@ -0,0 +1,137 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	// WaitDelete waits for a ControlPlane to be deleted.
+	WaitDelete = "delete"
+
+	conditionPrefix = "condition="
+
+	errFmtInvalidWaitFor = "invalid --for %q, must be delete or condition=<type>[=<status>]"
+	errFmtTimeout        = "timed out waiting for control plane %s to be %s"
+	errFmtNotFound       = "control plane %s does not exist"
+)
+
+// WatchFn is called with the ControlPlane on every change, and with nil once
+// it was deleted. Watching stops when it returns true or an error.
+type WatchFn func(r *Response) (bool, error)
+
+// Watcher watches a ControlPlane.
+type Watcher interface {
+	Watch(ctx context.Context, name string, fn WatchFn) error
+}
+
+// WaitFor is what to wait for, either a condition of a ControlPlane or its
+// deletion.
+type WaitFor struct {
+	// Delete waits for deletion if true.
+	Delete bool
+	// Type of the condition to wait for, e.g. Ready.
+	Type string
+	// Status of the condition to wait for, e.g. True.
+	Status string
+}
+
+// ParseWaitFor parses a condition in the format of kubectl wait --for, i.e.
+// delete or condition=<type>[=<status>]. The status defaults to True.
+func ParseWaitFor(s string) (WaitFor, error) {
+	if s == WaitDelete {
+		return WaitFor{Delete: true}, nil
+	}
+	if !strings.HasPrefix(s, conditionPrefix) {
+		return WaitFor{}, errors.Errorf(errFmtInvalidWaitFor, s)
+	}
+	t, status, found := strings.Cut(strings.TrimPrefix(s, conditionPrefix), "=")
+	if !found {
+		status = "True"
+	}
+	if t == "" || status == "" {
+		return WaitFor{}, errors.Errorf(errFmtInvalidWaitFor, s)
+	}
+	return WaitFor{Type: t, Status: status}, nil
+}
+
+// String returns the condition in a human readable format.
+func (w WaitFor) String() string {
+	if w.Delete {
+		return "deleted"
+	}
+	return fmt.Sprintf("%s=%s", w.Type, w.Status)
+}
+
+// state returns the state of the ControlPlane that is relevant to the
+// condition, used to report transitions.
+func (w WaitFor) state(r *Response) string {
+	if r == nil {
+		return "deleted"
+	}
+	if w.Delete {
+		return "exists"
+	}
+	c, ok := w.condition(r)
+	if !ok {
+		return fmt.Sprintf("%s=Unknown", w.Type)
+	}
+	s := fmt.Sprintf("%s=%s", c.Type, c.Status)
+	if c.Reason != "" {
+		s = fmt.Sprintf("%s (%s)", s, c.Reason)
+	}
+	if c.Message != "" {
+		s = fmt.Sprintf("%s: %s", s, c.Message)
+	}
+	return s
+}
+
+func (w WaitFor) condition(r *Response) (Condition, bool) {
+	return r.GetCondition(w.Type)
+}
+
+// met returns true if the ControlPlane meets the condition.
+func (w WaitFor) met(r *Response) bool {
+	if w.Delete {
+		return r == nil
+	}
+	c, ok := w.condition(r)
+	return ok && strings.EqualFold(c.Status, w.Status)
+}
+
+// Wait waits until the named ControlPlane meets the condition, or ctx is done.
+// Each transition of the state relevant to the condition is reported.
+func Wait(ctx context.Context, w Watcher, name string, f WaitFor, report func(state string)) error {
+	last := ""
+	err := w.Watch(ctx, name, func(r *Response) (bool, error) {
+		if r == nil && !f.Delete {
+			return false, errors.Errorf(errFmtNotFound, name)
+		}
+		if s := f.state(r); s != last {
+			last = s
+			report(s)
+		}
+		return f.met(r), nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errors.Errorf(errFmtTimeout, name, f)
+	}
+	return err
+}
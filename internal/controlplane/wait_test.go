@@ -0,0 +1,171 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controlplane
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+)
+
+type mockWatcher struct {
+	events []*Response
+	err    error
+}
+
+func (m *mockWatcher) Watch(_ context.Context, _ string, fn WatchFn) error {
+	for _, r := range m.events {
+		if done, err := fn(r); done || err != nil {
+			return err
+		}
+	}
+	return m.err
+}
+
+func ready(status, reason string) *Response {
+	return &Response{Name: "ctp1", Conditions: []Condition{{Type: "Ready", Status: status, Reason: reason}}}
+}
+
+func TestParseWaitFor(t *testing.T) {
+	type want struct {
+		f   WaitFor
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		s      string
+		want   want
+	}{
+		"Delete": {
+			reason: "Waiting for delete should be supported.",
+			s:      "delete",
+			want:   want{f: WaitFor{Delete: true}},
+		},
+		"ConditionDefaultStatus": {
+			reason: "The status of a condition should default to True.",
+			s:      "condition=Ready",
+			want:   want{f: WaitFor{Type: "Ready", Status: "True"}},
+		},
+		"ConditionStatus": {
+			reason: "An explicit status of a condition should be used.",
+			s:      "condition=Healthy=False",
+			want:   want{f: WaitFor{Type: "Healthy", Status: "False"}},
+		},
+		"Invalid": {
+			reason: "Anything else should be rejected.",
+			s:      "jsonpath=.status",
+			want:   want{err: errors.Errorf(errFmtInvalidWaitFor, "jsonpath=.status")},
+		},
+		"EmptyType": {
+			reason: "A condition without a type should be rejected.",
+			s:      "condition=",
+			want:   want{err: errors.Errorf(errFmtInvalidWaitFor, "condition=")},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseWaitFor(tc.s)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParseWaitFor(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.f, got); diff != "" {
+				t.Errorf("\n%s\nParseWaitFor(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWait(t *testing.T) {
+	type args struct {
+		w Watcher
+		f WaitFor
+	}
+	type want struct {
+		states []string
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ConditionMet": {
+			reason: "Waiting should stop once the condition is met, and report each transition once.",
+			args: args{
+				w: &mockWatcher{events: []*Response{
+					{Name: "ctp1"},
+					ready("False", "Creating"),
+					ready("False", "Creating"),
+					ready("True", "Available"),
+					ready("False", "Deleting"),
+				}},
+				f: WaitFor{Type: "Ready", Status: "True"},
+			},
+			want: want{
+				states: []string{"Ready=Unknown", "Ready=False (Creating)", "Ready=True (Available)"},
+			},
+		},
+		"Deleted": {
+			reason: "Waiting for delete should stop once the control plane is gone.",
+			args: args{
+				w: &mockWatcher{events: []*Response{ready("False", "Deleting"), nil}},
+				f: WaitFor{Delete: true},
+			},
+			want: want{
+				states: []string{"exists", "deleted"},
+			},
+		},
+		"NotFound": {
+			reason: "Waiting for a condition of a control plane that does not exist should fail.",
+			args: args{
+				w: &mockWatcher{events: []*Response{nil}},
+				f: WaitFor{Type: "Ready", Status: "True"},
+			},
+			want: want{
+				err: errors.Errorf(errFmtNotFound, "ctp1"),
+			},
+		},
+		"Timeout": {
+			reason: "An exceeded deadline should be reported as a timeout.",
+			args: args{
+				w: &mockWatcher{events: []*Response{ready("False", "Creating")}, err: context.DeadlineExceeded},
+				f: WaitFor{Type: "Ready", Status: "True"},
+			},
+			want: want{
+				states: []string{"Ready=False (Creating)"},
+				err:    errors.Errorf(errFmtTimeout, "ctp1", "Ready=True"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var states []string
+			err := Wait(context.Background(), tc.args.w, "ctp1", tc.args.f, func(s string) {
+				states = append(states, s)
+			})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nWait(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.states, states); diff != "" {
+				t.Errorf("\n%s\nWait(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
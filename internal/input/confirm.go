@@ -0,0 +1,272 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Exit codes of commands that require confirmation.
+const (
+	// ExitCodeDeclined is returned if the user declines a confirmation.
+	ExitCodeDeclined = 10
+	// ExitCodeNotInteractive is returned if a confirmation is required but
+	// input is not interactive, and confirmation was not given by flag.
+	ExitCodeNotInteractive = 11
+)
+
+const (
+	errDeclined       = "operation was not confirmed"
+	errNotInteractive = "confirmation required, but input is not interactive: rerun with --yes to confirm"
+	errGetConfirm     = "error getting user confirmation"
+	errWriteAudit     = "unable to write audit record"
+)
+
+// Methods by which a confirmation was answered.
+const (
+	MethodPrompt         = "prompt"
+	MethodFlag           = "flag"
+	MethodNonInteractive = "non-interactive"
+	MethodNotRequired    = "not-required"
+)
+
+// ExitError is an error that causes the CLI to exit with a specific code.
+type ExitError struct {
+	err  error
+	code int
+}
+
+// NewExitError returns an error that exits with the supplied code.
+func NewExitError(err error, code int) *ExitError {
+	return &ExitError{err: err, code: code}
+}
+
+// Error returns the message of the underlying error.
+func (e *ExitError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ExitError) Unwrap() error {
+	return e.err
+}
+
+// ExitCode returns the code the CLI should exit with.
+func (e *ExitError) ExitCode() int {
+	return e.code
+}
+
+// Confirmation describes an operation that must be confirmed before it is
+// performed.
+type Confirmation struct {
+	// Target is a description of what is affected, e.g. "robot my-org/ci".
+	Target string
+	// Profile is the name of the profile the operation is performed with.
+	Profile string
+	// Prompt is the question shown to the user.
+	Prompt string
+	// Phrase, if set, must be typed by the user to confirm. Otherwise a y/n
+	// answer is expected.
+	Phrase string
+	// Approved indicates that the operation was already confirmed by a flag
+	// of the command, such as --force.
+	Approved bool
+	// NotRequired indicates that the operation is performed without
+	// confirmation, e.g. because the command never prompted before. It is
+	// only recorded in the audit log.
+	NotRequired bool
+}
+
+// AuditRecord is a record of a confirmation decision.
+type AuditRecord struct {
+	Time      time.Time `json:"time"`
+	Command   string    `json:"command"`
+	Target    string    `json:"target"`
+	Profile   string    `json:"profile,omitempty"`
+	Method    string    `json:"method"`
+	Confirmed bool      `json:"confirmed"`
+}
+
+// AuditLog records confirmation decisions.
+type AuditLog interface {
+	Record(r AuditRecord) error
+}
+
+// FileAuditLog appends audit records to a file as JSON lines.
+type FileAuditLog struct {
+	path string
+}
+
+// NewFileAuditLog returns an audit log that appends to the supplied file.
+func NewFileAuditLog(path string) *FileAuditLog {
+	return &FileAuditLog{path: path}
+}
+
+// Record appends a record to the audit log file, creating it if necessary.
+func (l *FileAuditLog) Record(r AuditRecord) error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, newLine)); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Confirmer asks for confirmation of destructive operations. It never prompts
+// if input is not interactive, but fails instead, unless confirmation was
+// given by flag.
+type Confirmer struct {
+	command     string
+	yes         bool
+	prompter    Prompter
+	interactive func() bool
+	audit       AuditLog
+	warn        io.Writer
+	now         func() time.Time
+}
+
+// ConfirmerOption modifies a Confirmer.
+type ConfirmerOption func(*Confirmer)
+
+// WithYes answers all confirmations with yes.
+func WithYes(yes bool) ConfirmerOption {
+	return func(c *Confirmer) {
+		c.yes = yes
+	}
+}
+
+// WithCommand sets the command that is recorded in the audit log.
+func WithCommand(command string) ConfirmerOption {
+	return func(c *Confirmer) {
+		c.command = command
+	}
+}
+
+// WithPrompter sets the prompter used to ask for confirmation.
+func WithPrompter(p Prompter) ConfirmerOption {
+	return func(c *Confirmer) {
+		c.prompter = p
+	}
+}
+
+// WithInteractive sets the function that determines whether input is
+// interactive.
+func WithInteractive(fn func() bool) ConfirmerOption {
+	return func(c *Confirmer) {
+		c.interactive = fn
+	}
+}
+
+// WithAuditLog sets the audit log confirmation decisions are recorded in.
+func WithAuditLog(l AuditLog) ConfirmerOption {
+	return func(c *Confirmer) {
+		c.audit = l
+	}
+}
+
+// NewConfirmer constructs a new Confirmer that prompts on stdin if it is a
+// terminal.
+func NewConfirmer(opts ...ConfirmerOption) *Confirmer {
+	c := &Confirmer{
+		prompter: NewPrompter(),
+		interactive: func() bool {
+			return defaultTTY{}.IsTerminal(int(os.Stdin.Fd()))
+		},
+		warn: os.Stderr,
+		now:  time.Now,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Confirm returns nil if the operation was confirmed. It returns an ExitError
+// if the operation was declined, or if confirmation is required but input is
+// not interactive. Every decision is recorded in the audit log. Only answers
+// to prompts fail if they cannot be recorded; otherwise a warning is printed.
+func (c *Confirmer) Confirm(cf Confirmation) error {
+	if cf.NotRequired {
+		c.tryRecord(cf, MethodNotRequired, true)
+		return nil
+	}
+	if c.yes || cf.Approved {
+		c.tryRecord(cf, MethodFlag, true)
+		return nil
+	}
+	if !c.interactive() {
+		c.tryRecord(cf, MethodNonInteractive, false)
+		return NewExitError(errors.New(errNotInteractive), ExitCodeNotInteractive)
+	}
+
+	label := fmt.Sprintf("%s [y/n]", cf.Prompt)
+	if cf.Phrase != "" {
+		label = fmt.Sprintf("%s To proceed, type: %q", cf.Prompt, cf.Phrase)
+	}
+	in, err := c.prompter.Prompt(label, false)
+	if err != nil {
+		return NewExitError(errors.Wrap(err, errGetConfirm), ExitCodeNotInteractive)
+	}
+	confirmed := InputYes(in)
+	if cf.Phrase != "" {
+		confirmed = in == cf.Phrase
+	}
+	if err := c.record(cf, MethodPrompt, confirmed); err != nil {
+		return err
+	}
+	if !confirmed {
+		return NewExitError(errors.New(errDeclined), ExitCodeDeclined)
+	}
+	return nil
+}
+
+// tryRecord records a decision that was not made at a prompt, warning instead
+// of failing if it cannot be recorded.
+func (c *Confirmer) tryRecord(cf Confirmation, method string, confirmed bool) {
+	if err := c.record(cf, method, confirmed); err != nil {
+		fmt.Fprintf(c.warn, "Warning: %s\n", err) //nolint:errcheck
+	}
+}
+
+func (c *Confirmer) record(cf Confirmation, method string, confirmed bool) error {
+	if c.audit == nil {
+		return nil
+	}
+	return errors.Wrap(c.audit.Record(AuditRecord{
+		Time:      c.now().UTC(),
+		Command:   c.command,
+		Target:    cf.Target,
+		Profile:   cf.Profile,
+		Method:    method,
+		Confirmed: confirmed,
+	}), errWriteAudit)
+}
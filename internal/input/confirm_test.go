@@ -0,0 +1,213 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package input
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+)
+
+type mockPrompter struct {
+	answer string
+	err    error
+}
+
+func (m *mockPrompter) Prompt(string, bool) (string, error) {
+	return m.answer, m.err
+}
+
+type mockAuditLog struct {
+	records []AuditRecord
+	err     error
+}
+
+func (m *mockAuditLog) Record(r AuditRecord) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.records = append(m.records, r)
+	return nil
+}
+
+func TestConfirm(t *testing.T) {
+	errBoom := errors.New("boom")
+	now := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	record := func(method string, confirmed bool) []AuditRecord {
+		return []AuditRecord{{Time: now, Command: "robot delete", Target: "robot org/ci", Profile: "default", Method: method, Confirmed: confirmed}}
+	}
+
+	type want struct {
+		err     error
+		code    int
+		records []AuditRecord
+		warning string
+	}
+	cases := map[string]struct {
+		reason      string
+		yes         bool
+		cf          Confirmation
+		interactive bool
+		prompter    Prompter
+		auditErr    error
+		want        want
+	}{
+		"Yes": {
+			reason: "Confirmation should be given without prompting if --yes is set.",
+			yes:    true,
+			want:   want{records: record(MethodFlag, true)},
+		},
+		"Approved": {
+			reason: "Confirmation should be given without prompting if it was approved by a command flag.",
+			cf:     Confirmation{Approved: true},
+			want:   want{records: record(MethodFlag, true)},
+		},
+		"NotRequired": {
+			reason: "Operations that do not require confirmation should only be recorded, even if input is not interactive.",
+			cf:     Confirmation{NotRequired: true},
+			want:   want{records: record(MethodNotRequired, true)},
+		},
+		"AuditErrorApproved": {
+			reason:   "Failing to record a decision that was not prompted for should only warn.",
+			cf:       Confirmation{Approved: true},
+			auditErr: errBoom,
+			want:     want{warning: "Warning: unable to write audit record: boom\n"},
+		},
+		"AuditErrorNotRequired": {
+			reason:   "Failing to record an operation that does not require confirmation should only warn.",
+			cf:       Confirmation{NotRequired: true},
+			auditErr: errBoom,
+			want:     want{warning: "Warning: unable to write audit record: boom\n"},
+		},
+		"AuditErrorPrompt": {
+			reason:      "Failing to record an answer to a prompt should fail closed.",
+			interactive: true,
+			prompter:    &mockPrompter{answer: "y"},
+			auditErr:    errBoom,
+			want:        want{err: errors.Wrap(errBoom, errWriteAudit)},
+		},
+		"NotInteractive": {
+			reason: "Confirmation should fail closed if input is not interactive.",
+			want: want{
+				err:     NewExitError(errors.New(errNotInteractive), ExitCodeNotInteractive),
+				code:    ExitCodeNotInteractive,
+				records: record(MethodNonInteractive, false),
+			},
+		},
+		"Confirmed": {
+			reason:      "Answering yes to the prompt should confirm.",
+			interactive: true,
+			prompter:    &mockPrompter{answer: "y"},
+			want:        want{records: record(MethodPrompt, true)},
+		},
+		"Declined": {
+			reason:      "Answering no to the prompt should decline.",
+			interactive: true,
+			prompter:    &mockPrompter{answer: "n"},
+			want: want{
+				err:     NewExitError(errors.New(errDeclined), ExitCodeDeclined),
+				code:    ExitCodeDeclined,
+				records: record(MethodPrompt, false),
+			},
+		},
+		"PhraseMismatch": {
+			reason:      "Answering yes should decline if a phrase must be typed.",
+			cf:          Confirmation{Phrase: "CONFIRMED"},
+			interactive: true,
+			prompter:    &mockPrompter{answer: "yes"},
+			want: want{
+				err:     NewExitError(errors.New(errDeclined), ExitCodeDeclined),
+				code:    ExitCodeDeclined,
+				records: record(MethodPrompt, false),
+			},
+		},
+		"PhraseMatch": {
+			reason:      "Typing the phrase should confirm.",
+			cf:          Confirmation{Phrase: "CONFIRMED"},
+			interactive: true,
+			prompter:    &mockPrompter{answer: "CONFIRMED"},
+			want:        want{records: record(MethodPrompt, true)},
+		},
+		"PromptError": {
+			reason:      "Errors reading the answer should fail without recording a decision.",
+			interactive: true,
+			prompter:    &mockPrompter{err: errBoom},
+			want: want{
+				err:  NewExitError(errors.Wrap(errBoom, errGetConfirm), ExitCodeNotInteractive),
+				code: ExitCodeNotInteractive,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			audit := &mockAuditLog{err: tc.auditErr}
+			warn := &strings.Builder{}
+			c := NewConfirmer(
+				WithYes(tc.yes),
+				WithCommand("robot delete"),
+				WithPrompter(tc.prompter),
+				WithInteractive(func() bool { return tc.interactive }),
+				WithAuditLog(audit),
+			)
+			c.now = func() time.Time { return now }
+			c.warn = warn
+			tc.cf.Target = "robot org/ci"
+			tc.cf.Profile = "default"
+
+			err := c.Confirm(tc.cf)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nConfirm(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			code := 0
+			if e := (&ExitError{}); errors.As(err, &e) {
+				code = e.ExitCode()
+			}
+			if diff := cmp.Diff(tc.want.code, code); diff != "" {
+				t.Errorf("\n%s\nConfirm(...): -want exit code, +got exit code:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.records, audit.records); diff != "" {
+				t.Errorf("\n%s\nConfirm(...): -want records, +got records:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.warning, warn.String()); diff != "" {
+				t.Errorf("\n%s\nConfirm(...): -want warning, +got warning:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFileAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".up", "audit.log")
+	l := NewFileAuditLog(path)
+	r := AuditRecord{Time: time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC), Command: "space destroy", Target: "Space", Method: MethodFlag, Confirmed: true}
+	for i := 0; i < 2; i++ {
+		if err := l.Record(r); err != nil {
+			t.Fatalf("Record(...): %s", err)
+		}
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(...): %s", err)
+	}
+	line := `{"time":"2023-10-01T00:00:00Z","command":"space destroy","target":"Space","method":"flag","confirmed":true}` + "\n"
+	if diff := cmp.Diff(line+line, string(b)); diff != "" {
+		t.Errorf("Record(...): -want file, +got file:\n%s", diff)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2021 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bundle reads and writes installation bundles: OCI image layouts,
+// archived as a tar file, that contain Helm charts and the images and
+// packages they install, for installations without internet access.
+package bundle
+
+import (
+	"archive/tar"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/upbound/up/internal/install/helm"
+)
+
+// Kind is the kind of an artifact in a bundle.
+type Kind string
+
+const (
+	// KindChart is a Helm chart.
+	KindChart Kind = "chart"
+	// KindImage is a container image.
+	KindImage Kind = "image"
+	// KindPackage is a Crossplane package.
+	KindPackage Kind = "package"
+)
+
+const (
+	// AnnotationRefName is the OCI annotation holding the reference of an
+	// artifact.
+	AnnotationRefName = "org.opencontainers.image.ref.name"
+	// AnnotationKind holds the Kind of an artifact.
+	AnnotationKind = "io.upbound.bundle.kind"
+	// AnnotationChartName holds the name of a chart.
+	AnnotationChartName = "io.upbound.bundle.chart.name"
+	// AnnotationChartVersion holds the version of a chart.
+	AnnotationChartVersion = "io.upbound.bundle.chart.version"
+)
+
+const (
+	errCreateLayout  = "failed to create OCI image layout"
+	errReadChart     = "failed to read chart"
+	errFmtFetchImage = "failed to fetch image %s"
+	errFmtWriteImage = "failed to write %s to bundle"
+	errArchive       = "failed to archive bundle"
+)
+
+type fetchFn func(ref name.Reference, options ...remote.Option) (v1.Image, error)
+
+// Writer adds artifacts to a bundle.
+type Writer struct {
+	dir   string
+	path  layout.Path
+	fetch fetchFn
+	opts  []remote.Option
+	added map[string]bool
+}
+
+// WriterOption modifies a Writer.
+type WriterOption func(*Writer)
+
+// WithRemoteOptions sets the options used to fetch images, e.g. their
+// platform and credentials.
+func WithRemoteOptions(opts ...remote.Option) WriterOption {
+	return func(w *Writer) {
+		w.opts = opts
+	}
+}
+
+// NewWriter creates an empty bundle in dir. The supplied annotations are
+// added to the index of the bundle.
+func NewWriter(dir string, annotations map[string]string, opts ...WriterOption) (*Writer, error) {
+	idx := mutate.IndexMediaType(empty.Index, types.OCIImageIndex)
+	if len(annotations) > 0 {
+		idx = mutate.Annotations(idx, annotations).(v1.ImageIndex)
+	}
+	p, err := layout.Write(dir, idx)
+	if err != nil {
+		return nil, errors.Wrap(err, errCreateLayout)
+	}
+	w := &Writer{
+		dir:   dir,
+		path:  p,
+		fetch: remote.Image,
+		added: map[string]bool{},
+	}
+	for _, o := range opts {
+		o(w)
+	}
+	return w, nil
+}
+
+// AddChart adds the chart archive at the supplied path to the bundle.
+func (w *Writer) AddChart(chartName, version, path string) error {
+	b, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return errors.Wrap(err, errReadChart)
+	}
+	img, err := mutate.Append(
+		mutate.ConfigMediaType(mutate.MediaType(empty.Image, types.OCIManifestSchema1), helm.HelmChartConfigMediaType),
+		mutate.Addendum{Layer: static.NewLayer(b, helm.HelmChartContentLayerMediaType)},
+	)
+	if err != nil {
+		return errors.Wrapf(err, errFmtWriteImage, chartName)
+	}
+	return errors.Wrapf(w.path.AppendImage(img, layout.WithAnnotations(map[string]string{
+		AnnotationRefName:      chartName + ":" + version,
+		AnnotationKind:         string(KindChart),
+		AnnotationChartName:    chartName,
+		AnnotationChartVersion: version,
+	})), errFmtWriteImage, chartName)
+}
+
+// AddImage fetches an image or package and adds it to the bundle. Images
+// that were already added are skipped.
+func (w *Writer) AddImage(ref name.Reference, kind Kind) error {
+	if w.added[ref.Name()] {
+		return nil
+	}
+	img, err := w.fetch(ref, w.opts...)
+	if err != nil {
+		return errors.Wrapf(err, errFmtFetchImage, ref)
+	}
+	if err := w.path.AppendImage(img, layout.WithAnnotations(map[string]string{
+		AnnotationRefName: ref.String(),
+		AnnotationKind:    string(kind),
+	})); err != nil {
+		return errors.Wrapf(err, errFmtWriteImage, ref)
+	}
+	w.added[ref.Name()] = true
+	return nil
+}
+
+// Archive writes the bundle as a tar archive to out.
+func (w *Writer) Archive(out io.Writer) error {
+	tw := tar.NewWriter(out)
+	err := filepath.WalkDir(w.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(w.dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if d.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		f, err := os.Open(filepath.Clean(path))
+		if err != nil {
+			return err
+		}
+		defer f.Close() //nolint:errcheck,gosec
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, errArchive)
+	}
+	return errors.Wrap(tw.Close(), errArchive)
+}
@@ -0,0 +1,167 @@
+// Copyright 2021 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	chart := filepath.Join(dir, "spaces-1.2.0.tgz")
+	if err := os.WriteFile(chart, []byte("chart"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetched := 0
+	fetch := func(name.Reference, ...remote.Option) (v1.Image, error) {
+		fetched++
+		return img, nil
+	}
+
+	w, err := NewWriter(filepath.Join(dir, "layout"), map[string]string{"version": "1.2.0"}, func(w *Writer) { w.fetch = fetch })
+	if err != nil {
+		t.Fatalf("NewWriter(...): %v", err)
+	}
+	if err := w.AddChart("spaces", "1.2.0", chart); err != nil {
+		t.Fatalf("AddChart(...): %v", err)
+	}
+	ref := name.MustParseReference("quay.io/jetstack/cert-manager-controller:v1.11.0")
+	for i := 0; i < 2; i++ {
+		if err := w.AddImage(ref, KindImage); err != nil {
+			t.Fatalf("AddImage(...): %v", err)
+		}
+	}
+	if fetched != 1 {
+		t.Errorf("AddImage(...): fetched %d times, want 1", fetched)
+	}
+
+	archive := filepath.Join(dir, "bundle.tar")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Archive(f); err != nil {
+		t.Fatalf("Archive(...): %v", err)
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !IsBundle(f) {
+		t.Errorf("IsBundle(...): want true for bundle archive")
+	}
+	_ = f.Close()
+
+	cf, err := os.Open(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cf.Close() //nolint:errcheck
+	if IsBundle(cf) {
+		t.Errorf("IsBundle(...): want false for chart archive")
+	}
+
+	b, err := Open(archive)
+	if err != nil {
+		t.Fatalf("Open(...): %v", err)
+	}
+	defer b.Close() //nolint:errcheck
+
+	if diff := cmp.Diff(map[string]string{"version": "1.2.0"}, b.Annotations()); diff != "" {
+		t.Errorf("Annotations(): -want, +got:\n%s", diff)
+	}
+	entries := b.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Entries(): got %d entries, want 2", len(entries))
+	}
+	wantImage := Entry{Kind: KindImage, Ref: ref.String(), Digest: digest}
+	if diff := cmp.Diff(wantImage, entries[1]); diff != "" {
+		t.Errorf("Entries(): -want, +got:\n%s", diff)
+	}
+
+	e, cf2, err := b.Chart("spaces")
+	if err != nil {
+		t.Fatalf("Chart(...): %v", err)
+	}
+	defer cf2.Close() //nolint:errcheck
+	if diff := cmp.Diff("1.2.0", e.ChartVersion); diff != "" {
+		t.Errorf("Chart(...): -want version, +got version:\n%s", diff)
+	}
+	got, err := os.ReadFile(cf2.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("chart", string(got)); diff != "" {
+		t.Errorf("Chart(...): -want content, +got content:\n%s", diff)
+	}
+	if _, _, err := b.Chart("missing"); err == nil {
+		t.Errorf("Chart(...): want error for missing chart")
+	}
+
+	srv := httptest.NewServer(registry.New())
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := name.NewRepository(u.Host + "/mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := b.NewMirror(repo, "")
+	if err != nil {
+		t.Fatalf("NewMirror(...): %v", err)
+	}
+	if err := b.Push(context.Background(), m); err != nil {
+		t.Fatalf("Push(...): %v", err)
+	}
+	tag, err := name.NewTag(m.Ref(ref.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(u.Host+"/mirror/jetstack/cert-manager-controller:v1.11.0", tag.String()); diff != "" {
+		t.Errorf("Ref(...): -want, +got:\n%s", diff)
+	}
+	pushed, err := remote.Image(tag)
+	if err != nil {
+		t.Fatalf("Push(...): image not found in registry: %v", err)
+	}
+	pushedDigest, err := pushed.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(digest, pushedDigest); diff != "" {
+		t.Errorf("Push(...): -want digest, +got digest:\n%s", diff)
+	}
+}
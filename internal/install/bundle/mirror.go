@@ -0,0 +1,218 @@
+// Copyright 2021 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
+	"helm.sh/helm/v3/pkg/postrender"
+	kyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	errFmtMirrorRef = "failed to mirror %s"
+	errReadManifest = "failed to read rendered manifest"
+	errWriteImages  = "failed to write rewritten manifest"
+)
+
+var _ postrender.PostRenderer = &Mirror{}
+
+// Mirror maps the images and packages of a bundle to their copies in a
+// private registry. It is a Helm post renderer that replaces the images of
+// rendered workloads with their copies.
+type Mirror struct {
+	refs map[string]string
+}
+
+// NewMirror maps the images and packages of a bundle into the supplied
+// repository. An image is copied to the repository path of its source
+// without the registry, e.g. quay.io/jetstack/cert-manager-controller is
+// copied to <repository>/jetstack/cert-manager-controller. Images below
+// stripPrefix are copied to their path below stripPrefix instead. Images
+// referenced by digest are referenced by the digest of their bundled copy.
+func (b *Bundle) NewMirror(repository name.Repository, stripPrefix string) (*Mirror, error) {
+	m := &Mirror{refs: map[string]string{}}
+	for _, e := range b.entries {
+		if e.Kind == KindChart {
+			continue
+		}
+		src, err := name.ParseReference(e.Ref)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtMirrorRef, e.Ref)
+		}
+		path := src.Context().RepositoryStr()
+		if stripPrefix != "" && strings.HasPrefix(src.Context().Name(), stripPrefix+"/") {
+			path = strings.TrimPrefix(src.Context().Name(), stripPrefix+"/")
+		}
+		target := repository.Name() + "/" + path
+		if _, ok := src.(name.Digest); ok {
+			target += "@" + e.Digest.String()
+		} else {
+			target += ":" + src.Identifier()
+		}
+		m.refs[src.Name()] = target
+	}
+	return m, nil
+}
+
+// Ref returns the reference of the copy of the supplied image, or the image
+// itself if it has no copy.
+func (m *Mirror) Ref(image string) string {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return image
+	}
+	if target, ok := m.refs[ref.Name()]; ok {
+		return target
+	}
+	return image
+}
+
+// Run replaces the images of workloads in the rendered manifests with their
+// copies. Documents without images to replace are left unchanged.
+func (m *Mirror) Run(rendered *bytes.Buffer) (*bytes.Buffer, error) {
+	docs, err := splitDocuments(rendered)
+	if err != nil {
+		return nil, errors.Wrap(err, errReadManifest)
+	}
+	out := &bytes.Buffer{}
+	for _, doc := range docs {
+		obj := map[string]any{}
+		if err := yaml.Unmarshal(doc, &obj); err != nil {
+			return nil, errors.Wrap(err, errReadManifest)
+		}
+		changed := false
+		visitImages(obj, func(image string) string {
+			target := m.Ref(image)
+			changed = changed || target != image
+			return target
+		})
+		if changed {
+			if doc, err = yaml.Marshal(obj); err != nil {
+				return nil, errors.Wrap(err, errWriteImages)
+			}
+		}
+		out.WriteString("---\n")
+		out.Write(doc)
+		if !bytes.HasSuffix(doc, []byte("\n")) {
+			out.WriteString("\n")
+		}
+	}
+	return out, nil
+}
+
+// Images returns the sorted, unique images of the workloads in a rendered
+// manifest.
+func Images(manifest string) ([]string, error) {
+	docs, err := splitDocuments(strings.NewReader(manifest))
+	if err != nil {
+		return nil, errors.Wrap(err, errReadManifest)
+	}
+	seen := map[string]bool{}
+	for _, doc := range docs {
+		obj := map[string]any{}
+		if err := yaml.Unmarshal(doc, &obj); err != nil {
+			return nil, errors.Wrap(err, errReadManifest)
+		}
+		visitImages(obj, func(image string) string {
+			seen[image] = true
+			return image
+		})
+	}
+	images := make([]string, 0, len(seen))
+	for i := range seen {
+		images = append(images, i)
+	}
+	sort.Strings(images)
+	return images, nil
+}
+
+// splitDocuments splits a multi-document YAML stream. Empty documents are
+// dropped.
+func splitDocuments(r io.Reader) ([][]byte, error) {
+	yr := kyaml.NewYAMLReader(bufio.NewReader(r))
+	docs := [][]byte{}
+	for {
+		doc, err := yr.Read()
+		if err == io.EOF {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(stripComments(doc))) == 0 {
+			continue
+		}
+		docs = append(docs, doc)
+	}
+}
+
+func stripComments(doc []byte) []byte {
+	lines := bytes.Split(doc, []byte("\n"))
+	out := make([][]byte, 0, len(lines))
+	for _, l := range lines {
+		if !bytes.HasPrefix(bytes.TrimSpace(l), []byte("#")) {
+			out = append(out, l)
+		}
+	}
+	return bytes.Join(out, []byte("\n"))
+}
+
+// podSpecPaths are the paths of the pod spec of workload kinds.
+var podSpecPaths = map[string][]string{
+	"Pod":                   {"spec"},
+	"Deployment":            {"spec", "template", "spec"},
+	"StatefulSet":           {"spec", "template", "spec"},
+	"DaemonSet":             {"spec", "template", "spec"},
+	"ReplicaSet":            {"spec", "template", "spec"},
+	"ReplicationController": {"spec", "template", "spec"},
+	"Job":                   {"spec", "template", "spec"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// visitImages calls fn with the image of every container of a workload and
+// sets the image to the result.
+func visitImages(obj map[string]any, fn func(image string) string) {
+	kind, _ := obj["kind"].(string)
+	path, ok := podSpecPaths[kind]
+	if !ok {
+		return
+	}
+	spec := obj
+	for _, p := range path {
+		if spec, ok = spec[p].(map[string]any); !ok {
+			return
+		}
+	}
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _ := spec[field].([]any)
+		for _, c := range containers {
+			container, ok := c.(map[string]any)
+			if !ok {
+				continue
+			}
+			if image, ok := container["image"].(string); ok && image != "" {
+				container["image"] = fn(image)
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+const manifest = `---
+# Source: chart/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox
+      containers:
+      - name: controller
+        image: quay.io/jetstack/cert-manager-controller:v1.11.0
+---
+# Source: chart/templates/cronjob.yaml
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: job
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: job
+            image: registry.example.com/spaces/mxe-job:v1.2.0
+---
+# Source: chart/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cfg
+data:
+  image: quay.io/not/a-container:v1
+`
+
+func TestImages(t *testing.T) {
+	got, err := Images(manifest)
+	if err != nil {
+		t.Fatalf("Images(...): %v", err)
+	}
+	want := []string{
+		"busybox",
+		"quay.io/jetstack/cert-manager-controller:v1.11.0",
+		"registry.example.com/spaces/mxe-job:v1.2.0",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Images(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestMirror(t *testing.T) {
+	digest := v1.Hash{Algorithm: "sha256", Hex: "0000000000000000000000000000000000000000000000000000000000000001"}
+	b := &Bundle{entries: []Entry{
+		{Kind: KindChart, Ref: "spaces:1.2.0", ChartName: "spaces", ChartVersion: "1.2.0"},
+		{Kind: KindImage, Ref: "busybox"},
+		{Kind: KindImage, Ref: "quay.io/jetstack/cert-manager-controller:v1.11.0"},
+		{Kind: KindImage, Ref: "registry.example.com/spaces/mxe-job:v1.2.0"},
+		{Kind: KindPackage, Ref: "registry.k8s.io/ingress-nginx/controller:v1.8.1@sha256:e5c4824e7375fcf2a393e1c03c293b69759af37a9ca6abdb91b13d78a93da8bd", Digest: digest},
+	}}
+	m, err := b.NewMirror(name.MustParseReference("mirror.local/up").Context(), "registry.example.com/spaces")
+	if err != nil {
+		t.Fatalf("NewMirror(...): %v", err)
+	}
+
+	refs := map[string]string{
+		"busybox":               "mirror.local/up/library/busybox:latest",
+		"busybox:latest":        "mirror.local/up/library/busybox:latest",
+		"unknown.example.com/x": "unknown.example.com/x",
+		"registry.example.com/spaces/mxe-job:v1.2.0": "mirror.local/up/mxe-job:v1.2.0",
+		"registry.k8s.io/ingress-nginx/controller:v1.8.1@sha256:e5c4824e7375fcf2a393e1c03c293b69759af37a9ca6abdb91b13d78a93da8bd": "mirror.local/up/ingress-nginx/controller@" + digest.String(),
+	}
+	for in, want := range refs {
+		if diff := cmp.Diff(want, m.Ref(in)); diff != "" {
+			t.Errorf("Ref(%q): -want, +got:\n%s", in, diff)
+		}
+	}
+
+	out, err := m.Run(bytes.NewBufferString(manifest))
+	if err != nil {
+		t.Fatalf("Run(...): %v", err)
+	}
+	got, err := Images(out.String())
+	if err != nil {
+		t.Fatalf("Images(...): %v", err)
+	}
+	want := []string{
+		"mirror.local/up/jetstack/cert-manager-controller:v1.11.0",
+		"mirror.local/up/library/busybox:latest",
+		"mirror.local/up/mxe-job:v1.2.0",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Run(...): -want images, +got images:\n%s", diff)
+	}
+	if !bytes.Contains(out.Bytes(), []byte("image: quay.io/not/a-container:v1")) {
+		t.Errorf("Run(...): want documents without workloads unchanged, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2021 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+const (
+	layoutFile = "oci-layout"
+)
+
+const (
+	errExtract          = "failed to extract bundle"
+	errFmtUnsafePath    = "bundle contains unsafe path %q"
+	errReadLayout       = "failed to read OCI image layout of bundle"
+	errFmtChartNotFound = "chart %s not found in bundle"
+	errFmtExtractChart  = "failed to extract chart %s"
+	errFmtPushImage     = "failed to push %s"
+	errNotSingleLayer   = "chart does not have a single layer"
+)
+
+// Entry is an artifact in a bundle.
+type Entry struct {
+	Kind Kind
+	// Ref is the reference the artifact was fetched from. For charts it is
+	// the name and version of the chart.
+	Ref string
+	// ChartName and ChartVersion are only set for charts.
+	ChartName    string
+	ChartVersion string
+	Digest       v1.Hash
+}
+
+// Bundle is a bundle extracted from its archive.
+type Bundle struct {
+	dir         string
+	path        layout.Path
+	annotations map[string]string
+	entries     []Entry
+}
+
+// IsBundle reports whether the file is a bundle archive, i.e. a tar archive
+// of an OCI image layout, rather than e.g. a chart archive. It leaves the
+// file offset at the start of the file.
+func IsBundle(f *os.File) bool {
+	defer f.Seek(0, io.SeekStart) //nolint:errcheck
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			return false
+		}
+		if hdr.Name == layoutFile {
+			return true
+		}
+	}
+}
+
+// Open extracts the bundle archive at path into a temporary directory. Close
+// removes the directory.
+func Open(path string) (*Bundle, error) {
+	dir, err := os.MkdirTemp("", "up-bundle-")
+	if err != nil {
+		return nil, errors.Wrap(err, errExtract)
+	}
+	b := &Bundle{dir: dir}
+	if err := extract(path, dir); err != nil {
+		_ = b.Close()
+		return nil, errors.Wrap(err, errExtract)
+	}
+	if err := b.readIndex(); err != nil {
+		_ = b.Close()
+		return nil, errors.Wrap(err, errReadLayout)
+	}
+	return b, nil
+}
+
+func extract(archive, dir string) error {
+	f, err := os.Open(filepath.Clean(archive))
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck,gosec
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return errors.Errorf(errFmtUnsafePath, hdr.Name)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(target, tr); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeFile(path string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil { //nolint:gosec // Bundles are trusted input.
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (b *Bundle) readIndex() error {
+	p, err := layout.FromPath(b.dir)
+	if err != nil {
+		return err
+	}
+	idx, err := p.ImageIndex()
+	if err != nil {
+		return err
+	}
+	m, err := idx.IndexManifest()
+	if err != nil {
+		return err
+	}
+	b.path = p
+	b.annotations = m.Annotations
+	for _, d := range m.Manifests {
+		b.entries = append(b.entries, Entry{
+			Kind:         Kind(d.Annotations[AnnotationKind]),
+			Ref:          d.Annotations[AnnotationRefName],
+			ChartName:    d.Annotations[AnnotationChartName],
+			ChartVersion: d.Annotations[AnnotationChartVersion],
+			Digest:       d.Digest,
+		})
+	}
+	return nil
+}
+
+// Close removes the extracted bundle.
+func (b *Bundle) Close() error {
+	return os.RemoveAll(b.dir)
+}
+
+// Annotations returns the annotations of the bundle.
+func (b *Bundle) Annotations() map[string]string {
+	return b.annotations
+}
+
+// Entries returns the artifacts in the bundle.
+func (b *Bundle) Entries() []Entry {
+	return b.entries
+}
+
+// Chart returns the entry of the named chart and opens its chart archive.
+func (b *Bundle) Chart(chartName string) (Entry, *os.File, error) {
+	for _, e := range b.entries {
+		if e.Kind != KindChart || e.ChartName != chartName {
+			continue
+		}
+		f, err := b.extractChart(e)
+		return e, f, errors.Wrapf(err, errFmtExtractChart, chartName)
+	}
+	return Entry{}, nil, errors.Errorf(errFmtChartNotFound, chartName)
+}
+
+func (b *Bundle) extractChart(e Entry) (*os.File, error) {
+	img, err := b.path.Image(e.Digest)
+	if err != nil {
+		return nil, err
+	}
+	ls, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	if len(ls) != 1 {
+		return nil, errors.New(errNotSingleLayer)
+	}
+	rc, err := ls[0].Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close() //nolint:errcheck
+	path := filepath.Join(b.dir, "charts", fmt.Sprintf("%s-%s.tgz", e.ChartName, e.ChartVersion))
+	if err := writeFile(path, rc); err != nil {
+		return nil, err
+	}
+	return os.Open(filepath.Clean(path))
+}
+
+// Push pushes the images and packages of the bundle to the references the
+// mirror maps them to.
+func (b *Bundle) Push(ctx context.Context, m *Mirror, opts ...remote.Option) error {
+	opts = append(opts, remote.WithContext(ctx))
+	for _, e := range b.entries {
+		if e.Kind == KindChart {
+			continue
+		}
+		img, err := b.path.Image(e.Digest)
+		if err != nil {
+			return errors.Wrapf(err, errFmtPushImage, e.Ref)
+		}
+		target, err := name.ParseReference(m.Ref(e.Ref))
+		if err != nil {
+			return errors.Wrapf(err, errFmtPushImage, e.Ref)
+		}
+		if err := remote.Write(target, img, opts...); err != nil {
+			return errors.Wrapf(err, errFmtPushImage, e.Ref)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+// Copyright 2021 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package install
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+const diffContext = 3
+
+// Change is the kind of change to a resource.
+type Change string
+
+// Changes to resources.
+const (
+	ChangeAdd    Change = "add"
+	ChangeRemove Change = "remove"
+	ChangeUpdate Change = "update"
+)
+
+// ResourceDiff is the difference of a resource between two manifests.
+type ResourceDiff struct {
+	// Resource identifies the resource as Kind/namespace/name, or Kind/name
+	// for cluster scoped resources.
+	Resource string
+	// Change is the kind of change.
+	Change Change
+	// Diff is a line diff of the resource's YAML. Unchanged lines further
+	// than a few lines from a change are omitted.
+	Diff string
+}
+
+// DiffManifests returns the differences between the resources of two
+// rendered multi-document YAML manifests, sorted by resource. Resources that
+// are identical in both manifests are omitted.
+func DiffManifests(current, desired string) ([]ResourceDiff, error) {
+	cur, err := splitManifest(current)
+	if err != nil {
+		return nil, err
+	}
+	des, err := splitManifest(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]bool{}
+	for k := range cur {
+		keys[k] = true
+	}
+	for k := range des {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	diffs := []ResourceDiff{}
+	for _, k := range sorted {
+		c, inCur := cur[k]
+		d, inDes := des[k]
+		switch {
+		case !inCur:
+			diffs = append(diffs, ResourceDiff{Resource: k, Change: ChangeAdd, Diff: lineDiff("", d)})
+		case !inDes:
+			diffs = append(diffs, ResourceDiff{Resource: k, Change: ChangeRemove, Diff: lineDiff(c, "")})
+		case c != d:
+			diffs = append(diffs, ResourceDiff{Resource: k, Change: ChangeUpdate, Diff: lineDiff(c, d)})
+		}
+	}
+	return diffs, nil
+}
+
+// splitManifest returns the YAML of each resource in a manifest by resource
+// key. The YAML is normalized so that formatting differences do not show up
+// as changes.
+func splitManifest(manifest string) (map[string]string, error) {
+	resources := map[string]string{}
+	yr := yaml.NewYAMLReader(bufio.NewReader(strings.NewReader(manifest)))
+	for {
+		b, err := yr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(b)) == 0 {
+			continue
+		}
+		u := &unstructured.Unstructured{}
+		if err := k8syaml.Unmarshal(b, &u.Object); err != nil {
+			return nil, err
+		}
+		if len(u.Object) == 0 {
+			continue
+		}
+		out, err := k8syaml.Marshal(u.Object)
+		if err != nil {
+			return nil, err
+		}
+		resources[resourceKey(u)] = string(out)
+	}
+	return resources, nil
+}
+
+func resourceKey(u *unstructured.Unstructured) string {
+	if u.GetNamespace() == "" {
+		return fmt.Sprintf("%s/%s", u.GetKind(), u.GetName())
+	}
+	return fmt.Sprintf("%s/%s/%s", u.GetKind(), u.GetNamespace(), u.GetName())
+}
+
+type diffOp int
+
+const (
+	opEqual diffOp = iota
+	opDelete
+	opInsert
+)
+
+type diffLine struct {
+	op   diffOp
+	text string
+}
+
+// lineDiff returns a line diff of a and b, with lines prefixed by "-", "+"
+// or " ". Unchanged lines more than diffContext lines away from a change are
+// replaced by "...".
+func lineDiff(a, b string) string {
+	all := diffLines(splitLines(a), splitLines(b))
+
+	keep := make([]bool, len(all))
+	for i, l := range all {
+		if l.op == opEqual {
+			continue
+		}
+		for j := i - diffContext; j <= i+diffContext; j++ {
+			if j >= 0 && j < len(all) {
+				keep[j] = true
+			}
+		}
+	}
+
+	var sb strings.Builder
+	skipped := false
+	for i, l := range all {
+		if !keep[i] {
+			skipped = true
+			continue
+		}
+		if skipped {
+			sb.WriteString("  ...\n")
+			skipped = false
+		}
+		switch l.op {
+		case opDelete:
+			sb.WriteString("- ")
+		case opInsert:
+			sb.WriteString("+ ")
+		case opEqual:
+			sb.WriteString("  ")
+		}
+		sb.WriteString(l.text)
+		sb.WriteString("\n")
+	}
+	if skipped {
+		sb.WriteString("  ...\n")
+	}
+	return sb.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines returns the shortest edit of a into b, computed from their
+// longest common subsequence. Deletions are ordered before insertions.
+func diffLines(a, b []string) []diffLine {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	lines := make([]diffLine, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{op: opEqual, text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{op: opDelete, text: a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{op: opInsert, text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{op: opDelete, text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{op: opInsert, text: b[j]})
+	}
+	return lines
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package install
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffManifests(t *testing.T) {
+	current := `---
+# Source: spaces/templates/cm.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: upbound-system
+data:
+  a: "1"
+  b: "2"
+---
+# Source: spaces/templates/removed.yaml
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: removed
+  namespace: upbound-system
+---
+# Source: spaces/templates/role.yaml
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: same
+`
+	desired := `---
+# Source: spaces/templates/role.yaml
+kind: ClusterRole
+apiVersion: rbac.authorization.k8s.io/v1
+metadata:
+  name: same
+---
+# Source: spaces/templates/cm.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: upbound-system
+data:
+  a: "1"
+  b: "3"
+---
+# Source: spaces/templates/added.yaml
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: added
+`
+
+	type want struct {
+		diffs []ResourceDiff
+		err   error
+	}
+	cases := map[string]struct {
+		reason  string
+		current string
+		desired string
+		want    want
+	}{
+		"Install": {
+			reason:  "All resources should be added if there is no current manifest.",
+			desired: "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: added\n",
+			want: want{
+				diffs: []ResourceDiff{
+					{
+						Resource: "Namespace/added",
+						Change:   ChangeAdd,
+						Diff:     "+ apiVersion: v1\n+ kind: Namespace\n+ metadata:\n+   name: added\n",
+					},
+				},
+			},
+		},
+		"Upgrade": {
+			reason:  "Added, removed and updated resources should be returned, ignoring formatting and unchanged resources.",
+			current: current,
+			desired: desired,
+			want: want{
+				diffs: []ResourceDiff{
+					{
+						Resource: "ConfigMap/upbound-system/config",
+						Change:   ChangeUpdate,
+						Diff:     "  apiVersion: v1\n  data:\n    a: \"1\"\n-   b: \"2\"\n+   b: \"3\"\n  kind: ConfigMap\n  metadata:\n    name: config\n  ...\n",
+					},
+					{
+						Resource: "Namespace/added",
+						Change:   ChangeAdd,
+						Diff:     "+ apiVersion: v1\n+ kind: Namespace\n+ metadata:\n+   name: added\n",
+					},
+					{
+						Resource: "ServiceAccount/upbound-system/removed",
+						Change:   ChangeRemove,
+						Diff:     "- apiVersion: v1\n- kind: ServiceAccount\n- metadata:\n-   name: removed\n-   namespace: upbound-system\n",
+					},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := DiffManifests(tc.current, tc.desired)
+			if diff := cmp.Diff(tc.want.err, err); diff != "" {
+				t.Errorf("\n%s\nDiffManifests(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.diffs, got); diff != "" {
+				t.Errorf("\n%s\nDiffManifests(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
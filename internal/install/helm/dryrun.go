@@ -0,0 +1,90 @@
+// Copyright 2021 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"fmt"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/postrender"
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// dryRunInstaller renders a chart the way an install would, without
+// contacting the cluster other than to discover its Kubernetes version.
+// Unlike a server side dry run it does not require the CRDs of custom
+// resources in the chart to be installed.
+type dryRunInstaller struct {
+	getter    genericclioptions.RESTClientGetter
+	namespace string
+	name      string
+	log       logging.Logger
+	post      postrender.PostRenderer
+}
+
+// Run renders the supplied chart with the supplied values.
+func (d *dryRunInstaller) Run(ch *chart.Chart, values map[string]any) (*release.Release, error) {
+	// A client only install replaces the clients of its configuration, so
+	// it must not share it with other clients.
+	cfg := new(action.Configuration)
+	if err := cfg.Init(d.getter, d.namespace, helmDriverSecret, func(format string, v ...any) {
+		d.log.Debug(fmt.Sprintf(format, v...))
+	}); err != nil {
+		return nil, err
+	}
+	i := action.NewInstall(cfg)
+	i.Namespace = d.namespace
+	i.ReleaseName = d.name
+	i.DryRun = true
+	i.ClientOnly = true
+	i.PostRenderer = d.post
+	if dc, err := d.getter.ToDiscoveryClient(); err == nil {
+		if v, err := dc.ServerVersion(); err == nil {
+			i.KubeVersion = &chartutil.KubeVersion{
+				Version: v.GitVersion,
+				Major:   v.Major,
+				Minor:   v.Minor,
+			}
+		}
+	}
+	return i.Run(ch, values)
+}
+
+// renderKubeVersion is the Kubernetes version charts are rendered for when
+// there is no cluster to discover it from. Helm defaults to v1.20, which
+// charts that require newer versions reject.
+var renderKubeVersion = &chartutil.KubeVersion{Version: "v1.28.0", Major: "1", Minor: "28"}
+
+// Render renders a chart with the supplied values the way an install into an
+// empty cluster would, without connecting to a cluster, and returns the
+// rendered manifest.
+func Render(ch *chart.Chart, namespace string, values map[string]any) (string, error) {
+	i := action.NewInstall(&action.Configuration{Log: func(string, ...any) {}})
+	i.Namespace = namespace
+	i.ReleaseName = ch.Name()
+	i.DryRun = true
+	i.ClientOnly = true
+	i.KubeVersion = renderKubeVersion
+	rel, err := i.Run(ch, values)
+	if err != nil {
+		return "", errors.Wrap(err, errRenderChart)
+	}
+	return rel.Manifest, nil
+}
@@ -28,7 +28,9 @@ import (
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/postrender"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage/driver"
 	"k8s.io/client-go/rest"
@@ -51,6 +53,8 @@ const (
 	errGetInstalledReleaseFmt            = "could not identify installed release for %s in namespace %s"
 	errGetInstalledReleaseOrAlternateFmt = "could not identify installed release for %s or %s in namespace %s"
 	errVerifyInstalledVersion            = "could not identify current version"
+	errGetValues                         = "could not get values of installed release"
+	errRenderChart                       = "could not render chart"
 	errVerifyChartNotInstalled           = "could not verify that chart is not already installed"
 	errChartAlreadyInstalledFmt          = "chart already installed with version %s"
 	errPullChart                         = "could not pull chart"
@@ -127,6 +131,7 @@ type Installer struct {
 	tempDir         TempDirFn
 	log             logging.Logger
 	oci             bool
+	postRenderer    postrender.PostRenderer
 
 	// Auth
 	username string
@@ -137,6 +142,8 @@ type Installer struct {
 	getClient       helmGetter
 	installClient   helmInstaller
 	upgradeClient   helmUpgrader
+	dryRunInstaller helmInstaller
+	dryRunUpgrader  helmUpgrader
 	rollbackClient  helmRollbacker
 	uninstallClient helmUninstaller
 
@@ -218,6 +225,14 @@ func Wait() InstallerModifierFn {
 	}
 }
 
+// WithPostRenderer modifies the rendered manifests of installs and upgrades
+// before they are applied.
+func WithPostRenderer(pr postrender.PostRenderer) InstallerModifierFn {
+	return func(h *Installer) {
+		h.postRenderer = pr
+	}
+}
+
 // WithNoHooks will disable uninstall hooks
 func WithNoHooks() InstallerModifierFn {
 	return func(h *Installer) {
@@ -251,7 +266,7 @@ func NewManager(config *rest.Config, chartName string, repoURL *url.URL, modifie
 	}
 	actionConfig := new(action.Configuration)
 	if err := actionConfig.Init(newRESTClientGetter(config, h.namespace), h.namespace, helmDriverSecret, func(format string, v ...any) {
-		h.log.Debug(fmt.Sprintf(format, v))
+		h.log.Debug(fmt.Sprintf(format, v...))
 	}); err != nil {
 		return nil, err
 	}
@@ -267,25 +282,7 @@ func NewManager(config *rest.Config, chartName string, repoURL *url.URL, modifie
 	}
 
 	// Pull Client
-	if h.oci {
-		h.pullClient = newRegistryPuller(withRemoteOpts(remote.WithAuth(&authn.Basic{
-			Username: h.username,
-			Password: h.password,
-		})), withRepoURL(h.repoURL))
-	} else {
-		// TODO(hasheddan): we currently use our own OCI client instead of the
-		// upstream Helm support.
-		p := action.NewPullWithOpts(action.WithConfig(&action.Configuration{}))
-		p.DestDir = h.cacheDir
-		p.Username = h.username
-		p.Password = h.password
-		p.Devel = true
-		p.Username = h.username
-		p.Password = h.password
-		p.Settings = &cli.EnvSettings{}
-		p.RepoURL = h.repoURL.String()
-		h.pullClient = &puller{p}
-	}
+	h.pullClient = h.newPuller()
 
 	// Get Client
 	h.getClient = action.NewGet(actionConfig)
@@ -297,6 +294,7 @@ func NewManager(config *rest.Config, chartName string, repoURL *url.URL, modifie
 	ic.Wait = h.wait
 	ic.Timeout = waitTimeout
 	ic.DisableHooks = h.noHooks
+	ic.PostRenderer = h.postRenderer
 	h.installClient = ic
 
 	// Upgrade Client
@@ -305,8 +303,23 @@ func NewManager(config *rest.Config, chartName string, repoURL *url.URL, modifie
 	uc.Wait = h.wait
 	uc.Timeout = waitTimeout
 	uc.DisableHooks = h.noHooks
+	uc.PostRenderer = h.postRenderer
 	h.upgradeClient = uc
 
+	// Dry run clients
+	h.dryRunInstaller = &dryRunInstaller{
+		getter:    newRESTClientGetter(config, h.namespace),
+		namespace: h.namespace,
+		name:      h.chartName,
+		log:       h.log,
+		post:      h.postRenderer,
+	}
+	duc := action.NewUpgrade(actionConfig)
+	duc.Namespace = h.namespace
+	duc.DryRun = true
+	duc.PostRenderer = h.postRenderer
+	h.dryRunUpgrader = duc
+
 	// Uninstall Client
 	unc := action.NewUninstall(actionConfig)
 	unc.Wait = h.wait
@@ -323,6 +336,66 @@ func NewManager(config *rest.Config, chartName string, repoURL *url.URL, modifie
 	return h, nil
 }
 
+// newPuller builds the client used to pull charts.
+func (h *Installer) newPuller() helmPuller {
+	if h.oci {
+		return newRegistryPuller(withRemoteOpts(remote.WithAuth(&authn.Basic{
+			Username: h.username,
+			Password: h.password,
+		})), withRepoURL(h.repoURL))
+	}
+	// TODO(hasheddan): we currently use our own OCI client instead of the
+	// upstream Helm support.
+	p := action.NewPullWithOpts(action.WithConfig(&action.Configuration{}))
+	p.DestDir = h.cacheDir
+	p.Username = h.username
+	p.Password = h.password
+	p.Devel = true
+	p.Username = h.username
+	p.Password = h.password
+	p.Settings = &cli.EnvSettings{}
+	p.RepoURL = h.repoURL.String()
+	return &puller{p}
+}
+
+// PullChart pulls a version of a chart into dir without connecting to a
+// cluster, and returns the path of the chart archive. Modifiers other than
+// WithBasicAuth and IsOCI are ignored.
+func PullChart(chartName, version string, repoURL *url.URL, dir string, modifiers ...InstallerModifierFn) (string, error) {
+	h := &Installer{
+		repoURL:   repoURL,
+		chartName: chartName,
+		cacheDir:  dir,
+		fs:        afero.NewOsFs(),
+	}
+	for _, m := range modifiers {
+		m(h)
+	}
+	tmp, err := afero.TempDir(h.fs, dir, "")
+	if err != nil {
+		return "", err
+	}
+	defer h.fs.RemoveAll(tmp) //nolint:errcheck
+
+	h.pullClient = h.newPuller()
+	h.pullClient.SetDestDir(tmp)
+	if err := h.pullChart(version); err != nil {
+		return "", errors.Wrap(err, errPullChart)
+	}
+	files, err := afero.ReadDir(h.fs, tmp)
+	if err != nil {
+		return "", errors.Wrap(err, errGetLatestPulled)
+	}
+	if len(files) != 1 {
+		return "", errors.Errorf(errCorruptTempDirFmt, tmp)
+	}
+	path := filepath.Join(dir, files[0].Name())
+	if err := h.fs.Rename(filepath.Join(tmp, files[0].Name()), path); err != nil {
+		return "", errors.Wrap(err, errMoveLatest)
+	}
+	return path, nil
+}
+
 // GetCurrentVersion gets the current UXP version in the cluster.
 func (h *Installer) GetCurrentVersion() (string, error) {
 	var release *release.Release
@@ -348,15 +421,64 @@ func (h *Installer) GetCurrentVersion() (string, error) {
 	return release.Chart.Metadata.Version, nil
 }
 
+// GetValues gets the values of the current release in the cluster, i.e. the
+// chart's default values merged with the values supplied on install or
+// upgrade.
+func (h *Installer) GetValues() (map[string]any, error) {
+	release, err := h.getClient.Run(h.chartName)
+	if err != nil {
+		return nil, errors.Wrapf(err, errGetInstalledReleaseFmt, h.chartName, h.namespace)
+	}
+	if release == nil {
+		return nil, errors.New(errGetValues)
+	}
+	if release.Chart == nil {
+		return release.Config, nil
+	}
+	values, err := chartutil.CoalesceValues(release.Chart, release.Config)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetValues)
+	}
+	return values.AsMap(), nil
+}
+
 // Install installs in the cluster.
 func (h *Installer) Install(version string, parameters map[string]any, opts ...install.InstallOption) error {
+	helmChart, err := h.prepareInstall(version, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = h.installClient.Run(helmChart, parameters)
+	return err
+}
+
+// PlanInstall renders an install without changing the cluster.
+func (h *Installer) PlanInstall(version string, parameters map[string]any, opts ...install.InstallOption) (*install.Plan, error) {
+	helmChart, err := h.prepareInstall(version, opts...)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := h.dryRunInstaller.Run(helmChart, parameters)
+	if err != nil {
+		return nil, errors.Wrap(err, errRenderChart)
+	}
+	return &install.Plan{
+		Version:  helmChart.Metadata.Version,
+		Values:   parameters,
+		Manifest: rel.Manifest,
+	}, nil
+}
+
+// prepareInstall verifies that no version is installed and loads the chart to
+// install.
+func (h *Installer) prepareInstall(version string, opts ...install.InstallOption) (*chart.Chart, error) {
 	// make sure no version is already installed
 	current, err := h.GetCurrentVersion()
 	if err == nil {
-		return errors.Errorf(errChartAlreadyInstalledFmt, current)
+		return nil, errors.Errorf(errChartAlreadyInstalledFmt, current)
 	}
 	if !errors.Is(err, driver.ErrReleaseNotFound) {
-		return errors.Wrap(err, errVerifyChartNotInstalled)
+		return nil, errors.Wrap(err, errVerifyChartNotInstalled)
 	}
 
 	var helmChart *chart.Chart
@@ -371,28 +493,67 @@ func (h *Installer) Install(version string, parameters map[string]any, opts ...i
 		helmChart, err = h.load(h.chartFile.Name())
 	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	for _, o := range opts {
 		if err := o(helmChart); err != nil {
-			return err
+			return nil, err
 		}
 	}
-
-	_, err = h.installClient.Run(helmChart, parameters)
-	return err
+	return helmChart, nil
 }
 
 // Upgrade upgrades an existing installation to a new version.
-func (h *Installer) Upgrade(version string, parameters map[string]any, opts ...install.UpgradeOption) error { //nolint:gocyclo // looks still sane
+func (h *Installer) Upgrade(version string, parameters map[string]any, opts ...install.UpgradeOption) error {
+	_, helmChart, err := h.prepareUpgrade(version, opts...)
+	if err != nil {
+		return err
+	}
+
+	_, upErr := h.upgradeClient.Run(h.releaseName, helmChart, parameters)
+	if upErr != nil && h.rollbackOnError {
+		if rErr := h.rollbackClient.Run(h.releaseName); rErr != nil {
+			return errors.Wrap(rErr, errFailedUpgradeFailedRollback)
+		}
+		return errors.Wrap(upErr, errFailedUpgradeRollback)
+	}
+	return upErr
+}
+
+// PlanUpgrade renders an upgrade without changing the cluster.
+func (h *Installer) PlanUpgrade(version string, parameters map[string]any, opts ...install.UpgradeOption) (*install.Plan, error) {
+	current, helmChart, err := h.prepareUpgrade(version, opts...)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := h.getClient.Run(h.releaseName)
+	if err != nil {
+		return nil, errors.Wrapf(err, errGetInstalledReleaseFmt, h.releaseName, h.namespace)
+	}
+	upgraded, err := h.dryRunUpgrader.Run(h.releaseName, helmChart, parameters)
+	if err != nil {
+		return nil, errors.Wrap(err, errRenderChart)
+	}
+	return &install.Plan{
+		CurrentVersion:  current,
+		Version:         helmChart.Metadata.Version,
+		Values:          parameters,
+		CurrentManifest: rel.Manifest,
+		Manifest:        upgraded.Manifest,
+	}, nil
+}
+
+// prepareUpgrade verifies that a version is installed and loads the chart to
+// upgrade to. It returns the currently installed version and the chart.
+func (h *Installer) prepareUpgrade(version string, opts ...install.UpgradeOption) (string, *chart.Chart, error) {
 	// check if version exists
 	current, err := h.GetCurrentVersion()
 	if err != nil {
-		return err
+		return "", nil, err
 	}
 	if h.releaseName == h.alternateChart && !equivalentVersions(current, version) && !h.force {
-		return errors.Errorf(errUpgradeFromAlternateVersionFmt, h.alternateChart, h.chartName)
+		return "", nil, errors.Errorf(errUpgradeFromAlternateVersionFmt, h.alternateChart, h.chartName)
 	}
 
 	var helmChart *chart.Chart
@@ -406,23 +567,15 @@ func (h *Installer) Upgrade(version string, parameters map[string]any, opts ...i
 		helmChart, err = h.load(h.chartFile.Name())
 	}
 	if err != nil {
-		return err
+		return "", nil, err
 	}
 
 	for _, o := range opts {
 		if err := o(current, helmChart); err != nil {
-			return err
-		}
-	}
-
-	_, upErr := h.upgradeClient.Run(h.releaseName, helmChart, parameters)
-	if upErr != nil && h.rollbackOnError {
-		if rErr := h.rollbackClient.Run(h.releaseName); rErr != nil {
-			return errors.Wrap(rErr, errFailedUpgradeFailedRollback)
+			return "", nil, err
 		}
-		return errors.Wrap(upErr, errFailedUpgradeRollback)
 	}
-	return upErr
+	return current, helmChart, nil
 }
 
 // Uninstall uninstalls an installation.
@@ -431,6 +584,11 @@ func (h *Installer) Uninstall() error {
 	return err
 }
 
+// Rollback rolls an installation back to its previous release.
+func (h *Installer) Rollback() error {
+	return h.rollbackClient.Run(h.releaseName)
+}
+
 // pullAndLoad pulls and loads a chart or fetches it from the cache.
 func (h *Installer) pullAndLoad(version string) (*chart.Chart, error) { //nolint:gocyclo
 	// check to see if version is cached
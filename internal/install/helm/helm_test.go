@@ -25,6 +25,8 @@ import (
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage/driver"
+
+	"github.com/upbound/up/internal/install"
 )
 
 type mockGetClient struct {
@@ -190,6 +192,86 @@ func TestGetCurrentVersion(t *testing.T) {
 	}
 }
 
+func TestGetValues(t *testing.T) {
+	errBoom := errors.New("boom")
+	chartName := "primary-chart"
+	cases := map[string]struct {
+		reason    string
+		installer *Installer
+		values    map[string]any
+		err       error
+	}{
+		"ErrorGetRelease": {
+			reason: "If unable to get release an error should be returned.",
+			installer: &Installer{
+				namespace: "test",
+				chartName: chartName,
+				getClient: &mockGetClient{
+					runFn: func(string) (*release.Release, error) {
+						return nil, errBoom
+					},
+				},
+			},
+			err: errors.Wrapf(errBoom, errGetInstalledReleaseFmt, chartName, "test"),
+		},
+		"NoChart": {
+			reason: "If the release has no chart the supplied values should be returned.",
+			installer: &Installer{
+				getClient: &mockGetClient{
+					runFn: func(string) (*release.Release, error) {
+						return &release.Release{
+							Config: map[string]any{"account": "acct"},
+						}, nil
+					},
+				},
+			},
+			values: map[string]any{"account": "acct"},
+		},
+		"Successful": {
+			reason: "If successful the supplied values merged with the chart's defaults should be returned.",
+			installer: &Installer{
+				getClient: &mockGetClient{
+					runFn: func(string) (*release.Release, error) {
+						return &release.Release{
+							Chart: &chart.Chart{
+								Metadata: &chart.Metadata{Name: chartName},
+								Values: map[string]any{
+									"account": "default",
+									"billing": map[string]any{
+										"enabled": false,
+									},
+								},
+							},
+							Config: map[string]any{
+								"billing": map[string]any{
+									"enabled": true,
+								},
+							},
+						}, nil
+					},
+				},
+			},
+			values: map[string]any{
+				"account": "default",
+				"billing": map[string]any{
+					"enabled": true,
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v, err := tc.installer.GetValues()
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGetValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.values, v); diff != "" {
+				t.Errorf("\n%s\nGetValues(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestInstall(t *testing.T) {
 	errBoom := errors.New("boom")
 	chartName := "primary-chart"
@@ -314,6 +396,212 @@ func TestInstall(t *testing.T) {
 	}
 }
 
+func TestPlanInstall(t *testing.T) {
+	errBoom := errors.New("boom")
+	ch := &chart.Chart{Metadata: &chart.Metadata{Version: "real-version"}}
+	values := map[string]any{"account": "acct"}
+	fsSetup := func() afero.Fs {
+		fs := afero.NewMemMapFs()
+		f, _ := fs.Create("test-real-version.tgz")
+		_ = f.Close()
+		return fs
+	}
+	cases := map[string]struct {
+		reason    string
+		installer *Installer
+		plan      *install.Plan
+		err       error
+	}{
+		"ErrorAlreadyInstalled": {
+			reason: "If the chart is already installed an error should be returned.",
+			installer: &Installer{
+				getClient: &mockGetClient{
+					runFn: func(string) (*release.Release, error) {
+						return &release.Release{Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "old-version"}}}, nil
+					},
+				},
+			},
+			err: errors.Errorf(errChartAlreadyInstalledFmt, "old-version"),
+		},
+		"ErrorRender": {
+			reason: "If unable to render the chart an error should be returned.",
+			installer: &Installer{
+				getClient: &mockGetClient{
+					runFn: func(string) (*release.Release, error) {
+						return nil, driver.ErrReleaseNotFound
+					},
+				},
+				dryRunInstaller: &mockInstallClient{
+					runFn: func(*chart.Chart, map[string]any) (*release.Release, error) {
+						return nil, errBoom
+					},
+				},
+				cacheDir:  "/",
+				chartName: "test",
+				load: func(string) (*chart.Chart, error) {
+					return ch, nil
+				},
+			},
+			err: errors.Wrap(errBoom, errRenderChart),
+		},
+		"Successful": {
+			reason: "A successful dry run should return the rendered manifest.",
+			installer: &Installer{
+				getClient: &mockGetClient{
+					runFn: func(string) (*release.Release, error) {
+						return nil, driver.ErrReleaseNotFound
+					},
+				},
+				installClient: &mockInstallClient{
+					runFn: func(*chart.Chart, map[string]any) (*release.Release, error) {
+						return nil, errors.New("install must not be called")
+					},
+				},
+				dryRunInstaller: &mockInstallClient{
+					runFn: func(*chart.Chart, map[string]any) (*release.Release, error) {
+						return &release.Release{Manifest: "manifest"}, nil
+					},
+				},
+				cacheDir:  "/",
+				chartName: "test",
+				load: func(string) (*chart.Chart, error) {
+					return ch, nil
+				},
+			},
+			plan: &install.Plan{
+				Version:  "real-version",
+				Values:   values,
+				Manifest: "manifest",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tc.installer.fs = fsSetup()
+			tc.installer.pullClient = &mockPullClient{
+				runFn: func(string) (string, error) {
+					return "", nil
+				},
+			}
+			plan, err := tc.installer.PlanInstall("real-version", values)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nPlanInstall(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.plan, plan); diff != "" {
+				t.Errorf("\n%s\nPlanInstall(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPlanUpgrade(t *testing.T) {
+	errBoom := errors.New("boom")
+	ch := &chart.Chart{Metadata: &chart.Metadata{Version: "real-version"}}
+	current := &release.Release{
+		Chart:    &chart.Chart{Metadata: &chart.Metadata{Version: "old-version"}},
+		Manifest: "old-manifest",
+	}
+	values := map[string]any{"account": "acct"}
+	fsSetup := func() afero.Fs {
+		fs := afero.NewMemMapFs()
+		f, _ := fs.Create("test-real-version.tgz")
+		_ = f.Close()
+		return fs
+	}
+	cases := map[string]struct {
+		reason    string
+		installer *Installer
+		plan      *install.Plan
+		err       error
+	}{
+		"ErrorNotInstalled": {
+			reason: "If the chart is not installed an error should be returned.",
+			installer: &Installer{
+				namespace: "test",
+				chartName: "test",
+				getClient: &mockGetClient{
+					runFn: func(string) (*release.Release, error) {
+						return nil, driver.ErrReleaseNotFound
+					},
+				},
+			},
+			err: errors.Wrapf(driver.ErrReleaseNotFound, errGetInstalledReleaseFmt, "test", "test"),
+		},
+		"ErrorRender": {
+			reason: "If unable to render the chart an error should be returned.",
+			installer: &Installer{
+				getClient: &mockGetClient{
+					runFn: func(string) (*release.Release, error) {
+						return current, nil
+					},
+				},
+				dryRunUpgrader: &mockUpgradeClient{
+					runFn: func(string, *chart.Chart, map[string]any) (*release.Release, error) {
+						return nil, errBoom
+					},
+				},
+				cacheDir:    "/",
+				chartName:   "test",
+				releaseName: "test",
+				load: func(string) (*chart.Chart, error) {
+					return ch, nil
+				},
+			},
+			err: errors.Wrap(errBoom, errRenderChart),
+		},
+		"Successful": {
+			reason: "A successful dry run should return the current and the rendered manifest.",
+			installer: &Installer{
+				getClient: &mockGetClient{
+					runFn: func(string) (*release.Release, error) {
+						return current, nil
+					},
+				},
+				upgradeClient: &mockUpgradeClient{
+					runFn: func(string, *chart.Chart, map[string]any) (*release.Release, error) {
+						return nil, errors.New("upgrade must not be called")
+					},
+				},
+				dryRunUpgrader: &mockUpgradeClient{
+					runFn: func(string, *chart.Chart, map[string]any) (*release.Release, error) {
+						return &release.Release{Manifest: "manifest"}, nil
+					},
+				},
+				cacheDir:    "/",
+				chartName:   "test",
+				releaseName: "test",
+				load: func(string) (*chart.Chart, error) {
+					return ch, nil
+				},
+			},
+			plan: &install.Plan{
+				CurrentVersion:  "old-version",
+				Version:         "real-version",
+				Values:          values,
+				CurrentManifest: "old-manifest",
+				Manifest:        "manifest",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tc.installer.fs = fsSetup()
+			tc.installer.pullClient = &mockPullClient{
+				runFn: func(string) (string, error) {
+					return "", nil
+				},
+			}
+			plan, err := tc.installer.PlanUpgrade("real-version", values)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nPlanUpgrade(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.plan, plan); diff != "" {
+				t.Errorf("\n%s\nPlanUpgrade(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestUpgrade(t *testing.T) {
 	errBoom := errors.New("boom")
 	chartName := "primary-chart"
@@ -878,6 +1166,48 @@ func TestUninstall(t *testing.T) {
 	}
 }
 
+func TestRollback(t *testing.T) {
+	errBoom := errors.New("boom")
+	cases := map[string]struct {
+		reason    string
+		installer *Installer
+		err       error
+	}{
+		"Error": {
+			reason: "Should return error if rollback fails.",
+			installer: &Installer{
+				rollbackClient: &mockRollbackClient{
+					runFn: func(string) error {
+						return errBoom
+					},
+				},
+			},
+			err: errBoom,
+		},
+		"Successful": {
+			reason: "Should roll back the release of the installation.",
+			installer: &Installer{
+				releaseName: "spaces",
+				rollbackClient: &mockRollbackClient{
+					runFn: func(r string) error {
+						if r != "spaces" {
+							return errors.Errorf("unexpected release %q", r)
+						}
+						return nil
+					},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.err, tc.installer.Rollback(), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRollback(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestEquivalentVersions(t *testing.T) {
 	cases := map[string]struct {
 		reason  string
@@ -912,3 +1242,26 @@ func TestEquivalentVersions(t *testing.T) {
 		})
 	}
 }
+
+func TestRender(t *testing.T) {
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion:  "v2",
+			Name:        "test",
+			Version:     "1.0.0",
+			KubeVersion: ">=1.25.0-0",
+		},
+		Templates: []*chart.File{{
+			Name: "templates/cm.yaml",
+			Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Release.Name }}\n  namespace: {{ .Release.Namespace }}\ndata:\n  key: {{ .Values.key }}\n"),
+		}},
+	}
+	want := "---\n# Source: test/templates/cm.yaml\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\n  namespace: ns\ndata:\n  key: value\n"
+	got, err := Render(ch, "ns", map[string]any{"key": "value"})
+	if err != nil {
+		t.Fatalf("Render(...): %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Render(...): -want, +got:\n%s", diff)
+	}
+}
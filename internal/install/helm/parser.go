@@ -17,6 +17,7 @@ package helm
 import (
 	"fmt"
 
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/strvals"
 
 	"github.com/upbound/up/internal/install"
@@ -45,3 +46,26 @@ func (p *Parser) Parse() (map[string]any, error) {
 	}
 	return p.values, nil
 }
+
+// MergeValues merges overrides over base the way Helm merges values files:
+// nested maps are merged, other values in overrides replace those in base, and
+// null values in overrides remove keys from base. Neither map is modified.
+func MergeValues(base, overrides map[string]any) map[string]any {
+	if len(overrides) == 0 {
+		return base
+	}
+	return chartutil.CoalesceTables(copyTables(overrides), base)
+}
+
+// copyTables copies the nested maps of values, which are modified when they
+// are merged.
+func copyTables(values map[string]any) map[string]any {
+	out := make(map[string]any, len(values))
+	for k, v := range values {
+		if m, ok := v.(map[string]any); ok {
+			v = copyTables(m)
+		}
+		out[k] = v
+	}
+	return out
+}
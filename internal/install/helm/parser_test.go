@@ -86,3 +86,60 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeValues(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		base      map[string]any
+		overrides map[string]any
+		want      map[string]any
+	}{
+		"NoOverrides": {
+			reason: "If no overrides are provided the base should be returned.",
+			base:   map[string]any{"test": "value"},
+			want:   map[string]any{"test": "value"},
+		},
+		"NestedOverrides": {
+			reason: "Nested maps should be merged and overrides should take precedence.",
+			base: map[string]any{
+				"test": "value",
+				"other": map[string]any{
+					"nested": "something",
+					"kept":   true,
+				},
+			},
+			overrides: map[string]any{
+				"other": map[string]any{
+					"nested": "somethingElse",
+				},
+			},
+			want: map[string]any{
+				"test": "value",
+				"other": map[string]any{
+					"nested": "somethingElse",
+					"kept":   true,
+				},
+			},
+		},
+		"NullOverride": {
+			reason: "A null override should remove the key from the base.",
+			base:   map[string]any{"test": "value", "other": "value"},
+			overrides: map[string]any{
+				"other": nil,
+			},
+			want: map[string]any{"test": "value"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			overrides := copyTables(tc.overrides)
+			got := MergeValues(tc.base, tc.overrides)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nMergeValues(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(overrides, copyTables(tc.overrides)); diff != "" {
+				t.Errorf("\n%s\nMergeValues(...): overrides were modified: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
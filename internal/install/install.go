@@ -14,7 +14,11 @@
 
 package install
 
-import "helm.sh/helm/v3/pkg/chart"
+import (
+	"net/url"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
 
 // InstallOption customizes the behavior of an install.
 type InstallOption func(*chart.Chart) error
@@ -26,9 +30,43 @@ type UpgradeOption func(oldVersion string, ch *chart.Chart) error
 // TODO(hasheddan): support custom error types, such as AlreadyExists.
 type Manager interface {
 	GetCurrentVersion() (string, error)
+	GetValues() (map[string]any, error)
 	Install(version string, parameters map[string]any, opts ...InstallOption) error
 	Upgrade(version string, parameters map[string]any, opts ...UpgradeOption) error
 	Uninstall() error
+	// Rollback rolls an installation back to its previous release.
+	Rollback() error
+
+	// PlanInstall renders an install without changing the cluster.
+	PlanInstall(version string, parameters map[string]any, opts ...InstallOption) (*Plan, error)
+	// PlanUpgrade renders an upgrade without changing the cluster.
+	PlanUpgrade(version string, parameters map[string]any, opts ...UpgradeOption) (*Plan, error)
+}
+
+// Plan describes the changes an install or upgrade would make.
+type Plan struct {
+	// CurrentVersion is the currently installed version. Empty for installs.
+	CurrentVersion string
+	// Version is the version that would be installed.
+	Version string
+	// Values are the values supplied to the chart.
+	Values map[string]any
+	// CurrentManifest is the rendered manifest of the current release.
+	// Empty for installs.
+	CurrentManifest string
+	// Manifest is the rendered manifest that would be applied.
+	Manifest string
+}
+
+// ChartRef identifies a version of a chart in a repository and the values it
+// is installed with.
+type ChartRef struct {
+	Name    string
+	Version string
+	RepoURL *url.URL
+	// OCI indicates that RepoURL is an OCI registry.
+	OCI    bool
+	Values map[string]any
 }
 
 // ParameterParser parses install and upgrade parameters.
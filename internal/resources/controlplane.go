@@ -55,6 +55,16 @@ func (c *ControlPlane) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
 	return conditioned.GetCondition(ct)
 }
 
+// GetConditions returns all conditions of the ControlPlane.
+func (c *ControlPlane) GetConditions() []xpv1.Condition {
+	conditioned := xpv1.ConditionedStatus{}
+	// The path is directly `status` because conditions are inline.
+	if err := fieldpath.Pave(c.Object).GetValueInto("status", &conditioned); err != nil {
+		return nil
+	}
+	return conditioned.Conditions
+}
+
 // SetConditions of this composite resource claim.
 func (c *ControlPlane) SetConditions(conditions ...xpv1.Condition) {
 	conditioned := xpv1.ConditionedStatus{}
@@ -78,6 +88,21 @@ func (c *ControlPlane) SetControlPlaneID(id string) {
 	_ = fieldpath.Pave(c.Object).SetString("status.controlPlaneID", id)
 }
 
+// GetCrossplaneVersion returns the Crossplane version requested for the
+// ControlPlane, or an empty string if none is set.
+func (c *ControlPlane) GetCrossplaneVersion() string {
+	v, err := fieldpath.Pave(c.Object).GetString("spec.crossplane.version")
+	if err != nil {
+		return ""
+	}
+	return v
+}
+
+// SetCrossplaneVersion requests the Crossplane version for the ControlPlane.
+func (c *ControlPlane) SetCrossplaneVersion(v string) {
+	_ = fieldpath.Pave(c.Object).SetString("spec.crossplane.version", v)
+}
+
 // GetConnectionSecretToReference of this control plane.
 func (c *ControlPlane) GetConnectionSecretToReference() *xpv1.SecretReference {
 	out := &xpv1.SecretReference{}
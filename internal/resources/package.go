@@ -54,6 +54,12 @@ func (p *Package) GetHealthy() bool {
 	return resource.IsConditionTrue(conditioned.GetCondition("Healthy"))
 }
 
+// GetPackage returns the package reference.
+func (p *Package) GetPackage() string {
+	pkg, _ := fieldpath.Pave(p.Object).GetString("spec.package")
+	return pkg
+}
+
 // SetPackage sets the package reference.
 func (p *Package) SetPackage(pkg string) {
 	_ = fieldpath.Pave(p.Object).SetValue("spec.package", pkg)
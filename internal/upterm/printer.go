@@ -15,8 +15,10 @@
 package upterm
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"os"
 	"reflect"
 
 	"github.com/pterm/pterm"
@@ -28,7 +30,7 @@ import (
 
 // The ObjectPrinter is intended to make it easy to print individual structs
 // and lists of structs for the 'get' and 'list' commands. It can print as
-// a human-readable table, or computer-readable (JSON or YAML)
+// a human-readable table, or computer-readable (JSON, YAML or CSV)
 type ObjectPrinter struct {
 	Quiet  config.QuietFlag
 	Pretty bool
@@ -56,7 +58,7 @@ func init() {
 // names for those fields (used for column headers) and a function that can be called
 // on a single struct that returns those fields as strings.
 // When printing JSON or YAML, this will print *all* fields, regardless of
-// the list of fields.
+// the list of fields. CSV prints the same fields as the table.
 func (p *ObjectPrinter) Print(obj any, fieldNames []string, extractFields func(any) []string) error {
 	// Step 1: If user specified quiet, skip printing entirely
 	if p.Quiet {
@@ -75,6 +77,8 @@ func (p *ObjectPrinter) Print(obj any, fieldNames []string, extractFields func(a
 		return printJSON(obj)
 	case config.YAML:
 		return printYAML(obj)
+	case config.CSV:
+		return printCSV(obj, fieldNames, extractFields)
 	default:
 		return p.printDefault(obj, fieldNames, extractFields)
 	}
@@ -98,6 +102,25 @@ func printYAML(obj any) error {
 	return err
 }
 
+func printCSV(obj any, fieldNames []string, extractFields func(any) []string) error {
+	w := csv.NewWriter(os.Stdout)
+	if err := w.Write(fieldNames); err != nil {
+		return err
+	}
+	v := reflect.ValueOf(obj)
+	if k := v.Kind(); k == reflect.Array || k == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			if err := w.Write(extractFields(v.Index(i).Interface())); err != nil {
+				return err
+			}
+		}
+	} else if err := w.Write(extractFields(obj)); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
 func (p *ObjectPrinter) printDefault(obj any, fieldNames []string, extractFields func(any) []string) error {
 	t := reflect.TypeOf(obj)
 	k := t.Kind()
@@ -16,10 +16,13 @@ package aggregate
 
 import (
 	"fmt"
+	"sort"
+	"sync"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 
 	"github.com/upbound/up/internal/usage/model"
+	usagetime "github.com/upbound/up/internal/usage/time"
 )
 
 const (
@@ -27,6 +30,57 @@ const (
 	mrCountMaxUpboundEventName = "max_resource_count_per_gvk_per_mxp"
 )
 
+// Aggregator aggregates usage events recorded during a window of time into
+// Upbound usage events.
+type Aggregator interface {
+	// Add adds a usage event to the aggregate.
+	Add(model.MXPGVKEvent) error
+	// UpboundEvents returns the aggregated Upbound usage events.
+	UpboundEvents() []model.MXPGVKEvent
+}
+
+// NewFn returns an empty Aggregator for a window of time.
+type NewFn func(window usagetime.Range) Aggregator
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]NewFn{}
+)
+
+// Register makes an aggregation available by name. Registering an existing
+// name replaces the aggregation.
+func Register(name string, fn NewFn) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = fn
+}
+
+// Lookup returns the aggregation registered with the supplied name.
+func Lookup(name string) (NewFn, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	fn, ok := registry[name]
+	return fn, ok
+}
+
+// Names returns the names of all registered aggregations in lexical order.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for n := range registry {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Register(mrCountMaxUpboundEventName, func(usagetime.Range) Aggregator {
+		return &MaxResourceCountPerGVKPerMXP{}
+	})
+}
+
 type mxpGVK struct {
 	MXPID   string
 	Group   string
@@ -42,7 +96,7 @@ type MaxResourceCountPerGVKPerMXP struct {
 
 // Add adds a usage event to the aggregate.
 func (ag *MaxResourceCountPerGVKPerMXP) Add(e model.MXPGVKEvent) error {
-	if err := ag.validateEvent(e); err != nil {
+	if err := validateMRCountEvent(e); err != nil {
 		return err
 	}
 
@@ -83,7 +137,9 @@ func (ag *MaxResourceCountPerGVKPerMXP) UpboundEvents() []model.MXPGVKEvent {
 	return events
 }
 
-func (ag *MaxResourceCountPerGVKPerMXP) validateEvent(e model.MXPGVKEvent) error {
+// validateMRCountEvent returns an error if the supplied event is not a valid
+// managed resource count event.
+func validateMRCountEvent(e model.MXPGVKEvent) error {
 	if e.Name != mrCountUpboundEventName {
 		return fmt.Errorf("expected event name %s, got %s", mrCountUpboundEventName, e.Name)
 	}
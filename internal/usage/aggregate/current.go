@@ -0,0 +1,97 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"sort"
+	"time"
+
+	"github.com/upbound/up/internal/usage/model"
+)
+
+// ResourceCount is the current and peak number of managed resources of a GVK
+// in an MXP.
+type ResourceCount struct {
+	MXPID    string    `json:"mxp_id"`
+	Group    string    `json:"group"`
+	Version  string    `json:"version"`
+	Kind     string    `json:"kind"`
+	Current  int       `json:"current"`
+	Peak     int       `json:"peak"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// CurrentResourceCountPerGVKPerMXP tracks the most recent and the peak
+// recorded GVK counts per MXP. Unlike an Aggregator it is not bound to a
+// window, events from any number of windows can be added.
+type CurrentResourceCountPerGVKPerMXP struct {
+	counts map[mxpGVK]*ResourceCount
+}
+
+// Add adds a usage event. The count of the most recent event of each GVK and
+// MXP is the current count. Events with the same timestamp replace each other
+// in the order they are added.
+func (ag *CurrentResourceCountPerGVKPerMXP) Add(e model.MXPGVKEvent) error {
+	if err := validateMRCountEvent(e); err != nil {
+		return err
+	}
+
+	key := mxpGVK{
+		MXPID:   e.Tags.MXPID,
+		Group:   e.Tags.Group,
+		Version: e.Tags.Version,
+		Kind:    e.Tags.Kind,
+	}
+	if ag.counts == nil {
+		ag.counts = make(map[mxpGVK]*ResourceCount)
+	}
+	rc, ok := ag.counts[key]
+	if !ok {
+		rc = &ResourceCount{MXPID: key.MXPID, Group: key.Group, Version: key.Version, Kind: key.Kind}
+		ag.counts[key] = rc
+	}
+
+	value := int(e.Value)
+	if !e.Timestamp.Before(rc.LastSeen) {
+		rc.Current = value
+		rc.LastSeen = e.Timestamp
+	}
+	if value > rc.Peak {
+		rc.Peak = value
+	}
+	return nil
+}
+
+// ResourceCounts returns the resource counts sorted by MXP and GVK.
+func (ag *CurrentResourceCountPerGVKPerMXP) ResourceCounts() []ResourceCount {
+	counts := make([]ResourceCount, 0, len(ag.counts))
+	for _, rc := range ag.counts {
+		counts = append(counts, *rc)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		a, b := counts[i], counts[j]
+		if a.MXPID != b.MXPID {
+			return a.MXPID < b.MXPID
+		}
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Version < b.Version
+	})
+	return counts
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/usage/model"
+)
+
+func TestCurrentResourceCountPerGVKPerMXP(t *testing.T) {
+	at := func(e model.MXPGVKEvent, hour int) model.MXPGVKEvent {
+		e.Timestamp = time.Date(2023, 1, 1, hour, 0, 0, 0, time.UTC)
+		return e
+	}
+
+	type want struct {
+		counts []ResourceCount
+		err    bool
+	}
+	cases := map[string]struct {
+		reason string
+		events []model.MXPGVKEvent
+		want   want
+	}{
+		"Empty": {
+			reason: "No events should result in no counts.",
+			want: want{
+				counts: []ResourceCount{},
+			},
+		},
+		"CurrentAndPeak": {
+			reason: "The most recent count should be current and the largest count the peak.",
+			events: []model.MXPGVKEvent{
+				at(mrCountEvent("mxp-2", "example.com", "Thing", 3), 0),
+				at(mrCountEvent("mxp-1", "example.com", "Thing", 2), 0),
+				at(mrCountEvent("mxp-1", "example.com", "Thing", 12), 1),
+				at(mrCountEvent("mxp-1", "example.com", "Thing", 4), 3),
+				// Out of order events do not replace the current count.
+				at(mrCountEvent("mxp-1", "example.com", "Thing", 6), 2),
+				at(mrCountEvent("mxp-1", "example.com", "Other", 1), 1),
+			},
+			want: want{
+				counts: []ResourceCount{
+					{MXPID: "mxp-1", Group: "example.com", Version: "v1", Kind: "Other", Current: 1, Peak: 1, LastSeen: time.Date(2023, 1, 1, 1, 0, 0, 0, time.UTC)},
+					{MXPID: "mxp-1", Group: "example.com", Version: "v1", Kind: "Thing", Current: 4, Peak: 12, LastSeen: time.Date(2023, 1, 1, 3, 0, 0, 0, time.UTC)},
+					{MXPID: "mxp-2", Group: "example.com", Version: "v1", Kind: "Thing", Current: 3, Peak: 3, LastSeen: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+				},
+			},
+		},
+		"SameTimestamp": {
+			reason: "Events with the same timestamp should replace each other in order.",
+			events: []model.MXPGVKEvent{
+				mrCountEvent("mxp-1", "example.com", "Thing", 5),
+				mrCountEvent("mxp-1", "example.com", "Thing", 2),
+			},
+			want: want{
+				counts: []ResourceCount{
+					{MXPID: "mxp-1", Group: "example.com", Version: "v1", Kind: "Thing", Current: 2, Peak: 5},
+				},
+			},
+		},
+		"InvalidEvent": {
+			reason: "An event that is not a managed resource count should return an error.",
+			events: []model.MXPGVKEvent{
+				{Name: "other"},
+			},
+			want: want{
+				counts: []ResourceCount{},
+				err:    true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ag := &CurrentResourceCountPerGVKPerMXP{}
+			var err error
+			for _, e := range tc.events {
+				if err = ag.Add(e); err != nil {
+					break
+				}
+			}
+			if diff := cmp.Diff(tc.want.err, err != nil); diff != "" {
+				t.Errorf("\n%s\nAdd(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.counts, ag.ResourceCounts()); diff != "" {
+				t.Errorf("\n%s\nResourceCounts(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"math"
+	"sort"
+
+	"github.com/upbound/up/internal/usage/model"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+const (
+	mrCountAvgUpboundEventName      = "avg_resource_count_per_gvk_per_mxp"
+	mrCountP95UpboundEventName      = "p95_resource_count_per_gvk_per_mxp"
+	mrHoursUpboundEventName         = "resource_hours_per_mxp"
+	mrCountMaxGroupUpboundEventName = "max_resource_count_per_group"
+)
+
+func init() {
+	Register(mrCountAvgUpboundEventName, func(usagetime.Range) Aggregator {
+		return &AvgResourceCountPerGVKPerMXP{}
+	})
+	Register(mrCountP95UpboundEventName, func(usagetime.Range) Aggregator {
+		return &P95ResourceCountPerGVKPerMXP{}
+	})
+	Register(mrHoursUpboundEventName, func(window usagetime.Range) Aggregator {
+		return &ResourceHoursPerMXP{Window: window}
+	})
+	Register(mrCountMaxGroupUpboundEventName, func(usagetime.Range) Aggregator {
+		return &MaxResourceCountPerGroup{}
+	})
+}
+
+var (
+	_ Aggregator = &MaxResourceCountPerGVKPerMXP{}
+	_ Aggregator = &AvgResourceCountPerGVKPerMXP{}
+	_ Aggregator = &P95ResourceCountPerGVKPerMXP{}
+	_ Aggregator = &ResourceHoursPerMXP{}
+	_ Aggregator = &MaxResourceCountPerGroup{}
+)
+
+// samplesPerGVKPerMXP records all resource counts per GVK per MXP. Negative
+// counts are ignored.
+type samplesPerGVKPerMXP struct {
+	samples map[mxpGVK][]float64
+}
+
+func (s *samplesPerGVKPerMXP) add(e model.MXPGVKEvent) error {
+	if err := validateMRCountEvent(e); err != nil {
+		return err
+	}
+	if e.Value < 0 {
+		return nil
+	}
+	key := mxpGVK{
+		MXPID:   e.Tags.MXPID,
+		Group:   e.Tags.Group,
+		Version: e.Tags.Version,
+		Kind:    e.Tags.Kind,
+	}
+	if s.samples == nil {
+		s.samples = make(map[mxpGVK][]float64)
+	}
+	s.samples[key] = append(s.samples[key], e.Value)
+	return nil
+}
+
+// events returns an event with the supplied name for each GVK and MXP that
+// had at least one resource, with its value computed by fn.
+func (s *samplesPerGVKPerMXP) events(name string, fn func([]float64) float64) []model.MXPGVKEvent {
+	events := []model.MXPGVKEvent{}
+	for key, samples := range s.samples {
+		if maxSample(samples) <= 0 {
+			continue
+		}
+		events = append(events, model.MXPGVKEvent{
+			Name:  name,
+			Value: fn(samples),
+			Tags: model.MXPGVKEventTags{
+				MXPID:   key.MXPID,
+				Group:   key.Group,
+				Version: key.Version,
+				Kind:    key.Kind,
+			},
+		})
+	}
+	return events
+}
+
+// AvgResourceCountPerGVKPerMXP aggregates the average recorded GVK counts per
+// MXP from Upbound usage events.
+type AvgResourceCountPerGVKPerMXP struct {
+	samplesPerGVKPerMXP
+}
+
+// Add adds a usage event to the aggregate.
+func (ag *AvgResourceCountPerGVKPerMXP) Add(e model.MXPGVKEvent) error {
+	return ag.add(e)
+}
+
+// UpboundEvents returns an Upbound usage event for each combination of MXP and
+// GVK.
+func (ag *AvgResourceCountPerGVKPerMXP) UpboundEvents() []model.MXPGVKEvent {
+	return ag.events(mrCountAvgUpboundEventName, avg)
+}
+
+// P95ResourceCountPerGVKPerMXP aggregates the 95th percentile of recorded GVK
+// counts per MXP from Upbound usage events.
+type P95ResourceCountPerGVKPerMXP struct {
+	samplesPerGVKPerMXP
+}
+
+// Add adds a usage event to the aggregate.
+func (ag *P95ResourceCountPerGVKPerMXP) Add(e model.MXPGVKEvent) error {
+	return ag.add(e)
+}
+
+// UpboundEvents returns an Upbound usage event for each combination of MXP and
+// GVK.
+func (ag *P95ResourceCountPerGVKPerMXP) UpboundEvents() []model.MXPGVKEvent {
+	return ag.events(mrCountP95UpboundEventName, func(samples []float64) float64 {
+		return percentile(samples, 95)
+	})
+}
+
+// ResourceHoursPerMXP aggregates the resource hours per MXP during a window
+// of time from Upbound usage events. The resource hours of a GVK are its
+// average recorded count multiplied by the length of the window in hours.
+type ResourceHoursPerMXP struct {
+	Window usagetime.Range
+	samplesPerGVKPerMXP
+}
+
+// Add adds a usage event to the aggregate.
+func (ag *ResourceHoursPerMXP) Add(e model.MXPGVKEvent) error {
+	return ag.add(e)
+}
+
+// UpboundEvents returns an Upbound usage event for each MXP.
+func (ag *ResourceHoursPerMXP) UpboundEvents() []model.MXPGVKEvent {
+	hours := ag.Window.End.Sub(ag.Window.Start).Hours()
+	perMXP := map[string]float64{}
+	for key, samples := range ag.samples {
+		perMXP[key.MXPID] += avg(samples) * hours
+	}
+	events := []model.MXPGVKEvent{}
+	for mxp, v := range perMXP {
+		if v <= 0 {
+			continue
+		}
+		events = append(events, model.MXPGVKEvent{
+			Name:  mrHoursUpboundEventName,
+			Value: v,
+			Tags:  model.MXPGVKEventTags{MXPID: mxp},
+		})
+	}
+	return events
+}
+
+// MaxResourceCountPerGroup aggregates the total of the maximum recorded GVK
+// counts of all MXPs per API group from Upbound usage events.
+type MaxResourceCountPerGroup struct {
+	max MaxResourceCountPerGVKPerMXP
+}
+
+// Add adds a usage event to the aggregate.
+func (ag *MaxResourceCountPerGroup) Add(e model.MXPGVKEvent) error {
+	return ag.max.Add(e)
+}
+
+// UpboundEvents returns an Upbound usage event for each API group.
+func (ag *MaxResourceCountPerGroup) UpboundEvents() []model.MXPGVKEvent {
+	perGroup := map[string]int{}
+	for key, count := range ag.max.counts {
+		perGroup[key.Group] += count
+	}
+	events := []model.MXPGVKEvent{}
+	for group, count := range perGroup {
+		events = append(events, model.MXPGVKEvent{
+			Name:  mrCountMaxGroupUpboundEventName,
+			Value: float64(count),
+			Tags:  model.MXPGVKEventTags{Group: group},
+		})
+	}
+	return events
+}
+
+func avg(samples []float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, s := range samples {
+		sum += s
+	}
+	return sum / float64(len(samples))
+}
+
+func maxSample(samples []float64) float64 {
+	m := 0.0
+	for _, s := range samples {
+		m = math.Max(m, s)
+	}
+	return m
+}
+
+// percentile returns the p-th percentile of samples using the nearest-rank
+// method.
+func percentile(samples []float64, p float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
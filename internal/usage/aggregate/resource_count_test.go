@@ -0,0 +1,169 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/usage/model"
+	usagetesting "github.com/upbound/up/internal/usage/testing"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+func mrCountEvent(mxp, group, kind string, value float64) model.MXPGVKEvent {
+	return model.MXPGVKEvent{
+		Name:  "kube_managedresource_uid",
+		Value: value,
+		Tags: model.MXPGVKEventTags{
+			MXPID:   mxp,
+			Group:   group,
+			Version: "v1",
+			Kind:    kind,
+		},
+	}
+}
+
+func TestAggregatorUpboundEvents(t *testing.T) {
+	window := usagetime.Range{
+		Start: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2023, 1, 1, 2, 0, 0, 0, time.UTC),
+	}
+	events := []model.MXPGVKEvent{
+		mrCountEvent("mxp-1", "example.com", "Thing", 2),
+		mrCountEvent("mxp-1", "example.com", "Thing", 4),
+		mrCountEvent("mxp-1", "example.com", "Thing", 6),
+		mrCountEvent("mxp-1", "example.com", "Thing", 12),
+		mrCountEvent("mxp-1", "example.org", "Item", 1),
+		mrCountEvent("mxp-2", "example.com", "Thing", 3),
+		mrCountEvent("mxp-2", "example.com", "Other", 0),
+	}
+
+	type args struct {
+		name   string
+		events []model.MXPGVKEvent
+	}
+	type want struct {
+		events []model.MXPGVKEvent
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Avg": {
+			reason: "Upbound events should use the average added value for a resource count on an MXP, omitting GVKs without resources.",
+			args: args{
+				name:   "avg_resource_count_per_gvk_per_mxp",
+				events: events,
+			},
+			want: want{
+				events: []model.MXPGVKEvent{
+					{Name: "avg_resource_count_per_gvk_per_mxp", Value: 6, Tags: model.MXPGVKEventTags{MXPID: "mxp-1", Group: "example.com", Version: "v1", Kind: "Thing"}},
+					{Name: "avg_resource_count_per_gvk_per_mxp", Value: 1, Tags: model.MXPGVKEventTags{MXPID: "mxp-1", Group: "example.org", Version: "v1", Kind: "Item"}},
+					{Name: "avg_resource_count_per_gvk_per_mxp", Value: 3, Tags: model.MXPGVKEventTags{MXPID: "mxp-2", Group: "example.com", Version: "v1", Kind: "Thing"}},
+				},
+			},
+		},
+		"P95": {
+			reason: "Upbound events should use the nearest-rank 95th percentile of added values for a resource count on an MXP.",
+			args: args{
+				name:   "p95_resource_count_per_gvk_per_mxp",
+				events: events,
+			},
+			want: want{
+				events: []model.MXPGVKEvent{
+					{Name: "p95_resource_count_per_gvk_per_mxp", Value: 12, Tags: model.MXPGVKEventTags{MXPID: "mxp-1", Group: "example.com", Version: "v1", Kind: "Thing"}},
+					{Name: "p95_resource_count_per_gvk_per_mxp", Value: 1, Tags: model.MXPGVKEventTags{MXPID: "mxp-1", Group: "example.org", Version: "v1", Kind: "Item"}},
+					{Name: "p95_resource_count_per_gvk_per_mxp", Value: 3, Tags: model.MXPGVKEventTags{MXPID: "mxp-2", Group: "example.com", Version: "v1", Kind: "Thing"}},
+				},
+			},
+		},
+		"ResourceHours": {
+			reason: "Upbound events should sum the average resource counts of all GVKs on an MXP multiplied by the hours in the window.",
+			args: args{
+				name:   "resource_hours_per_mxp",
+				events: events,
+			},
+			want: want{
+				events: []model.MXPGVKEvent{
+					{Name: "resource_hours_per_mxp", Value: 14, Tags: model.MXPGVKEventTags{MXPID: "mxp-1"}},
+					{Name: "resource_hours_per_mxp", Value: 6, Tags: model.MXPGVKEventTags{MXPID: "mxp-2"}},
+				},
+			},
+		},
+		"MaxPerGroup": {
+			reason: "Upbound events should sum the largest resource counts of all GVKs and MXPs in an API group.",
+			args: args{
+				name:   "max_resource_count_per_group",
+				events: events,
+			},
+			want: want{
+				events: []model.MXPGVKEvent{
+					{Name: "max_resource_count_per_group", Value: 15, Tags: model.MXPGVKEventTags{Group: "example.com"}},
+					{Name: "max_resource_count_per_group", Value: 1, Tags: model.MXPGVKEventTags{Group: "example.org"}},
+				},
+			},
+		},
+		"NoEvents": {
+			reason: "There should be no events emitted if none were added.",
+			args: args{
+				name: "resource_hours_per_mxp",
+			},
+			want: want{
+				events: []model.MXPGVKEvent{},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fn, ok := Lookup(tc.args.name)
+			if !ok {
+				t.Fatalf("\n%s\nLookup(%q): aggregation is not registered", tc.reason, tc.args.name)
+			}
+			ag := fn(window)
+			for i, e := range tc.args.events {
+				if err := ag.Add(e); err != nil {
+					t.Errorf("\n%s\nAggregator.Add(...): error adding event %d: %v", tc.reason, i, err)
+				}
+			}
+
+			got := ag.UpboundEvents()
+
+			// Sort for stability.
+			usagetesting.SortEvents(got)
+			usagetesting.SortEvents(tc.want.events)
+
+			if diff := cmp.Diff(tc.want.events, got); diff != "" {
+				t.Errorf("\n%s\nAggregator.UpboundEvents(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestNames(t *testing.T) {
+	want := []string{
+		"avg_resource_count_per_gvk_per_mxp",
+		"max_resource_count_per_group",
+		"max_resource_count_per_gvk_per_mxp",
+		"p95_resource_count_per_gvk_per_mxp",
+		"resource_hours_per_mxp",
+	}
+	if diff := cmp.Diff(want, Names()); diff != "" {
+		t.Errorf("\nNames(): -want, +got:\n%s", diff)
+	}
+}
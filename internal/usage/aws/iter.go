@@ -35,6 +35,8 @@ type WindowIterator struct {
 	Client *s3.S3
 	Bucket string
 	Iter   *ListObjectsV2InputIterator
+	// Concurrency is the number of objects read at once within a window.
+	Concurrency int
 }
 
 // NewWindowIterator returns an initialized *WindowIterator.
@@ -66,6 +68,7 @@ func (i *WindowIterator) Next() (event.Reader, usagetime.Range, error) {
 			Bucket:             i.Bucket,
 			Client:             i.Client,
 			ListObjectsV2Input: loi,
+			Concurrency:        i.Concurrency,
 		}
 	}
 
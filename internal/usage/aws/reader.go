@@ -15,14 +15,13 @@
 package aws
 
 import (
-	"compress/gzip"
 	"context"
 	"io"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/s3"
 
-	"github.com/upbound/up/internal/usage/encoding/json"
 	"github.com/upbound/up/internal/usage/event"
 	"github.com/upbound/up/internal/usage/event/reader"
 	"github.com/upbound/up/internal/usage/model"
@@ -38,7 +37,9 @@ type ListObjectsV2InputEventReader struct {
 	Client             *s3.S3
 	Bucket             string
 	ListObjectsV2Input *s3.ListObjectsV2Input
-	reader             *reader.MultiReader
+	// Concurrency is the number of objects read at once.
+	Concurrency int
+	reader      event.Reader
 }
 
 func (r *ListObjectsV2InputEventReader) Read(ctx context.Context) (model.MXPGVKEvent, error) {
@@ -62,7 +63,7 @@ func (r *ListObjectsV2InputEventReader) Read(ctx context.Context) (model.MXPGVKE
 		); err != nil {
 			return model.MXPGVKEvent{}, err
 		}
-		r.reader = &reader.MultiReader{Readers: readers}
+		r.reader = reader.New(readers, r.Concurrency)
 	}
 	return r.reader.Read(ctx)
 }
@@ -76,61 +77,46 @@ func (r *ListObjectsV2InputEventReader) Close() error {
 
 var _ event.Reader = &GetObjectInputEventReader{}
 
-// GetObjectInputEventReader reads usage events from a *s3.GetObjectInput.
+// GetObjectInputEventReader reads usage events from a *s3.GetObjectInput. The
+// object is read in full on the first call to Read.
 type GetObjectInputEventReader struct {
 	Client         *s3.S3
 	GetObjectInput *s3.GetObjectInput
-	decoder        *json.MXPGVKEventDecoder
-	closers        []io.Closer
+	events         []model.MXPGVKEvent
+	read           bool
 }
 
 func (r *GetObjectInputEventReader) Read(ctx context.Context) (model.MXPGVKEvent, error) {
-	if r.decoder == nil {
+	if !r.read {
 		// TODO(branden): Use s3manager.Downloader for streaming and concurrent
 		// downloads.
-		resp, err := r.Client.GetObjectWithContext(ctx, r.GetObjectInput)
-		if err != nil {
-			return model.MXPGVKEvent{}, err
-		}
-
-		contentType := ""
-		if resp.ContentType != nil {
-			contentType = *resp.ContentType
-		}
-
-		var body io.ReadCloser
-		switch contentType {
-		case "application/gzip":
-			fallthrough
-		case "application/x-gzip":
-			r.closers = append(r.closers, resp.Body)
-			body, err = gzip.NewReader(resp.Body)
+		events, err := reader.ReadObject(ctx, reader.DefaultBackoff, isRetryable, func() (io.ReadCloser, string, error) {
+			resp, err := r.Client.GetObjectWithContext(ctx, r.GetObjectInput)
 			if err != nil {
-				return model.MXPGVKEvent{}, err
+				return nil, "", err
 			}
-		default:
-			body = resp.Body
-		}
-		r.closers = append(r.closers, body)
-
-		decoder, err := json.NewMXPGVKEventDecoder(body)
+			return resp.Body, aws.StringValue(resp.ContentType), nil
+		})
 		if err != nil {
 			return model.MXPGVKEvent{}, err
 		}
-		r.decoder = decoder
+		r.events = events
+		r.read = true
 	}
-	if !r.decoder.More() {
+	if len(r.events) == 0 {
 		return model.MXPGVKEvent{}, ErrEOF
 	}
-	return r.decoder.Decode()
+	e := r.events[0]
+	r.events = r.events[1:]
+	return e, nil
 }
 
 func (r *GetObjectInputEventReader) Close() error {
-	// Close closers in reverse.
-	for i := len(r.closers) - 1; i >= 0; i-- {
-		if err := r.closers[i].Close(); err != nil {
-			return err
-		}
-	}
 	return nil
 }
+
+// isRetryable returns true for errors the AWS SDK considers retryable, such as
+// throttling, and for transient network and server errors.
+func isRetryable(err error) bool {
+	return request.IsErrorRetryable(err) || request.IsErrorThrottle(err) || reader.IsTransient(err)
+}
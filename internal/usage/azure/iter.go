@@ -34,6 +34,8 @@ var _ event.WindowIterator = &WindowIterator{}
 type WindowIterator struct {
 	Iter   *ListBlobsOptionsIterator
 	Client *container.Client
+	// Concurrency is the number of blobs read at once within a window.
+	Concurrency int
 }
 
 // NewWindowIterator returns an initialized *WindowIterator.
@@ -61,7 +63,7 @@ func (i *WindowIterator) Next() (event.Reader, usagetime.Range, error) {
 	readers := make([]event.Reader, len(lo))
 	for j, opts := range lo {
 		opts := opts
-		readers[j] = &PagerEventReader{Client: i.Client, Pager: i.Client.NewListBlobsFlatPager(&opts)}
+		readers[j] = &PagerEventReader{Client: i.Client, Pager: i.Client.NewListBlobsFlatPager(&opts), Concurrency: i.Concurrency}
 	}
 
 	return &reader.MultiReader{Readers: readers}, window, nil
@@ -15,18 +15,18 @@
 package azure
 
 import (
-	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 
-	"github.com/upbound/up/internal/usage/encoding/json"
 	"github.com/upbound/up/internal/usage/event"
+	"github.com/upbound/up/internal/usage/event/reader"
 	"github.com/upbound/up/internal/usage/model"
 )
 
@@ -36,9 +36,11 @@ var _ event.Reader = &PagerEventReader{}
 
 // PagerEventReader reads usage events from a pager for blob list responses.
 type PagerEventReader struct {
-	Client     *container.Client
-	Pager      *runtime.Pager[container.ListBlobsFlatResponse]
-	currReader *ListBlobsResponseEventReader
+	Client *container.Client
+	Pager  *runtime.Pager[container.ListBlobsFlatResponse]
+	// Concurrency is the number of blobs of a page read at once.
+	Concurrency int
+	currReader  *ListBlobsResponseEventReader
 }
 
 func (r *PagerEventReader) Read(ctx context.Context) (model.MXPGVKEvent, error) {
@@ -51,7 +53,7 @@ func (r *PagerEventReader) Read(ctx context.Context) (model.MXPGVKEvent, error)
 			if err != nil {
 				return model.MXPGVKEvent{}, err
 			}
-			r.currReader = &ListBlobsResponseEventReader{Client: r.Client, Response: &resp}
+			r.currReader = &ListBlobsResponseEventReader{Client: r.Client, Response: &resp, Concurrency: r.Concurrency}
 		}
 		e, err := r.currReader.Read(ctx)
 		if !errors.Is(err, ErrEOF) {
@@ -72,33 +74,31 @@ var _ event.Reader = &ListBlobsResponseEventReader{}
 
 // ListBlobsResponseEventReader reads usage events from a blob list response.
 type ListBlobsResponseEventReader struct {
-	Client     *container.Client
-	Response   *container.ListBlobsFlatResponse
-	itemIdx    int
-	currReader *BlobEventReader
+	Client   *container.Client
+	Response *container.ListBlobsFlatResponse
+	// Concurrency is the number of blobs read at once. Blobs are read one
+	// after another if it is less than 2.
+	Concurrency int
+	itemIdx     int
+	currReader  *BlobEventReader
+	parallel    *reader.ParallelReader
 }
 
 func (r *ListBlobsResponseEventReader) Read(ctx context.Context) (model.MXPGVKEvent, error) {
+	if r.Concurrency > 1 {
+		return r.readParallel(ctx)
+	}
 	for {
 		if r.currReader == nil {
 			if r.itemIdx >= len(r.Response.Segment.BlobItems) {
 				return model.MXPGVKEvent{}, ErrEOF
 			}
 
-			blob := r.Response.Segment.BlobItems[r.itemIdx]
-			if blob.Name == nil {
-				return model.MXPGVKEvent{}, fmt.Errorf("blob name is nil")
-			}
-
-			contentType := ""
-			if blob.Properties.ContentType != nil {
-				contentType = *blob.Properties.ContentType
-			}
-
-			r.currReader = &BlobEventReader{
-				Client:      r.Client.NewBlobClient(*blob.Name),
-				ContentType: contentType,
+			br, err := r.blobReader(r.Response.Segment.BlobItems[r.itemIdx])
+			if err != nil {
+				return model.MXPGVKEvent{}, err
 			}
+			r.currReader = br
 			r.itemIdx += 1
 		}
 		if e, err := r.currReader.Read(ctx); !errors.Is(err, ErrEOF) {
@@ -111,7 +111,41 @@ func (r *ListBlobsResponseEventReader) Read(ctx context.Context) (model.MXPGVKEv
 	}
 }
 
+func (r *ListBlobsResponseEventReader) readParallel(ctx context.Context) (model.MXPGVKEvent, error) {
+	if r.parallel == nil {
+		readers := make([]event.Reader, len(r.Response.Segment.BlobItems))
+		for i, blob := range r.Response.Segment.BlobItems {
+			br, err := r.blobReader(blob)
+			if err != nil {
+				return model.MXPGVKEvent{}, err
+			}
+			readers[i] = br
+		}
+		r.parallel = &reader.ParallelReader{Readers: readers, Concurrency: r.Concurrency}
+	}
+	return r.parallel.Read(ctx)
+}
+
+func (r *ListBlobsResponseEventReader) blobReader(blob *container.BlobItem) (*BlobEventReader, error) {
+	if blob.Name == nil {
+		return nil, fmt.Errorf("blob name is nil")
+	}
+
+	contentType := ""
+	if blob.Properties.ContentType != nil {
+		contentType = *blob.Properties.ContentType
+	}
+
+	return &BlobEventReader{
+		Client:      r.Client.NewBlobClient(*blob.Name),
+		ContentType: contentType,
+	}, nil
+}
+
 func (r *ListBlobsResponseEventReader) Close() error {
+	if r.parallel != nil {
+		return r.parallel.Close()
+	}
 	if r.currReader == nil {
 		return nil
 	}
@@ -120,55 +154,48 @@ func (r *ListBlobsResponseEventReader) Close() error {
 
 var _ event.Reader = &BlobEventReader{}
 
-// BlobEventReader reads usage events from a blob client.
+// BlobEventReader reads usage events from a blob client. The blob is read in
+// full on the first call to Read.
 type BlobEventReader struct {
 	Client      *blob.Client
 	ContentType string
-	decoder     *json.MXPGVKEventDecoder
-	closers     []io.Closer
+	events      []model.MXPGVKEvent
+	read        bool
 }
 
 func (r *BlobEventReader) Read(ctx context.Context) (model.MXPGVKEvent, error) {
-	if r.decoder == nil {
-		resp, err := r.Client.DownloadStream(ctx, nil)
-		if err != nil {
-			return model.MXPGVKEvent{}, err
-		}
-
-		var body io.ReadCloser
-		switch r.ContentType {
-		case "application/gzip":
-			fallthrough
-		case "application/x-gzip":
-			r.closers = append(r.closers, resp.Body)
-			body, err = gzip.NewReader(resp.Body)
+	if !r.read {
+		events, err := reader.ReadObject(ctx, reader.DefaultBackoff, isRetryable, func() (io.ReadCloser, string, error) {
+			resp, err := r.Client.DownloadStream(ctx, nil)
 			if err != nil {
-				return model.MXPGVKEvent{}, err
+				return nil, "", err
 			}
-		default:
-			body = resp.Body
-		}
-		r.closers = append(r.closers, body)
-
-		decoder, err := json.NewMXPGVKEventDecoder(body)
+			return resp.Body, r.ContentType, nil
+		})
 		if err != nil {
 			return model.MXPGVKEvent{}, err
 		}
-		r.decoder = decoder
+		r.events = events
+		r.read = true
 	}
-
-	if !r.decoder.More() {
+	if len(r.events) == 0 {
 		return model.MXPGVKEvent{}, ErrEOF
 	}
-	return r.decoder.Decode()
+	e := r.events[0]
+	r.events = r.events[1:]
+	return e, nil
 }
 
 func (r *BlobEventReader) Close() error {
-	// Close closers in reverse.
-	for i := len(r.closers) - 1; i >= 0; i-- {
-		if err := r.closers[i].Close(); err != nil {
-			return err
-		}
-	}
 	return nil
 }
+
+// isRetryable returns true for responses with a transient HTTP status, and for
+// transient network errors.
+func isRetryable(err error) bool {
+	var re *azcore.ResponseError
+	if errors.As(err, &re) {
+		return reader.IsTransientStatus(re.StatusCode)
+	}
+	return reader.IsTransient(err)
+}
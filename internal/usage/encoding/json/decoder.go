@@ -58,3 +58,16 @@ func (d *MXPGVKEventDecoder) Decode() (model.MXPGVKEvent, error) {
 	}
 	return e, nil
 }
+
+// End consumes the close bracket of the JSON array once More returns false. It
+// returns an error if input could not be read, or ended, before the bracket.
+func (d *MXPGVKEventDecoder) End() error {
+	t, err := d.jd.Token()
+	if err != nil {
+		return fmt.Errorf("error reading end of events: %s", err.Error())
+	}
+	if t != json.Delim(']') {
+		return fmt.Errorf("reader does not contain JSON array. expected ], got %s", t)
+	}
+	return nil
+}
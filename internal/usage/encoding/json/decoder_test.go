@@ -234,3 +234,49 @@ func TestMXPGVKEventDecoderDecode(t *testing.T) {
 		})
 	}
 }
+
+func TestMXPGVKEventDecoderEnd(t *testing.T) {
+	type args struct {
+		reader io.Reader
+	}
+	type want struct {
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"EmptyJSONArray": {
+			reason: "Ending an empty JSON array should not return an error.",
+			args: args{
+				reader: strings.NewReader("[]"),
+			},
+			want: want{},
+		},
+		"TruncatedJSONArray": {
+			reason: "Ending a JSON array whose input ended before its close bracket should return an error.",
+			args: args{
+				reader: strings.NewReader("["),
+			},
+			want: want{
+				err: errors.New("error reading end of events: EOF"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewMXPGVKEventDecoder(tc.args.reader)
+			if err != nil {
+				diff := cmp.Diff(nil, err, test.EquateErrors())
+				t.Errorf("\n%s\nNewMXPGVKEventDecoder(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+
+			err = d.End()
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nMXPGVKEventDecoder.End(): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reader
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/upbound/up/internal/usage/event"
+	"github.com/upbound/up/internal/usage/model"
+)
+
+var _ event.Reader = &ParallelReader{}
+
+// New returns a reader for events from all of the supplied readers in order.
+// Readers are read concurrently by a ParallelReader if concurrency is greater
+// than 1, and one after another by a MultiReader otherwise.
+func New(readers []event.Reader, concurrency int) event.Reader {
+	if concurrency > 1 {
+		return &ParallelReader{Readers: readers, Concurrency: concurrency}
+	}
+	return &MultiReader{Readers: readers}
+}
+
+// ParallelReader reads events from up to Concurrency of its readers at once.
+// Events are returned in the same order as by a MultiReader: all events of
+// the first reader, then all events of the second reader, and so on.
+//
+// Each reader is read to completion into memory before its events are
+// returned. A reader is not started until fewer than Concurrency readers are
+// being read or waiting to be returned, so at most Concurrency+1 readers'
+// events are held in memory at once.
+type ParallelReader struct {
+	Readers     []event.Reader
+	Concurrency int
+
+	started bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	sem     chan struct{}
+	results []chan readResult
+	curr    int
+	buf     readResult
+}
+
+type readResult struct {
+	events []model.MXPGVKEvent
+	err    error
+}
+
+// Read returns the next event. Returns EOF when finished.
+func (r *ParallelReader) Read(ctx context.Context) (model.MXPGVKEvent, error) {
+	if !r.started {
+		r.start(ctx)
+	}
+	for {
+		if len(r.buf.events) > 0 {
+			e := r.buf.events[0]
+			r.buf.events = r.buf.events[1:]
+			return e, nil
+		}
+		if r.buf.err != nil {
+			err := r.buf.err
+			r.buf.err = nil
+			return model.MXPGVKEvent{}, err
+		}
+		if r.curr >= len(r.results) {
+			return model.MXPGVKEvent{}, ErrEOF
+		}
+		select {
+		case r.buf = <-r.results[r.curr]:
+		case <-ctx.Done():
+			return model.MXPGVKEvent{}, ctx.Err()
+		}
+		// The result is no longer counted against the concurrency limit
+		// once it is being returned.
+		<-r.sem
+		r.curr++
+	}
+}
+
+// Close stops reading and closes all readers.
+func (r *ParallelReader) Close() error {
+	if !r.started {
+		return (&MultiReader{Readers: r.Readers}).Close()
+	}
+	r.cancel()
+	r.wg.Wait()
+	// Readers are closed by their goroutine, except those that were never
+	// started. Return their errors in order.
+	for i := r.curr; i < len(r.results); i++ {
+		select {
+		case res := <-r.results[i]:
+			if res.err != nil && !errors.Is(res.err, context.Canceled) {
+				return res.err
+			}
+		default:
+		}
+	}
+	return nil
+}
+
+func (r *ParallelReader) start(ctx context.Context) {
+	r.started = true
+	ctx, r.cancel = context.WithCancel(ctx)
+	concurrency := r.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	r.sem = make(chan struct{}, concurrency)
+	r.results = make([]chan readResult, len(r.Readers))
+	for i := range r.results {
+		r.results[i] = make(chan readResult, 1)
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		for i, er := range r.Readers {
+			select {
+			case r.sem <- struct{}{}:
+			case <-ctx.Done():
+				for _, er := range r.Readers[i:] {
+					_ = er.Close()
+				}
+				return
+			}
+			r.wg.Add(1)
+			go func(i int, er event.Reader) {
+				defer r.wg.Done()
+				r.results[i] <- readAll(ctx, er)
+			}(i, er)
+		}
+	}()
+}
+
+// readAll reads all events from a reader and closes it.
+func readAll(ctx context.Context, er event.Reader) readResult {
+	res := readResult{}
+	for {
+		e, err := er.Read(ctx)
+		if errors.Is(err, ErrEOF) {
+			break
+		}
+		if err != nil {
+			res.err = err
+			break
+		}
+		res.events = append(res.events, e)
+	}
+	if err := er.Close(); err != nil && res.err == nil {
+		res.err = err
+	}
+	return res
+}
@@ -0,0 +1,182 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reader
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/usage/event"
+	"github.com/upbound/up/internal/usage/model"
+	usagetesting "github.com/upbound/up/internal/usage/testing"
+)
+
+// slowReader returns its events after a delay, tracking how many slowReaders
+// are being read at once.
+type slowReader struct {
+	usagetesting.MockReader
+	delay  time.Duration
+	active *int32
+	peak   *int32
+	closed bool
+}
+
+func (r *slowReader) Read(ctx context.Context) (model.MXPGVKEvent, error) {
+	n := atomic.AddInt32(r.active, 1)
+	for {
+		p := atomic.LoadInt32(r.peak)
+		if n <= p || atomic.CompareAndSwapInt32(r.peak, p, n) {
+			break
+		}
+	}
+	time.Sleep(r.delay)
+	atomic.AddInt32(r.active, -1)
+	return r.MockReader.Read(ctx)
+}
+
+func (r *slowReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestParallelReader(t *testing.T) {
+	type want struct {
+		reads []usagetesting.ReadResult
+		peak  int32
+	}
+	cases := map[string]struct {
+		reason      string
+		concurrency int
+		delays      []time.Duration
+		reads       [][]usagetesting.ReadResult
+		want        want
+	}{
+		"NoReaders": {
+			reason:      "No events are returned without readers.",
+			concurrency: 2,
+			want: want{
+				reads: []usagetesting.ReadResult{},
+			},
+		},
+		"Ordered": {
+			reason:      "Events are returned in reader order even if later readers finish first.",
+			concurrency: 3,
+			delays:      []time.Duration{30 * time.Millisecond, 10 * time.Millisecond, 0},
+			reads: [][]usagetesting.ReadResult{
+				{{Event: model.MXPGVKEvent{Name: "event-1"}}},
+				{{Event: model.MXPGVKEvent{Name: "event-2"}}, {Event: model.MXPGVKEvent{Name: "event-3"}}},
+				{{Event: model.MXPGVKEvent{Name: "event-4"}}},
+			},
+			want: want{
+				reads: []usagetesting.ReadResult{
+					{Event: model.MXPGVKEvent{Name: "event-1"}},
+					{Event: model.MXPGVKEvent{Name: "event-2"}},
+					{Event: model.MXPGVKEvent{Name: "event-3"}},
+					{Event: model.MXPGVKEvent{Name: "event-4"}},
+				},
+				peak: 3,
+			},
+		},
+		"ConcurrencyLimit": {
+			reason:      "No more than the concurrency limit of readers are read at once.",
+			concurrency: 2,
+			delays:      []time.Duration{10 * time.Millisecond, 10 * time.Millisecond, 10 * time.Millisecond, 10 * time.Millisecond},
+			reads: [][]usagetesting.ReadResult{
+				{{Event: model.MXPGVKEvent{Name: "event-1"}}},
+				{{Event: model.MXPGVKEvent{Name: "event-2"}}},
+				{{Event: model.MXPGVKEvent{Name: "event-3"}}},
+				{{Event: model.MXPGVKEvent{Name: "event-4"}}},
+			},
+			want: want{
+				reads: []usagetesting.ReadResult{
+					{Event: model.MXPGVKEvent{Name: "event-1"}},
+					{Event: model.MXPGVKEvent{Name: "event-2"}},
+					{Event: model.MXPGVKEvent{Name: "event-3"}},
+					{Event: model.MXPGVKEvent{Name: "event-4"}},
+				},
+				peak: 2,
+			},
+		},
+		"Error": {
+			reason:      "Events read before an error are returned before the error.",
+			concurrency: 2,
+			delays:      []time.Duration{0, 0},
+			reads: [][]usagetesting.ReadResult{
+				{{Event: model.MXPGVKEvent{Name: "event-1"}}, {Err: fmt.Errorf("boom")}},
+				{{Event: model.MXPGVKEvent{Name: "event-2"}}},
+			},
+			want: want{
+				reads: []usagetesting.ReadResult{
+					{Event: model.MXPGVKEvent{Name: "event-1"}},
+					{Err: fmt.Errorf("boom")},
+				},
+				peak: 2,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var active, peak int32
+			readers := make([]event.Reader, len(tc.reads))
+			for i := range tc.reads {
+				readers[i] = &slowReader{
+					MockReader: usagetesting.MockReader{Reads: tc.reads[i]},
+					delay:      tc.delays[i],
+					active:     &active,
+					peak:       &peak,
+				}
+			}
+			r := &ParallelReader{Readers: readers, Concurrency: tc.concurrency}
+
+			ctx := context.Background()
+			got := []usagetesting.ReadResult{}
+			for {
+				e, err := r.Read(ctx)
+				if errors.Is(err, ErrEOF) {
+					break
+				}
+				got = append(got, usagetesting.ReadResult{Event: e, Err: err})
+				if err != nil {
+					break
+				}
+			}
+
+			err := r.Close()
+			if diff := cmp.Diff(nil, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParallelReader.Close(): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			for i, er := range readers {
+				if !er.(*slowReader).closed {
+					t.Errorf("\n%s\nParallelReader.Close(): reader %d was not closed", tc.reason, i)
+				}
+			}
+
+			if diff := cmp.Diff(tc.want.reads, got, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParallelReader: -want, +got:\n%s", tc.reason, diff)
+			}
+			if peak > tc.want.peak {
+				t.Errorf("\n%s\nParallelReader: read %d readers at once, want at most %d", tc.reason, peak, tc.want.peak)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reader
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/upbound/up/internal/usage/encoding/json"
+	"github.com/upbound/up/internal/usage/model"
+)
+
+// DefaultBackoff is the backoff used to retry reading storage objects. It
+// makes 5 attempts over about 7.5 seconds.
+var DefaultBackoff = wait.Backoff{
+	Duration: 500 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    5,
+}
+
+// Retry calls fn until it succeeds, making up to b.Steps attempts with
+// exponential backoff between them. Only errors for which retryable returns
+// true are retried. It returns the error of the last attempt if all attempts
+// fail, the error is not retryable or ctx is done.
+func Retry(ctx context.Context, b wait.Backoff, retryable func(error) bool, fn func() error) error {
+	for {
+		err := fn()
+		if err == nil || !retryable(err) || ctx.Err() != nil || b.Steps <= 1 {
+			return err
+		}
+		t := time.NewTimer(b.Step())
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		}
+	}
+}
+
+// IsTransient returns true if err is likely to go away when the request is
+// retried: a timeout, a reset, refused or aborted connection, a stream that
+// ended early, or an error with an HTTP status of 408, 429 or 5xx.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, t := range []error{io.ErrUnexpectedEOF, syscall.ECONNRESET, syscall.ECONNREFUSED, syscall.ECONNABORTED, syscall.EPIPE} {
+		if errors.Is(err, t) {
+			return true
+		}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var sc interface{ StatusCode() int }
+	if errors.As(err, &sc) {
+		return IsTransientStatus(sc.StatusCode())
+	}
+	return false
+}
+
+// IsTransientStatus returns true if an HTTP status indicates a timeout,
+// throttling or a server error.
+func IsTransientStatus(code int) bool {
+	return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// ReadObject opens a storage object with open and decodes all of its usage
+// events, which are gzipped if its content type is application/gzip or
+// application/x-gzip. Opening and decoding are retried together with backoff
+// on errors for which retryable returns true, so each attempt reads the object
+// from its start.
+func ReadObject(ctx context.Context, b wait.Backoff, retryable func(error) bool, open func() (body io.ReadCloser, contentType string, err error)) ([]model.MXPGVKEvent, error) {
+	var events []model.MXPGVKEvent
+	err := Retry(ctx, b, retryable, func() error {
+		body, contentType, err := open()
+		if err != nil {
+			return err
+		}
+		defer body.Close() // nolint:errcheck
+		events, err = decodeAll(body, contentType)
+		return err
+	})
+	return events, err
+}
+
+// decodeAll decodes all usage events of body. Errors reading body are returned
+// as is, so that they can be classified as transient.
+func decodeAll(body io.Reader, contentType string) ([]model.MXPGVKEvent, error) {
+	er := &errReader{r: body}
+	events, err := decodeAllFrom(er, contentType)
+	if err != nil && er.err != nil {
+		return nil, er.err
+	}
+	return events, err
+}
+
+func decodeAllFrom(body io.Reader, contentType string) ([]model.MXPGVKEvent, error) {
+	switch contentType {
+	case "application/gzip", "application/x-gzip":
+		gr, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close() // nolint:errcheck
+		body = gr
+	}
+	decoder, err := json.NewMXPGVKEventDecoder(body)
+	if err != nil {
+		return nil, err
+	}
+	events := []model.MXPGVKEvent{}
+	for decoder.More() {
+		e, err := decoder.Decode()
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	// More also returns false if the object could not be read to its end.
+	if err := decoder.End(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// errReader records the last error other than io.EOF returned by r.
+type errReader struct {
+	r   io.Reader
+	err error
+}
+
+func (e *errReader) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		e.err = err
+	}
+	return n, err
+}
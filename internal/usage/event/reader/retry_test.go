@@ -0,0 +1,255 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/upbound/up/internal/usage/model"
+)
+
+type statusError int
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("status %d", int(e))
+}
+
+func (e statusError) StatusCode() int {
+	return int(e)
+}
+
+func TestRetry(t *testing.T) {
+	b := wait.Backoff{Duration: time.Millisecond, Factor: 2, Steps: 3}
+
+	type want struct {
+		attempts int
+		err      error
+	}
+	cases := map[string]struct {
+		reason   string
+		failures int
+		err      error
+		want     want
+	}{
+		"Success": {
+			reason:   "A function that succeeds is called once.",
+			failures: 0,
+			want: want{
+				attempts: 1,
+			},
+		},
+		"TransientError": {
+			reason:   "A function is retried until it succeeds.",
+			failures: 2,
+			want: want{
+				attempts: 3,
+			},
+		},
+		"PersistentError": {
+			reason:   "The last error is returned after all attempts fail.",
+			failures: 5,
+			want: want{
+				attempts: 3,
+				err:      fmt.Errorf("attempt 3 failed: %w", syscall.ECONNRESET),
+			},
+		},
+		"PermanentError": {
+			reason:   "An error that is not retryable is returned without retrying.",
+			failures: 5,
+			err:      statusError(403),
+			want: want{
+				attempts: 1,
+				err:      fmt.Errorf("attempt 1 failed: %w", statusError(403)),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			attempts := 0
+			cause := tc.err
+			if cause == nil {
+				cause = syscall.ECONNRESET
+			}
+			err := Retry(context.Background(), b, IsTransient, func() error {
+				attempts++
+				if attempts <= tc.failures {
+					return fmt.Errorf("attempt %d failed: %w", attempts, cause)
+				}
+				return nil
+			})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRetry(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.attempts, attempts); diff != "" {
+				t.Errorf("\n%s\nRetry(...): -want attempts, +got attempts:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   bool
+	}{
+		"ConnectionReset": {
+			reason: "A reset connection is transient.",
+			err:    fmt.Errorf("read: %w", syscall.ECONNRESET),
+			want:   true,
+		},
+		"UnexpectedEOF": {
+			reason: "A stream that ended early is transient.",
+			err:    io.ErrUnexpectedEOF,
+			want:   true,
+		},
+		"Throttled": {
+			reason: "A throttled request is transient.",
+			err:    statusError(429),
+			want:   true,
+		},
+		"ServerError": {
+			reason: "A server error is transient.",
+			err:    statusError(503),
+			want:   true,
+		},
+		"AccessDenied": {
+			reason: "A denied request is permanent.",
+			err:    statusError(403),
+			want:   false,
+		},
+		"NotFound": {
+			reason: "A missing object is permanent.",
+			err:    statusError(404),
+			want:   false,
+		},
+		"Other": {
+			reason: "An unknown error is permanent.",
+			err:    errors.New("boom"),
+			want:   false,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := IsTransient(tc.err)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nIsTransient(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// resetReader returns the bytes of r up to n, then a reset connection.
+type resetReader struct {
+	r io.Reader
+	n int
+}
+
+func (r *resetReader) Read(p []byte) (int, error) {
+	if r.n <= 0 {
+		return 0, syscall.ECONNRESET
+	}
+	if len(p) > r.n {
+		p = p[:r.n]
+	}
+	n, err := r.r.Read(p)
+	r.n -= n
+	return n, err
+}
+
+func TestReadObject(t *testing.T) {
+	b := wait.Backoff{Duration: time.Millisecond, Factor: 2, Steps: 3}
+	body := `[{"name":"a","value":1},{"name":"b","value":2}]`
+	gz := &bytes.Buffer{}
+	zw := gzip.NewWriter(gz)
+	_, _ = zw.Write([]byte(body))
+	_ = zw.Close()
+	events := []model.MXPGVKEvent{{Name: "a", Value: 1}, {Name: "b", Value: 2}}
+
+	type want struct {
+		events   []model.MXPGVKEvent
+		attempts int
+		err      error
+	}
+	cases := map[string]struct {
+		reason      string
+		body        []byte
+		contentType string
+		resets      int
+		openErr     error
+		want        want
+	}{
+		"Success": {
+			reason: "All events of an object are read.",
+			body:   []byte(body),
+			want:   want{events: events, attempts: 1},
+		},
+		"Gzip": {
+			reason:      "Gzipped objects are decompressed.",
+			body:        gz.Bytes(),
+			contentType: "application/gzip",
+			want:        want{events: events, attempts: 1},
+		},
+		"ResetMidStream": {
+			reason: "An object whose connection is reset while it is read is read again from its start.",
+			body:   []byte(body),
+			resets: 2,
+			want:   want{events: events, attempts: 3},
+		},
+		"PermanentOpenError": {
+			reason:  "An object that cannot be opened for a permanent reason is not retried.",
+			body:    []byte(body),
+			openErr: statusError(404),
+			want:    want{attempts: 1, err: statusError(404)},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			attempts := 0
+			got, err := ReadObject(context.Background(), b, IsTransient, func() (io.ReadCloser, string, error) {
+				attempts++
+				if tc.openErr != nil {
+					return nil, "", tc.openErr
+				}
+				var r io.Reader = bytes.NewReader(tc.body)
+				if attempts <= tc.resets {
+					r = &resetReader{r: r, n: len(tc.body) / 2}
+				}
+				return io.NopCloser(r), tc.contentType, nil
+			})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nReadObject(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, got); diff != "" {
+				t.Errorf("\n%s\nReadObject(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.attempts, attempts); diff != "" {
+				t.Errorf("\n%s\nReadObject(...): -want attempts, +got attempts:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+	clock "k8s.io/utils/clock/testing"
+
+	"github.com/upbound/up/internal/usage/event"
+	"github.com/upbound/up/internal/usage/event/reader"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+var _ event.WindowIterator = &WindowIterator{}
+
+// WindowIterator iterates through readers for windows of usage events from a
+// directory with the same layout as a usage storage bucket. Must be
+// initialized with NewWindowIterator().
+type WindowIterator struct {
+	FS   afero.Fs
+	Iter *DirIterator
+	// Concurrency is the number of files read at once within a window.
+	Concurrency int
+}
+
+// NewWindowIterator returns an initialized *WindowIterator.
+func NewWindowIterator(fs afero.Fs, root, account string, tr usagetime.Range, window time.Duration) (*WindowIterator, error) {
+	iter, err := NewDirIterator(root, account, tr, window)
+	if err != nil {
+		return nil, err
+	}
+	return &WindowIterator{
+		FS:   fs,
+		Iter: iter,
+	}, nil
+}
+
+func (i *WindowIterator) More() bool {
+	return i.Iter.More()
+}
+
+func (i *WindowIterator) Next() (event.Reader, usagetime.Range, error) {
+	dirs, window, err := i.Iter.Next()
+	if err != nil {
+		return nil, usagetime.Range{}, err
+	}
+
+	readers := make([]event.Reader, len(dirs))
+	for j, dir := range dirs {
+		readers[j] = &DirEventReader{FS: i.FS, Dir: dir, Concurrency: i.Concurrency}
+	}
+
+	return &reader.MultiReader{Readers: readers}, window, nil
+}
+
+// DirIterator iterates through the directories holding usage data for each
+// window of time in a time range. Must be initialized with NewDirIterator().
+type DirIterator struct {
+	Root    string
+	Account string
+	Iter    *usagetime.WindowIterator
+}
+
+// NewDirIterator returns an initialized *DirIterator.
+func NewDirIterator(root, account string, tr usagetime.Range, window time.Duration) (*DirIterator, error) {
+	iter, err := usagetime.NewWindowIterator(tr, window)
+	if err != nil {
+		return nil, err
+	}
+	return &DirIterator{
+		Root:    root,
+		Account: account,
+		Iter:    iter,
+	}, nil
+}
+
+// More returns true if Next() has more to return.
+func (i *DirIterator) More() bool {
+	return i.Iter.More()
+}
+
+// Next returns the directories covering the next window of time, as well as a
+// time range marking the window.
+func (i *DirIterator) Next() ([]string, usagetime.Range, error) {
+	window, err := i.Iter.Next()
+	if err != nil {
+		return nil, usagetime.Range{}, err
+	}
+
+	// Create a directory path for each hour in the window.
+	dirs := []string{}
+	c := clock.SimpleIntervalClock{Time: window.Start, Duration: time.Hour}
+	now := window.Start
+	for {
+		if now.Equal(window.End) || now.After(window.End) {
+			break
+		}
+		dirs = append(dirs, filepath.Join(
+			i.Root,
+			fmt.Sprintf("account=%s", i.Account),
+			fmt.Sprintf("date=%s", usagetime.FormatDateUTC(now)),
+			fmt.Sprintf("hour=%02d", now.Hour()),
+		))
+		now = c.Now()
+	}
+
+	return dirs, window, nil
+}
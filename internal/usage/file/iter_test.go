@@ -0,0 +1,164 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/usage/event"
+	"github.com/upbound/up/internal/usage/model"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+func TestDirIterator(t *testing.T) {
+	type args struct {
+		root    string
+		account string
+		tr      usagetime.Range
+		window  time.Duration
+	}
+	type iteration struct {
+		// These fields are exported for cmp.Diff().
+		Dirs   []string
+		Window usagetime.Range
+		Err    error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   []iteration
+	}{
+		"3HourRange2HourWindow": {
+			reason: "3h range divided into 2h windows, the last one truncated.",
+			args: args{
+				root:    "/usage",
+				account: "test-account",
+				tr: usagetime.Range{
+					Start: time.Date(2006, 5, 4, 22, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 5, 5, 1, 0, 0, 0, time.UTC),
+				},
+				window: 2 * time.Hour,
+			},
+			want: []iteration{
+				{
+					Dirs: []string{
+						"/usage/account=test-account/date=2006-05-04/hour=22",
+						"/usage/account=test-account/date=2006-05-04/hour=23",
+					},
+					Window: usagetime.Range{
+						Start: time.Date(2006, 5, 4, 22, 0, 0, 0, time.UTC),
+						End:   time.Date(2006, 5, 5, 0, 0, 0, 0, time.UTC),
+					},
+				},
+				{
+					Dirs: []string{
+						"/usage/account=test-account/date=2006-05-05/hour=00",
+					},
+					Window: usagetime.Range{
+						Start: time.Date(2006, 5, 5, 0, 0, 0, 0, time.UTC),
+						End:   time.Date(2006, 5, 5, 1, 0, 0, 0, time.UTC),
+					},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			iter, err := NewDirIterator(tc.args.root, tc.args.account, tc.args.tr, tc.args.window)
+			if err != nil {
+				t.Fatalf("NewDirIterator(...): unexpected error: %s", err)
+			}
+
+			got := []iteration{}
+			for iter.More() {
+				dirs, window, err := iter.Next()
+				got = append(got, iteration{Dirs: dirs, Window: window, Err: err})
+			}
+			if diff := cmp.Diff(tc.want, got, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDirIterator: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWindowIterator(t *testing.T) {
+	events := `[
+{"name":"kube_managedresource_uid","tags":{"customresource_group":"example.com","customresource_version":"v1","customresource_kind":"Thing","mxp_id":"mxp1"},"value":3}
+]`
+	gz := &bytes.Buffer{}
+	gw := gzip.NewWriter(gz)
+	_, _ = gw.Write([]byte(`[
+{"name":"kube_managedresource_uid","tags":{"customresource_group":"example.com","customresource_version":"v1","customresource_kind":"Thing","mxp_id":"mxp2"},"value":5}
+]`))
+	_ = gw.Close()
+
+	fs := afero.NewMemMapFs()
+	_ = afero.WriteFile(fs, "/usage/account=acct/date=2006-05-04/hour=03/a.json", []byte(events), 0o644)
+	_ = afero.WriteFile(fs, "/usage/account=acct/date=2006-05-04/hour=04/b.json.gz", gz.Bytes(), 0o644)
+
+	iter, err := NewWindowIterator(fs, "/usage", "acct", usagetime.Range{
+		Start: time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC),
+		End:   time.Date(2006, 5, 4, 6, 0, 0, 0, time.UTC),
+	}, time.Hour)
+	if err != nil {
+		t.Fatalf("NewWindowIterator(...): unexpected error: %s", err)
+	}
+
+	got := map[string][]string{}
+	for iter.More() {
+		r, window, err := iter.Next()
+		if err != nil {
+			t.Fatalf("WindowIterator.Next(): unexpected error: %s", err)
+		}
+		key := window.Start.Format(time.RFC3339)
+		got[key] = []string{}
+		for {
+			e, err := r.Read(context.Background())
+			if errors.Is(err, event.ErrEOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Reader.Read(): unexpected error: %s", err)
+			}
+			got[key] = append(got[key], describe(e))
+		}
+		if err := r.Close(); err != nil {
+			t.Fatalf("Reader.Close(): unexpected error: %s", err)
+		}
+	}
+
+	want := map[string][]string{
+		"2006-05-04T03:00:00Z": {"mxp1/Thing=3"},
+		"2006-05-04T04:00:00Z": {"mxp2/Thing=5"},
+		"2006-05-04T05:00:00Z": {},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("\nWindowIterator: -want, +got:\n%s", diff)
+	}
+}
+
+func describe(e model.MXPGVKEvent) string {
+	return fmt.Sprintf("%s/%s=%g", e.Tags.MXPID, e.Tags.Kind, e.Value)
+}
@@ -0,0 +1,126 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/usage/encoding/json"
+	"github.com/upbound/up/internal/usage/event"
+	"github.com/upbound/up/internal/usage/event/reader"
+	"github.com/upbound/up/internal/usage/model"
+)
+
+var ErrEOF = event.ErrEOF
+
+// gzipMagic are the leading bytes of a gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+var _ event.Reader = &DirEventReader{}
+
+// DirEventReader reads usage events from the files in a directory. Files are
+// read in lexical order. A missing directory contains no events.
+type DirEventReader struct {
+	FS  afero.Fs
+	Dir string
+	// Concurrency is the number of files read at once.
+	Concurrency int
+	reader      event.Reader
+}
+
+func (r *DirEventReader) Read(ctx context.Context) (model.MXPGVKEvent, error) {
+	if r.reader == nil {
+		infos, err := afero.ReadDir(r.FS, r.Dir)
+		if err != nil && !os.IsNotExist(err) {
+			return model.MXPGVKEvent{}, err
+		}
+		readers := []event.Reader{}
+		for _, info := range infos {
+			if info.IsDir() {
+				continue
+			}
+			readers = append(readers, &FileEventReader{FS: r.FS, Path: filepath.Join(r.Dir, info.Name())})
+		}
+		r.reader = reader.New(readers, r.Concurrency)
+	}
+	return r.reader.Read(ctx)
+}
+
+func (r *DirEventReader) Close() error {
+	if r.reader == nil {
+		return nil
+	}
+	return r.reader.Close()
+}
+
+var _ event.Reader = &FileEventReader{}
+
+// FileEventReader reads usage events from a file. Gzipped files are detected
+// by their content and decompressed.
+type FileEventReader struct {
+	FS      afero.Fs
+	Path    string
+	decoder *json.MXPGVKEventDecoder
+	closers []io.Closer
+}
+
+func (r *FileEventReader) Read(_ context.Context) (model.MXPGVKEvent, error) {
+	if r.decoder == nil {
+		f, err := r.FS.Open(r.Path)
+		if err != nil {
+			return model.MXPGVKEvent{}, err
+		}
+		r.closers = append(r.closers, f)
+
+		br := bufio.NewReader(f)
+		var body io.Reader = br
+		if magic, err := br.Peek(len(gzipMagic)); err == nil && bytes.Equal(magic, gzipMagic) {
+			gr, err := gzip.NewReader(br)
+			if err != nil {
+				return model.MXPGVKEvent{}, err
+			}
+			r.closers = append(r.closers, gr)
+			body = gr
+		}
+
+		decoder, err := json.NewMXPGVKEventDecoder(body)
+		if err != nil {
+			return model.MXPGVKEvent{}, err
+		}
+		r.decoder = decoder
+	}
+	if !r.decoder.More() {
+		return model.MXPGVKEvent{}, ErrEOF
+	}
+	return r.decoder.Decode()
+}
+
+func (r *FileEventReader) Close() error {
+	// Close closers in reverse.
+	for i := len(r.closers) - 1; i >= 0; i-- {
+		if err := r.closers[i].Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -31,6 +31,8 @@ var _ event.WindowIterator = &WindowIterator{}
 type WindowIterator struct {
 	Iter   *QueryIterator
 	Bucket *storage.BucketHandle
+	// Concurrency is the number of objects read at once within a window.
+	Concurrency int
 }
 
 // NewWindowIterator returns an initialized *WindowIterator.
@@ -54,7 +56,7 @@ func (i *WindowIterator) Next() (event.Reader, usagetime.Range, error) {
 	if err != nil {
 		return nil, usagetime.Range{}, err
 	}
-	return &QueryEventReader{Bucket: i.Bucket, Query: query}, window, nil
+	return &QueryEventReader{Bucket: i.Bucket, Query: query, Concurrency: i.Concurrency}, window, nil
 }
 
 // QueryIterator iterates through queries for usage data for an Upbound
@@ -15,7 +15,6 @@
 package gcp
 
 import (
-	"compress/gzip"
 	"context"
 	"errors"
 	"io"
@@ -23,8 +22,8 @@ import (
 	"cloud.google.com/go/storage"
 	"google.golang.org/api/iterator"
 
-	"github.com/upbound/up/internal/usage/encoding/json"
 	"github.com/upbound/up/internal/usage/event"
+	"github.com/upbound/up/internal/usage/event/reader"
 	"github.com/upbound/up/internal/usage/model"
 )
 
@@ -35,12 +34,32 @@ var _ event.Reader = &QueryEventReader{}
 type QueryEventReader struct {
 	Bucket *storage.BucketHandle
 	Query  *storage.Query
-	reader *ObjectIteratorEventReader
+	// Concurrency is the number of objects read at once. Objects are listed
+	// and read one after another if it is less than 2.
+	Concurrency int
+	reader      event.Reader
 }
 
 func (r *QueryEventReader) Read(ctx context.Context) (model.MXPGVKEvent, error) {
 	if r.reader == nil {
-		r.reader = &ObjectIteratorEventReader{Bucket: r.Bucket, Iterator: r.Bucket.Objects(ctx, r.Query)}
+		it := r.Bucket.Objects(ctx, r.Query)
+		if r.Concurrency < 2 {
+			r.reader = &ObjectIteratorEventReader{Bucket: r.Bucket, Iterator: it}
+			return r.reader.Read(ctx)
+		}
+		// All objects must be listed before they can be read concurrently.
+		readers := []event.Reader{}
+		for {
+			attrs, err := it.Next()
+			if errors.Is(err, iterator.Done) {
+				break
+			}
+			if err != nil {
+				return model.MXPGVKEvent{}, err
+			}
+			readers = append(readers, &ObjectHandleEventReader{Object: r.Bucket.Object(attrs.Name), Attrs: attrs})
+		}
+		r.reader = &reader.ParallelReader{Readers: readers, Concurrency: r.Concurrency}
 	}
 	return r.reader.Read(ctx)
 }
@@ -88,58 +107,45 @@ func (r *ObjectIteratorEventReader) Close() error {
 
 var _ event.Reader = &ObjectHandleEventReader{}
 
+// ObjectHandleEventReader reads usage events from a *storage.ObjectHandle. The
+// object is read in full on the first call to Read.
 type ObjectHandleEventReader struct {
-	Object  *storage.ObjectHandle
-	Attrs   *storage.ObjectAttrs
-	decoder *json.MXPGVKEventDecoder
-	closers []io.Closer
+	Object *storage.ObjectHandle
+	Attrs  *storage.ObjectAttrs
+	events []model.MXPGVKEvent
+	read   bool
 }
 
 func (r *ObjectHandleEventReader) Read(ctx context.Context) (model.MXPGVKEvent, error) {
-	if r.decoder == nil {
-		reader, err := r.Object.NewReader(ctx)
-		if err != nil {
-			return model.MXPGVKEvent{}, err
-		}
-
+	if !r.read {
 		contentType := ""
 		if r.Attrs != nil {
 			contentType = r.Attrs.ContentType
 		}
-
-		var body io.ReadCloser
-		switch contentType {
-		case "application/gzip":
-			fallthrough
-		case "application/x-gzip":
-			r.closers = append(r.closers, reader)
-			body, err = gzip.NewReader(reader)
-			if err != nil {
-				return model.MXPGVKEvent{}, err
-			}
-		default:
-			body = reader
-		}
-		r.closers = append(r.closers, body)
-
-		decoder, err := json.NewMXPGVKEventDecoder(body)
+		events, err := reader.ReadObject(ctx, reader.DefaultBackoff, isRetryable, func() (io.ReadCloser, string, error) {
+			or, err := r.Object.NewReader(ctx)
+			return or, contentType, err
+		})
 		if err != nil {
 			return model.MXPGVKEvent{}, err
 		}
-		r.decoder = decoder
+		r.events = events
+		r.read = true
 	}
-	if !r.decoder.More() {
+	if len(r.events) == 0 {
 		return model.MXPGVKEvent{}, ErrEOF
 	}
-	return r.decoder.Decode()
+	e := r.events[0]
+	r.events = r.events[1:]
+	return e, nil
 }
 
 func (r *ObjectHandleEventReader) Close() error {
-	// Close closers in reverse.
-	for i := len(r.closers) - 1; i >= 0; i-- {
-		if err := r.closers[i].Close(); err != nil {
-			return err
-		}
-	}
 	return nil
 }
+
+// isRetryable returns true for errors the Cloud Storage client considers
+// retryable, and for transient network and server errors.
+func isRetryable(err error) bool {
+	return storage.ShouldRetry(err) || reader.IsTransient(err)
+}
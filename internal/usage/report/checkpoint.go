@@ -0,0 +1,218 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/usage/model"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+const (
+	errReadCheckpoint  = "error reading checkpoint"
+	errWriteCheckpoint = "error writing checkpoint"
+)
+
+// Checkpoint records the aggregated events of completed windows of a report
+// in a file, so that an interrupted report can be resumed without reading
+// those windows again.
+//
+// The file contains one JSON object per line. The first line holds the
+// parameters of the report, every following line a completed window. Lines
+// are only appended, so a checkpoint interrupted while being written loses at
+// most the window being written.
+type Checkpoint struct {
+	fs      afero.Fs
+	path    string
+	mu      sync.Mutex
+	f       afero.File
+	windows map[time.Time]checkpointWindow
+}
+
+type checkpointHeader struct {
+	Params json.RawMessage `json:"params"`
+}
+
+type checkpointWindow struct {
+	Window usagetime.Range     `json:"window"`
+	Events []model.MXPGVKEvent `json:"events"`
+}
+
+// OpenCheckpoint opens the checkpoint at path for a report with the supplied
+// parameters. Completed windows are loaded from an existing checkpoint with
+// the same parameters. A checkpoint for different parameters is replaced.
+// Callers must call Close() when finished with the checkpoint.
+func OpenCheckpoint(fs afero.Fs, path string, params any) (*Checkpoint, error) {
+	p, err := json.Marshal(params)
+	if err != nil {
+		return nil, errors.Wrap(err, errReadCheckpoint)
+	}
+	c := &Checkpoint{fs: fs, path: path, windows: map[time.Time]checkpointWindow{}}
+
+	ok, err := c.load(p)
+	if err != nil {
+		return nil, errors.Wrap(err, errReadCheckpoint)
+	}
+	if ok {
+		if c.f, err = fs.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600); err != nil {
+			return nil, errors.Wrap(err, errWriteCheckpoint)
+		}
+		return c, nil
+	}
+
+	c.windows = map[time.Time]checkpointWindow{}
+	if c.f, err = fs.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600); err != nil {
+		return nil, errors.Wrap(err, errWriteCheckpoint)
+	}
+	if err := c.writeLine(checkpointHeader{Params: p}); err != nil {
+		_ = c.f.Close()
+		return nil, errors.Wrap(err, errWriteCheckpoint)
+	}
+	return c, nil
+}
+
+// HasCheckpoint returns true if a checkpoint for a report with the supplied
+// parameters exists at path, i.e. if OpenCheckpoint would resume it.
+func HasCheckpoint(fs afero.Fs, path string, params any) (bool, error) {
+	p, err := json.Marshal(params)
+	if err != nil {
+		return false, errors.Wrap(err, errReadCheckpoint)
+	}
+	f, err := fs.Open(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, errReadCheckpoint)
+	}
+	defer f.Close() // nolint:errcheck
+	line, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil {
+		return false, nil //nolint:nilerr // A checkpoint without a complete header is replaced.
+	}
+	h := checkpointHeader{}
+	if err := json.Unmarshal(line, &h); err != nil {
+		return false, nil //nolint:nilerr // An unreadable checkpoint is replaced.
+	}
+	return bytes.Equal(h.Params, p), nil
+}
+
+// load loads the windows of an existing checkpoint. It returns false if there
+// is no checkpoint for the supplied parameters.
+func (c *Checkpoint) load(params []byte) (bool, error) {
+	b, err := afero.ReadFile(c.fs, c.path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	s := bufio.NewScanner(bytes.NewReader(b))
+	s.Buffer(nil, len(b)+1)
+	if !s.Scan() {
+		return false, nil
+	}
+	h := checkpointHeader{}
+	if err := json.Unmarshal(s.Bytes(), &h); err != nil || !bytes.Equal(h.Params, params) {
+		return false, nil //nolint:nilerr // An unreadable checkpoint is replaced.
+	}
+	for s.Scan() {
+		w := checkpointWindow{}
+		if err := json.Unmarshal(s.Bytes(), &w); err != nil {
+			// A line is incomplete if writing it was interrupted.
+			// Its window is read again.
+			continue
+		}
+		c.windows[w.Window.Start] = w
+	}
+	// Terminate an incomplete last line so that appended windows start on
+	// a new line.
+	if len(b) > 0 && b[len(b)-1] != '\n' {
+		f, err := c.fs.OpenFile(c.path, os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return false, err
+		}
+		if _, err := f.Write([]byte("\n")); err != nil {
+			_ = f.Close()
+			return false, err
+		}
+		return true, f.Close()
+	}
+	return true, s.Err()
+}
+
+// Len returns the number of completed windows in the checkpoint.
+func (c *Checkpoint) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.windows)
+}
+
+// Get returns the aggregated events of a window if it was completed.
+func (c *Checkpoint) Get(window usagetime.Range) ([]model.MXPGVKEvent, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w, ok := c.windows[window.Start]
+	if !ok || !w.Window.End.Equal(window.End) {
+		return nil, false
+	}
+	return w.Events, true
+}
+
+// Put records the aggregated events of a completed window.
+func (c *Checkpoint) Put(window usagetime.Range, events []model.MXPGVKEvent) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := checkpointWindow{Window: window, Events: events}
+	if err := c.writeLine(w); err != nil {
+		return errors.Wrap(err, errWriteCheckpoint)
+	}
+	c.windows[window.Start] = w
+	return nil
+}
+
+// Close closes the checkpoint file.
+func (c *Checkpoint) Close() error {
+	return c.f.Close()
+}
+
+// Remove closes and removes the checkpoint file. Call it once the report is
+// complete.
+func (c *Checkpoint) Remove() error {
+	if err := c.f.Close(); err != nil {
+		return err
+	}
+	return c.fs.Remove(c.path)
+}
+
+func (c *Checkpoint) writeLine(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := c.f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	return c.f.Sync()
+}
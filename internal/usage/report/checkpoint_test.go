@@ -0,0 +1,289 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/usage/aggregate"
+	"github.com/upbound/up/internal/usage/model"
+	usagetesting "github.com/upbound/up/internal/usage/testing"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+func TestCheckpoint(t *testing.T) {
+	w1 := usagetime.Range{Start: time.Date(2006, 5, 4, 0, 0, 0, 0, time.UTC), End: time.Date(2006, 5, 4, 1, 0, 0, 0, time.UTC)}
+	w2 := usagetime.Range{Start: time.Date(2006, 5, 4, 1, 0, 0, 0, time.UTC), End: time.Date(2006, 5, 4, 2, 0, 0, 0, time.UTC)}
+	e1 := []model.MXPGVKEvent{{Name: "event-1", Value: 1, Timestamp: w1.Start, TimestampEnd: w1.End}}
+	e2 := []model.MXPGVKEvent{{Name: "event-2", Value: 2, Timestamp: w2.Start, TimestampEnd: w2.End}}
+	params := map[string]string{"account": "test-account"}
+
+	type args struct {
+		// setup writes a checkpoint before it is opened.
+		setup  func(t *testing.T, fs afero.Fs)
+		params any
+	}
+	type want struct {
+		windows map[usagetime.Range][]model.MXPGVKEvent
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoCheckpoint": {
+			reason: "A new checkpoint has no completed windows.",
+			args: args{
+				setup:  func(*testing.T, afero.Fs) {},
+				params: params,
+			},
+			want: want{
+				windows: map[usagetime.Range][]model.MXPGVKEvent{},
+			},
+		},
+		"Resume": {
+			reason: "Windows of a checkpoint with the same parameters are loaded.",
+			args: args{
+				setup: func(t *testing.T, fs afero.Fs) {
+					c, err := OpenCheckpoint(fs, "report.checkpoint", params)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if err := c.Put(w1, e1); err != nil {
+						t.Fatal(err)
+					}
+					if err := c.Put(w2, e2); err != nil {
+						t.Fatal(err)
+					}
+					if err := c.Close(); err != nil {
+						t.Fatal(err)
+					}
+				},
+				params: params,
+			},
+			want: want{
+				windows: map[usagetime.Range][]model.MXPGVKEvent{w1: e1, w2: e2},
+			},
+		},
+		"DifferentParams": {
+			reason: "A checkpoint with different parameters is replaced.",
+			args: args{
+				setup: func(t *testing.T, fs afero.Fs) {
+					c, err := OpenCheckpoint(fs, "report.checkpoint", params)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if err := c.Put(w1, e1); err != nil {
+						t.Fatal(err)
+					}
+					if err := c.Close(); err != nil {
+						t.Fatal(err)
+					}
+				},
+				params: map[string]string{"account": "other-account"},
+			},
+			want: want{
+				windows: map[usagetime.Range][]model.MXPGVKEvent{},
+			},
+		},
+		"IncompleteLastWindow": {
+			reason: "A window that was not completely written is ignored.",
+			args: args{
+				setup: func(t *testing.T, fs afero.Fs) {
+					c, err := OpenCheckpoint(fs, "report.checkpoint", params)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if err := c.Put(w1, e1); err != nil {
+						t.Fatal(err)
+					}
+					if _, err := c.f.Write([]byte(`{"window":{"start":"2006-05-04T01:00:00Z`)); err != nil {
+						t.Fatal(err)
+					}
+					if err := c.Close(); err != nil {
+						t.Fatal(err)
+					}
+				},
+				params: params,
+			},
+			want: want{
+				windows: map[usagetime.Range][]model.MXPGVKEvent{w1: e1},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			tc.args.setup(t, fs)
+
+			c, err := OpenCheckpoint(fs, "report.checkpoint", tc.args.params)
+			if err != nil {
+				t.Fatalf("\n%s\nOpenCheckpoint(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(len(tc.want.windows), c.Len()); diff != "" {
+				t.Errorf("\n%s\nCheckpoint.Len(): -want, +got:\n%s", tc.reason, diff)
+			}
+			for w, want := range tc.want.windows {
+				got, ok := c.Get(w)
+				if !ok {
+					t.Errorf("\n%s\nCheckpoint.Get(%v): window is not completed", tc.reason, w)
+				}
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("\n%s\nCheckpoint.Get(%v): -want, +got:\n%s", tc.reason, w, diff)
+				}
+			}
+
+			// Windows completed after resuming must be loaded by the next
+			// resume.
+			if err := c.Put(w2, e2); err != nil {
+				t.Fatalf("\n%s\nCheckpoint.Put(...): %v", tc.reason, err)
+			}
+			if err := c.Close(); err != nil {
+				t.Fatalf("\n%s\nCheckpoint.Close(): %v", tc.reason, err)
+			}
+			c, err = OpenCheckpoint(fs, "report.checkpoint", tc.args.params)
+			if err != nil {
+				t.Fatalf("\n%s\nOpenCheckpoint(...): %v", tc.reason, err)
+			}
+			got, _ := c.Get(w2)
+			if diff := cmp.Diff(e2, got); diff != "" {
+				t.Errorf("\n%s\nCheckpoint.Get(...) after resuming: -want, +got:\n%s", tc.reason, diff)
+			}
+			if err := c.Remove(); err != nil {
+				t.Errorf("\n%s\nCheckpoint.Remove(): %v", tc.reason, err)
+			}
+		})
+	}
+}
+
+func TestHasCheckpoint(t *testing.T) {
+	params := map[string]string{"account": "test-account"}
+
+	cases := map[string]struct {
+		reason string
+		setup  func(t *testing.T, fs afero.Fs)
+		params any
+		want   bool
+	}{
+		"NoCheckpoint": {
+			reason: "There is no checkpoint if the file does not exist.",
+			setup:  func(*testing.T, afero.Fs) {},
+			params: params,
+			want:   false,
+		},
+		"SameParams": {
+			reason: "A checkpoint with the same parameters exists.",
+			setup: func(t *testing.T, fs afero.Fs) {
+				c, err := OpenCheckpoint(fs, "report.checkpoint", params)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := c.Close(); err != nil {
+					t.Fatal(err)
+				}
+			},
+			params: params,
+			want:   true,
+		},
+		"DifferentParams": {
+			reason: "A checkpoint with different parameters would be replaced.",
+			setup: func(t *testing.T, fs afero.Fs) {
+				c, err := OpenCheckpoint(fs, "report.checkpoint", params)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := c.Close(); err != nil {
+					t.Fatal(err)
+				}
+			},
+			params: map[string]string{"account": "other-account"},
+			want:   false,
+		},
+		"Unreadable": {
+			reason: "An unreadable checkpoint would be replaced.",
+			setup: func(t *testing.T, fs afero.Fs) {
+				if err := afero.WriteFile(fs, "report.checkpoint", []byte(`{"params":`), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			params: params,
+			want:   false,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			tc.setup(t, fs)
+
+			got, err := HasCheckpoint(fs, "report.checkpoint", tc.params)
+			if err != nil {
+				t.Fatalf("\n%s\nHasCheckpoint(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nHasCheckpoint(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAggregateWithCheckpoint(t *testing.T) {
+	w1 := usagetime.Range{Start: time.Date(2006, 5, 4, 0, 0, 0, 0, time.UTC), End: time.Date(2006, 5, 4, 1, 0, 0, 0, time.UTC)}
+	w2 := usagetime.Range{Start: time.Date(2006, 5, 4, 1, 0, 0, 0, time.UTC), End: time.Date(2006, 5, 4, 2, 0, 0, 0, time.UTC)}
+	cached := []model.MXPGVKEvent{{Name: "max_resource_count_per_gvk_per_mxp", Value: 7, Timestamp: w1.Start, TimestampEnd: w1.End}}
+	tags := model.MXPGVKEventTags{Group: "example.com", Version: "v1", Kind: "Thing", MXPID: "mxp1"}
+
+	fs := afero.NewMemMapFs()
+	c, err := OpenCheckpoint(fs, "report.checkpoint", "params")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Put(w1, cached); err != nil {
+		t.Fatal(err)
+	}
+
+	iter := &usagetesting.MockWindowIterator{Windows: []usagetesting.Window{
+		// The first window must not be read since it is in the checkpoint.
+		{Window: w1, Reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{{Err: fmt.Errorf("boom")}}}},
+		{Window: w2, Reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{
+			{Event: model.MXPGVKEvent{Name: "kube_managedresource_uid", Value: 3, Tags: tags}},
+		}}},
+	}}
+	w := &usagetesting.MockWriter{}
+	err = Aggregate(context.Background(), iter, w, []aggregate.NewFn{func(usagetime.Range) aggregate.Aggregator {
+		return &aggregate.MaxResourceCountPerGVKPerMXP{}
+	}}, WithCheckpoint(c), WithConcurrency(2))
+	if diff := cmp.Diff(nil, err, test.EquateErrors()); diff != "" {
+		t.Errorf("Aggregate(...): -want err, +got err:\n%s", diff)
+	}
+
+	read := model.MXPGVKEvent{Name: "max_resource_count_per_gvk_per_mxp", Value: 3, Tags: tags, Timestamp: w2.Start, TimestampEnd: w2.End}
+	if diff := cmp.Diff(append(cached, read), w.Events); diff != "" {
+		t.Errorf("Aggregate(...): -want events, +got events:\n%s", diff)
+	}
+	got, ok := c.Get(w2)
+	if !ok {
+		t.Errorf("Aggregate(...): window %v was not recorded in the checkpoint", w2)
+	}
+	if diff := cmp.Diff([]model.MXPGVKEvent{read}, got); diff != "" {
+		t.Errorf("Aggregate(...): -want checkpoint, +got checkpoint:\n%s", diff)
+	}
+}
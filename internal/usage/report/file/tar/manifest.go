@@ -0,0 +1,137 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tar
+
+import (
+	"archive/tar"
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up/internal/usage/report/sign"
+)
+
+const (
+	errNoManifest       = "report does not contain a manifest"
+	errReadManifest     = "error reading report manifest"
+	errReadSignature    = "error reading report signature"
+	errNotSigned        = "report is not signed"
+	errVerifySignature  = "error verifying report signature"
+	errFmtChecksum      = "checksum of %s does not match the manifest"
+	errFmtMissingFile   = "report does not contain %s listed in the manifest"
+	errFmtUnlistedFile  = "report contains %s which is not listed in the manifest"
+	errFmtDuplicateFile = "report contains %s more than once"
+)
+
+// Manifest lists the files of a usage report with their checksums.
+type Manifest struct {
+	// Files maps the name of each file in the report to the hex encoded
+	// SHA-256 checksum of its content.
+	Files map[string]string `json:"files"`
+}
+
+// Verification is the result of verifying a usage report.
+type Verification struct {
+	// Files are the names of the files whose checksums were verified.
+	Files []string
+	// Signed is true if the report contains a signature.
+	Signed bool
+	// SignatureVerified is true if the signature of the report was verified.
+	SignatureVerified bool
+}
+
+// Verify reads a usage report from a tar archive and verifies the checksums
+// of its files against its manifest. If pub is not nil the report must be
+// signed and the signature of its manifest is verified with pub.
+func Verify(tr *tar.Reader, pub crypto.PublicKey) (Verification, error) { //nolint:gocyclo
+	files := map[string][]byte{}
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Verification{}, err
+		}
+		if _, ok := files[h.Name]; ok {
+			return Verification{}, errors.Errorf(errFmtDuplicateFile, h.Name)
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			return Verification{}, err
+		}
+		files[h.Name] = b
+	}
+
+	mb, ok := files[manifestFilename]
+	if !ok {
+		return Verification{}, errors.New(errNoManifest)
+	}
+	m := Manifest{}
+	if err := json.Unmarshal(mb, &m); err != nil {
+		return Verification{}, errors.Wrap(err, errReadManifest)
+	}
+
+	v := Verification{}
+	for name, b := range files {
+		if name == manifestFilename || name == signatureFilename {
+			continue
+		}
+		sum, ok := m.Files[name]
+		if !ok {
+			return Verification{}, errors.Errorf(errFmtUnlistedFile, name)
+		}
+		if checksum(b) != sum {
+			return Verification{}, errors.Errorf(errFmtChecksum, name)
+		}
+		v.Files = append(v.Files, name)
+	}
+	for name := range m.Files {
+		if _, ok := files[name]; !ok {
+			return Verification{}, errors.Errorf(errFmtMissingFile, name)
+		}
+	}
+	sort.Strings(v.Files)
+
+	sb, ok := files[signatureFilename]
+	v.Signed = ok
+	if pub == nil {
+		return v, nil
+	}
+	if !ok {
+		return Verification{}, errors.New(errNotSigned)
+	}
+	sig, err := base64.StdEncoding.DecodeString(string(sb))
+	if err != nil {
+		return Verification{}, errors.Wrap(err, errReadSignature)
+	}
+	if err := sign.Verify(pub, mb, sig); err != nil {
+		return Verification{}, errors.Wrap(err, errVerifySignature)
+	}
+	v.SignatureVerified = true
+	return v, nil
+}
+
+// checksum returns the hex encoded SHA-256 checksum of b.
+func checksum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,227 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tar
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/usage/model"
+	"github.com/upbound/up/internal/usage/report"
+)
+
+// writeReport returns a report with a single event written by a Writer with
+// the supplied options.
+func writeReport(t *testing.T, opts ...WriterOption) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	rw, err := NewWriter(tw, report.Meta{UpboundAccount: "test-account"}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rw.Write(model.MXPGVKEvent{Name: "test", Value: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// editReport returns a copy of a report with its files modified by fn. Files
+// for which fn returns nil are removed.
+func editReport(t *testing.T, b []byte, fn func(name string, content []byte) []byte) []byte {
+	t.Helper()
+	tr := tar.NewReader(bytes.NewReader(b))
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if content = fn(h.Name, content); content == nil {
+			continue
+		}
+		if err := writeFile(tw, h.Name, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestVerify(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	unsigned := writeReport(t)
+	signed := writeReport(t, WithSigner(priv))
+	files := []string{metaFilename, usageFilename}
+
+	type args struct {
+		report []byte
+		pub    crypto.PublicKey
+	}
+	type want struct {
+		v   Verification
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Unsigned": {
+			reason: "The checksums of an unsigned report should be verified.",
+			args: args{
+				report: unsigned,
+			},
+			want: want{
+				v: Verification{Files: files},
+			},
+		},
+		"SignedWithoutKey": {
+			reason: "The checksums of a signed report should be verified without a public key.",
+			args: args{
+				report: signed,
+			},
+			want: want{
+				v: Verification{Files: files, Signed: true},
+			},
+		},
+		"Signed": {
+			reason: "The signature of a signed report should be verified with its public key.",
+			args: args{
+				report: signed,
+				pub:    pub,
+			},
+			want: want{
+				v: Verification{Files: files, Signed: true, SignatureVerified: true},
+			},
+		},
+		"SignedWithOtherKey": {
+			reason: "Verifying the signature of a report with another public key should return an error.",
+			args: args{
+				report: signed,
+				pub:    otherPub,
+			},
+			want: want{
+				err: errors.Wrap(errors.New("signature is invalid"), errVerifySignature),
+			},
+		},
+		"NotSigned": {
+			reason: "Verifying the signature of an unsigned report should return an error.",
+			args: args{
+				report: unsigned,
+				pub:    pub,
+			},
+			want: want{
+				err: errors.New(errNotSigned),
+			},
+		},
+		"EditedFile": {
+			reason: "A report with a file that does not match its checksum should return an error.",
+			args: args{
+				report: editReport(t, signed, func(name string, b []byte) []byte {
+					if name == usageFilename {
+						return bytes.Replace(b, []byte("test-account"), []byte("edit-account"), 1)
+					}
+					return b
+				}),
+				pub: pub,
+			},
+			want: want{
+				err: errors.Errorf(errFmtChecksum, usageFilename),
+			},
+		},
+		"EditedManifest": {
+			reason: "A signed report with a manifest that does not match its signature should return an error.",
+			args: args{
+				report: editReport(t, signed, func(name string, b []byte) []byte {
+					if name == manifestFilename {
+						return append(b, '\n')
+					}
+					return b
+				}),
+				pub: pub,
+			},
+			want: want{
+				err: errors.Wrap(errors.New("signature is invalid"), errVerifySignature),
+			},
+		},
+		"RemovedFile": {
+			reason: "A report missing a file listed in its manifest should return an error.",
+			args: args{
+				report: editReport(t, unsigned, func(name string, b []byte) []byte {
+					if name == metaFilename {
+						return nil
+					}
+					return b
+				}),
+			},
+			want: want{
+				err: errors.Errorf(errFmtMissingFile, metaFilename),
+			},
+		},
+		"NoManifest": {
+			reason: "A report without a manifest should return an error.",
+			args: args{
+				report: editReport(t, unsigned, func(name string, b []byte) []byte {
+					if name == manifestFilename {
+						return nil
+					}
+					return b
+				}),
+			},
+			want: want{
+				err: errors.New(errNoManifest),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v, err := Verify(tar.NewReader(bytes.NewReader(tc.args.report)), tc.args.pub)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nVerify(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.v, v); diff != "" {
+				t.Errorf("\n%s\nVerify(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tar
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"io"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	usagejson "github.com/upbound/up/internal/usage/encoding/json"
+	"github.com/upbound/up/internal/usage/model"
+	"github.com/upbound/up/internal/usage/report"
+)
+
+const (
+	errReadMeta    = "error reading report metadata"
+	errReadUsage   = "error reading report usage"
+	errFmtNotFound = "report does not contain %s"
+)
+
+// Read reads the metadata and Upbound usage events of a usage report from a
+// tar archive written by a Writer.
+func Read(tr *tar.Reader) (report.Meta, []model.MXPGVKEvent, error) {
+	var meta report.Meta
+	var events []model.MXPGVKEvent
+	var foundMeta, foundUsage bool
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return report.Meta{}, nil, err
+		}
+		switch h.Name {
+		case metaFilename:
+			if err := json.NewDecoder(tr).Decode(&meta); err != nil {
+				return report.Meta{}, nil, errors.Wrap(err, errReadMeta)
+			}
+			foundMeta = true
+		case usageFilename:
+			if events, err = readUsage(tr); err != nil {
+				return report.Meta{}, nil, errors.Wrap(err, errReadUsage)
+			}
+			foundUsage = true
+		}
+	}
+	if !foundMeta {
+		return report.Meta{}, nil, errors.Errorf(errFmtNotFound, metaFilename)
+	}
+	if !foundUsage {
+		return report.Meta{}, nil, errors.Errorf(errFmtNotFound, usageFilename)
+	}
+	return meta, events, nil
+}
+
+// readUsage reads Upbound usage events from a JSON array.
+func readUsage(r io.Reader) ([]model.MXPGVKEvent, error) {
+	d, err := usagejson.NewMXPGVKEventDecoder(r)
+	if err != nil {
+		return nil, err
+	}
+	events := []model.MXPGVKEvent{}
+	for d.More() {
+		e, err := d.Decode()
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
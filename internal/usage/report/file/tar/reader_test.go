@@ -0,0 +1,108 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tar
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/usage/model"
+	"github.com/upbound/up/internal/usage/report"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+func TestRead(t *testing.T) {
+	meta := report.Meta{
+		UpboundAccount: "test-account",
+		TimeRange: usagetime.Range{
+			Start: time.Date(2006, 5, 4, 3, 2, 1, 0, time.UTC),
+			End:   time.Date(2006, 5, 4, 4, 2, 1, 0, time.UTC),
+		},
+		CollectedAt: time.Date(2006, 5, 4, 3, 2, 1, 0, time.UTC),
+	}
+	event := model.MXPGVKEvent{Tags: model.MXPGVKEventTags{UpboundAccount: "test-account"}}
+
+	type args struct {
+		file string
+	}
+	type want struct {
+		meta   report.Meta
+		events []model.MXPGVKEvent
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoEvents": {
+			reason: "Reading a report without events should return its metadata.",
+			args: args{
+				file: "testdata/empty.tar",
+			},
+			want: want{
+				meta:   meta,
+				events: []model.MXPGVKEvent{},
+			},
+		},
+		"MultipleEvents": {
+			reason: "Reading a report should return its metadata and all events.",
+			args: args{
+				file: "testdata/example.tar",
+			},
+			want: want{
+				meta:   meta,
+				events: []model.MXPGVKEvent{event, event, event, event, event, event, event},
+			},
+		},
+		"NotAReport": {
+			reason: "Reading an archive that is not a report should return an error.",
+			want: want{
+				meta: report.Meta{},
+				err:  errors.Errorf(errFmtNotFound, metaFilename),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			b := []byte{}
+			if tc.args.file != "" {
+				var err error
+				if b, err = os.ReadFile(tc.args.file); err != nil {
+					t.Fatalf("os.ReadFile(...): %v", err)
+				}
+			}
+
+			meta, events, err := Read(tar.NewReader(bytes.NewReader(b)))
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRead(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.meta, meta); diff != "" {
+				t.Errorf("\n%s\nRead(...): -want meta, +got meta:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, events); diff != "" {
+				t.Errorf("\n%s\nRead(...): -want events, +got events:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
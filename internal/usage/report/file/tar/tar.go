@@ -17,37 +17,59 @@ package tar
 import (
 	"archive/tar"
 	"bytes"
+	"crypto"
+	"encoding/base64"
 	"encoding/json"
 
 	usagejson "github.com/upbound/up/internal/usage/encoding/json"
 	"github.com/upbound/up/internal/usage/model"
 	"github.com/upbound/up/internal/usage/report"
+	"github.com/upbound/up/internal/usage/report/sign"
 )
 
 const (
-	metaFilename  = "report/meta.json"
-	usageFilename = "report/usage.json"
-	mode          = 0644
+	metaFilename      = "report/meta.json"
+	usageFilename     = "report/usage.json"
+	manifestFilename  = "report/manifest.json"
+	signatureFilename = "report/manifest.json.sig"
+	mode              = 0644
 )
 
 // Writer writes Upbound usage events for a single account to a usage report in
 // a tar archive. Must be initialized with NewWriter(). Callers must call
 // Close() on the writer when finished writing to it.
 type Writer struct {
-	tw   *tar.Writer
-	meta report.Meta
-	ee   *usagejson.MXPGVKEventEncoder
-	buf  *bytes.Buffer
+	tw       *tar.Writer
+	meta     report.Meta
+	ee       *usagejson.MXPGVKEventEncoder
+	buf      *bytes.Buffer
+	signer   crypto.Signer
+	manifest Manifest
+}
+
+// WriterOption configures a Writer.
+type WriterOption func(*Writer)
+
+// WithSigner signs the manifest of the report with the supplied key. The
+// signature is written to the report as a detached signature.
+func WithSigner(s crypto.Signer) WriterOption {
+	return func(w *Writer) {
+		w.signer = s
+	}
 }
 
 // NewWriter returns an initialized *Writer.
-func NewWriter(tw *tar.Writer, meta report.Meta) (*Writer, error) {
+func NewWriter(tw *tar.Writer, meta report.Meta, opts ...WriterOption) (*Writer, error) {
 	buf := &bytes.Buffer{}
 	ue, err := usagejson.NewMXPGVKEventEncoder(buf)
 	if err != nil {
 		return nil, err
 	}
-	return &Writer{tw: tw, meta: meta, ee: ue, buf: buf}, nil
+	w := &Writer{tw: tw, meta: meta, ee: ue, buf: buf, manifest: Manifest{Files: map[string]string{}}}
+	for _, o := range opts {
+		o(w)
+	}
+	return w, nil
 }
 
 // Write writes an Upbound usage event to a tar archive.
@@ -56,38 +78,50 @@ func (w *Writer) Write(e model.MXPGVKEvent) error {
 	return w.ee.Encode(e)
 }
 
-// Close closes the writer.
+// Close closes the writer. The manifest of the report, and its signature if
+// the writer has a signer, are written after all other files.
 func (w *Writer) Close() error {
 	if err := w.ee.Close(); err != nil {
 		return err
 	}
-	if err := writeMeta(w.tw, w.meta); err != nil {
+	meta, err := json.MarshalIndent(w.meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := w.writeFile(metaFilename, meta); err != nil {
 		return err
 	}
-	return writeUsage(w.tw, w.buf.Bytes())
-}
-
-// writeMeta writes usage report metadata to a *tar.Writer.
-func writeMeta(tw *tar.Writer, meta report.Meta) error {
-	b, err := json.MarshalIndent(meta, "", "  ")
+	if err := w.writeFile(usageFilename, w.buf.Bytes()); err != nil {
+		return err
+	}
+	manifest, err := json.MarshalIndent(w.manifest, "", "  ")
 	if err != nil {
 		return err
 	}
-	if err := tw.WriteHeader(&tar.Header{
-		Name: metaFilename,
-		Mode: mode,
-		Size: int64(len(b)),
-	}); err != nil {
+	if err := writeFile(w.tw, manifestFilename, manifest); err != nil {
 		return err
 	}
-	_, err = tw.Write(b)
-	return err
+	if w.signer == nil {
+		return nil
+	}
+	sig, err := sign.Sign(w.signer, manifest)
+	if err != nil {
+		return err
+	}
+	return writeFile(w.tw, signatureFilename, []byte(base64.StdEncoding.EncodeToString(sig)))
+}
+
+// writeFile writes a file to the report and records its checksum in the
+// manifest.
+func (w *Writer) writeFile(name string, b []byte) error {
+	w.manifest.Files[name] = checksum(b)
+	return writeFile(w.tw, name, b)
 }
 
-// writeUsage writes usage data to a *tar.Writer.
-func writeUsage(tw *tar.Writer, b []byte) error {
+// writeFile writes a file to a *tar.Writer.
+func writeFile(tw *tar.Writer, name string, b []byte) error {
 	if err := tw.WriteHeader(&tar.Header{
-		Name: usageFilename,
+		Name: name,
 		Mode: mode,
 		Size: int64(len(b)),
 	}); err != nil {
@@ -16,12 +16,15 @@ package report
 
 import (
 	"context"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 
 	"github.com/upbound/up/internal/usage/aggregate"
 	"github.com/upbound/up/internal/usage/event"
+	"github.com/upbound/up/internal/usage/model"
 	usagetime "github.com/upbound/up/internal/usage/time"
 )
 
@@ -35,43 +38,197 @@ type Meta struct {
 	UpboundAccount string          `json:"account"`
 	TimeRange      usagetime.Range `json:"time_range"`
 	CollectedAt    time.Time       `json:"collected_at"`
+	// Aggregations are the names of the aggregations included in the
+	// report. Reports without aggregations include only the default
+	// aggregation.
+	Aggregations []string `json:"aggregations,omitempty"`
+	// Window is the window of time usage is aggregated over, formatted as
+	// a Go duration.
+	Window string `json:"window,omitempty"`
+	// TimeZone is the IANA name of the time zone the billing period was
+	// defined in.
+	TimeZone string `json:"time_zone,omitempty"`
 }
 
 // MaxResourceCountPerGVKPerMXP reads events from i and writes aggregated events
 // to w. Events are aggregated across each window of time returned by i. An
 // aggregated event records the largest observed count of instances of a GVK on
-// an MXP during a window. The order of written events is not stable.
+// an MXP during a window.
 func MaxResourceCountPerGVKPerMXP(ctx context.Context, i event.WindowIterator, w event.Writer) error {
-	for i.More() {
-		r, window, err := i.Next()
-		if err != nil {
-			return errors.Wrap(err, errReadEvents)
-		}
+	return Aggregate(ctx, i, w, []aggregate.NewFn{func(usagetime.Range) aggregate.Aggregator {
+		return &aggregate.MaxResourceCountPerGVKPerMXP{}
+	}})
+}
+
+// An AggregateOption configures Aggregate.
+type AggregateOption func(*aggregateOptions)
+
+type aggregateOptions struct {
+	concurrency int
+	checkpoint  *Checkpoint
+}
+
+// WithConcurrency reads up to n windows at once.
+func WithConcurrency(n int) AggregateOption {
+	return func(o *aggregateOptions) {
+		o.concurrency = n
+	}
+}
+
+// WithCheckpoint records the aggregated events of each window in the supplied
+// checkpoint once they are written. Windows already recorded in the checkpoint
+// are not read again.
+func WithCheckpoint(c *Checkpoint) AggregateOption {
+	return func(o *aggregateOptions) {
+		o.checkpoint = c
+	}
+}
 
-		ag := &aggregate.MaxResourceCountPerGVKPerMXP{}
-		for {
-			e, err := r.Read(ctx)
-			if errors.Is(err, event.ErrEOF) {
-				break
+// Aggregate reads events from i and writes events aggregated by each of the
+// supplied aggregations to w. Events are aggregated across each window of time
+// returned by i. Each window is read once.
+//
+// Windows are read one after another unless WithConcurrency is supplied.
+// Events are written in the order of their windows and, within a window, in
+// the order of the aggregations and then sorted by their tags, so the output
+// does not depend on concurrency. Only the aggregated events of a window are
+// held in memory while it waits to be written, so memory use grows with
+// concurrency times the memory used by the readers of a window.
+func Aggregate(ctx context.Context, i event.WindowIterator, w event.Writer, aggs []aggregate.NewFn, opts ...AggregateOption) error { //nolint:gocyclo
+	o := &aggregateOptions{concurrency: 1}
+	for _, fn := range opts {
+		fn(o)
+	}
+	concurrency := o.concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	// Readers that are still being read are stopped and waited for, so that
+	// none is used after Aggregate returns.
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	// Results are queued in window order. The queue holds concurrency-1
+	// windows in addition to the one being waited for.
+	results := make(chan chan windowResult, concurrency-1)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(results)
+		for i.More() {
+			r, window, err := i.Next()
+			res := make(chan windowResult, 1)
+			select {
+			case results <- res:
+			case <-ctx.Done():
+				if r != nil {
+					_ = r.Close()
+				}
+				return
 			}
 			if err != nil {
-				return err
+				res <- windowResult{err: errors.Wrap(err, errReadEvents)}
+				return
 			}
-			if err := ag.Add(e); err != nil {
+			if o.checkpoint != nil {
+				if events, ok := o.checkpoint.Get(window); ok {
+					res <- windowResult{window: window, events: events, done: true, err: r.Close()}
+					continue
+				}
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				events, err := aggregateWindow(ctx, r, window, aggs)
+				res <- windowResult{window: window, events: events, err: err}
+			}()
+		}
+	}()
+
+	for res := range results {
+		r := <-res
+		if r.err != nil {
+			return r.err
+		}
+		for _, e := range r.events {
+			if err := w.Write(e); err != nil {
+				return errors.Wrap(err, errWriteEvents)
+			}
+		}
+		if o.checkpoint != nil && !r.done {
+			if err := o.checkpoint.Put(r.window, r.events); err != nil {
 				return err
 			}
 		}
-		if err := r.Close(); err != nil {
-			return errors.Wrap(err, errReadEvents)
+	}
+	return ctx.Err()
+}
+
+type windowResult struct {
+	window usagetime.Range
+	events []model.MXPGVKEvent
+	// done is true if the window was already recorded in the checkpoint.
+	done bool
+	err  error
+}
+
+// aggregateWindow reads all events from r and returns the events aggregated by
+// each of the supplied aggregations for the window.
+func aggregateWindow(ctx context.Context, r event.Reader, window usagetime.Range, aggs []aggregate.NewFn) ([]model.MXPGVKEvent, error) {
+	ags := make([]aggregate.Aggregator, len(aggs))
+	for j, fn := range aggs {
+		ags[j] = fn(window)
+	}
+	for {
+		e, err := r.Read(ctx)
+		if errors.Is(err, event.ErrEOF) {
+			break
+		}
+		if err != nil {
+			_ = r.Close()
+			return nil, err
 		}
+		for _, ag := range ags {
+			if err := ag.Add(e); err != nil {
+				_ = r.Close()
+				return nil, err
+			}
+		}
+	}
+	if err := r.Close(); err != nil {
+		return nil, errors.Wrap(err, errReadEvents)
+	}
 
-		for _, e := range ag.UpboundEvents() {
+	events := []model.MXPGVKEvent{}
+	for _, ag := range ags {
+		agEvents := ag.UpboundEvents()
+		sortEvents(agEvents)
+		for _, e := range agEvents {
 			e.Timestamp = window.Start
 			e.TimestampEnd = window.End
-			if err := w.Write(e); err != nil {
-				return errors.Wrap(err, errWriteEvents)
-			}
+			events = append(events, e)
 		}
 	}
-	return nil
+	return events, nil
+}
+
+// sortEvents sorts events by their tags.
+func sortEvents(events []model.MXPGVKEvent) {
+	sort.Slice(events, func(i, j int) bool {
+		a, b := events[i].Tags, events[j].Tags
+		if a.MXPID != b.MXPID {
+			return a.MXPID < b.MXPID
+		}
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		return a.Kind < b.Kind
+	})
 }
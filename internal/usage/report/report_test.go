@@ -23,6 +23,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/google/go-cmp/cmp"
 
+	"github.com/upbound/up/internal/usage/aggregate"
 	"github.com/upbound/up/internal/usage/model"
 	usagetesting "github.com/upbound/up/internal/usage/testing"
 	usagetime "github.com/upbound/up/internal/usage/time"
@@ -395,3 +396,141 @@ func TestMaxResourceCountPerGVKPerMXP(t *testing.T) {
 		})
 	}
 }
+
+func TestAggregate(t *testing.T) {
+	mrCount := func(mxp string, value float64) usagetesting.ReadResult {
+		return usagetesting.ReadResult{Event: model.MXPGVKEvent{
+			Name:  "kube_managedresource_uid",
+			Value: value,
+			Tags:  model.MXPGVKEventTags{Group: "example.com", Version: "v1", Kind: "Thing", MXPID: mxp},
+		}}
+	}
+	maxCount := func(mxp string, value float64, window usagetime.Range) model.MXPGVKEvent {
+		return model.MXPGVKEvent{
+			Name:         "max_resource_count_per_gvk_per_mxp",
+			Value:        value,
+			Tags:         model.MXPGVKEventTags{Group: "example.com", Version: "v1", Kind: "Thing", MXPID: mxp},
+			Timestamp:    window.Start,
+			TimestampEnd: window.End,
+		}
+	}
+	windows := make([]usagetime.Range, 3)
+	for i := range windows {
+		start := time.Date(2006, 5, 4, i, 0, 0, 0, time.UTC)
+		windows[i] = usagetime.Range{Start: start, End: start.Add(time.Hour)}
+	}
+	iter := func() *usagetesting.MockWindowIterator {
+		return &usagetesting.MockWindowIterator{Windows: []usagetesting.Window{
+			{Window: windows[0], Reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{mrCount("mxp2", 2), mrCount("mxp1", 1)}}},
+			{Window: windows[1], Reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{mrCount("mxp3", 3), mrCount("mxp1", 4)}}},
+			{Window: windows[2], Reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{mrCount("mxp1", 5)}}},
+		}}
+	}
+	ordered := []model.MXPGVKEvent{
+		maxCount("mxp1", 1, windows[0]),
+		maxCount("mxp2", 2, windows[0]),
+		maxCount("mxp1", 4, windows[1]),
+		maxCount("mxp3", 3, windows[1]),
+		maxCount("mxp1", 5, windows[2]),
+	}
+
+	type args struct {
+		iter        *usagetesting.MockWindowIterator
+		concurrency int
+	}
+	type want struct {
+		events []model.MXPGVKEvent
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Sequential": {
+			reason: "Events are written in window order and sorted by their tags within a window.",
+			args: args{
+				iter:        iter(),
+				concurrency: 1,
+			},
+			want: want{
+				events: ordered,
+			},
+		},
+		"Concurrent": {
+			reason: "Events are written in the same order when windows are read concurrently.",
+			args: args{
+				iter:        iter(),
+				concurrency: 3,
+			},
+			want: want{
+				events: ordered,
+			},
+		},
+		"ReadError": {
+			reason: "Read errors of a window are returned after the events of previous windows are written.",
+			args: args{
+				iter: &usagetesting.MockWindowIterator{Windows: []usagetesting.Window{
+					{Window: windows[0], Reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{mrCount("mxp1", 1)}}},
+					{Window: windows[1], Reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{{Err: fmt.Errorf("boom")}}}},
+					{Window: windows[2], Reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{mrCount("mxp1", 5)}}},
+				}},
+				concurrency: 3,
+			},
+			want: want{
+				events: []model.MXPGVKEvent{maxCount("mxp1", 1, windows[0])},
+				err:    fmt.Errorf("boom"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := &usagetesting.MockWriter{}
+			err := Aggregate(context.Background(), tc.args.iter, w, []aggregate.NewFn{func(usagetime.Range) aggregate.Aggregator {
+				return &aggregate.MaxResourceCountPerGVKPerMXP{}
+			}}, WithConcurrency(tc.args.concurrency))
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAggregate: -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, w.Events); diff != "" {
+				t.Errorf("\n%s\nAggregate: -want events, +got events:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// blockingReader blocks reads until the context is done.
+type blockingReader struct {
+	closed chan struct{}
+}
+
+func (r *blockingReader) Read(ctx context.Context) (model.MXPGVKEvent, error) {
+	<-ctx.Done()
+	return model.MXPGVKEvent{}, ctx.Err()
+}
+
+func (r *blockingReader) Close() error {
+	close(r.closed)
+	return nil
+}
+
+func TestAggregateWaitsForReaders(t *testing.T) {
+	start := time.Date(2006, 5, 4, 0, 0, 0, 0, time.UTC)
+	blocked := &blockingReader{closed: make(chan struct{})}
+	iter := &usagetesting.MockWindowIterator{Windows: []usagetesting.Window{
+		{Window: usagetime.Range{Start: start, End: start.Add(time.Hour)}, Reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{{Err: fmt.Errorf("boom")}}}},
+		{Window: usagetime.Range{Start: start.Add(time.Hour), End: start.Add(2 * time.Hour)}, Reader: blocked},
+	}}
+
+	err := Aggregate(context.Background(), iter, &usagetesting.MockWriter{}, []aggregate.NewFn{func(usagetime.Range) aggregate.Aggregator {
+		return &aggregate.MaxResourceCountPerGVKPerMXP{}
+	}}, WithConcurrency(2))
+	if diff := cmp.Diff(fmt.Errorf("boom"), err, test.EquateErrors()); diff != "" {
+		t.Errorf("\nAggregate: -want err, +got err:\n%s", diff)
+	}
+	select {
+	case <-blocked.closed:
+	default:
+		t.Errorf("\nAggregate should close the readers of all windows before it returns.")
+	}
+}
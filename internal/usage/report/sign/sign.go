@@ -0,0 +1,111 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sign signs and verifies usage reports with local keys.
+package sign
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	errNoPEM             = "key is not PEM encoded"
+	errParsePrivateKey   = "cannot parse PKCS #8 private key"
+	errParsePublicKey    = "cannot parse PKIX public key"
+	errInvalidSignature  = "signature is invalid"
+	errFmtKeyUnsupported = "key type %T is not supported, must be an Ed25519, ECDSA or RSA key"
+)
+
+// ParsePrivateKey parses a PEM encoded PKCS #8 Ed25519, ECDSA or RSA private
+// key, as created by openssl genpkey.
+func ParsePrivateKey(b []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New(errNoPEM)
+	}
+	k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, errParsePrivateKey)
+	}
+	switch k := k.(type) {
+	case ed25519.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		return k, nil
+	case *rsa.PrivateKey:
+		return k, nil
+	default:
+		return nil, errors.Errorf(errFmtKeyUnsupported, k)
+	}
+}
+
+// ParsePublicKey parses a PEM encoded PKIX Ed25519, ECDSA or RSA public key,
+// as created by openssl pkey -pubout.
+func ParsePublicKey(b []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New(errNoPEM)
+	}
+	k, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, errParsePublicKey)
+	}
+	switch k.(type) {
+	case ed25519.PublicKey, *ecdsa.PublicKey, *rsa.PublicKey:
+		return k, nil
+	default:
+		return nil, errors.Errorf(errFmtKeyUnsupported, k)
+	}
+}
+
+// Sign returns a signature of data. Ed25519 keys sign data directly. ECDSA
+// and RSA keys sign its SHA-256 digest, using ASN.1 and PKCS #1 v1.5
+// signatures respectively.
+func Sign(s crypto.Signer, data []byte) ([]byte, error) {
+	if _, ok := s.(ed25519.PrivateKey); ok {
+		return s.Sign(rand.Reader, data, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(data)
+	return s.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+// Verify returns an error if sig is not a valid signature of data made with
+// the private key of pub.
+func Verify(pub crypto.PublicKey, data, sig []byte) error {
+	digest := sha256.Sum256(data)
+	var ok bool
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(k, data, sig)
+	case *ecdsa.PublicKey:
+		ok = ecdsa.VerifyASN1(k, digest[:], sig)
+	case *rsa.PublicKey:
+		ok = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil
+	default:
+		return errors.Errorf(errFmtKeyUnsupported, pub)
+	}
+	if !ok {
+		return errors.New(errInvalidSignature)
+	}
+	return nil
+}
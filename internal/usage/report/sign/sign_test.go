@@ -0,0 +1,162 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sign
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+)
+
+func encodeKeys(t *testing.T, priv crypto.Signer) (privPEM, pubPEM []byte) {
+	t.Helper()
+	pb, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ub, err := x509.MarshalPKIXPublicKey(priv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pb}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: ub})
+}
+
+func TestSignVerify(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, otherPub := encodeKeys(t, otherKey)
+
+	type args struct {
+		key      crypto.Signer
+		pub      []byte
+		data     []byte
+		verified []byte
+	}
+	type want struct {
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Ed25519": {
+			reason: "A signature made with an Ed25519 key should be verified by its public key.",
+			args: args{
+				key:      edKey,
+				data:     []byte("report"),
+				verified: []byte("report"),
+			},
+		},
+		"ECDSA": {
+			reason: "A signature made with an ECDSA key should be verified by its public key.",
+			args: args{
+				key:      ecKey,
+				data:     []byte("report"),
+				verified: []byte("report"),
+			},
+		},
+		"RSA": {
+			reason: "A signature made with an RSA key should be verified by its public key.",
+			args: args{
+				key:      rsaKey,
+				data:     []byte("report"),
+				verified: []byte("report"),
+			},
+		},
+		"TamperedData": {
+			reason: "A signature should not be valid for modified data.",
+			args: args{
+				key:      edKey,
+				data:     []byte("report"),
+				verified: []byte("edited report"),
+			},
+			want: want{
+				err: errors.New(errInvalidSignature),
+			},
+		},
+		"OtherKey": {
+			reason: "A signature should not be valid for the public key of another key.",
+			args: args{
+				key:      edKey,
+				pub:      otherPub,
+				data:     []byte("report"),
+				verified: []byte("report"),
+			},
+			want: want{
+				err: errors.New(errInvalidSignature),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			privPEM, pubPEM := encodeKeys(t, tc.args.key)
+			if tc.args.pub != nil {
+				pubPEM = tc.args.pub
+			}
+			priv, err := ParsePrivateKey(privPEM)
+			if err != nil {
+				t.Fatalf("\n%s\nParsePrivateKey(...): %v", tc.reason, err)
+			}
+			pub, err := ParsePublicKey(pubPEM)
+			if err != nil {
+				t.Fatalf("\n%s\nParsePublicKey(...): %v", tc.reason, err)
+			}
+			sig, err := Sign(priv, tc.args.data)
+			if err != nil {
+				t.Fatalf("\n%s\nSign(...): %v", tc.reason, err)
+			}
+			err = Verify(pub, tc.args.verified, sig)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nVerify(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		key    []byte
+		err    error
+	}{
+		"NotPEM": {
+			reason: "A key that is not PEM encoded should return an error.",
+			key:    []byte("not a key"),
+			err:    errors.New(errNoPEM),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParsePrivateKey(tc.key)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParsePrivateKey(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
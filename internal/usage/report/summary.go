@@ -0,0 +1,112 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up/internal/usage/model"
+)
+
+const errFmtLoadTimeZone = "cannot load time zone %q of report"
+
+// Total is the sum of the values of the Upbound usage events of an
+// aggregation that share a key, such as an MXP, a GVK or a day.
+type Total struct {
+	Aggregation string  `json:"aggregation"`
+	Key         string  `json:"key"`
+	Value       float64 `json:"value"`
+}
+
+// Summary summarizes a usage report.
+type Summary struct {
+	Meta Meta `json:"meta"`
+	// MXPs are the totals per MXP. Events not associated with an MXP are
+	// not included.
+	MXPs []Total `json:"mxps"`
+	// GVKs are the totals per GVK. Events not associated with a kind are
+	// totaled per API group.
+	GVKs []Total `json:"gvks"`
+	// Days are the totals per day the events' windows start on, in the time
+	// zone of the report, or UTC if the report has none.
+	Days []Total `json:"days"`
+}
+
+// Summarize returns a summary of the supplied usage report. Totals are sorted
+// by aggregation and key.
+func Summarize(meta Meta, events []model.MXPGVKEvent) (Summary, error) {
+	loc := time.UTC
+	if meta.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(meta.TimeZone); err != nil {
+			return Summary{}, errors.Wrapf(err, errFmtLoadTimeZone, meta.TimeZone)
+		}
+	}
+	mxps := totals{}
+	gvks := totals{}
+	days := totals{}
+	for _, e := range events {
+		if e.Tags.MXPID != "" {
+			mxps.add(e.Name, e.Tags.MXPID, e.Value)
+		}
+		if k := gvkKey(e.Tags); k != "" {
+			gvks.add(e.Name, k, e.Value)
+		}
+		days.add(e.Name, e.Timestamp.In(loc).Format(time.DateOnly), e.Value)
+	}
+	return Summary{
+		Meta: meta,
+		MXPs: mxps.sorted(),
+		GVKs: gvks.sorted(),
+		Days: days.sorted(),
+	}, nil
+}
+
+// gvkKey returns the key of the GVK of an event, formatted like Kind.version.group.
+func gvkKey(t model.MXPGVKEventTags) string {
+	if t.Kind == "" {
+		return t.Group
+	}
+	return fmt.Sprintf("%s.%s.%s", t.Kind, t.Version, t.Group)
+}
+
+type totalKey struct {
+	aggregation string
+	key         string
+}
+
+type totals map[totalKey]float64
+
+func (t totals) add(aggregation, key string, value float64) {
+	t[totalKey{aggregation: aggregation, key: key}] += value
+}
+
+func (t totals) sorted() []Total {
+	out := make([]Total, 0, len(t))
+	for k, v := range t {
+		out = append(out, Total{Aggregation: k.aggregation, Key: k.key, Value: v})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Aggregation != out[j].Aggregation {
+			return out[i].Aggregation < out[j].Aggregation
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
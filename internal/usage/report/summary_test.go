@@ -0,0 +1,123 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/usage/model"
+)
+
+func TestSummarize(t *testing.T) {
+	day1 := time.Date(2023, 1, 1, 23, 0, 0, 0, time.UTC)
+	day2 := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	meta := Meta{UpboundAccount: "test-account"}
+	berlin := Meta{UpboundAccount: "test-account", TimeZone: "Europe/Berlin"}
+	invalid := Meta{UpboundAccount: "test-account", TimeZone: "Nowhere/Nowhere"}
+	_, errLoad := time.LoadLocation(invalid.TimeZone)
+
+	type args struct {
+		meta   Meta
+		events []model.MXPGVKEvent
+	}
+	type want struct {
+		summary Summary
+		err     error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoEvents": {
+			reason: "A summary of a report without events has no totals.",
+			args:   args{meta: meta},
+			want: want{
+				summary: Summary{Meta: meta, MXPs: []Total{}, GVKs: []Total{}, Days: []Total{}},
+			},
+		},
+		"Totals": {
+			reason: "A summary should total the values of each aggregation per MXP, GVK and day.",
+			args: args{
+				meta: meta,
+				events: []model.MXPGVKEvent{
+					{Name: "max", Value: 2, Timestamp: day1, Tags: model.MXPGVKEventTags{MXPID: "mxp-1", Group: "example.com", Version: "v1", Kind: "Thing"}},
+					{Name: "max", Value: 3, Timestamp: day2, Tags: model.MXPGVKEventTags{MXPID: "mxp-1", Group: "example.com", Version: "v1", Kind: "Thing"}},
+					{Name: "max", Value: 4, Timestamp: day2, Tags: model.MXPGVKEventTags{MXPID: "mxp-2", Group: "example.com", Version: "v1", Kind: "Thing"}},
+					{Name: "group", Value: 7, Timestamp: day2, Tags: model.MXPGVKEventTags{Group: "example.com"}},
+				},
+			},
+			want: want{
+				summary: Summary{
+					Meta: meta,
+					MXPs: []Total{
+						{Aggregation: "max", Key: "mxp-1", Value: 5},
+						{Aggregation: "max", Key: "mxp-2", Value: 4},
+					},
+					GVKs: []Total{
+						{Aggregation: "group", Key: "example.com", Value: 7},
+						{Aggregation: "max", Key: "Thing.v1.example.com", Value: 9},
+					},
+					Days: []Total{
+						{Aggregation: "group", Key: "2023-01-02", Value: 7},
+						{Aggregation: "max", Key: "2023-01-01", Value: 2},
+						{Aggregation: "max", Key: "2023-01-02", Value: 7},
+					},
+				},
+			},
+		},
+		"TimeZone": {
+			reason: "Days should be split at midnight in the time zone of the report.",
+			args: args{
+				meta: berlin,
+				events: []model.MXPGVKEvent{
+					{Name: "max", Value: 2, Timestamp: day1, Tags: model.MXPGVKEventTags{MXPID: "mxp-1"}},
+					{Name: "max", Value: 3, Timestamp: day2, Tags: model.MXPGVKEventTags{MXPID: "mxp-1"}},
+				},
+			},
+			want: want{
+				summary: Summary{
+					Meta: berlin,
+					MXPs: []Total{{Aggregation: "max", Key: "mxp-1", Value: 5}},
+					GVKs: []Total{},
+					Days: []Total{{Aggregation: "max", Key: "2023-01-02", Value: 5}},
+				},
+			},
+		},
+		"InvalidTimeZone": {
+			reason: "An unknown time zone should return an error.",
+			args:   args{meta: invalid},
+			want: want{
+				err: errors.Wrapf(errLoad, errFmtLoadTimeZone, invalid.TimeZone),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Summarize(tc.args.meta, tc.args.events)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSummarize(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.summary, got); diff != "" {
+				t.Errorf("\n%s\nSummarize(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
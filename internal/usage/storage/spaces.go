@@ -0,0 +1,131 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"k8s.io/client-go/rest"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/install/helm"
+	"github.com/upbound/up/internal/profile"
+)
+
+const (
+	spacesChart     = "spaces"
+	spacesNamespace = "upbound-system"
+
+	errGetSpacesValues = "error reading billing storage settings from Spaces cluster"
+)
+
+// spacesParams are the billing storage settings of a Spaces installation.
+type spacesParams struct {
+	Provider            Provider
+	Bucket              string
+	Endpoint            string
+	Region              string
+	Account             string
+	AzureStorageAccount string
+}
+
+// paramsFromValues extracts the billing storage settings from the Helm
+// values of a Spaces release. Missing values are left empty.
+func paramsFromValues(values map[string]any) spacesParams {
+	p := fieldpath.Pave(values)
+	get := func(path string) string {
+		// Missing or non-string values are treated as unset.
+		s, _ := p.GetString(path)
+		return s
+	}
+
+	sp := spacesParams{
+		Provider: Provider(get("billing.storage.provider")),
+		Account:  get("account"),
+	}
+	switch sp.Provider {
+	case ProviderAWS:
+		sp.Bucket = get("billing.storage.aws.bucket")
+		sp.Endpoint = get("billing.storage.aws.endpoint")
+		sp.Region = get("billing.storage.aws.region")
+	case ProviderGCP:
+		sp.Bucket = get("billing.storage.gcp.bucket")
+		sp.Endpoint = get("billing.storage.gcp.endpoint")
+	case ProviderAzure:
+		sp.Bucket = get("billing.storage.azure.container")
+		sp.AzureStorageAccount = get("billing.storage.azure.storageAccount")
+	}
+	return sp
+}
+
+// getSpacesParams reads the billing storage settings from the Spaces
+// release in the cluster of the supplied kubeconfig.
+func getSpacesParams(kubeconfig *rest.Config) (spacesParams, error) {
+	mgr, err := helm.NewManager(kubeconfig, spacesChart, nil, helm.WithNamespace(spacesNamespace), helm.IsOCI())
+	if err != nil {
+		return spacesParams{}, errors.Wrap(err, errGetSpacesValues)
+	}
+	values, err := mgr.GetValues()
+	if err != nil {
+		return spacesParams{}, errors.Wrap(err, errGetSpacesValues)
+	}
+	return paramsFromValues(values), nil
+}
+
+// getKubeconfig returns the kubeconfig from flags if provided, otherwise the
+// kubeconfig of the current profile if it is a Spaces profile, otherwise the
+// default kubeconfig.
+func (f *Flags) getKubeconfig() (*rest.Config, error) {
+	if f.Kube.Kubeconfig == "" && f.Kube.Context == "" {
+		path, err := config.GetDefaultPath()
+		if err != nil {
+			return nil, err
+		}
+		src := config.NewFSSource(config.WithPath(path))
+		if err := src.Initialize(); err != nil {
+			return nil, err
+		}
+		kubeconfig, err := profileKubeconfig(src, f.Profile)
+		if err != nil || kubeconfig != nil {
+			return kubeconfig, err
+		}
+	}
+	if err := f.Kube.AfterApply(); err != nil {
+		return nil, err
+	}
+	return f.Kube.GetConfig(), nil
+}
+
+// profileKubeconfig returns the kubeconfig of the named profile, or of the
+// default profile if name is empty. It returns nil if the profile is not a
+// Spaces profile, or if name is empty and there is no default profile.
+func profileKubeconfig(src config.Source, name string) (*rest.Config, error) {
+	conf, err := config.Extract(src)
+	if err != nil {
+		return nil, err
+	}
+	var p profile.Profile
+	if name == "" {
+		if _, p, err = conf.GetDefaultUpboundProfile(); err != nil {
+			return nil, nil //nolint:nilerr // The default kubeconfig is used without a default profile.
+		}
+	} else if p, err = conf.GetUpboundProfile(name); err != nil {
+		return nil, err
+	}
+	if !p.IsSpace() {
+		return nil, nil
+	}
+	return p.GetKubeClientConfig()
+}
@@ -0,0 +1,200 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/profile"
+)
+
+func TestStorageParamsFromValues(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		values map[string]any
+		want   spacesParams
+	}{
+		"Empty": {
+			reason: "Missing values should be left empty.",
+			values: map[string]any{},
+			want:   spacesParams{},
+		},
+		"AWS": {
+			reason: "AWS storage settings should be read.",
+			values: map[string]any{
+				"account": "acct",
+				"billing": map[string]any{
+					"enabled": true,
+					"storage": map[string]any{
+						"provider": "aws",
+						"aws": map[string]any{
+							"bucket":   "usage",
+							"endpoint": "https://minio.example.com",
+							"region":   "us-west-2",
+						},
+						"gcp": map[string]any{
+							"bucket": "other",
+						},
+					},
+				},
+			},
+			want: spacesParams{
+				Provider: "aws",
+				Bucket:   "usage",
+				Endpoint: "https://minio.example.com",
+				Region:   "us-west-2",
+				Account:  "acct",
+			},
+		},
+		"GCP": {
+			reason: "GCP storage settings should be read.",
+			values: map[string]any{
+				"account": "acct",
+				"billing": map[string]any{
+					"storage": map[string]any{
+						"provider": "gcp",
+						"gcp": map[string]any{
+							"bucket": "usage",
+						},
+					},
+				},
+			},
+			want: spacesParams{
+				Provider: "gcp",
+				Bucket:   "usage",
+				Account:  "acct",
+			},
+		},
+		"Azure": {
+			reason: "Azure storage settings should be read, using the container as bucket.",
+			values: map[string]any{
+				"account": "acct",
+				"billing": map[string]any{
+					"storage": map[string]any{
+						"provider": "azure",
+						"azure": map[string]any{
+							"storageAccount": "upbound",
+							"container":      "usage",
+						},
+					},
+				},
+			},
+			want: spacesParams{
+				Provider:            "azure",
+				Bucket:              "usage",
+				Account:             "acct",
+				AzureStorageAccount: "upbound",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := paramsFromValues(tc.values)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nparamsFromValues(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestProfileKubeconfig(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: spaces
+  cluster:
+    server: https://spaces.example.com
+contexts:
+- name: spaces
+  context:
+    cluster: spaces
+current-context: spaces
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	src := func(def string) config.Source {
+		return &config.MockSource{GetConfigFn: func() (*config.Config, error) {
+			return &config.Config{Upbound: config.Upbound{
+				Default: def,
+				Profiles: map[string]profile.Profile{
+					"cloud":  {ID: "someone", Type: profile.User},
+					"spaces": {Type: profile.Space, Kubeconfig: kubeconfig},
+				},
+			}}, nil
+		}}
+	}
+
+	type want struct {
+		host string
+		err  error
+	}
+	cases := map[string]struct {
+		reason string
+		src    config.Source
+		name   string
+		want   want
+	}{
+		"NoDefault": {
+			reason: "No kubeconfig should be returned if there is no default profile.",
+			src:    src(""),
+		},
+		"DefaultSpaces": {
+			reason: "The kubeconfig of a default Spaces profile should be returned.",
+			src:    src("spaces"),
+			want:   want{host: "https://spaces.example.com"},
+		},
+		"NamedSpaces": {
+			reason: "The kubeconfig of a named Spaces profile should be returned.",
+			src:    src("cloud"),
+			name:   "spaces",
+			want:   want{host: "https://spaces.example.com"},
+		},
+		"NamedCloud": {
+			reason: "No kubeconfig should be returned for a profile that is not a Spaces profile.",
+			src:    src("spaces"),
+			name:   "cloud",
+		},
+		"NamedMissing": {
+			reason: "A missing named profile should return an error.",
+			src:    src("spaces"),
+			name:   "missing",
+			want:   want{err: errors.New("profile not found with identifier: missing")},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := profileKubeconfig(tc.src, tc.name)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nprofileKubeconfig(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			host := ""
+			if got != nil {
+				host = got.Host
+			}
+			if diff := cmp.Diff(tc.want.host, host); diff != "" {
+				t.Errorf("\n%s\nprofileKubeconfig(...): -want host, +got host:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
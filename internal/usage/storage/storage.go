@@ -0,0 +1,314 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package storage reads usage events from the storage backends Spaces
+// writes usage data to.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/alecthomas/kong"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/spf13/afero"
+	gcpopt "google.golang.org/api/option"
+
+	"github.com/upbound/up/internal/upbound"
+	usageaws "github.com/upbound/up/internal/usage/aws"
+	"github.com/upbound/up/internal/usage/azure"
+	"github.com/upbound/up/internal/usage/event"
+	"github.com/upbound/up/internal/usage/file"
+	"github.com/upbound/up/internal/usage/gcp"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+const (
+	// ProviderAWS is AWS S3 or S3-compatible storage.
+	ProviderAWS Provider = "aws"
+	// ProviderGCP is GCP Cloud Storage.
+	ProviderGCP Provider = "gcp"
+	// ProviderAzure is Azure Blob Storage.
+	ProviderAzure Provider = "azure"
+	// ProviderFile is a local directory with the layout of a storage bucket.
+	ProviderFile Provider = "file"
+
+	errFmtProviderNotSupported = "%q is not supported"
+)
+
+// Provider is a storage provider.
+type Provider string
+
+// Validate returns an error if the provider is not supported. An empty
+// provider is valid, it is read from the Spaces cluster.
+func (p Provider) Validate() error {
+	switch p {
+	case ProviderGCP, ProviderAWS, ProviderAzure, ProviderFile, "":
+		return nil
+	default:
+		return fmt.Errorf(errFmtProviderNotSupported, p)
+	}
+}
+
+// Flags are the flags used to select the storage usage data is read from.
+type Flags struct {
+	Provider            Provider `optional:"" env:"UP_BILLING_PROVIDER" group:"Storage" help:"Storage provider. Must be one of: aws, gcp, azure, file."`
+	Bucket              string   `optional:"" env:"UP_BILLING_BUCKET" group:"Storage" help:"Storage bucket. Required for --provider=aws, gcp and azure."`
+	Directory           string   `optional:"" type:"path" env:"UP_BILLING_DIRECTORY" group:"Storage" help:"Local directory with the same layout as a storage bucket. Required for --provider=file."`
+	Endpoint            string   `env:"UP_BILLING_ENDPOINT" group:"Storage" help:"Custom storage endpoint."`
+	Account             string   `optional:"" env:"UP_BILLING_ACCOUNT" group:"Storage" help:"Name of the Upbound account whose usage is read."`
+	AzureStorageAccount string   `optional:"" env:"UP_AZURE_STORAGE_ACCOUNT" group:"Storage" help:"Name of the Azure storage account. Required for --provider=azure."`
+
+	S3PathStyle       bool   `env:"UP_BILLING_S3_PATH_STYLE" group:"S3-compatible storage" help:"Use path-style addressing for S3 requests. Required by most S3-compatible storage such as MinIO or Ceph. Only for --provider=aws."`
+	S3Region          string `env:"UP_BILLING_S3_REGION" group:"S3-compatible storage" help:"Region of the S3 bucket. Defaults to us-east-1 if --endpoint is set and no region is configured. Only for --provider=aws."`
+	S3AccessKeyID     string `env:"UP_BILLING_S3_ACCESS_KEY_ID" group:"S3-compatible storage" help:"Static access key ID. Only for --provider=aws."`
+	S3SecretAccessKey string `env:"UP_BILLING_S3_SECRET_ACCESS_KEY" group:"S3-compatible storage" help:"Static secret access key. Only for --provider=aws."`
+
+	Profile string            `env:"UP_PROFILE" group:"Spaces cluster" help:"Profile whose Spaces cluster missing storage settings are read from."`
+	Kube    upbound.KubeFlags `embed:"" group:"Spaces cluster"`
+}
+
+// Resolve reads missing storage settings from the Spaces cluster and
+// validates them. The endpoint is only read from the Spaces cluster if
+// endpointSet is false.
+func (f *Flags) Resolve(endpointSet bool) error {
+	if f.needsSpacesParams() {
+		if err := f.fillFromSpaces(endpointSet); err != nil {
+			return err
+		}
+	}
+	return f.validate()
+}
+
+// EndpointSet returns true if --endpoint was set, including to an empty
+// value.
+func EndpointSet(kongCtx *kong.Context) bool {
+	for _, f := range kongCtx.Flags() {
+		if f.Name == "endpoint" {
+			return f.Set
+		}
+	}
+	return false
+}
+
+// needsSpacesParams returns true if required storage settings are missing.
+func (f *Flags) needsSpacesParams() bool {
+	switch {
+	case f.Provider == "" || f.Account == "":
+		return true
+	case f.Provider == ProviderFile:
+		return false
+	case f.Bucket == "":
+		return true
+	default:
+		return f.Provider == ProviderAzure && f.AzureStorageAccount == ""
+	}
+}
+
+// fillFromSpaces sets missing storage settings from the Spaces cluster. The
+// bucket, endpoint and region are only used if the cluster's storage provider
+// matches the configured provider.
+func (f *Flags) fillFromSpaces(endpointSet bool) error {
+	kubeconfig, err := f.getKubeconfig()
+	if err != nil {
+		return errors.Wrap(err, errGetSpacesValues)
+	}
+	sp, err := getSpacesParams(kubeconfig)
+	if err != nil {
+		return err
+	}
+	if f.Account == "" {
+		f.Account = sp.Account
+	}
+	if f.Provider == "" {
+		f.Provider = sp.Provider
+	}
+	if f.Provider != sp.Provider {
+		return nil
+	}
+	if f.Bucket == "" {
+		f.Bucket = sp.Bucket
+	}
+	if !endpointSet {
+		f.Endpoint = sp.Endpoint
+	}
+	if f.S3Region == "" {
+		f.S3Region = sp.Region
+	}
+	if f.AzureStorageAccount == "" {
+		f.AzureStorageAccount = sp.AzureStorageAccount
+	}
+	return nil
+}
+
+func (f *Flags) validate() error { //nolint:gocyclo
+	if f.Provider == "" {
+		return fmt.Errorf("--provider must be set, billing storage is not configured in the Spaces cluster")
+	}
+	if err := f.Provider.Validate(); err != nil {
+		return err
+	}
+	if f.Account == "" {
+		return fmt.Errorf("--account must be set, account is not configured in the Spaces cluster")
+	}
+	switch f.Provider {
+	case ProviderFile:
+		if f.Directory == "" {
+			return fmt.Errorf("--directory must be set for --provider=file")
+		}
+		if info, err := os.Stat(f.Directory); err != nil || !info.IsDir() {
+			return fmt.Errorf("directory %q does not exist", f.Directory)
+		}
+		if f.Endpoint != "" {
+			return fmt.Errorf("--endpoint is not supported for --provider=file")
+		}
+	default:
+		if f.Bucket == "" {
+			return fmt.Errorf("--bucket must be set for --provider=%s", f.Provider)
+		}
+	}
+	if f.Provider != ProviderAWS && (f.S3PathStyle || f.S3Region != "" || f.S3AccessKeyID != "" || f.S3SecretAccessKey != "") {
+		return fmt.Errorf("--s3-* flags are only supported for --provider=aws")
+	}
+	if (f.S3AccessKeyID == "") != (f.S3SecretAccessKey == "") {
+		return fmt.Errorf("--s3-access-key-id and --s3-secret-access-key must be set together")
+	}
+	if f.Provider == ProviderAzure {
+		if f.AzureStorageAccount == "" {
+			return fmt.Errorf("--azure-storage-account must be set for --provider=azure")
+		}
+		if f.Endpoint != "" {
+			return fmt.Errorf("--endpoint is not supported for --provider=azure")
+		}
+	}
+	return nil
+}
+
+// Location returns a description of where usage data is read from.
+func (f *Flags) Location() string {
+	if f.Provider == ProviderFile {
+		return f.Directory
+	}
+	return f.Bucket
+}
+
+// WindowIterator returns an iterator over windows of usage data within the
+// supplied time range. Up to concurrency storage objects are read at once
+// within each window.
+func (f *Flags) WindowIterator(ctx context.Context, tr usagetime.Range, window time.Duration, concurrency int) (event.WindowIterator, error) {
+	switch f.Provider {
+	case ProviderGCP:
+		return f.gcpIter(ctx, tr, window, concurrency)
+	case ProviderAWS:
+		return f.awsIter(tr, window, concurrency)
+	case ProviderAzure:
+		return f.azureIter(tr, window, concurrency)
+	case ProviderFile:
+		return f.fileIter(tr, window, concurrency)
+	default:
+		return nil, fmt.Errorf(errFmtProviderNotSupported, f.Provider)
+	}
+}
+
+func (f *Flags) gcpIter(ctx context.Context, tr usagetime.Range, window time.Duration, concurrency int) (event.WindowIterator, error) {
+	opts := []gcpopt.ClientOption{}
+	if f.Endpoint != "" {
+		opts = append(opts, gcpopt.WithEndpoint(f.Endpoint))
+	}
+	gcsCli, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating storage client")
+	}
+	bkt := gcsCli.Bucket(f.Bucket)
+	iter, err := gcp.NewWindowIterator(bkt, f.Account, tr, window)
+	if err != nil {
+		return nil, err
+	}
+	iter.Concurrency = concurrency
+	return iter, nil
+}
+
+func (f *Flags) awsIter(tr usagetime.Range, window time.Duration, concurrency int) (event.WindowIterator, error) {
+	sess, err := session.NewSession(&aws.Config{})
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating aws session")
+	}
+	s3client := s3.New(sess, f.awsConfig(aws.StringValue(sess.Config.Region)))
+	iter, err := usageaws.NewWindowIterator(s3client, f.Bucket, f.Account, tr, window)
+	if err != nil {
+		return nil, err
+	}
+	iter.Concurrency = concurrency
+	return iter, nil
+}
+
+// awsConfig returns the S3 client config for the storage flags. sessionRegion
+// is the region configured for the AWS session, if any.
+func (f *Flags) awsConfig(sessionRegion string) *aws.Config {
+	config := &aws.Config{}
+	if f.Endpoint != "" {
+		config.Endpoint = aws.String(f.Endpoint)
+		// S3-compatible storage usually ignores the region, but the SDK
+		// refuses to sign requests without one.
+		if sessionRegion == "" {
+			config.Region = aws.String("us-east-1")
+		}
+	}
+	if f.S3Region != "" {
+		config.Region = aws.String(f.S3Region)
+	}
+	if f.S3PathStyle {
+		config.S3ForcePathStyle = aws.Bool(true)
+	}
+	if f.S3AccessKeyID != "" {
+		config.Credentials = credentials.NewStaticCredentials(f.S3AccessKeyID, f.S3SecretAccessKey, "")
+	}
+	return config
+}
+
+func (f *Flags) azureIter(tr usagetime.Range, window time.Duration, concurrency int) (event.WindowIterator, error) {
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, err
+	}
+	cli, err := azblob.NewClient(fmt.Sprintf("https://%s.blob.core.windows.net/", f.AzureStorageAccount), cred, nil)
+	if err != nil {
+		return nil, err
+	}
+	containerCli := cli.ServiceClient().NewContainerClient(f.Bucket)
+	iter, err := azure.NewWindowIterator(containerCli, f.Account, tr, window)
+	if err != nil {
+		return nil, err
+	}
+	iter.Concurrency = concurrency
+	return iter, nil
+}
+
+func (f *Flags) fileIter(tr usagetime.Range, window time.Duration, concurrency int) (event.WindowIterator, error) {
+	iter, err := file.NewWindowIterator(afero.NewOsFs(), f.Directory, f.Account, tr, window)
+	if err != nil {
+		return nil, err
+	}
+	iter.Concurrency = concurrency
+	return iter, nil
+}
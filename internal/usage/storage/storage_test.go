@@ -0,0 +1,89 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestAWSConfig(t *testing.T) {
+	type args struct {
+		flags         *Flags
+		sessionRegion string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   *aws.Config
+	}{
+		"Default": {
+			reason: "Without storage flags the session config should be used as is.",
+			args: args{
+				flags:         &Flags{},
+				sessionRegion: "eu-west-1",
+			},
+			want: &aws.Config{},
+		},
+		"Endpoint": {
+			reason: "A custom endpoint without a configured region should default the region.",
+			args: args{
+				flags: &Flags{Endpoint: "https://minio.example.com"},
+			},
+			want: &aws.Config{
+				Endpoint: aws.String("https://minio.example.com"),
+				Region:   aws.String("us-east-1"),
+			},
+		},
+		"S3Compatible": {
+			reason: "S3-compatible storage flags should be set on the config.",
+			args: args{
+				flags: &Flags{
+					Endpoint:          "https://minio.example.com",
+					S3PathStyle:       true,
+					S3Region:          "local",
+					S3AccessKeyID:     "key",
+					S3SecretAccessKey: "secret",
+				},
+			},
+			want: &aws.Config{
+				Endpoint:         aws.String("https://minio.example.com"),
+				Region:           aws.String("local"),
+				S3ForcePathStyle: aws.Bool(true),
+				Credentials:      credentials.NewStaticCredentials("key", "secret", ""),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.args.flags.awsConfig(tc.args.sessionRegion)
+			if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreUnexported(credentials.Credentials{})); diff != "" {
+				t.Errorf("\n%s\nawsConfig(): -want, +got:\n%s", tc.reason, diff)
+			}
+			if tc.want.Credentials != nil {
+				want, _ := tc.want.Credentials.Get()
+				got, _ := got.Credentials.Get()
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("\n%s\nawsConfig(): -want credentials, +got credentials:\n%s", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message  string `xml:"message,attr"`
+	Contents string `xml:",chardata"`
+}
+
+// WriteJUnit writes the supplied results as a JUnit XML report with a single
+// test suite of the supplied name.
+func WriteJUnit(w io.Writer, suite string, results []Result) error {
+	s := junitTestSuite{
+		Name:  suite,
+		Tests: len(results),
+		Cases: make([]junitTestCase, len(results)),
+	}
+	var total time.Duration
+	for i, r := range results {
+		total += r.Duration
+		tc := junitTestCase{
+			Name:      r.Name,
+			ClassName: suite,
+			Time:      seconds(r.Duration),
+		}
+		switch {
+		case r.Err != nil:
+			s.Errors++
+			tc.Error = &junitMessage{Message: r.Err.Error(), Contents: r.Err.Error()}
+		case len(r.Failures) > 0:
+			s.Failures++
+			tc.Failure = &junitMessage{
+				Message:  fmt.Sprintf("%d failures", len(r.Failures)),
+				Contents: strings.Join(r.Failures, "\n"),
+			}
+		}
+		s.Cases[i] = tc
+	}
+	s.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{s}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
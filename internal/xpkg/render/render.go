@@ -0,0 +1,270 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package render renders the resources a Composition would compose for a
+// composite resource, without the need for a cluster.
+package render
+
+import (
+	"context"
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	icomposite "github.com/crossplane/crossplane/controller/apiextensions/composite"
+	icompositions "github.com/crossplane/crossplane/controller/apiextensions/compositions"
+	"github.com/crossplane/crossplane/xcrd"
+)
+
+const (
+	errCompositionNotCompatible = "composition %q is not compatible with %s"
+	errCompositionNotFound      = "composition %q not found"
+	errNoComposition            = "no composition found for %s"
+	errMultipleCompositions     = "multiple compositions match %s, select one explicitly"
+	errInvalidSelector          = "invalid composition selector"
+	errConvertComposition       = "cannot convert composition"
+	errCompose                  = "cannot compose resources"
+	errFmtRender                = "cannot render composed resource %q"
+	errFmtRenderComposite       = "cannot render composite resource from observed composed resource %q"
+
+	// fields of a claim's spec that are not propagated to the composite
+	// resource by the claim reconciler.
+	fieldCompositeDeletePolicy = "compositeDeletePolicy"
+	fieldResourceRef           = "resourceRef"
+	fieldWriteConnectionSecret = "writeConnectionSecretToRef"
+)
+
+// Composed is a single resource rendered from a Composition template.
+type Composed struct {
+	// Name is the name of the template in the Composition. Anonymous
+	// templates are named by their index.
+	Name string
+	// Resource is the rendered composed resource.
+	Resource *unstructured.Unstructured
+	// Err is the error encountered while rendering the resource, if any.
+	Err error
+}
+
+// Option modifies how resources are rendered.
+type Option func(*options)
+
+type options struct {
+	observed map[string]*unstructured.Unstructured
+}
+
+// WithObserved supplies the observed state of composed resources. Observed
+// resources are matched to Composition templates by their
+// crossplane.io/composition-resource-name annotation. Their state is patched
+// to the composite resource before the composed resources are rendered, the
+// same way Crossplane does on subsequent reconciles.
+func WithObserved(obs ...*unstructured.Unstructured) Option {
+	return func(o *options) {
+		for _, ob := range obs {
+			o.observed[icomposite.GetCompositionResourceName(ob)] = ob
+		}
+	}
+}
+
+// Render renders the resources the supplied Composition would compose for
+// the supplied composite resource. Errors rendering an individual template
+// do not fail the whole render, but are reported on the returned Composed.
+func Render(ctx context.Context, xr *unstructured.Unstructured, comp *xpextv1.Composition, opts ...Option) ([]Composed, error) {
+	o := &options{
+		observed: map[string]*unstructured.Unstructured{},
+	}
+	for _, fn := range opts {
+		fn(o)
+	}
+
+	gvk := xr.GroupVersionKind()
+	if !compatible(comp, gvk) {
+		return nil, errors.Errorf(errCompositionNotCompatible, comp.GetName(), gvk)
+	}
+
+	cr := composite.New(composite.WithGroupVersionKind(gvk))
+	cr.Object = xr.DeepCopy().Object
+	if cr.GetName() == "" {
+		cr.SetName(icomposite.PlaceholderName)
+	}
+	if cr.GetUID() == "" {
+		cr.SetUID(icomposite.PlaceholderUID)
+	}
+	if err := icomposite.NewAPINamingConfigurator().Configure(ctx, cr, comp); err != nil {
+		return nil, err
+	}
+
+	// convert v1.Composition to v1alpha1.CompositionRevision back to
+	// v1.Composition to take advantage of default fields being set for
+	// various sub objects within the v1.Composition definition.
+	c := icomposite.AsComposition(icompositions.NewCompositionRevision(comp, 1))
+
+	composer := icomposite.NewPTComposer()
+	req := icomposite.CompositionRequest{Composition: c}
+	cds, err := composer.Compose(ctx, cr, req)
+	if err != nil {
+		return nil, errors.Wrap(err, errCompose)
+	}
+
+	if len(o.observed) > 0 {
+		// Patch the observed state of the composed resources to the
+		// composite resource, then render the composed resources again from
+		// the updated composite resource.
+		for _, cd := range cds {
+			ob, ok := o.observed[string(cd.ResourceName)]
+			if !ok || cd.Template == nil {
+				continue
+			}
+			ocd := composed.New()
+			ocd.Object = ob.DeepCopy().Object
+			if err := icomposite.RenderComposite(ctx, cr, ocd, *cd.Template, nil); err != nil {
+				return nil, errors.Wrapf(err, errFmtRenderComposite, cd.ResourceName)
+			}
+		}
+		if cds, err = composer.Compose(ctx, cr, req); err != nil {
+			return nil, errors.Wrap(err, errCompose)
+		}
+	}
+
+	out := make([]Composed, len(cds))
+	for i, cd := range cds {
+		out[i] = Composed{
+			Name: string(cd.ResourceName),
+		}
+		if cd.TemplateRenderErr != nil {
+			out[i].Err = errors.Wrapf(cd.TemplateRenderErr, errFmtRender, cd.ResourceName)
+			continue
+		}
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cd.Resource)
+		if err != nil {
+			out[i].Err = errors.Wrapf(err, errFmtRender, cd.ResourceName)
+			continue
+		}
+		out[i].Resource = &unstructured.Unstructured{Object: u}
+		// Observed composed resources are already named, which is retained
+		// by Crossplane when they are rendered again.
+		if ob, ok := o.observed[out[i].Name]; ok && ob.GetName() != "" {
+			out[i].Resource.SetName(ob.GetName())
+		}
+	}
+	return out, nil
+}
+
+// SelectComposition selects the Composition to use for the supplied
+// composite resource from the supplied list, the same way Crossplane would:
+// by the composite's composition reference, then by its composition
+// selector. If neither is set, the only compatible Composition is selected.
+func SelectComposition(xr *unstructured.Unstructured, comps []xpextv1.Composition) (*xpextv1.Composition, error) {
+	gvk := xr.GroupVersionKind()
+	cr := &composite.Unstructured{Unstructured: *xr}
+
+	if ref := cr.GetCompositionReference(); ref != nil {
+		for i := range comps {
+			if comps[i].GetName() != ref.Name {
+				continue
+			}
+			if !compatible(&comps[i], gvk) {
+				return nil, errors.Errorf(errCompositionNotCompatible, ref.Name, gvk)
+			}
+			return &comps[i], nil
+		}
+		return nil, errors.Errorf(errCompositionNotFound, ref.Name)
+	}
+
+	sel := labels.Everything()
+	if s := cr.GetCompositionSelector(); s != nil {
+		var err error
+		if sel, err = metav1.LabelSelectorAsSelector(s); err != nil {
+			return nil, errors.Wrap(err, errInvalidSelector)
+		}
+	}
+
+	var found *xpextv1.Composition
+	for i := range comps {
+		if !compatible(&comps[i], gvk) || !sel.Matches(labels.Set(comps[i].GetLabels())) {
+			continue
+		}
+		if found != nil {
+			return nil, errors.Errorf(errMultipleCompositions, gvk)
+		}
+		found = &comps[i]
+	}
+	if found == nil {
+		return nil, errors.Errorf(errNoComposition, gvk)
+	}
+	return found, nil
+}
+
+// CompositeFromClaim builds the composite resource of the supplied kind that
+// Crossplane would create for the supplied claim.
+func CompositeFromClaim(claim *unstructured.Unstructured, xrGVK schema.GroupVersionKind) (*unstructured.Unstructured, error) {
+	xr := &composite.Unstructured{}
+	xr.SetGroupVersionKind(xrGVK)
+	xr.SetName(claim.GetName())
+	xr.SetLabels(claim.GetLabels())
+	xr.SetAnnotations(claim.GetAnnotations())
+	meta.AddLabels(xr, map[string]string{
+		xcrd.LabelKeyClaimName:      claim.GetName(),
+		xcrd.LabelKeyClaimNamespace: claim.GetNamespace(),
+	})
+
+	spec, ok, err := unstructured.NestedMap(claim.Object, "spec")
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		for _, f := range []string{fieldCompositeDeletePolicy, fieldResourceRef, fieldWriteConnectionSecret} {
+			delete(spec, f)
+		}
+		if err := unstructured.SetNestedMap(xr.Object, spec, "spec"); err != nil {
+			return nil, err
+		}
+	}
+
+	xr.SetClaimReference(&corev1.ObjectReference{
+		APIVersion: claim.GetAPIVersion(),
+		Kind:       claim.GetKind(),
+		Name:       claim.GetName(),
+		Namespace:  claim.GetNamespace(),
+	})
+	return xr.GetUnstructured(), nil
+}
+
+// CompositionFromUnstructured converts the supplied object to a Composition.
+func CompositionFromUnstructured(u *unstructured.Unstructured) (*xpextv1.Composition, error) {
+	b, err := u.MarshalJSON()
+	if err != nil {
+		return nil, errors.Wrap(err, errConvertComposition)
+	}
+	comp := &xpextv1.Composition{}
+	if err := json.Unmarshal(b, comp); err != nil {
+		return nil, errors.Wrap(err, errConvertComposition)
+	}
+	return comp, nil
+}
+
+func compatible(comp *xpextv1.Composition, gvk schema.GroupVersionKind) bool {
+	apiVersion, kind := gvk.ToAPIVersionAndKind()
+	return comp.Spec.CompositeTypeRef.APIVersion == apiVersion && comp.Spec.CompositeTypeRef.Kind == kind
+}
@@ -0,0 +1,343 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+var (
+	testComposition = []byte(`
+apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: bucket
+  labels:
+    provider: aws
+spec:
+  compositeTypeRef:
+    apiVersion: acme.io/v1alpha1
+    kind: XBucket
+  resources:
+  - name: bucket
+    base:
+      apiVersion: s3.aws.upbound.io/v1beta1
+      kind: Bucket
+      spec:
+        forProvider:
+          region: us-east-1
+    patches:
+    - fromFieldPath: spec.region
+      toFieldPath: spec.forProvider.region
+    - fromFieldPath: spec.name
+      toFieldPath: metadata.annotations[crossplane.io/external-name]
+      transforms:
+      - type: string
+        string:
+          fmt: "acme-%s"
+    - type: ToCompositeFieldPath
+      fromFieldPath: status.atProvider.arn
+      toFieldPath: status.arn
+    - fromFieldPath: status.arn
+      toFieldPath: spec.forProvider.tags.arn
+`)
+
+	testXR = []byte(`
+apiVersion: acme.io/v1alpha1
+kind: XBucket
+metadata:
+  name: my-bucket
+spec:
+  region: eu-central-1
+  name: logs
+`)
+)
+
+func unmarshal(t *testing.T, b []byte, into any) {
+	t.Helper()
+	if err := yaml.Unmarshal(b, into); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	comp := &xpextv1.Composition{}
+	unmarshal(t, testComposition, comp)
+	xr := &unstructured.Unstructured{}
+	unmarshal(t, testXR, xr)
+
+	other := &unstructured.Unstructured{}
+	other.SetAPIVersion("acme.io/v1alpha1")
+	other.SetKind("XDatabase")
+
+	observed := &unstructured.Unstructured{}
+	unmarshal(t, []byte(`
+apiVersion: s3.aws.upbound.io/v1beta1
+kind: Bucket
+metadata:
+  name: my-bucket-x7k2p
+  annotations:
+    crossplane.io/composition-resource-name: bucket
+status:
+  atProvider:
+    arn: arn:aws:s3:::acme-logs
+`), observed)
+
+	bucket := func(name, arn string) map[string]any {
+		md := map[string]any{
+			"generateName": "my-bucket-",
+			"annotations": map[string]any{
+				"crossplane.io/composition-resource-name": "bucket",
+				"crossplane.io/external-name":             "acme-logs",
+			},
+			"labels": map[string]any{
+				"crossplane.io/composite":       "my-bucket",
+				"crossplane.io/claim-name":      "",
+				"crossplane.io/claim-namespace": "",
+			},
+			"ownerReferences": []any{map[string]any{
+				"apiVersion":         "acme.io/v1alpha1",
+				"kind":               "XBucket",
+				"name":               "my-bucket",
+				"uid":                "placeholder-uid",
+				"controller":         true,
+				"blockOwnerDeletion": true,
+			}},
+		}
+		fp := map[string]any{
+			"region": "eu-central-1",
+		}
+		if name != "" {
+			md["name"] = name
+		}
+		if arn != "" {
+			fp["tags"] = map[string]any{"arn": arn}
+		}
+		return map[string]any{
+			"apiVersion": "s3.aws.upbound.io/v1beta1",
+			"kind":       "Bucket",
+			"metadata":   md,
+			"spec": map[string]any{
+				"forProvider": fp,
+			},
+		}
+	}
+
+	type args struct {
+		xr   *unstructured.Unstructured
+		opts []Option
+	}
+
+	type want struct {
+		names []string
+		res   []map[string]any
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Incompatible": {
+			reason: "We should return an error if the composition does not compose the XR's kind.",
+			args: args{
+				xr: other,
+			},
+			want: want{
+				err: errors.Errorf(errCompositionNotCompatible, "bucket", other.GroupVersionKind()),
+			},
+		},
+		"Success": {
+			reason: "We should apply patches and transforms to the composed resources.",
+			args: args{
+				xr: xr,
+			},
+			want: want{
+				names: []string{"bucket"},
+				res:   []map[string]any{bucket("", "")},
+			},
+		},
+		"Observed": {
+			reason: "We should patch observed composed resources to the XR before rendering.",
+			args: args{
+				xr:   xr,
+				opts: []Option{WithObserved(observed)},
+			},
+			want: want{
+				names: []string{"bucket"},
+				res:   []map[string]any{bucket("my-bucket-x7k2p", "arn:aws:s3:::acme-logs")},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cds, err := Render(context.Background(), tc.args.xr, comp, tc.args.opts...)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+
+			var names []string
+			var res []map[string]any
+			for _, cd := range cds {
+				if cd.Err != nil {
+					t.Errorf("\n%s\nRender(...): unexpected error for %q: %v", tc.reason, cd.Name, cd.Err)
+					continue
+				}
+				names = append(names, cd.Name)
+				res = append(res, cd.Resource.Object)
+			}
+			if diff := cmp.Diff(tc.want.names, names); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want names, +got names:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.res, res, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSelectComposition(t *testing.T) {
+	aws := xpextv1.Composition{}
+	unmarshal(t, testComposition, &aws)
+	gcp := *aws.DeepCopy()
+	gcp.SetName("bucket-gcp")
+	gcp.SetLabels(map[string]string{"provider": "gcp"})
+
+	xr := func(s string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		unmarshal(t, testXR, u)
+		unmarshal(t, []byte(s), &u.Object)
+		return u
+	}
+
+	type want struct {
+		name string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		xr     *unstructured.Unstructured
+		comps  []xpextv1.Composition
+		want   want
+	}{
+		"Only": {
+			reason: "We should select the only compatible composition.",
+			xr:     xr(`{}`),
+			comps:  []xpextv1.Composition{aws},
+			want:   want{name: "bucket"},
+		},
+		"Ambiguous": {
+			reason: "We should return an error if multiple compositions match.",
+			xr:     xr(`{}`),
+			comps:  []xpextv1.Composition{aws, gcp},
+			want:   want{err: errors.Errorf(errMultipleCompositions, xr(`{}`).GroupVersionKind())},
+		},
+		"Reference": {
+			reason: "We should select the referenced composition.",
+			xr:     xr(`{"spec": {"compositionRef": {"name": "bucket-gcp"}}}`),
+			comps:  []xpextv1.Composition{aws, gcp},
+			want:   want{name: "bucket-gcp"},
+		},
+		"ReferenceNotFound": {
+			reason: "We should return an error if the referenced composition does not exist.",
+			xr:     xr(`{"spec": {"compositionRef": {"name": "bucket-azure"}}}`),
+			comps:  []xpextv1.Composition{aws, gcp},
+			want:   want{err: errors.Errorf(errCompositionNotFound, "bucket-azure")},
+		},
+		"Selector": {
+			reason: "We should select the composition matching the composition selector.",
+			xr:     xr(`{"spec": {"compositionSelector": {"matchLabels": {"provider": "gcp"}}}}`),
+			comps:  []xpextv1.Composition{aws, gcp},
+			want:   want{name: "bucket-gcp"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			comp, err := SelectComposition(tc.xr, tc.comps)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSelectComposition(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			var got string
+			if comp != nil {
+				got = comp.GetName()
+			}
+			if diff := cmp.Diff(tc.want.name, got); diff != "" {
+				t.Errorf("\n%s\nSelectComposition(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCompositeFromClaim(t *testing.T) {
+	claim := &unstructured.Unstructured{}
+	unmarshal(t, []byte(`
+apiVersion: acme.io/v1alpha1
+kind: Bucket
+metadata:
+  name: my-bucket
+  namespace: default
+spec:
+  region: eu-central-1
+  compositeDeletePolicy: Foreground
+  writeConnectionSecretToRef:
+    name: bucket-conn
+`), claim)
+
+	xr, err := CompositeFromClaim(claim, schema.GroupVersionKind{Group: "acme.io", Version: "v1alpha1", Kind: "XBucket"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{
+		"apiVersion": "acme.io/v1alpha1",
+		"kind":       "XBucket",
+		"metadata": map[string]any{
+			"name": "my-bucket",
+			"labels": map[string]any{
+				"crossplane.io/claim-name":      "my-bucket",
+				"crossplane.io/claim-namespace": "default",
+			},
+		},
+		"spec": map[string]any{
+			"region": "eu-central-1",
+			"claimRef": map[string]any{
+				"apiVersion": "acme.io/v1alpha1",
+				"kind":       "Bucket",
+				"name":       "my-bucket",
+				"namespace":  "default",
+			},
+		},
+	}
+	if diff := cmp.Diff(want, xr.Object); diff != "" {
+		t.Errorf("\nCompositeFromClaim(...): -want, +got:\n%s", diff)
+	}
+}
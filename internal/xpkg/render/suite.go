@@ -0,0 +1,268 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	k8syaml "sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	icomposite "github.com/crossplane/crossplane/controller/apiextensions/composite"
+)
+
+const (
+	// ResourceFile is the file in a test case directory holding the
+	// composite resource or claim under test.
+	ResourceFile = "xr.yaml"
+	// ObservedFile is the optional file in a test case directory holding
+	// the observed state of composed resources.
+	ObservedFile = "observed.yaml"
+	// ExpectedFile is the file in a test case directory holding the
+	// expected composed resources.
+	ExpectedFile = "expected.yaml"
+
+	errReadTests    = "cannot read test cases"
+	errFmtReadFile  = "cannot read %s"
+	errFmtNoObjects = "%s does not contain an object"
+	errFmtMissing   = "expected composed resource %q was not rendered"
+	errFmtExtra     = "composed resource %q was rendered but not expected"
+	errFmtDiff      = "composed resource %q: -want, +got:\n%s"
+)
+
+// A Case is a single golden-file test case for a Composition.
+type Case struct {
+	// Name of the test case, i.e. the name of its directory.
+	Name string
+	// Dir is the directory the test case was loaded from.
+	Dir string
+	// Resource is the composite resource or claim under test.
+	Resource *unstructured.Unstructured
+	// Observed is the observed state of composed resources, if any.
+	Observed []*unstructured.Unstructured
+	// Expected are the expected composed resources.
+	Expected []*unstructured.Unstructured
+}
+
+// A Result is the outcome of running a Case.
+type Result struct {
+	// Name of the test case.
+	Name string
+	// Failures are differences between the expected and the rendered
+	// composed resources.
+	Failures []string
+	// Err is an error that prevented the test case from being run.
+	Err error
+	// Duration is the time the test case took to run.
+	Duration time.Duration
+}
+
+// Passed returns true if the test case ran and found no differences.
+func (r Result) Passed() bool {
+	return r.Err == nil && len(r.Failures) == 0
+}
+
+// LoadCases loads the test cases from the supplied root directory. Every
+// directory containing a ResourceFile is a test case.
+func LoadCases(fs afero.Fs, root string) ([]Case, error) {
+	cases := []Case{}
+	err := afero.Walk(fs, root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || info.Name() != ResourceFile {
+			return nil
+		}
+		c, err := loadCase(fs, root, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		cases = append(cases, c)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errReadTests)
+	}
+	sort.Slice(cases, func(i, j int) bool { return cases[i].Name < cases[j].Name })
+	return cases, nil
+}
+
+func loadCase(fs afero.Fs, root, dir string) (Case, error) {
+	name, err := filepath.Rel(root, dir)
+	if err != nil {
+		return Case{}, err
+	}
+	c := Case{Name: filepath.ToSlash(name), Dir: dir}
+
+	res, err := readObjects(fs, filepath.Join(dir, ResourceFile))
+	if err != nil {
+		return Case{}, err
+	}
+	if len(res) == 0 {
+		return Case{}, errors.Errorf(errFmtNoObjects, filepath.Join(dir, ResourceFile))
+	}
+	c.Resource = res[0]
+
+	if c.Observed, err = readOptionalObjects(fs, filepath.Join(dir, ObservedFile)); err != nil {
+		return Case{}, err
+	}
+	if c.Expected, err = readOptionalObjects(fs, filepath.Join(dir, ExpectedFile)); err != nil {
+		return Case{}, err
+	}
+	return c, nil
+}
+
+// Run renders the composed resources of the supplied Case with the supplied
+// Composition and compares them with the expected composed resources.
+// Composed resources are matched by their
+// crossplane.io/composition-resource-name annotation, or by their position if
+// the Composition's templates are anonymous.
+func Run(ctx context.Context, c Case, xr *unstructured.Unstructured, comp *xpextv1.Composition) Result {
+	start := time.Now()
+	r := Result{Name: c.Name}
+
+	cds, err := Render(ctx, xr, comp, WithObserved(c.Observed...))
+	if err != nil {
+		r.Err = err
+		r.Duration = time.Since(start)
+		return r
+	}
+
+	got := map[string]*unstructured.Unstructured{}
+	for i, cd := range cds {
+		if cd.Err != nil {
+			r.Failures = append(r.Failures, cd.Err.Error())
+			continue
+		}
+		got[resourceKey(cd.Resource, i)] = cd.Resource
+	}
+	want := map[string]*unstructured.Unstructured{}
+	for i, e := range c.Expected {
+		want[resourceKey(e, i)] = e
+	}
+
+	for _, k := range sortedKeys(want) {
+		g, ok := got[k]
+		if !ok {
+			r.Failures = append(r.Failures, fmt.Sprintf(errFmtMissing, k))
+			continue
+		}
+		if diff := cmp.Diff(want[k].Object, g.Object); diff != "" {
+			r.Failures = append(r.Failures, fmt.Sprintf(errFmtDiff, k, diff))
+		}
+	}
+	for _, k := range sortedKeys(got) {
+		if _, ok := want[k]; !ok {
+			r.Failures = append(r.Failures, fmt.Sprintf(errFmtExtra, k))
+		}
+	}
+	r.Duration = time.Since(start)
+	return r
+}
+
+// WriteExpected renders the composed resources of the supplied Case and
+// writes them as its expected composed resources.
+func WriteExpected(ctx context.Context, fs afero.Fs, c Case, xr *unstructured.Unstructured, comp *xpextv1.Composition) error {
+	cds, err := Render(ctx, xr, comp, WithObserved(c.Observed...))
+	if err != nil {
+		return err
+	}
+	buf := &bytes.Buffer{}
+	for _, cd := range cds {
+		if cd.Err != nil {
+			return cd.Err
+		}
+		b, err := k8syaml.Marshal(cd.Resource.Object)
+		if err != nil {
+			return err
+		}
+		buf.WriteString("---\n")
+		buf.Write(b)
+	}
+	return afero.WriteFile(fs, filepath.Join(c.Dir, ExpectedFile), buf.Bytes(), 0o644)
+}
+
+// resourceKey returns the key a composed resource at the supplied position is
+// matched by.
+func resourceKey(u *unstructured.Unstructured, i int) string {
+	if n := icomposite.GetCompositionResourceName(u); n != "" {
+		return n
+	}
+	return strconv.Itoa(i)
+}
+
+func sortedKeys(m map[string]*unstructured.Unstructured) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func readOptionalObjects(fs afero.Fs, path string) ([]*unstructured.Unstructured, error) {
+	if _, err := fs.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return readObjects(fs, path)
+}
+
+// readObjects reads all objects of a multi-document YAML file.
+func readObjects(fs afero.Fs, path string) ([]*unstructured.Unstructured, error) {
+	f, err := fs.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtReadFile, path)
+	}
+	defer func() { _ = f.Close() }()
+
+	objs := []*unstructured.Unstructured{}
+	yr := yaml.NewYAMLReader(bufio.NewReader(f))
+	for {
+		b, err := yr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtReadFile, path)
+		}
+		if len(bytes.TrimSpace(b)) == 0 {
+			continue
+		}
+		u := &unstructured.Unstructured{}
+		if err := k8syaml.Unmarshal(b, u); err != nil {
+			return nil, errors.Wrapf(err, errFmtReadFile, path)
+		}
+		if len(u.Object) == 0 {
+			continue
+		}
+		objs = append(objs, u)
+	}
+	return objs, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+func TestRunCases(t *testing.T) {
+	comp := &xpextv1.Composition{}
+	unmarshal(t, testComposition, comp)
+
+	fs := afero.NewMemMapFs()
+	_ = afero.WriteFile(fs, "/tests/pass/"+ResourceFile, testXR, 0o644)
+	_ = afero.WriteFile(fs, "/tests/fail/"+ResourceFile, testXR, 0o644)
+	_ = afero.WriteFile(fs, "/tests/fail/"+ExpectedFile, []byte(`
+apiVersion: s3.aws.upbound.io/v1beta1
+kind: Bucket
+metadata:
+  annotations:
+    crossplane.io/composition-resource-name: bucket
+---
+apiVersion: s3.aws.upbound.io/v1beta1
+kind: Bucket
+metadata:
+  annotations:
+    crossplane.io/composition-resource-name: logs
+`), 0o644)
+
+	cases, err := LoadCases(fs, "/tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"fail", "pass"}, []string{cases[0].Name, cases[1].Name}); diff != "" {
+		t.Fatalf("\nLoadCases(...): -want, +got:\n%s", diff)
+	}
+
+	// Generate the expected output of the passing case.
+	pass := cases[1]
+	if err := WriteExpected(context.Background(), fs, pass, pass.Resource, comp); err != nil {
+		t.Fatal(err)
+	}
+	cases, err = LoadCases(fs, "/tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := make([]Result, len(cases))
+	for i, c := range cases {
+		results[i] = Run(context.Background(), c, c.Resource, comp)
+	}
+
+	if !results[1].Passed() {
+		t.Errorf("\nRun(...): expected %q to pass, got failures: %v", results[1].Name, results[1].Failures)
+	}
+	if results[0].Passed() {
+		t.Errorf("\nRun(...): expected %q to fail", results[0].Name)
+	}
+	if len(results[0].Failures) != 2 || !strings.HasPrefix(results[0].Failures[0], `composed resource "bucket": -want, +got`) ||
+		results[0].Failures[1] != fmt.Sprintf(errFmtMissing, "logs") {
+		t.Errorf("\nRun(...): unexpected failures: %v", results[0].Failures)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteJUnit(buf, "suite", results); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`<testsuite name="suite" tests="2" failures="1" errors="0"`, `<testcase name="fail" classname="suite"`, `<failure message="2 failures">`} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("\nWriteJUnit(...): expected report to contain %q, got:\n%s", s, buf.String())
+		}
+	}
+}
+
+func TestReadObjects(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	_ = afero.WriteFile(fs, "objs.yaml", []byte("---\napiVersion: v1\nkind: A\n---\n# comment only\n---\napiVersion: v1\nkind: B\n"), 0o644)
+
+	objs, err := readObjects(fs, "objs.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := []string{}
+	for _, o := range objs {
+		kinds = append(kinds, o.GetKind())
+	}
+	if diff := cmp.Diff([]string{"A", "B"}, kinds); diff != "" {
+		t.Errorf("\nreadObjects(...): -want, +got:\n%s", diff)
+	}
+
+	none, err := readOptionalObjects(fs, "missing.yaml")
+	if err != nil || none != nil {
+		t.Errorf("\nreadOptionalObjects(...): expected no objects and no error, got %v, %v", none, err)
+	}
+}